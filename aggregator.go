@@ -0,0 +1,105 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrAggregatorExists is returned when attaching an aggregator under a
+// name that is already in use on the timer.
+var ErrAggregatorExists = errors.New("aggregator already attached")
+
+// Aggregator is a user-defined summary of observed durations, such as a
+// proprietary sketch, attached to a Timer with AddAggregator.
+//
+// The timer calls all methods with its lock held, so implementations need
+// no synchronization of their own as long as they are only used through
+// the timer.
+type Aggregator interface {
+	// Observe records a duration.
+	Observe(d time.Duration)
+	// Snapshot returns the current summary.
+	Snapshot() any
+	// Reset clears the summary.
+	Reset()
+	// Merge folds other, an aggregator of the same kind, into this one.
+	Merge(other Aggregator) error
+}
+
+// mergeMutex serializes Timer.Merge, which holds two timer locks at once.
+var mergeMutex sync.Mutex
+
+// AddAggregator attaches a under name. It receives every later
+// observation and is reset along with the timer.
+func (t *Timer) AddAggregator(name string, a Aggregator) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.aggregators[name]; ok {
+		return fmt.Errorf("%w: %q", ErrAggregatorExists, name)
+	}
+	if t.aggregators == nil {
+		t.aggregators = make(map[string]Aggregator)
+	}
+	t.aggregators[name] = a
+	return nil
+}
+
+// RemoveAggregator detaches the aggregator named name, if any.
+func (t *Timer) RemoveAggregator(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.aggregators, name)
+}
+
+// AggregatorSnapshot returns the snapshot of the aggregator named name, or
+// false if none is attached.
+func (t *Timer) AggregatorSnapshot(name string) (any, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	a, ok := t.aggregators[name]
+	if !ok {
+		return nil, false
+	}
+	return a.Snapshot(), true
+}
+
+// AggregatorSnapshots returns the snapshots of all attached aggregators by
+// name.
+func (t *Timer) AggregatorSnapshots() map[string]any {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	snaps := make(map[string]any, len(t.aggregators))
+	for name, a := range t.aggregators {
+		snaps[name] = a.Snapshot()
+	}
+	return snaps
+}
+
+// Merge folds the statistics of o into t, along with each of o's
+// aggregators into t's aggregator of the same name. Aggregators attached
+// to only one of the timers are skipped. Returns the joined errors of the
+// aggregator merges; the statistics are merged regardless.
+func (t *Timer) Merge(o *Timer) error {
+	if t == o {
+		return errors.New("cannot merge timer into itself")
+	}
+	mergeMutex.Lock()
+	defer mergeMutex.Unlock()
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.setSnapshotNoLock(t.snapshotNoLock().Merge(o.snapshotNoLock()))
+	var errs []error
+	for name, a := range t.aggregators {
+		if oa, ok := o.aggregators[name]; ok {
+			if err := a.Merge(oa); err != nil {
+				errs = append(errs, fmt.Errorf("aggregator %q: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
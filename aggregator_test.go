@@ -0,0 +1,125 @@
+package timer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowCounter counts observations at or above a threshold.
+type slowCounter struct {
+	threshold time.Duration
+	n         int
+}
+
+func (c *slowCounter) Observe(d time.Duration) {
+	if d >= c.threshold {
+		c.n++
+	}
+}
+
+func (c *slowCounter) Snapshot() any { return c.n }
+func (c *slowCounter) Reset()        { c.n = 0 }
+
+func (c *slowCounter) Merge(other Aggregator) error {
+	o, ok := other.(*slowCounter)
+	if !ok {
+		return errors.New("not a slowCounter")
+	}
+	c.n += o.n
+	return nil
+}
+
+func TestAggregator(t *testing.T) {
+	timer := NewTimer()
+	if err := timer.AddAggregator("slow", &slowCounter{threshold: time.Second}); err != nil {
+		t.Fatalf("AddAggregator failed: %v", err)
+	}
+	if err := timer.AddAggregator("slow", &slowCounter{}); !errors.Is(err, ErrAggregatorExists) {
+		t.Errorf("Expected ErrAggregatorExists, got %v", err)
+	}
+
+	timer.Observe(time.Millisecond)
+	timer.Observe(2 * time.Second)
+	timer.Time(func() {})
+	if got, ok := timer.AggregatorSnapshot("slow"); !ok || got != 1 {
+		t.Errorf("AggregatorSnapshot = %v, %v; want 1, true", got, ok)
+	}
+	if snaps := timer.AggregatorSnapshots(); len(snaps) != 1 || snaps["slow"] != 1 {
+		t.Errorf("Unexpected snapshots: %v", snaps)
+	}
+
+	timer.Reset()
+	if got, _ := timer.AggregatorSnapshot("slow"); got != 0 {
+		t.Errorf("Expected aggregator reset with timer, got %v", got)
+	}
+	timer.RemoveAggregator("slow")
+	if _, ok := timer.AggregatorSnapshot("slow"); ok {
+		t.Errorf("Expected aggregator to be removed")
+	}
+}
+
+func TestTimerMerge(t *testing.T) {
+	a, b := NewTimer(), NewTimer()
+	_ = a.AddAggregator("slow", &slowCounter{threshold: time.Second})
+	_ = b.AddAggregator("slow", &slowCounter{threshold: time.Second})
+	_ = b.AddAggregator("only-b", &slowCounter{})
+	a.Observe(3 * time.Second)
+	b.Observe(time.Second)
+	b.Observe(time.Millisecond)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if a.Count() != 3 || a.Min() != time.Millisecond || a.Max() != 3*time.Second {
+		t.Errorf("Unexpected merged stats: %v", a)
+	}
+	if got, _ := a.AggregatorSnapshot("slow"); got != 2 {
+		t.Errorf("Merged aggregator = %v; want 2", got)
+	}
+	if b.Count() != 2 {
+		t.Errorf("Expected source timer unchanged, got count %d", b.Count())
+	}
+	if err := a.Merge(a); err == nil {
+		t.Errorf("Expected error merging timer into itself")
+	}
+}
+
+func TestTimerMergeAggregatorError(t *testing.T) {
+	a, b := NewTimer(), NewTimer()
+	_ = a.AddAggregator("x", &slowCounter{})
+	_ = b.AddAggregator("x", mismatched{})
+	b.Observe(time.Second)
+	if err := a.Merge(b); err == nil {
+		t.Errorf("Expected aggregator merge error")
+	}
+	if a.Count() != 1 {
+		t.Errorf("Expected stats merged despite aggregator error, got count %d", a.Count())
+	}
+}
+
+type mismatched struct{}
+
+func (mismatched) Observe(time.Duration)    {}
+func (mismatched) Snapshot() any            { return nil }
+func (mismatched) Reset()                   {}
+func (mismatched) Merge(o Aggregator) error { return nil }
+
+type panicky struct{ mismatched }
+
+func (panicky) Observe(time.Duration) { panic("observe") }
+
+func TestAggregatorPanicUnlocks(t *testing.T) {
+	timer := NewTimer()
+	if err := timer.AddAggregator("panicky", panicky{}); err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() { _ = recover() }()
+		timer.Observe(time.Millisecond)
+	}()
+	// the lock must have been released for this to return
+	if timer.Count() != 1 {
+		t.Errorf("Expected 1 observation, got %d", timer.Count())
+	}
+}
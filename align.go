@@ -0,0 +1,40 @@
+package timer
+
+import "time"
+
+// oneDay is the length of a calendar day without daylight saving changes.
+const oneDay = 24 * time.Hour
+
+// alignTime returns the start of the window of length d containing t,
+// with windows aligned to the wall clock in loc, or to UTC by
+// time.Truncate if loc is nil. Windows of whole days start at local
+// midnight, counting from a Monday, so weekly windows start on Mondays.
+// Shorter windows are aligned by the zone offset at t, so hourly windows
+// in a zone half an hour off UTC start on the local hour.
+func alignTime(t time.Time, d time.Duration, loc *time.Location) time.Time {
+	if loc == nil || d <= 0 {
+		return t.Truncate(d)
+	}
+	if d%oneDay == 0 {
+		days := int64(d / oneDay)
+		y, m, dd := t.In(loc).Date()
+		// days since Monday, January 5 1970
+		n := time.Date(y, m, dd, 0, 0, 0, 0, time.UTC).Unix()/int64(oneDay/time.Second) - 4
+		back := ((n % days) + days) % days
+		return time.Date(y, m, dd-int(back), 0, 0, 0, 0, loc)
+	}
+	_, off := t.In(loc).Zone()
+	shift := time.Duration(off) * time.Second
+	return t.Add(shift).Truncate(d).Add(-shift)
+}
+
+// nextAligned returns the start of the window after the one containing t,
+// see alignTime. Windows of whole days account for daylight saving
+// changes.
+func nextAligned(t time.Time, d time.Duration, loc *time.Location) time.Time {
+	start := alignTime(t, d, loc)
+	if loc == nil || d <= 0 || d%oneDay != 0 {
+		return start.Add(d)
+	}
+	return start.AddDate(0, 0, int(d/oneDay))
+}
@@ -0,0 +1,76 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAlignTime(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	at := time.Date(2024, 3, 6, 10, 45, 30, 0, ist) // a Wednesday
+	tests := []struct {
+		d    time.Duration
+		loc  *time.Location
+		want time.Time
+	}{
+		{time.Minute, nil, at.Truncate(time.Minute)},
+		{time.Hour, nil, at.Truncate(time.Hour)},
+		{time.Hour, ist, time.Date(2024, 3, 6, 10, 0, 0, 0, ist)},
+		{15 * time.Minute, ist, time.Date(2024, 3, 6, 10, 45, 0, 0, ist)},
+		{24 * time.Hour, ist, time.Date(2024, 3, 6, 0, 0, 0, 0, ist)},
+		{7 * 24 * time.Hour, ist, time.Date(2024, 3, 4, 0, 0, 0, 0, ist)},
+	}
+	for _, tt := range tests {
+		if got := alignTime(at, tt.d, tt.loc); !got.Equal(tt.want) {
+			t.Errorf("alignTime(%v, %v) = %v; want %v", tt.d, tt.loc, got, tt.want)
+		}
+	}
+	if next := nextAligned(at, 24*time.Hour, ist); !next.Equal(time.Date(2024, 3, 7, 0, 0, 0, 0, ist)) {
+		t.Errorf("Expected the next day to start at midnight, got %v", next)
+	}
+}
+
+func TestAlignTimeDaylightSaving(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	// clocks go forward on March 10 2024
+	start := alignTime(time.Date(2024, 3, 10, 12, 0, 0, 0, ny), 24*time.Hour, ny)
+	next := nextAligned(start, 24*time.Hour, ny)
+	if !next.Equal(time.Date(2024, 3, 11, 0, 0, 0, 0, ny)) || next.Sub(start) != 23*time.Hour {
+		t.Errorf("Expected a 23h day ending at midnight, got %v..%v", start, next)
+	}
+}
+
+func TestReporterAligned(t *testing.T) {
+	defer checkNoLeaks(t)
+	const interval = 100 * time.Millisecond
+	var mu sync.Mutex
+	var times []time.Time
+	rp := NewReporter(NewRegistry(), interval, func(map[string]Snapshot) {
+		mu.Lock()
+		defer mu.Unlock()
+		times = append(times, time.Now())
+	})
+	rp.SetAligned(true)
+	if err := rp.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	time.Sleep(350 * time.Millisecond)
+	_ = rp.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) < 3 {
+		t.Fatalf("Expected at least 3 reports, got %d", len(times))
+	}
+	// the last report is Close's
+	for _, at := range times[:len(times)-1] {
+		if off := at.Sub(at.Truncate(interval)); off > interval/2 {
+			t.Errorf("Expected reports on %v boundaries, got one %v after", interval, off)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// The default configuration promises zero allocations on the hot paths.
+func TestZeroAllocs(t *testing.T) {
+	if testing.CoverMode() != "" || raceEnabled {
+		t.Skip("allocation counts are not meaningful under coverage or race instrumentation")
+	}
+
+	timer := NewTimer()
+	start := time.Now().Add(-time.Millisecond)
+	buf := make([]byte, 0, 256)
+	timer.Observe(time.Hour) // make sure every formatted field is non-trivial
+
+	tests := map[string]func(){
+		"Observe":      func() { timer.Observe(time.Millisecond) },
+		"Update":       func() { _ = timer.Update(start) },
+		"Snapshot":     func() { _ = timer.Snapshot() },
+		"Mean":         func() { _ = timer.Mean() },
+		"AppendString": func() { buf = timer.AppendString(buf[:0]) },
+	}
+	for name, fn := range tests {
+		if allocs := testing.AllocsPerRun(100, fn); allocs != 0 {
+			t.Errorf("%s allocated %v times per run; want 0", name, allocs)
+		}
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(1500 * time.Microsecond)
+
+	got := string(timer.AppendString([]byte("prefix ")))
+	if want := "prefix " + timer.String(); got != want {
+		t.Errorf("AppendString = %q; want %q", got, want)
+	}
+}
+
+func BenchmarkTimerAppendString(b *testing.B) {
+	timer := NewTimer()
+	timer.Observe(time.Millisecond)
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for b.Loop() {
+		buf = timer.AppendString(buf[:0])
+	}
+}
+
+func TestStringAllocs(t *testing.T) {
+	if testing.CoverMode() != "" || raceEnabled {
+		t.Skip("allocation counts are not meaningful under coverage or race instrumentation")
+	}
+	timer := NewTimer()
+	timer.Observe(time.Millisecond)
+	if allocs := testing.AllocsPerRun(100, func() { _ = timer.String() }); allocs > 1 {
+		t.Errorf("String allocated %v times per run; want at most 1", allocs)
+	}
+}
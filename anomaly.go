@@ -0,0 +1,239 @@
+package timer
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AnomalyMethod selects how an AnomalyDetector scores an interval against
+// the history of previous intervals.
+type AnomalyMethod int
+
+const (
+	// AnomalyZScore scores by the number of standard deviations from the
+	// mean of the history.
+	AnomalyZScore AnomalyMethod = iota
+	// AnomalyMAD scores by the modified z-score, using the median and the
+	// median absolute deviation of the history, which single outliers in
+	// the history do not skew.
+	AnomalyMAD
+)
+
+// AnomalyConfig configures an AnomalyDetector.
+type AnomalyConfig struct {
+	// Method is the scoring method. Defaults to AnomalyZScore.
+	Method AnomalyMethod
+	// Threshold is the absolute score above which an interval is an
+	// anomaly. Defaults to 3 for AnomalyZScore and 3.5 for AnomalyMAD.
+	Threshold float64
+	// Window is the number of previous intervals per timer kept as
+	// history. Defaults to 30.
+	Window int
+	// MinHistory is the number of intervals a timer needs before its
+	// intervals are scored. Defaults to 8.
+	MinHistory int
+	// Metric reduces an interval to the value scored. It receives the
+	// difference of consecutive cumulative snapshots, whose Count and Sum
+	// cover the interval only; Min and Max are the cumulative ones.
+	// Defaults to Snapshot.Mean.
+	Metric func(Snapshot) time.Duration
+	// OnAnomaly, if not nil, is called for every anomaly found, after the
+	// detector's lock is released.
+	OnAnomaly func(Anomaly)
+}
+
+// Anomaly is an interval whose metric deviates from the timer's history.
+type Anomaly struct {
+	Time     time.Time
+	Name     string
+	Value    time.Duration
+	Baseline time.Duration // mean or median of the history
+	Score    float64       // signed, positive when Value is above Baseline
+}
+
+// maxAnomalies is the number of anomalies returned by Anomalies.
+const maxAnomalies = 256
+
+// AnomalyDetector flags intervals whose latency deviates from the recent
+// history of the same timer. Use it as the Exporter of a Reporter, or feed
+// it the records of an IntervalStore with Add. Snapshots are cumulative,
+// as a Reporter exports them; a timer whose count went down is taken to
+// have been reset. Intervals without observations are skipped.
+type AnomalyDetector struct {
+	mutex     sync.Mutex
+	cfg       AnomalyConfig
+	series    map[string]*anomalySeries
+	anomalies []Anomaly
+	now       func() time.Time
+}
+
+// anomalySeries is the state of one timer.
+type anomalySeries struct {
+	last    Snapshot  // cumulative snapshot of the previous interval
+	history []float64 // metric of the previous intervals, oldest first
+}
+
+// NewAnomalyDetector creates an AnomalyDetector.
+func NewAnomalyDetector(cfg AnomalyConfig) *AnomalyDetector {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 3
+		if cfg.Method == AnomalyMAD {
+			cfg.Threshold = 3.5
+		}
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 30
+	}
+	if cfg.MinHistory <= 0 {
+		cfg.MinHistory = 8
+	}
+	cfg.MinHistory = min(cfg.MinHistory, cfg.Window)
+	if cfg.Metric == nil {
+		cfg.Metric = Snapshot.Mean
+	}
+	return &AnomalyDetector{cfg: cfg, series: make(map[string]*anomalySeries), now: time.Now}
+}
+
+// Export scores one interval of every timer in snaps, stamped with the
+// current time, implementing Exporter.
+func (d *AnomalyDetector) Export(snaps map[string]Snapshot) error {
+	now := d.now()
+	var found []Anomaly
+	d.mutex.Lock()
+	for _, name := range sortedNames(snaps) {
+		if a, ok := d.addNoLock(now, name, snaps[name]); ok {
+			found = append(found, a)
+		}
+	}
+	d.mutex.Unlock()
+	d.notify(found)
+	return nil
+}
+
+// Add scores one interval of a single timer, such as a Record returned by
+// IntervalStore.Query, and returns the anomaly if it is one. Records of a
+// timer must be added in time order.
+func (d *AnomalyDetector) Add(rec Record) (Anomaly, bool) {
+	d.mutex.Lock()
+	a, ok := d.addNoLock(rec.Time, rec.Name, rec.Snapshot)
+	d.mutex.Unlock()
+	if ok {
+		d.notify([]Anomaly{a})
+	}
+	return a, ok
+}
+
+// Anomalies returns the most recent anomalies found, oldest first.
+func (d *AnomalyDetector) Anomalies() []Anomaly {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return slices.Clone(d.anomalies)
+}
+
+// notify calls OnAnomaly for each anomaly.
+func (d *AnomalyDetector) notify(found []Anomaly) {
+	if d.cfg.OnAnomaly == nil {
+		return
+	}
+	for _, a := range found {
+		d.cfg.OnAnomaly(a)
+	}
+}
+
+// addNoLock scores the interval ending with the cumulative snapshot s and
+// appends it to the history.
+// Callers must hold the lock.
+func (d *AnomalyDetector) addNoLock(t time.Time, name string, s Snapshot) (Anomaly, bool) {
+	ser := d.series[name]
+	if ser == nil {
+		ser = &anomalySeries{}
+		d.series[name] = ser
+	}
+	interval := intervalSnapshot(ser.last, s)
+	ser.last = s
+	if interval.Count == 0 {
+		return Anomaly{}, false
+	}
+	v := float64(d.cfg.Metric(interval))
+
+	var a Anomaly
+	var found bool
+	if len(ser.history) >= d.cfg.MinHistory {
+		baseline, score := d.score(ser.history, v)
+		if math.Abs(score) > d.cfg.Threshold {
+			a = Anomaly{Time: t, Name: name, Value: time.Duration(v), Baseline: time.Duration(baseline), Score: score}
+			found = true
+			d.anomalies = append(d.anomalies, a)
+			if len(d.anomalies) > maxAnomalies {
+				d.anomalies = slices.Delete(d.anomalies, 0, len(d.anomalies)-maxAnomalies)
+			}
+		}
+	}
+	ser.history = append(ser.history, v)
+	if len(ser.history) > d.cfg.Window {
+		ser.history = slices.Delete(ser.history, 0, len(ser.history)-d.cfg.Window)
+	}
+	return a, found
+}
+
+// score returns the baseline of history and the score of v against it.
+// A history without spread scores any other value as infinite.
+func (d *AnomalyDetector) score(history []float64, v float64) (baseline, score float64) {
+	var spread float64
+	if d.cfg.Method == AnomalyMAD {
+		baseline = median(slices.Clone(history))
+		dev := make([]float64, len(history))
+		for i, x := range history {
+			dev[i] = math.Abs(x - baseline)
+		}
+		// 0.6745 makes the MAD consistent with the standard deviation
+		spread = median(dev) / 0.6745
+	} else {
+		for _, x := range history {
+			baseline += x
+		}
+		baseline /= float64(len(history))
+		for _, x := range history {
+			spread += (x - baseline) * (x - baseline)
+		}
+		spread = math.Sqrt(spread / float64(len(history)))
+	}
+	switch {
+	case spread > 0:
+		return baseline, (v - baseline) / spread
+	case v > baseline:
+		return baseline, math.Inf(1)
+	case v < baseline:
+		return baseline, math.Inf(-1)
+	}
+	return baseline, 0
+}
+
+// median returns the median of xs, reordering it.
+func median(xs []float64) float64 {
+	slices.Sort(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
+
+// intervalSnapshot returns the observations made between the cumulative
+// snapshots prev and cur. Count, Sum, and Panicked cover the interval;
+// Min and Max are those of cur. If cur has fewer observations than prev,
+// the timer was reset and cur is returned.
+func intervalSnapshot(prev, cur Snapshot) Snapshot {
+	if cur.Count < prev.Count || cur.Panicked < prev.Panicked || cur.SumOverflowed {
+		return cur
+	}
+	return Snapshot{
+		Count:    cur.Count - prev.Count,
+		Min:      cur.Min,
+		Max:      cur.Max,
+		Sum:      cur.Sum - prev.Sum,
+		Panicked: cur.Panicked - prev.Panicked,
+	}
+}
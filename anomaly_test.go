@@ -0,0 +1,76 @@
+package timer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	var notified []Anomaly
+	d := NewAnomalyDetector(AnomalyConfig{MinHistory: 5, OnAnomaly: func(a Anomaly) {
+		notified = append(notified, a)
+	}})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	// cumulative snapshots with interval means alternating 9ms and 11ms
+	var cum Snapshot
+	for i := range 10 {
+		mean := 9 * time.Millisecond
+		if i%2 == 1 {
+			mean = 11 * time.Millisecond
+		}
+		cum = cum.Merge(Snapshot{Count: 10, Min: mean, Max: mean, Sum: 10 * mean})
+		d.Export(map[string]Snapshot{"db": cum})
+		now = now.Add(time.Minute)
+	}
+	if len(d.Anomalies()) != 0 {
+		t.Fatalf("Expected no anomalies in a stable history, got %v", d.Anomalies())
+	}
+
+	// an unchanged snapshot has no observations and is skipped
+	d.Export(map[string]Snapshot{"db": cum})
+
+	cum = cum.Merge(Snapshot{Count: 10, Min: 50 * time.Millisecond, Max: 50 * time.Millisecond, Sum: 500 * time.Millisecond})
+	d.Export(map[string]Snapshot{"db": cum})
+	got := d.Anomalies()
+	if len(got) != 1 || len(notified) != 1 {
+		t.Fatalf("Expected 1 anomaly reported and notified, got %v and %v", got, notified)
+	}
+	a := got[0]
+	if a.Name != "db" || a.Value != 50*time.Millisecond || a.Baseline != 10*time.Millisecond || a.Score != 40 || !a.Time.Equal(now) {
+		t.Errorf("Unexpected anomaly %+v", a)
+	}
+}
+
+func TestAnomalyDetectorMAD(t *testing.T) {
+	d := NewAnomalyDetector(AnomalyConfig{Method: AnomalyMAD, Window: 5, MinHistory: 5})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// a constant history flags any change
+	var cum Snapshot
+	for i, mean := range []time.Duration{10, 10, 10, 10, 10, 10, 12} {
+		cum = cum.Merge(Snapshot{Count: 1, Min: mean, Max: mean, Sum: mean})
+		a, ok := d.Add(Record{Time: start.Add(time.Duration(i) * time.Minute), Name: "api", Snapshot: cum})
+		if want := i == 6; ok != want {
+			t.Errorf("Add(%d) reported anomaly %v; want %v", i, ok, want)
+		}
+		if ok && (a.Baseline != 10 || !math.IsInf(a.Score, 1)) {
+			t.Errorf("Expected infinite score against baseline 10ns, got %+v", a)
+		}
+	}
+}
+
+func TestIntervalSnapshot(t *testing.T) {
+	prev := Snapshot{Count: 10, Min: 1, Max: 9, Sum: 50}
+	cur := Snapshot{Count: 15, Min: 1, Max: 20, Sum: 100, Panicked: 1}
+	want := Snapshot{Count: 5, Min: 1, Max: 20, Sum: 50, Panicked: 1}
+	if got := intervalSnapshot(prev, cur); got != want {
+		t.Errorf("intervalSnapshot() = %+v; want %+v", got, want)
+	}
+	// a timer reset since prev
+	reset := Snapshot{Count: 3, Min: 2, Max: 4, Sum: 9}
+	if got := intervalSnapshot(prev, reset); got != reset {
+		t.Errorf("intervalSnapshot() after reset = %+v; want %+v", got, reset)
+	}
+}
@@ -0,0 +1,147 @@
+package timer
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"time"
+)
+
+// ErrInvalidInterval is returned by MergeIntervals for an interval ending
+// before it starts.
+var ErrInvalidInterval = errors.New("interval ends before it starts")
+
+// Interval is the observations a timer made between Start and End. Unlike
+// a Record, which holds a cumulative snapshot at a point in time, an
+// interval stands on its own, so intervals of different processes can be
+// merged whatever their tickers.
+type Interval struct {
+	Name       string
+	Start, End time.Time
+	Snapshot   Snapshot
+}
+
+// Intervals converts cumulative records, as an IntervalStore stores them,
+// into intervals between consecutive records of each timer. Records may
+// be in any order; records of a timer at the same time are duplicates, of
+// which the last is kept. The first record of each timer only serves as
+// the start of its first interval, and intervals without observations are
+// skipped. The Min and Max of an interval are those of the later
+// cumulative snapshot. The result is sorted by name and start.
+func Intervals(recs []Record) []Interval {
+	recs = slices.Clone(recs)
+	slices.SortStableFunc(recs, func(a, b Record) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), a.Time.Compare(b.Time))
+	})
+	var out []Interval
+	for i, rec := range recs {
+		if i+1 < len(recs) && recs[i+1].Name == rec.Name && recs[i+1].Time.Equal(rec.Time) {
+			// a later duplicate replaces this one
+			continue
+		}
+		prev := i - 1
+		for prev >= 0 && recs[prev].Name == rec.Name && recs[prev].Time.Equal(rec.Time) {
+			prev--
+		}
+		if prev < 0 || recs[prev].Name != rec.Name {
+			continue
+		}
+		s := intervalSnapshot(recs[prev].Snapshot, rec.Snapshot)
+		if s.Count == 0 {
+			continue
+		}
+		out = append(out, Interval{Name: rec.Name, Start: recs[prev].Time, End: rec.Time, Snapshot: s})
+	}
+	return out
+}
+
+// MergeIntervals sums the interval histories of several processes into
+// windows of length step aligned to the wall clock, as time.Truncate
+// aligns them. The intervals of a history may be in any order and
+// overlap the intervals of other histories; an interval within a history
+// with the same name, start and end as an earlier one is a resent
+// duplicate and replaces it. An interval spanning several windows is
+// split between them in proportion to its overlap with each, assuming
+// its observations were spread evenly; Min and Max are copied to every
+// part. An interval with no length counts in the window containing it.
+// The result holds the windows with observations, sorted by name and
+// start.
+// Returns ErrInvalidInterval if an interval ends before it starts.
+func MergeIntervals(step time.Duration, histories ...[]Interval) ([]Interval, error) {
+	if step <= 0 {
+		return nil, errors.New("interval step must be positive")
+	}
+	type key struct {
+		name       string
+		start, end int64
+	}
+	type window struct {
+		name  string
+		start int64
+	}
+	sums := make(map[window]Snapshot)
+	for _, history := range histories {
+		latest := make(map[key]Interval, len(history))
+		for _, iv := range history {
+			if iv.End.Before(iv.Start) {
+				return nil, ErrInvalidInterval
+			}
+			latest[key{iv.Name, iv.Start.UnixNano(), iv.End.UnixNano()}] = iv
+		}
+		for _, iv := range latest {
+			splitInterval(iv, step, func(start time.Time, part Snapshot) {
+				w := window{iv.Name, start.UnixNano()}
+				sums[w] = sums[w].Merge(part)
+			})
+		}
+	}
+	out := make([]Interval, 0, len(sums))
+	for w, s := range sums {
+		start := time.Unix(0, w.start)
+		out = append(out, Interval{Name: w.name, Start: start, End: start.Add(step), Snapshot: s})
+	}
+	slices.SortFunc(out, func(a, b Interval) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), a.Start.Compare(b.Start))
+	})
+	return out, nil
+}
+
+// splitInterval calls add with the part of iv falling into each window
+// of length step it overlaps. Counts and sums are split by rounding their
+// running totals, so the parts add up to iv exactly.
+func splitInterval(iv Interval, step time.Duration, add func(time.Time, Snapshot)) {
+	s := iv.Snapshot
+	if s.Count == 0 {
+		return
+	}
+	total := iv.End.Sub(iv.Start)
+	if total == 0 {
+		add(iv.Start.Truncate(step), s)
+		return
+	}
+	var done Snapshot
+	for ws := iv.Start.Truncate(step); ws.Before(iv.End); ws = ws.Add(step) {
+		upto := s
+		if we := ws.Add(step); we.Before(iv.End) {
+			frac := float64(we.Sub(iv.Start)) / float64(total)
+			upto = Snapshot{
+				Count:    uint64(math.Round(float64(s.Count) * frac)),
+				Sum:      time.Duration(math.Round(float64(s.Sum) * frac)),
+				Panicked: uint64(math.Round(float64(s.Panicked) * frac)),
+			}
+		}
+		part := Snapshot{
+			Count:         upto.Count - done.Count,
+			Min:           s.Min,
+			Max:           s.Max,
+			Sum:           upto.Sum - done.Sum,
+			SumOverflowed: s.SumOverflowed,
+			Panicked:      upto.Panicked - done.Panicked,
+		}
+		done = upto
+		if part.Count > 0 {
+			add(ws, part)
+		}
+	}
+}
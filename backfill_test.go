@@ -0,0 +1,81 @@
+package timer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntervals(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recs := []Record{
+		{Time: t0.Add(2 * time.Minute), Name: "a", Snapshot: Snapshot{Count: 5, Sum: 50}},
+		{Time: t0, Name: "a", Snapshot: Snapshot{Count: 1, Sum: 10}},
+		{Time: t0.Add(time.Minute), Name: "a", Snapshot: Snapshot{Count: 2, Sum: 20}},
+		// resent with more observations, replaces the previous one
+		{Time: t0.Add(time.Minute), Name: "a", Snapshot: Snapshot{Count: 3, Sum: 30}},
+		{Time: t0, Name: "b", Snapshot: Snapshot{Count: 1, Sum: 1}},
+	}
+	got := Intervals(recs)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 intervals, got %+v", got)
+	}
+	if got[0].Name != "a" || !got[0].Start.Equal(t0) || got[0].Snapshot.Count != 2 || got[0].Snapshot.Sum != 20 {
+		t.Errorf("Expected 2 observations in the first minute, got %+v", got[0])
+	}
+	if !got[1].Start.Equal(t0.Add(time.Minute)) || got[1].Snapshot.Count != 2 || got[1].Snapshot.Sum != 20 {
+		t.Errorf("Expected 2 observations in the second minute, got %+v", got[1])
+	}
+}
+
+func TestMergeIntervals(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// one process reports every minute, out of order and with a resend
+	a := []Interval{
+		{Name: "x", Start: t0.Add(time.Minute), End: t0.Add(2 * time.Minute), Snapshot: Snapshot{Count: 4, Min: 1, Max: 9, Sum: 40}},
+		{Name: "x", Start: t0, End: t0.Add(time.Minute), Snapshot: Snapshot{Count: 2, Min: 1, Max: 9, Sum: 20}},
+		{Name: "x", Start: t0, End: t0.Add(time.Minute), Snapshot: Snapshot{Count: 2, Min: 1, Max: 9, Sum: 20}},
+	}
+	// another every 90s, offset by 30s
+	b := []Interval{
+		{Name: "x", Start: t0.Add(30 * time.Second), End: t0.Add(2 * time.Minute), Snapshot: Snapshot{Count: 3, Min: 2, Max: 20, Sum: 300}},
+	}
+	got, err := MergeIntervals(time.Minute, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Snapshot{
+		{Count: 3, Min: 1, Max: 20, Sum: 120},
+		{Count: 6, Min: 1, Max: 20, Sum: 240},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d windows, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if !got[i].Start.Equal(t0.Add(time.Duration(i)*time.Minute)) || got[i].End.Sub(got[i].Start) != time.Minute {
+			t.Errorf("window %d = %v..%v; want aligned minutes", i, got[i].Start, got[i].End)
+		}
+		if got[i].Snapshot != w {
+			t.Errorf("window %d = %+v; want %+v", i, got[i].Snapshot, w)
+		}
+	}
+
+	_, err = MergeIntervals(time.Minute, []Interval{{Start: t0.Add(time.Second), End: t0, Snapshot: Snapshot{Count: 1}}})
+	if !errors.Is(err, ErrInvalidInterval) {
+		t.Errorf("Expected ErrInvalidInterval, got %v", err)
+	}
+}
+
+func TestSplitIntervalConservesTotals(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 7, 0, time.UTC)
+	iv := Interval{Start: t0, End: t0.Add(7*time.Minute + 13*time.Second), Snapshot: Snapshot{Count: 1001, Sum: 123457, Panicked: 7}}
+	var sum Snapshot
+	splitInterval(iv, time.Minute, func(_ time.Time, part Snapshot) {
+		sum.Count += part.Count
+		sum.Sum += part.Sum
+		sum.Panicked += part.Panicked
+	})
+	if sum.Count != 1001 || sum.Sum != 123457 || sum.Panicked != 7 {
+		t.Errorf("Expected the parts to add up to the interval, got %+v", sum)
+	}
+}
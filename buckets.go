@@ -0,0 +1,78 @@
+package timer
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Curated bucket upper bounds for HeatmapRecorder and other bucketed
+// summaries. They are shared; clone them before modifying.
+var (
+	// WebLatencyBuckets suit HTTP and RPC handlers, from 5ms to 10s.
+	WebLatencyBuckets = []time.Duration{
+		5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond,
+		50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+		500 * time.Millisecond, time.Second, 2500 * time.Millisecond,
+		5 * time.Second, 10 * time.Second,
+	}
+	// DBLatencyBuckets suit database queries and cache lookups, from
+	// 100µs to 1s.
+	DBLatencyBuckets = []time.Duration{
+		100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+		time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+		10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+		100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+		time.Second,
+	}
+	// GCBuckets suit garbage collector pauses, from 10µs to 50ms.
+	GCBuckets = []time.Duration{
+		10 * time.Microsecond, 25 * time.Microsecond, 50 * time.Microsecond,
+		100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+		time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+		10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	}
+	// SubMicrosecondBuckets suit in-memory operations, from 10ns to 10µs.
+	SubMicrosecondBuckets = []time.Duration{
+		10 * time.Nanosecond, 25 * time.Nanosecond, 50 * time.Nanosecond,
+		100 * time.Nanosecond, 250 * time.Nanosecond, 500 * time.Nanosecond,
+		time.Microsecond, 2500 * time.Nanosecond, 5 * time.Microsecond,
+		10 * time.Microsecond,
+	}
+)
+
+// LinearBuckets returns count bounds, the first being start and each
+// following one width larger. It panics if count < 1 or width <= 0.
+func LinearBuckets(start, width time.Duration, count int) []time.Duration {
+	if count < 1 || width <= 0 {
+		panic(fmt.Sprintf("timer: invalid linear buckets: width %v, count %d", width, count))
+	}
+	bounds := make([]time.Duration, count)
+	for i := range bounds {
+		bounds[i] = start + time.Duration(i)*width
+	}
+	return bounds
+}
+
+// ExponentialBuckets returns count bounds, the first being start and each
+// following one factor times larger, rounded to the nanosecond. It panics
+// if count < 1, start <= 0, factor <= 1, or the bounds would not be
+// strictly increasing or would overflow.
+func ExponentialBuckets(start time.Duration, factor float64, count int) []time.Duration {
+	if count < 1 || start <= 0 || factor <= 1 {
+		panic(fmt.Sprintf("timer: invalid exponential buckets: start %v, factor %v, count %d", start, factor, count))
+	}
+	bounds := make([]time.Duration, count)
+	v := float64(start)
+	for i := range bounds {
+		if v >= math.MaxInt64 {
+			panic(fmt.Sprintf("timer: exponential bucket %d overflows", i))
+		}
+		bounds[i] = time.Duration(math.Round(v))
+		if i > 0 && bounds[i] <= bounds[i-1] {
+			panic(fmt.Sprintf("timer: exponential buckets not increasing at %v", bounds[i]))
+		}
+		v *= factor
+	}
+	return bounds
+}
@@ -0,0 +1,59 @@
+package timer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBucketPresets(t *testing.T) {
+	for name, bounds := range map[string][]time.Duration{
+		"Web":            WebLatencyBuckets,
+		"DB":             DBLatencyBuckets,
+		"GC":             GCBuckets,
+		"SubMicrosecond": SubMicrosecondBuckets,
+	} {
+		if len(bounds) == 0 || !slices.IsSorted(bounds) || len(slices.Compact(slices.Clone(bounds))) != len(bounds) {
+			t.Errorf("%s buckets are not strictly increasing: %v", name, bounds)
+		}
+		if _, err := NewHeatmapRecorder(bounds, time.Minute, 1); err != nil {
+			t.Errorf("%s buckets rejected by HeatmapRecorder: %v", name, err)
+		}
+	}
+}
+
+func TestLinearBuckets(t *testing.T) {
+	got := LinearBuckets(time.Millisecond, 2*time.Millisecond, 3)
+	want := []time.Duration{time.Millisecond, 3 * time.Millisecond, 5 * time.Millisecond}
+	if !slices.Equal(got, want) {
+		t.Errorf("LinearBuckets = %v; want %v", got, want)
+	}
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	got := ExponentialBuckets(time.Millisecond, 2.5, 4)
+	want := []time.Duration{time.Millisecond, 2500 * time.Microsecond, 6250 * time.Microsecond, 15625 * time.Microsecond}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExponentialBuckets = %v; want %v", got, want)
+	}
+}
+
+func TestBucketGeneratorsPanic(t *testing.T) {
+	for name, f := range map[string]func(){
+		"zero width":     func() { LinearBuckets(0, 0, 3) },
+		"zero count":     func() { LinearBuckets(0, 1, 0) },
+		"factor 1":       func() { ExponentialBuckets(1, 1, 3) },
+		"not increasing": func() { ExponentialBuckets(1, 1.1, 3) },
+		"overflow":       func() { ExponentialBuckets(time.Hour, 1000, 10) },
+		"zero start":     func() { ExponentialBuckets(0, 2, 3) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,59 @@
+package timer
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// callerNames caches timer names derived from call sites, keyed by PC.
+var callerNames sync.Map
+
+// ObserveHere records d in the DefaultRegistry timer named after the calling
+// function, creating the timer on first use. Names have the form
+// "package.Function" (e.g. "store.(*DB).Get" or "main.main.func1"), where
+// package is the last element of the import path.
+func ObserveHere(d time.Duration) {
+	DefaultRegistry.GetOrCreate(callerName(2)).Observe(d)
+}
+
+// TrackHere starts timing and returns a function that records the elapsed
+// time in the DefaultRegistry timer named after the calling function.
+// It is designed for use with defer:
+//
+//	defer timer.TrackHere()()
+func TrackHere() func() {
+	t := DefaultRegistry.GetOrCreate(callerName(2))
+	start := time.Now()
+	return func() {
+		t.Observe(max(time.Since(start), 0))
+	}
+}
+
+// callerName returns the timer name for the function skip frames above
+// callerName. Returns "unknown" if the caller cannot be determined.
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	if name, ok := callerNames.Load(pc); ok {
+		return name.(string)
+	}
+	name := "unknown"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = shortFuncName(fn.Name())
+	}
+	callerNames.Store(pc, name)
+	return name
+}
+
+// shortFuncName strips the import path from a fully-qualified function name,
+// keeping the package name.
+func shortFuncName(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,54 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func observeHereHelper() {
+	ObserveHere(time.Millisecond)
+}
+
+func trackHereHelper() {
+	defer TrackHere()()
+}
+
+func TestObserveHere(t *testing.T) {
+	observeHereHelper()
+	observeHereHelper()
+
+	t0 := DefaultRegistry.Get("go-timer.observeHereHelper")
+	if t0 == nil {
+		t.Fatalf("Expected timer named after caller, got registry %v", DefaultRegistry.Snapshot())
+	}
+	if t0.Count() != 2 {
+		t.Errorf("Expected count to be 2, got %d", t0.Count())
+	}
+	DefaultRegistry.Unregister("go-timer.observeHereHelper")
+}
+
+func TestTrackHere(t *testing.T) {
+	trackHereHelper()
+
+	t0 := DefaultRegistry.Get("go-timer.trackHereHelper")
+	if t0 == nil {
+		t.Fatalf("Expected timer named after caller, got registry %v", DefaultRegistry.Snapshot())
+	}
+	if t0.Count() != 1 {
+		t.Errorf("Expected count to be 1, got %d", t0.Count())
+	}
+	DefaultRegistry.Unregister("go-timer.trackHereHelper")
+}
+
+func TestShortFuncName(t *testing.T) {
+	tests := map[string]string{
+		"github.com/jnpr-pranav/go-timer.TestShortFuncName": "go-timer.TestShortFuncName",
+		"example.com/store.(*DB).Get":                       "store.(*DB).Get",
+		"main.main.func1":                                   "main.main.func1",
+	}
+	for in, want := range tests {
+		if got := shortFuncName(in); got != want {
+			t.Errorf("shortFuncName(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package timer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// ErrChecksum is returned by LoadDump when a checkpoint's checksum does
+// not match its contents.
+var ErrChecksum = errors.New("snapshot checkpoint checksum mismatch")
+
+// ErrInvalidSnapshot is returned by Snapshot.Validate for statistics that
+// no timer could have produced.
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// checkpointMagic starts a framed checkpoint written by SaveDump.
+var checkpointMagic = []byte("TMRC")
+
+// crcTable is the Castagnoli table, which has hardware support on common
+// platforms.
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SaveDump writes d to w as a checkpoint: the magic "TMRC", the length of
+// the binary encoding of d as a big-endian uint32, the encoding itself,
+// and its CRC-32C as a big-endian uint32.
+func SaveDump(w io.Writer, d Dump) error {
+	payload, err := d.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if uint64(len(payload)) > math.MaxUint32 {
+		return fmt.Errorf("%w: dump too large", ErrInvalidDump)
+	}
+	b := make([]byte, 0, len(checkpointMagic)+len(payload)+8)
+	b = append(b, checkpointMagic...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(payload)))
+	b = append(b, payload...)
+	b = binary.BigEndian.AppendUint32(b, crc32.Checksum(payload, crcTable))
+	_, err = w.Write(b)
+	return err
+}
+
+// LoadDump reads a checkpoint written by SaveDump from r and validates
+// every snapshot in it. Returns an error wrapping ErrInvalidDump for a
+// malformed or truncated checkpoint, ErrChecksum if the contents are
+// corrupted, and ErrInvalidSnapshot if a snapshot is inconsistent.
+func LoadDump(r io.Reader) (Dump, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Dump{}, fmt.Errorf("%w: reading header: %v", ErrInvalidDump, err)
+	}
+	if !bytes.Equal(header[:4], checkpointMagic) {
+		return Dump{}, fmt.Errorf("%w: missing checkpoint magic", ErrInvalidDump)
+	}
+	n := binary.BigEndian.Uint32(header[4:])
+	if n > MaxDumpSize {
+		return Dump{}, fmt.Errorf("%w: larger than %d bytes", ErrInvalidDump, MaxDumpSize)
+	}
+	// read through a LimitReader so a corrupt length cannot force a huge
+	// allocation up front
+	payload, err := io.ReadAll(io.LimitReader(r, int64(n)+4))
+	if err != nil {
+		return Dump{}, err
+	}
+	if len(payload) != int(n)+4 {
+		return Dump{}, fmt.Errorf("%w: truncated checkpoint", ErrInvalidDump)
+	}
+	payload, sum := payload[:n], binary.BigEndian.Uint32(payload[n:])
+	if crc32.Checksum(payload, crcTable) != sum {
+		return Dump{}, ErrChecksum
+	}
+	var d Dump
+	if err := d.UnmarshalBinary(payload); err != nil {
+		return Dump{}, err
+	}
+	for _, name := range sortedNames(d.Snapshots) {
+		if err := d.Snapshots[name].Validate(); err != nil {
+			return Dump{}, fmt.Errorf("snapshot %q: %w", name, err)
+		}
+	}
+	return d, nil
+}
+
+// Validate reports whether s is consistent, as every snapshot taken from a
+// Timer is: an empty snapshot has no statistics, and otherwise
+// 0 <= Min <= Mean <= Max, Panicked <= Count, and Sum is capped only if
+// SumOverflowed is set, with an exact sum in SumEpoch and SumRemainder
+// only then. Returns an error wrapping ErrInvalidSnapshot
+// describing the first violation.
+func (s Snapshot) Validate() error {
+	if s.Count == 0 {
+		if s != (Snapshot{}) {
+			return fmt.Errorf("%w: statistics without observations", ErrInvalidSnapshot)
+		}
+		return nil
+	}
+	switch {
+	case s.Min < 0:
+		return fmt.Errorf("%w: negative min %v", ErrInvalidSnapshot, s.Min)
+	case s.Min > s.Max:
+		return fmt.Errorf("%w: min %v > max %v", ErrInvalidSnapshot, s.Min, s.Max)
+	case s.Panicked > s.Count:
+		return fmt.Errorf("%w: %d panicked > count %d", ErrInvalidSnapshot, s.Panicked, s.Count)
+	case s.SumOverflowed && s.Sum != math.MaxInt64:
+		return fmt.Errorf("%w: overflowed sum %v is not capped", ErrInvalidSnapshot, s.Sum)
+	case s.SumEpoch == 0 && s.SumRemainder != 0, s.SumEpoch > 0 && !s.SumOverflowed:
+		return fmt.Errorf("%w: exact sum without overflow", ErrInvalidSnapshot)
+	case s.SumRemainder < 0:
+		return fmt.Errorf("%w: negative sum remainder %v", ErrInvalidSnapshot, s.SumRemainder)
+	case s.SumOverflowed && s.SumEpoch == 0:
+		return nil // capped, the mean is unknown
+	case !s.SumOverflowed && s.Sum < s.Max:
+		return fmt.Errorf("%w: sum %v < max %v", ErrInvalidSnapshot, s.Sum, s.Max)
+	}
+	if s.SumEpoch > 0 {
+		// the rounded mean saturates, compare the exact sum
+		hi, lo, _ := s.exactSum()
+		if mhi, mlo := bits.Mul64(s.Count, uint64(s.Max)); hi > mhi || hi == mhi && lo > mlo {
+			return fmt.Errorf("%w: exact sum exceeds count * max %v", ErrInvalidSnapshot, s.Max)
+		}
+	}
+	if mean := s.Mean(); mean < s.Min || mean > s.Max {
+		return fmt.Errorf("%w: mean %v outside [%v, %v]", ErrInvalidSnapshot, mean, s.Min, s.Max)
+	}
+	return nil
+}
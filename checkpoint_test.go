@@ -0,0 +1,100 @@
+package timer
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadDump(t *testing.T) {
+	d := testDump()
+	var buf bytes.Buffer
+	if err := SaveDump(&buf, d); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadDump(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got.Snapshots, d.Snapshots) || !got.Time.Equal(d.Time) {
+		t.Errorf("LoadDump = %+v; want %+v", got, d)
+	}
+}
+
+func TestLoadDumpCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	_ = SaveDump(&buf, testDump())
+	good := buf.Bytes()
+
+	flipped := bytes.Clone(good)
+	flipped[len(flipped)/2] ^= 0x40
+	if _, err := LoadDump(bytes.NewReader(flipped)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Flipped bit: got %v; want ErrChecksum", err)
+	}
+	if _, err := LoadDump(bytes.NewReader(good[:len(good)-3])); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("Truncated: got %v; want ErrInvalidDump", err)
+	}
+	if _, err := LoadDump(bytes.NewReader([]byte("JUNKJUNK"))); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("Bad magic: got %v; want ErrInvalidDump", err)
+	}
+
+	var bad bytes.Buffer
+	_ = SaveDump(&bad, NewDump(map[string]Snapshot{"db": {Count: 1, Min: 2, Max: 1, Sum: 2}}))
+	if _, err := LoadDump(&bad); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("Inconsistent snapshot: got %v; want ErrInvalidSnapshot", err)
+	}
+}
+
+func TestSnapshotValidate(t *testing.T) {
+	timer := NewTimer()
+	if err := timer.Snapshot().Validate(); err != nil {
+		t.Errorf("Empty timer snapshot: %v", err)
+	}
+	timer.Observe(time.Millisecond)
+	timer.Observe(3 * time.Millisecond)
+	if err := timer.Snapshot().Validate(); err != nil {
+		t.Errorf("Timer snapshot: %v", err)
+	}
+	timer.Observe(math.MaxInt64)
+	if err := timer.Snapshot().Validate(); err != nil {
+		t.Errorf("Overflowed timer snapshot: %v", err)
+	}
+
+	for _, s := range []Snapshot{
+		{Max: 1},
+		{Count: 1, Min: -1, Max: 1, Sum: 1},
+		{Count: 1, Min: 2, Max: 1, Sum: 2},
+		{Count: 1, Min: 1, Max: 1, Sum: 1, Panicked: 2},
+		{Count: 2, Min: 1, Max: 2, Sum: 5},
+		{Count: 2, Min: 1, Max: 2, Sum: 1},
+		{Count: 2, Min: 1, Max: 2, Sum: 100, SumOverflowed: true},
+		{Count: 2, Min: 1, Max: 2, Sum: math.MaxInt64, SumEpoch: 1},
+		{Count: 2, Min: 1, Max: 2, Sum: math.MaxInt64, SumOverflowed: true, SumRemainder: 1},
+		{Count: 2, Min: 1, Max: math.MaxInt64, Sum: math.MaxInt64, SumOverflowed: true, SumEpoch: 3},
+	} {
+		if err := s.Validate(); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Validate(%+v) = %v; want ErrInvalidSnapshot", s, err)
+		}
+	}
+}
+
+func FuzzLoadDump(f *testing.F) {
+	var buf bytes.Buffer
+	_ = SaveDump(&buf, testDump())
+	f.Add(buf.Bytes())
+	f.Add([]byte("TMRC\xff\xff\xff\xff"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := LoadDump(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		for name, s := range d.Snapshots {
+			if err := s.Validate(); err != nil {
+				t.Fatalf("LoadDump accepted invalid snapshot %q: %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package timer
+
+import "time"
+
+// Clock tells the current time. Timers and windowed recorders read the
+// time from a Clock so simulations and tests can drive them
+// deterministically with a fake one.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock makes t read the time from c instead of time.Now, for Update,
+// Time, stopwatches, EnqueueTokens and throughput, and restarts
+// throughput measurement.
+// It must be called before t is shared between goroutines.
+func (t *Timer) SetClock(c Clock) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.clock = c
+	t.since = t.now()
+}
+
+// now returns the time according to the timer's clock.
+func (t *Timer) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
@@ -0,0 +1,43 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func TestSetClock(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	timer := NewTimer()
+	timer.SetClock(clock)
+
+	start := clock.t
+	clock.t = clock.t.Add(30 * time.Millisecond)
+	if err := timer.Update(start); err != nil {
+		t.Fatalf("Update() = %v; want nil", err)
+	}
+	sw := timer.Start()
+	clock.t = clock.t.Add(10 * time.Millisecond)
+	if d := sw.Stop(); d != 10*time.Millisecond {
+		t.Errorf("Stop() = %v; want 10ms", d)
+	}
+	timer.Time(func() { clock.t = clock.t.Add(20 * time.Millisecond) })
+
+	if s := timer.Snapshot(); s.Count != 3 || s.Sum != 60*time.Millisecond {
+		t.Errorf("Snapshot() = %+v; want 3 observations summing to 60ms", s)
+	}
+	clock.t = clock.t.Add(time.Second)
+	if tp := timer.Throughput(); tp != 3/1.06 {
+		t.Errorf("Throughput() = %v; want %v", tp, 3/1.06)
+	}
+}
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	if now := SystemClock.Now(); now.Before(before) {
+		t.Errorf("SystemClock.Now() = %v; want at least %v", now, before)
+	}
+}
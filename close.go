@@ -0,0 +1,67 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ClosePolicy selects what a Timer does with recordings after Close.
+type ClosePolicy int
+
+const (
+	// CloseNoop silently drops recordings.
+	CloseNoop ClosePolicy = iota
+	// CloseError drops recordings and makes Update, UpdateAt and
+	// UpdateWithContext return ErrClosed, to catch instrumentation that
+	// outlives shutdown.
+	CloseError
+)
+
+// SetClosePolicy selects what t does with recordings after Close. The
+// default is CloseNoop.
+func (t *Timer) SetClosePolicy(p ClosePolicy) {
+	t.closePolicy.Store(int32(p))
+}
+
+// Close stops t from recording. Its statistics stay readable and
+// exportable. Attached aggregators are detached, and those implementing
+// io.Closer are closed so their goroutines stop and pending output is
+// flushed. Returns their joined Close errors. Close is idempotent.
+//
+// Background components such as a Reporter are closed on their own, or all
+// at once with Shutdown, which should run before timers are closed so the
+// final report includes every observation.
+func (t *Timer) Close() error {
+	if t.closed.Swap(true) {
+		return nil
+	}
+	t.mutex.Lock()
+	aggregators := t.aggregators
+	t.aggregators = nil
+	t.logEventNoLock(EventClosed)
+	t.mutex.Unlock()
+
+	var errs []error
+	for name, a := range aggregators {
+		if c, ok := a.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("aggregator %q: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Closed reports whether Close has been called on t.
+func (t *Timer) Closed() bool {
+	return t.closed.Load()
+}
+
+// closedErr returns ErrClosed if t was closed with the CloseError policy.
+func (t *Timer) closedErr() error {
+	if t.closed.Load() && ClosePolicy(t.closePolicy.Load()) == CloseError {
+		return ErrClosed
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type closingAggregator struct {
+	slowCounter
+	closed bool
+	err    error
+}
+
+func (a *closingAggregator) Close() error {
+	a.closed = true
+	return a.err
+}
+
+func TestCloseNoop(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(time.Millisecond)
+	if err := timer.Close(); err != nil {
+		t.Fatalf("Close() = %v; want nil", err)
+	}
+	if !timer.Closed() {
+		t.Errorf("Expected Closed to be true after Close")
+	}
+	timer.Observe(time.Second)
+	if err := timer.Update(time.Now()); err != nil {
+		t.Errorf("Update() = %v; want nil", err)
+	}
+	if d := timer.Start().Stop(); d != 0 {
+		t.Errorf("Expected no-op stopwatch after Close, got %v", d)
+	}
+	if timer.Count() != 1 || timer.Max() != time.Millisecond {
+		t.Errorf("Expected statistics to stay readable and unchanged, got %v", timer)
+	}
+	if err := timer.Close(); err != nil {
+		t.Errorf("Second Close() = %v; want nil", err)
+	}
+}
+
+func TestCloseError(t *testing.T) {
+	timer := NewTimer()
+	timer.SetClosePolicy(CloseError)
+	_ = timer.Close()
+
+	now := time.Now()
+	if err := timer.Update(now); !errors.Is(err, ErrClosed) {
+		t.Errorf("Update() = %v; want ErrClosed", err)
+	}
+	if err := timer.UpdateAt(now, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("UpdateAt() = %v; want ErrClosed", err)
+	}
+	if err := timer.UpdateWithContext(context.Background(), now); !errors.Is(err, ErrClosed) {
+		t.Errorf("UpdateWithContext() = %v; want ErrClosed", err)
+	}
+	if err := timer.Update(time.Time{}); !errors.Is(err, ErrZeroTime) {
+		t.Errorf("Update(zero) = %v; want ErrZeroTime", err)
+	}
+	if timer.Count() != 0 {
+		t.Errorf("Expected nothing recorded after Close, got %d", timer.Count())
+	}
+}
+
+func TestCloseAggregators(t *testing.T) {
+	timer := NewTimer()
+	ok := &closingAggregator{}
+	failing := &closingAggregator{err: errors.New("flush failed")}
+	_ = timer.AddAggregator("ok", ok)
+	_ = timer.AddAggregator("failing", failing)
+
+	err := timer.Close()
+	if err == nil || !failing.closed || !ok.closed {
+		t.Errorf("Expected both aggregators closed and the error returned, got %v", err)
+	}
+	if len(timer.AggregatorSnapshots()) != 0 {
+		t.Errorf("Expected aggregators to be detached after Close")
+	}
+}
@@ -0,0 +1,171 @@
+// Command timerstat merges timer snapshot dumps and prints them as a table.
+//
+// A dump is anything timer.ParseDump reads: a JSON or binary timer.Dump
+// of any version, or a bare JSON object mapping timer names to snapshots.
+// Dumps from several processes or shards are merged by name before
+// printing:
+//
+//	timerstat shard-0.json shard-1.json shard-2.json
+//
+// With -base, the merged result is compared against a previous run and the
+// change in mean and max is printed alongside each timer:
+//
+//	timerstat -base main.json shard-*.json
+//
+// If the dumps carry histograms, see Registry.Dump, the p50, p90 and p99
+// of each timer are printed as well, and compared against the base.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func main() {
+	base := flag.String("base", "", "path of a previous dump to compare against")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: timerstat [-base dump.json] dump.json...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cur, err := loadAll(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "timerstat: %v\n", err)
+		os.Exit(1)
+	}
+
+	var prev *timer.Dump
+	if *base != "" {
+		if prev, err = loadAll([]string{*base}); err != nil {
+			fmt.Fprintf(os.Stderr, "timerstat: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := printTable(os.Stdout, cur, prev); err != nil {
+		fmt.Fprintf(os.Stderr, "timerstat: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadAll reads every dump in paths and merges them by timer name.
+func loadAll(paths []string) (*timer.Dump, error) {
+	var merged timer.Dump
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		dump, err := decode(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		merged.Merge(dump)
+	}
+	return &merged, nil
+}
+
+// decode parses a single dump in any format timer.ParseDump accepts.
+func decode(r io.Reader) (timer.Dump, error) {
+	data, err := io.ReadAll(io.LimitReader(r, timer.MaxDumpSize+1))
+	if err != nil {
+		return timer.Dump{}, err
+	}
+	return timer.ParseDump(data)
+}
+
+// quantiles are the quantiles printed for timers with histograms.
+var quantiles = []struct {
+	name string
+	q    float64
+}{{"P50", 0.5}, {"P90", 0.9}, {"P99", 0.99}}
+
+// printTable writes cur as an aligned table sorted by name. If any
+// timer has a histogram, quantile columns are added. If prev is non-nil,
+// columns with the relative change of mean and max, and of p99 with
+// histograms, are added.
+func printTable(w io.Writer, cur, prev *timer.Dump) error {
+	names := slices.Sorted(maps.Keys(cur.Snapshots))
+	hists := len(cur.Histograms) > 0
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "NAME\tCOUNT\tMIN\tMEAN\tMAX\t"
+	if hists {
+		for _, q := range quantiles {
+			header += q.name + "\t"
+		}
+	}
+	if prev != nil {
+		header += "ΔMEAN\tΔMAX\t"
+		if hists {
+			header += "ΔP99\t"
+		}
+	}
+	fmt.Fprintln(tw, header)
+	for _, name := range names {
+		s := cur.Snapshots[name]
+		mean := s.Mean().String()
+		if s.SumOverflowed {
+			mean = "~" + mean
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%s\t%v\t", name, s.Count, s.Min, mean, s.Max)
+		h, hasHist := cur.Histograms[name]
+		if hists {
+			for _, q := range quantiles {
+				if hasHist {
+					fmt.Fprintf(tw, "%v\t", h.Quantile(q.q))
+				} else {
+					fmt.Fprint(tw, "-\t")
+				}
+			}
+		}
+		if prev != nil {
+			p, ok := prev.Snapshots[name]
+			if ok && p.Count > 0 {
+				fmt.Fprintf(tw, "%s\t%s\t", delta(p.Mean(), s.Mean()), delta(p.Max, s.Max))
+			} else {
+				fmt.Fprint(tw, "new\tnew\t")
+			}
+			if hists {
+				if ph, ok := prev.Histograms[name]; ok && hasHist && ph.Count > 0 {
+					fmt.Fprintf(tw, "%s\t", delta(ph.Quantile(0.99), h.Quantile(0.99)))
+				} else {
+					fmt.Fprint(tw, "-\t")
+				}
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// delta formats the relative change from old to cur as a signed percentage.
+func delta(old, cur time.Duration) string {
+	if old == 0 {
+		if cur == 0 {
+			return "+0.0%"
+		}
+		return "+inf%"
+	}
+	pct := (float64(cur) - float64(old)) / float64(old) * 100
+	s := strconv.FormatFloat(pct, 'f', 1, 64) + "%"
+	if pct >= 0 {
+		s = "+" + s
+	}
+	return s
+}
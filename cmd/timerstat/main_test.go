@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestDecodeAndMerge(t *testing.T) {
+	a, err := decode(strings.NewReader(`{"db":{"count":1,"min_ns":10,"max_ns":10,"sum_ns":10}}`))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	bin, _ := timer.NewDump(map[string]timer.Snapshot{
+		"db":   {Count: 1, Min: 30, Max: 30, Sum: 30},
+		"http": {Count: 2, Min: 1, Max: 3, Sum: 4},
+	}).MarshalBinary()
+	b, err := decode(bytes.NewReader(bin))
+	if err != nil {
+		t.Fatalf("decode of binary dump failed: %v", err)
+	}
+
+	var merged timer.Dump
+	merged.Merge(a)
+	merged.Merge(b)
+
+	want := timer.Snapshot{Count: 2, Min: 10, Max: 30, Sum: 40}
+	if got := merged.Snapshots["db"]; got != want {
+		t.Errorf("merged db = %+v; want %+v", got, want)
+	}
+	if got := merged.Snapshots["http"].Count; got != 2 {
+		t.Errorf("merged http count = %d; want 2", got)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := decode(strings.NewReader(`[1,2,3]`)); err == nil {
+		t.Errorf("Expected error decoding a non-object dump")
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	cur := map[string]timer.Snapshot{
+		"b": {Count: 1, Min: 20 * time.Millisecond, Max: 20 * time.Millisecond, Sum: 20 * time.Millisecond},
+		"a": {Count: 1, Min: 10 * time.Millisecond, Max: 10 * time.Millisecond, Sum: 10 * time.Millisecond},
+	}
+	prev := map[string]timer.Snapshot{
+		"a": {Count: 1, Min: 8 * time.Millisecond, Max: 8 * time.Millisecond, Sum: 8 * time.Millisecond},
+	}
+
+	var buf bytes.Buffer
+	if err := printTable(&buf, &timer.Dump{Snapshots: cur}, &timer.Dump{Snapshots: prev}); err != nil {
+		t.Fatalf("printTable failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "ΔMEAN") {
+		t.Errorf("Expected delta columns in header, got %q", lines[0])
+	}
+	if !strings.HasPrefix(strings.TrimSpace(lines[1]), "a") || !strings.Contains(lines[1], "+25.0%") {
+		t.Errorf("Expected row for a with +25.0%% delta, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "new") {
+		t.Errorf("Expected row for b to be marked new, got %q", lines[2])
+	}
+}
+
+func TestPrintTableQuantiles(t *testing.T) {
+	r := timer.NewRegistry()
+	db := r.GetOrCreate("db")
+	db.AddAggregator("hist", timer.NewExpHistogram(0))
+	for i := range 100 {
+		db.Observe(time.Duration(i+1) * time.Millisecond)
+	}
+	r.GetOrCreate("cache").Observe(time.Millisecond)
+
+	cur, prev := r.Dump(), r.Dump()
+	var buf bytes.Buffer
+	if err := printTable(&buf, &cur, &prev); err != nil {
+		t.Fatalf("printTable failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	for _, col := range []string{"P50", "P90", "P99", "ΔP99"} {
+		if !strings.Contains(lines[0], col) {
+			t.Errorf("Expected %s column in header, got %q", col, lines[0])
+		}
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[1]), "-") {
+		t.Errorf("Expected cache without quantiles, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "+0.0%") {
+		t.Errorf("Expected db with unchanged p99, got %q", lines[2])
+	}
+}
+
+func TestDelta(t *testing.T) {
+	tests := []struct {
+		old, cur time.Duration
+		want     string
+	}{
+		{100, 150, "+50.0%"},
+		{100, 50, "-50.0%"},
+		{0, 0, "+0.0%"},
+		{0, 1, "+inf%"},
+	}
+	for _, tt := range tests {
+		if got := delta(tt.old, tt.cur); got != tt.want {
+			t.Errorf("delta(%v, %v) = %q; want %q", tt.old, tt.cur, got, tt.want)
+		}
+	}
+}
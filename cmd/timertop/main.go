@@ -0,0 +1,318 @@
+// Command timertop shows the timers of a running process live, sorted and
+// filtered like top.
+//
+// It polls either the JSON endpoint of timerhttp.DebugHandler or the unix
+// socket of a timer.SnapshotServer:
+//
+//	timertop http://localhost:8080/debug/timers/json
+//	timertop /run/app/timers.sock
+//
+// Keys: m, x, n, c and t sort by mean, max, name, count and throughput,
+// r reverses the order, / followed by text and Enter filters names by
+// substring, and q quits. Terminals that cannot be put in cbreak mode with
+// stty need Enter after each key.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// fetchTimeout bounds a single poll of the endpoint.
+const fetchTimeout = 5 * time.Second
+
+func main() {
+	interval := flag.Duration("interval", 2*time.Second, "refresh interval")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: timertop [-interval d] http-url|socket-path\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// restore the terminal on Ctrl-C and kill too, not only on q
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, flag.Arg(0), *interval)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "timertop: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run polls addr and redraws the screen until the user quits or ctx is
+// done.
+func run(ctx context.Context, addr string, interval time.Duration) error {
+	restore := cbreak()
+	defer restore()
+
+	keys := make(chan byte)
+	go func() {
+		r := bufio.NewReader(os.Stdin)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- b
+		}
+	}()
+
+	v := view{sort: 'm'}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	poll := func() {
+		snaps, err := fetch(addr)
+		now := time.Now()
+		if err != nil {
+			v.status = err.Error()
+		} else {
+			v.update(snaps, now)
+			v.status = ""
+		}
+	}
+	poll()
+	for {
+		fmt.Print("\x1b[H\x1b[2J")
+		v.render(os.Stdout, addr)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			poll()
+		case k, ok := <-keys:
+			if !ok || !v.key(k) {
+				return nil
+			}
+		}
+	}
+}
+
+// cbreak switches the terminal to unbuffered input without echo using
+// stty, and returns a function restoring the previous mode. It does
+// nothing if stty is unavailable.
+func cbreak() (restore func()) {
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		return cmd.Output()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return func() {}
+	}
+	if _, err := stty("cbreak", "-echo"); err != nil {
+		return func() {}
+	}
+	return func() { stty(strings.TrimSpace(string(saved))) }
+}
+
+// fetch reads a snapshot from an HTTP JSON endpoint or a unix socket.
+func fetch(addr string) (map[string]timer.Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return fetchHTTP(ctx, addr)
+	}
+	return fetchUnix(ctx, strings.TrimPrefix(addr, "unix:"))
+}
+
+func fetchHTTP(ctx context.Context, url string) (map[string]timer.Snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	var snaps map[string]timer.Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snaps); err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// fetchUnix reads the JSON lines a SnapshotServer writes on connect.
+func fetchUnix(ctx context.Context, path string) (map[string]timer.Snapshot, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	snaps := make(map[string]timer.Snapshot)
+	dec := json.NewDecoder(conn)
+	for {
+		var ns timer.NamedSnapshot
+		if err := dec.Decode(&ns); errors.Is(err, io.EOF) {
+			return snaps, nil
+		} else if err != nil {
+			return nil, err
+		}
+		snaps[ns.Name] = ns.Snapshot
+	}
+}
+
+// row is a timer as displayed, with rates derived from the previous poll.
+type row struct {
+	name string
+	timer.Snapshot
+	rate float64 // observations per second since the previous poll
+}
+
+// view holds the display state.
+type view struct {
+	prev      map[string]timer.Snapshot
+	prevTime  time.Time
+	rows      []row
+	sort      byte
+	reverse   bool
+	filter    string
+	filtering bool   // reading filter text
+	input     []byte // filter text typed so far
+	status    string
+}
+
+// update replaces the displayed timers with snaps taken at now.
+func (v *view) update(snaps map[string]timer.Snapshot, now time.Time) {
+	elapsed := now.Sub(v.prevTime).Seconds()
+	v.rows = v.rows[:0]
+	for name, s := range snaps {
+		r := row{name: name, Snapshot: s}
+		if p, ok := v.prev[name]; ok && elapsed > 0 && s.Count >= p.Count {
+			r.rate = float64(s.Count-p.Count) / elapsed
+		}
+		v.rows = append(v.rows, r)
+	}
+	v.prev, v.prevTime = snaps, now
+}
+
+// key handles a key press. Returns false to quit.
+func (v *view) key(k byte) bool {
+	if v.filtering {
+		switch k {
+		case '\n', '\r':
+			v.filter, v.filtering = string(v.input), false
+		case 0x7f, '\b':
+			if len(v.input) > 0 {
+				v.input = v.input[:len(v.input)-1]
+			}
+		case 0x1b:
+			v.filtering = false
+		default:
+			v.input = append(v.input, k)
+		}
+		return true
+	}
+	switch k {
+	case 'q':
+		return false
+	case 'm', 'x', 'n', 'c', 't':
+		v.sort = k
+	case 'r':
+		v.reverse = !v.reverse
+	case '/':
+		v.filtering, v.input = true, v.input[:0]
+	}
+	return true
+}
+
+// visible returns the rows matching the filter in display order. Numeric
+// columns sort descending, names ascending.
+func (v *view) visible() []row {
+	var rows []row
+	for _, r := range v.rows {
+		if strings.Contains(r.name, v.filter) {
+			rows = append(rows, r)
+		}
+	}
+	slices.SortFunc(rows, func(a, b row) int {
+		var c int
+		switch v.sort {
+		case 'x':
+			c = cmpDesc(a.Max, b.Max)
+		case 'n':
+			c = strings.Compare(a.name, b.name)
+		case 'c':
+			c = cmpDesc(a.Count, b.Count)
+		case 't':
+			c = cmpDesc(a.rate, b.rate)
+		default:
+			c = cmpDesc(a.Mean(), b.Mean())
+		}
+		if c == 0 {
+			c = strings.Compare(a.name, b.name)
+		}
+		if v.reverse {
+			c = -c
+		}
+		return c
+	})
+	return rows
+}
+
+func cmpDesc[T int64 | uint64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// sortNames labels the sort keys in the header.
+var sortNames = map[byte]string{'m': "mean", 'x': "max", 'n': "name", 'c': "count", 't': "throughput"}
+
+// render writes the screen to w.
+func (v *view) render(w io.Writer, addr string) {
+	order := sortNames[v.sort]
+	if v.reverse {
+		order += " (reversed)"
+	}
+	fmt.Fprintf(w, "timertop %s  sort: %s  filter: %q\n", addr, order, v.filter)
+	switch {
+	case v.filtering:
+		fmt.Fprintf(w, "filter: %s_\n", v.input)
+	case v.status != "":
+		fmt.Fprintf(w, "error: %s\n", v.status)
+	default:
+		fmt.Fprintln(w, "keys: m x n c t sort, r reverse, / filter, q quit")
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "NAME\tCOUNT\tRATE/s\tMIN\tMEAN\tMAX\t")
+	for _, r := range v.visible() {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%v\t%v\t%v\t\n", r.name, r.Count, r.rate, r.Min, r.Mean(), r.Max)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+	"github.com/jnpr-pranav/go-timer/timerhttp"
+)
+
+func TestFetchHTTP(t *testing.T) {
+	r := timer.NewRegistry()
+	r.GetOrCreate("db").Observe(time.Millisecond)
+	srv := httptest.NewServer(timerhttp.DebugHandler(r))
+	defer srv.Close()
+
+	snaps, err := fetch(srv.URL + "/json")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if snaps["db"].Count != 1 {
+		t.Errorf("Unexpected snapshots: %v", snaps)
+	}
+	if _, err := fetch(srv.URL + "/missing/json/"); err == nil {
+		t.Errorf("Expected error for non-JSON response")
+	}
+}
+
+func TestFetchUnix(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "js" || runtime.GOOS == "wasip1" {
+		t.Skip("unix sockets not supported")
+	}
+	r := timer.NewRegistry()
+	r.GetOrCreate("a").Observe(time.Millisecond)
+	r.GetOrCreate("b").Observe(2 * time.Millisecond)
+	path := filepath.Join(t.TempDir(), "timers.sock")
+	srv, err := r.ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix failed: %v", err)
+	}
+	defer srv.Close()
+
+	snaps, err := fetch("unix:" + path)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(snaps) != 2 || snaps["b"].Max != 2*time.Millisecond {
+		t.Errorf("Unexpected snapshots: %v", snaps)
+	}
+}
+
+func TestView(t *testing.T) {
+	var v view
+	v.sort = 'm'
+	start := time.Now()
+	v.update(map[string]timer.Snapshot{
+		"fast": {Count: 10, Min: 1, Max: 1, Sum: 10},
+		"slow": {Count: 1, Min: 100, Max: 100, Sum: 100},
+	}, start)
+	v.update(map[string]timer.Snapshot{
+		"fast": {Count: 30, Min: 1, Max: 1, Sum: 30},
+		"slow": {Count: 1, Min: 100, Max: 100, Sum: 100},
+	}, start.Add(2*time.Second))
+
+	names := func() string {
+		var ns []string
+		for _, r := range v.visible() {
+			ns = append(ns, r.name)
+		}
+		return strings.Join(ns, ",")
+	}
+	if got := names(); got != "slow,fast" {
+		t.Errorf("Sorted by mean = %s; want slow,fast", got)
+	}
+	v.key('t')
+	if got := names(); got != "fast,slow" || v.visible()[0].rate != 10 {
+		t.Errorf("Sorted by throughput = %s (rate %v); want fast,slow at 10/s", got, v.visible()[0].rate)
+	}
+	v.key('r')
+	if got := names(); got != "slow,fast" {
+		t.Errorf("Reversed = %s; want slow,fast", got)
+	}
+
+	for _, k := range []byte("/slx\x7fo\n") {
+		v.key(k)
+	}
+	if v.filter != "slo" || names() != "slow" {
+		t.Errorf("Filter %q shows %s; want slow", v.filter, names())
+	}
+	if v.key('q') {
+		t.Errorf("Expected q to quit")
+	}
+
+	var out strings.Builder
+	v.render(&out, "addr")
+	if !strings.Contains(out.String(), "sort: throughput (reversed)") || !strings.Contains(out.String(), "slow") {
+		t.Errorf("Unexpected render output:\n%s", out.String())
+	}
+}
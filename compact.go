@@ -0,0 +1,121 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Compacter is implemented by aggregators that can release their memory
+// while their timer is idle. Compact discards the aggregator's data; it
+// allocates again on its next use. ExpHistogram, HeatmapRecorder and
+// MultiWindow implement Compacter.
+type Compacter interface {
+	Compact()
+}
+
+// Compact releases the memory t holds beyond its scalar summary: attached
+// aggregators implementing Compacter are compacted, and the deadline
+// sample is dropped. Count, min, max, sum and the other scalar statistics
+// are kept, and the released structures are rebuilt on the next
+// observation.
+func (t *Timer) Compact() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, a := range t.aggregators {
+		if c, ok := a.(Compacter); ok {
+			c.Compact()
+		}
+	}
+	t.deadline.samples = nil
+}
+
+// IdleCompactor compacts the timers of a registry, including vec
+// children, once they have not been observed for a configured idle
+// period, bounding memory in bursty multi-tenant services whose
+// per-tenant histograms sit unused most of the time. Like IdleEvictor it
+// detects activity by comparing counts between sweeps, and a timer is
+// compacted once per idle period.
+type IdleCompactor struct {
+	registry *Registry
+	idle     time.Duration
+	interval time.Duration
+
+	mutex sync.Mutex
+	seen  map[*Timer]compactState
+	lc    lifecycle
+}
+
+// compactState is what the compactor remembers about a timer between
+// sweeps.
+type compactState struct {
+	count     uint64    // Count at the last sweep
+	active    time.Time // When the count was last seen to change
+	compacted bool      // Whether the timer was compacted since then
+}
+
+// NewIdleCompactor creates an IdleCompactor compacting timers of r idle
+// for at least idle.
+func NewIdleCompactor(r *Registry, idle time.Duration) *IdleCompactor {
+	return &IdleCompactor{
+		registry: r,
+		idle:     idle,
+		interval: max(idle/4, time.Millisecond),
+		seen:     make(map[*Timer]compactState),
+	}
+}
+
+// Start begins sweeping every quarter of the idle period until ctx is done
+// or Close is called.
+func (c *IdleCompactor) Start(ctx context.Context) error {
+	return c.lc.start(ctx, c, c.run)
+}
+
+func (c *IdleCompactor) run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.sweep(now)
+		}
+	}
+}
+
+// Sweep compacts idle timers immediately and returns how many were
+// compacted.
+func (c *IdleCompactor) Sweep() int {
+	return c.sweep(time.Now())
+}
+
+func (c *IdleCompactor) sweep(now time.Time) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	timers := c.registry.allTimers()
+	compacted := 0
+	seen := make(map[*Timer]compactState, len(timers))
+	for _, t := range timers {
+		count := t.Count()
+		st, ok := c.seen[t]
+		if !ok || st.count != count {
+			st = compactState{count: count, active: now}
+		}
+		if !st.compacted && now.Sub(st.active) >= c.idle {
+			t.Compact()
+			st.compacted = true
+			compacted++
+		}
+		seen[t] = st
+	}
+	c.seen = seen
+	return compacted
+}
+
+// Close stops sweeping.
+func (c *IdleCompactor) Close() error {
+	c.lc.close()
+	return nil
+}
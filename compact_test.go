@@ -0,0 +1,72 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimerCompact(t *testing.T) {
+	timer := NewTimer()
+	h := NewExpHistogram(0)
+	mw, _ := NewMultiWindow(time.Second, time.Minute)
+	hm, _ := NewHeatmapRecorder([]time.Duration{time.Millisecond}, time.Minute, 2)
+	_ = timer.AddAggregator("hist", h)
+	_ = timer.AddAggregator("windows", mw)
+	_ = timer.AddAggregator("heatmap", hm)
+	timer.SetDeadlineSampleSize(8)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_ = timer.UpdateWithContext(ctx, time.Now())
+	timer.Observe(5 * time.Millisecond)
+
+	timer.Compact()
+	if timer.Count() != 2 || timer.Max() < 5*time.Millisecond {
+		t.Errorf("Expected the scalar summary to survive Compact, got %v", timer)
+	}
+	if h.ExpSnapshot().Count != 0 || h.buckets != nil || mw.slots != nil || hm.counts != nil {
+		t.Errorf("Expected aggregators to release their data")
+	}
+	if n := len(timer.DeadlineSamples()); n != 0 {
+		t.Errorf("Expected the deadline sample to be dropped, got %d", n)
+	}
+
+	timer.Observe(2 * time.Millisecond)
+	if h.ExpSnapshot().Count != 1 {
+		t.Errorf("Expected the histogram to rehydrate on the next observation")
+	}
+	if s, _ := mw.Window(time.Minute); s.Count != 1 {
+		t.Errorf("Expected the windows to rehydrate on the next observation, got %+v", s)
+	}
+	if m := hm.Heatmap(); len(m.Windows) != 2 || m.Windows[1].Counts[1] != 1 {
+		t.Errorf("Expected the heatmap to rehydrate on the next observation, got %+v", m)
+	}
+}
+
+func TestIdleCompactor(t *testing.T) {
+	r := NewRegistry()
+	idle, busy := r.GetOrCreate("idle"), r.GetOrCreate("busy")
+	vec := NewTimerVec("tenant")
+	_ = r.RegisterVec("tenants", vec)
+	child := vec.WithLabelValues("acme")
+	for _, tm := range []*Timer{idle, busy, child} {
+		_ = tm.AddAggregator("hist", NewExpHistogram(0))
+		tm.Observe(time.Millisecond)
+	}
+
+	c := NewIdleCompactor(r, time.Minute)
+	now := time.Now()
+	if n := c.sweep(now); n != 0 {
+		t.Errorf("First sweep compacted %d timers; want 0", n)
+	}
+	busy.Observe(time.Millisecond)
+	if n := c.sweep(now.Add(time.Minute)); n != 2 {
+		t.Errorf("Expected the idle timer and vec child to be compacted, got %d", n)
+	}
+	if n := c.sweep(now.Add(2 * time.Minute)); n != 1 {
+		t.Errorf("Expected only the newly idle timer to be compacted, got %d", n)
+	}
+	if idle.Count() != 1 {
+		t.Errorf("Expected compacted timers to keep their count, got %d", idle.Count())
+	}
+}
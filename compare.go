@@ -0,0 +1,85 @@
+package timer
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CompareBy returns a function ordering snapshots by metric, ascending,
+// for slices.SortFunc and slices.SortStableFunc. Reverse the arguments
+// for descending order:
+//
+//	slices.SortStableFunc(snaps, func(a, b timer.Snapshot) int {
+//		return timer.CompareBy(timer.Snapshot.Mean)(b, a)
+//	})
+//
+// A percentile can be compared with a metric reading it from a Quantiler,
+// as snapshots themselves hold no distribution.
+func CompareBy(metric func(Snapshot) time.Duration) func(a, b Snapshot) int {
+	return func(a, b Snapshot) int {
+		return cmp.Compare(metric(a), metric(b))
+	}
+}
+
+// CompareCount orders snapshots by Count, ascending.
+func CompareCount(a, b Snapshot) int {
+	return cmp.Compare(a.Count, b.Count)
+}
+
+// CompareMean orders snapshots by Mean, ascending.
+func CompareMean(a, b Snapshot) int {
+	return cmp.Compare(a.Mean(), b.Mean())
+}
+
+// CompareMax orders snapshots by Max, ascending.
+func CompareMax(a, b Snapshot) int {
+	return cmp.Compare(a.Max, b.Max)
+}
+
+// ByCount sorts snapshots by Count, ascending, implementing
+// sort.Interface. Use sort.Stable to keep ties in their order.
+type ByCount []Snapshot
+
+func (s ByCount) Len() int           { return len(s) }
+func (s ByCount) Less(i, j int) bool { return CompareCount(s[i], s[j]) < 0 }
+func (s ByCount) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ByMean sorts snapshots by Mean, ascending, implementing sort.Interface.
+// Use sort.Stable to keep ties in their order.
+type ByMean []Snapshot
+
+func (s ByMean) Len() int           { return len(s) }
+func (s ByMean) Less(i, j int) bool { return CompareMean(s[i], s[j]) < 0 }
+func (s ByMean) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ByMax sorts snapshots by Max, ascending, implementing sort.Interface.
+// Use sort.Stable to keep ties in their order.
+type ByMax []Snapshot
+
+func (s ByMax) Len() int           { return len(s) }
+func (s ByMax) Less(i, j int) bool { return CompareMax(s[i], s[j]) < 0 }
+func (s ByMax) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// CompareNamed returns a function ordering named snapshots by metric,
+// ascending, and ties by name, so the order is total and stable across
+// runs.
+func CompareNamed(metric func(Snapshot) time.Duration) func(a, b NamedSnapshot) int {
+	return func(a, b NamedSnapshot) int {
+		if c := cmp.Compare(metric(a.Snapshot), metric(b.Snapshot)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	}
+}
+
+// SortKey returns d as a fixed-width decimal string whose byte order is
+// the numeric order of durations, negative ones included, for sorting by
+// latency in tools that only compare text, such as sort(1) or key-value
+// stores.
+func SortKey(d time.Duration) string {
+	// flipping the sign bit maps int64 order onto uint64 order
+	s := strconv.FormatUint(uint64(d)^(1<<63), 10)
+	return strings.Repeat("0", 20-len(s)) + s
+}
@@ -0,0 +1,53 @@
+package timer
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	a := Snapshot{Count: 3, Min: 1, Max: 9, Sum: 15} // mean 5
+	b := Snapshot{Count: 1, Min: 7, Max: 7, Sum: 7}  // mean 7
+	c := Snapshot{Count: 2, Min: 2, Max: 4, Sum: 6}  // mean 3
+
+	snaps := []Snapshot{a, b, c}
+	sort.Stable(ByMean(snaps))
+	if !slices.Equal(snaps, []Snapshot{c, a, b}) {
+		t.Errorf("ByMean sorted to %v", snaps)
+	}
+	sort.Stable(ByMax(snaps))
+	if !slices.Equal(snaps, []Snapshot{c, b, a}) {
+		t.Errorf("ByMax sorted to %v", snaps)
+	}
+	sort.Stable(ByCount(snaps))
+	if !slices.Equal(snaps, []Snapshot{b, c, a}) {
+		t.Errorf("ByCount sorted to %v", snaps)
+	}
+	slices.SortFunc(snaps, CompareBy(func(s Snapshot) time.Duration { return s.Min }))
+	if !slices.Equal(snaps, []Snapshot{a, c, b}) {
+		t.Errorf("CompareBy(min) sorted to %v", snaps)
+	}
+
+	named := []NamedSnapshot{{"z", a}, {"y", a}, {"x", b}}
+	slices.SortFunc(named, CompareNamed(Snapshot.Mean))
+	if named[0].Name != "y" || named[1].Name != "z" || named[2].Name != "x" {
+		t.Errorf("CompareNamed sorted to %v", named)
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	ds := []time.Duration{math.MinInt64, -time.Second, -1, 0, 1, 999, 1000, time.Hour, math.MaxInt64}
+	keys := make([]string, len(ds))
+	for i, d := range ds {
+		keys[i] = SortKey(d)
+		if len(keys[i]) != 20 {
+			t.Errorf("SortKey(%d) = %q; want 20 digits", d, keys[i])
+		}
+	}
+	if !slices.IsSorted(keys) {
+		t.Errorf("Expected keys in numeric order, got %v", keys)
+	}
+}
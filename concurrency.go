@@ -0,0 +1,42 @@
+package timer
+
+import "time"
+
+// Throughput returns the average number of observations per second since
+// the timer was created or last reset.
+func (t *Timer) Throughput() float64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.throughputNoLock(t.now())
+}
+
+func (t *Timer) throughputNoLock(now time.Time) float64 {
+	elapsed := now.Sub(t.since).Seconds()
+	if t.since.IsZero() || elapsed <= 0 {
+		return 0
+	}
+	return float64(t.count) / elapsed
+}
+
+// SuggestedConcurrency applies Little's law to estimate how many
+// operations are in progress on average: throughput times mean latency.
+// A worker pool sized to this value is fully busy at the observed load.
+func (t *Timer) SuggestedConcurrency() float64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.concurrencyNoLock(t.now())
+}
+
+func (t *Timer) concurrencyNoLock(now time.Time) float64 {
+	return t.throughputNoLock(now) * t.meanNoLock().Seconds()
+}
+
+// Utilization returns the fraction of workers kept busy at the observed
+// load, i.e. SuggestedConcurrency divided by workers. Values above 1 mean
+// the pool is too small to sustain the load. Returns 0 if workers <= 0.
+func (t *Timer) Utilization(workers int) float64 {
+	if workers <= 0 {
+		return 0
+	}
+	return t.SuggestedConcurrency() / float64(workers)
+}
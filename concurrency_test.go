@@ -0,0 +1,39 @@
+package timer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSuggestedConcurrency(t *testing.T) {
+	timer := NewTimer()
+	for range 100 {
+		timer.Observe(200 * time.Millisecond)
+	}
+	// 100 observations in 10s at 200ms each keep 2 workers busy
+	now := timer.since.Add(10 * time.Second)
+	if got := timer.throughputNoLock(now); got != 10 {
+		t.Errorf("Throughput = %v; want 10", got)
+	}
+	if got := timer.concurrencyNoLock(now); math.Abs(got-2) > 1e-9 {
+		t.Errorf("SuggestedConcurrency = %v; want 2", got)
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	timer := NewTimer()
+	timer.since = time.Now().Add(-time.Hour)
+	timer.Observe(time.Hour)
+	if u := timer.Utilization(2); u < 0.49 || u > 0.51 {
+		t.Errorf("Utilization(2) = %v; want about 0.5", u)
+	}
+	if u := timer.Utilization(0); u != 0 {
+		t.Errorf("Utilization(0) = %v; want 0", u)
+	}
+
+	var zero Timer
+	if zero.Throughput() != 0 || zero.SuggestedConcurrency() != 0 {
+		t.Errorf("Expected zero-value timer to report no throughput")
+	}
+}
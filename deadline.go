@@ -0,0 +1,139 @@
+package timer
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// deadlineStats accumulates how much of a context deadline budget was used.
+type deadlineStats struct {
+	count    uint64  // Number of observations made with a deadline
+	exceeded uint64  // Observations that used the whole budget or more
+	sum      float64 // Sum of utilization fractions
+	max      float64 // Largest utilization fraction
+	// Uniform sample of at most sampleSize observations, see
+	// SetDeadlineSampleSize
+	samples    []DeadlinePoint
+	sampleSize int
+}
+
+// reset clears the statistics, keeping the sample size.
+func (ds *deadlineStats) reset() {
+	*ds = deadlineStats{samples: ds.samples[:0], sampleSize: ds.sampleSize}
+}
+
+// sample adds p to the sample by reservoir sampling, so every observation
+// made with a deadline is retained with the same probability. Must be
+// called after count has been incremented.
+func (ds *deadlineStats) sample(p DeadlinePoint) {
+	if ds.sampleSize <= 0 {
+		return
+	}
+	if len(ds.samples) < ds.sampleSize {
+		ds.samples = append(ds.samples, p)
+		return
+	}
+	if i := rand.Uint64N(ds.count); i < uint64(ds.sampleSize) {
+		ds.samples[i] = p
+	}
+}
+
+// DeadlinePoint is a sampled observation made with UpdateWithContext: the
+// budget between start and the context deadline, and the actual duration.
+type DeadlinePoint struct {
+	Budget time.Duration `json:"budget_ns"`
+	Actual time.Duration `json:"actual_ns"`
+}
+
+// DeadlineStats describes how much of their context deadline budget the
+// operations recorded with UpdateWithContext consumed. Utilization is the
+// elapsed time divided by the time between start and the deadline, so 0.5
+// means half the budget was used and values above 1 mean it was overrun.
+type DeadlineStats struct {
+	Count    uint64  // Number of observations made with a deadline
+	Exceeded uint64  // Observations with utilization of 1 or more
+	Mean     float64 // Mean utilization, 0 if Count is 0
+	Max      float64 // Largest utilization, 0 if Count is 0
+}
+
+// UpdateWithContext records the duration since start like Update and, if
+// ctx has a deadline, also records the fraction of the budget between start
+// and the deadline that was consumed. Contexts without a deadline only
+// contribute to the duration statistics.
+// Returns ErrZeroTime if start is a zero time value, ErrClosed if the
+// timer was closed with the CloseError policy, ErrFrozen if it is frozen
+// with SetNoop, and ErrNegativeDuration for a start in the future under
+// the NegativeError policy.
+func (t *Timer) UpdateWithContext(ctx context.Context, start time.Time) error {
+	if start.IsZero() {
+		return ErrZeroTime
+	}
+	if t.off() {
+		return t.offErr()
+	}
+	d, err := t.elapsed(start, time.Now())
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Observe(d)
+		return nil
+	}
+
+	budget := deadline.Sub(start)
+	util := 1.0
+	if budget > 0 {
+		util = float64(d) / float64(budget)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.observeNoLock(d)
+	t.deadline.count++
+	t.deadline.sum += util
+	t.deadline.max = max(t.deadline.max, util)
+	if util >= 1 {
+		t.deadline.exceeded++
+	}
+	t.deadline.sample(DeadlinePoint{Budget: budget, Actual: d})
+	return nil
+}
+
+// DeadlineUtilization returns statistics about how much of their deadline
+// budget the operations recorded with UpdateWithContext consumed.
+func (t *Timer) DeadlineUtilization() DeadlineStats {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	ds := DeadlineStats{
+		Count:    t.deadline.count,
+		Exceeded: t.deadline.exceeded,
+		Max:      t.deadline.max,
+	}
+	if ds.Count > 0 {
+		ds.Mean = t.deadline.sum / float64(ds.Count)
+	}
+	return ds
+}
+
+// SetDeadlineSampleSize makes t retain a uniform random sample of up to n
+// (budget, actual duration) pairs of the observations made with
+// UpdateWithContext, for scatter plots showing whether timeouts are
+// systematically too tight or too loose. A size of 0, the default,
+// disables sampling. Changing the size clears the current sample.
+func (t *Timer) SetDeadlineSampleSize(n int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.deadline.sampleSize = max(n, 0)
+	t.deadline.samples = nil
+}
+
+// DeadlineSamples returns a copy of the sampled (budget, actual duration)
+// pairs, in no particular order. It encodes to JSON as an array of
+// {"budget_ns":...,"actual_ns":...} objects.
+func (t *Timer) DeadlineSamples() []DeadlinePoint {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return append(make([]DeadlinePoint, 0, len(t.deadline.samples)), t.deadline.samples...)
+}
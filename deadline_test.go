@@ -0,0 +1,112 @@
+package timer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateWithContext(t *testing.T) {
+	timer := NewTimer()
+
+	// no deadline: only duration stats are recorded
+	if err := timer.UpdateWithContext(context.Background(), time.Now().Add(-10*time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if timer.Count() != 1 {
+		t.Errorf("Expected count to be 1, got %d", timer.Count())
+	}
+	if ds := timer.DeadlineUtilization(); ds != (DeadlineStats{}) {
+		t.Errorf("Expected no deadline stats without a deadline, got %+v", ds)
+	}
+
+	// 100ms of a ~1s budget used
+	start := time.Now().Add(-100 * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.Background(), start.Add(time.Second))
+	defer cancel()
+	if err := timer.UpdateWithContext(ctx, start); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ds := timer.DeadlineUtilization()
+	if ds.Count != 1 || ds.Exceeded != 0 {
+		t.Errorf("Expected 1 observation within budget, got %+v", ds)
+	}
+	if ds.Mean < 0.09 || ds.Mean > 0.5 {
+		t.Errorf("Expected utilization around 0.1, got %v", ds.Mean)
+	}
+
+	// deadline already passed
+	start = time.Now().Add(-200 * time.Millisecond)
+	ctx2, cancel2 := context.WithDeadline(context.Background(), start.Add(100*time.Millisecond))
+	defer cancel2()
+	if err := timer.UpdateWithContext(ctx2, start); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ds = timer.DeadlineUtilization()
+	if ds.Count != 2 || ds.Exceeded != 1 {
+		t.Errorf("Expected 1 of 2 observations to exceed budget, got %+v", ds)
+	}
+	if ds.Max < 2 {
+		t.Errorf("Expected max utilization of at least 2, got %v", ds.Max)
+	}
+	if timer.Count() != 3 {
+		t.Errorf("Expected count to be 3, got %d", timer.Count())
+	}
+
+	timer.Reset()
+	if ds := timer.DeadlineUtilization(); ds != (DeadlineStats{}) {
+		t.Errorf("Expected deadline stats to be cleared by Reset, got %+v", ds)
+	}
+}
+
+func TestUpdateWithContextZeroTime(t *testing.T) {
+	timer := NewTimer()
+	if err := timer.UpdateWithContext(context.Background(), time.Time{}); err == nil {
+		t.Errorf("Expected error when updating with zero time, got nil")
+	}
+	if timer.Count() != 0 {
+		t.Errorf("Expected count to be 0, got %d", timer.Count())
+	}
+}
+
+func TestDeadlineSamples(t *testing.T) {
+	timer := NewTimer()
+	start := time.Now().Add(-10 * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.Background(), start.Add(time.Second))
+	defer cancel()
+
+	_ = timer.UpdateWithContext(ctx, start)
+	if n := len(timer.DeadlineSamples()); n != 0 {
+		t.Errorf("Expected no samples by default, got %d", n)
+	}
+
+	timer.SetDeadlineSampleSize(4)
+	for range 100 {
+		_ = timer.UpdateWithContext(ctx, start)
+	}
+	_ = timer.UpdateWithContext(context.Background(), start)
+	samples := timer.DeadlineSamples()
+	if len(samples) != 4 {
+		t.Fatalf("Expected 4 samples, got %d", len(samples))
+	}
+	for _, p := range samples {
+		if p.Budget != time.Second || p.Actual < 10*time.Millisecond {
+			t.Errorf("Unexpected sample %+v", p)
+		}
+	}
+	b, err := json.Marshal(samples[:1])
+	if err != nil || !strings.HasPrefix(string(b), `[{"budget_ns":1000000000,"actual_ns":`) {
+		t.Errorf("Unexpected JSON %s, %v", b, err)
+	}
+
+	timer.Reset()
+	if n := len(timer.DeadlineSamples()); n != 0 {
+		t.Errorf("Expected Reset to clear samples, got %d", n)
+	}
+	_ = timer.UpdateWithContext(ctx, start)
+	if n := len(timer.DeadlineSamples()); n != 1 {
+		t.Errorf("Expected Reset to keep the sample size, got %d samples", n)
+	}
+}
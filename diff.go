@@ -0,0 +1,58 @@
+package timer
+
+import (
+	"strconv"
+	"time"
+)
+
+// Diff describes how after differs from before in a single human-readable
+// line, for perf reports and PR descriptions:
+//
+//	count 100 -> 120 (+20.0%), mean 10ms -> 11.25ms (+12.5%, +1.25ms), min 1ms -> 1ms (+0.0%, 0s), max 40ms -> 80ms (+100.0%, +40ms)
+//
+// Relative changes from a zero baseline are shown as "n/a".
+func Diff(before, after Snapshot) string {
+	b := make([]byte, 0, 160)
+	b = append(b, "count "...)
+	b = strconv.AppendUint(b, before.Count, 10)
+	b = append(b, " -> "...)
+	b = strconv.AppendUint(b, after.Count, 10)
+	b = append(b, " ("...)
+	b = appendChange(b, float64(before.Count), float64(after.Count))
+	b = append(b, ')')
+	b = appendDurationDiff(b, "mean", before.Mean(), after.Mean())
+	b = appendDurationDiff(b, "min", before.Min, after.Min)
+	b = appendDurationDiff(b, "max", before.Max, after.Max)
+	return string(b)
+}
+
+// appendDurationDiff appends ", name BEFORE -> AFTER (+X%, +D)".
+func appendDurationDiff(b []byte, name string, before, after time.Duration) []byte {
+	b = append(b, ", "...)
+	b = append(b, name...)
+	b = append(b, ' ')
+	b = append(b, before.String()...)
+	b = append(b, " -> "...)
+	b = append(b, after.String()...)
+	b = append(b, " ("...)
+	b = appendChange(b, float64(before), float64(after))
+	b = append(b, ", "...)
+	if after > before {
+		b = append(b, '+')
+	}
+	b = append(b, (after - before).String()...)
+	return append(b, ')')
+}
+
+// appendChange appends the signed relative change from before to after.
+func appendChange(b []byte, before, after float64) []byte {
+	if before == 0 {
+		return append(b, "n/a"...)
+	}
+	change := (after - before) / before * 100
+	if change >= 0 {
+		b = append(b, '+')
+	}
+	b = strconv.AppendFloat(b, change, 'f', 1, 64)
+	return append(b, '%')
+}
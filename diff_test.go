@@ -0,0 +1,27 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	before := Snapshot{Count: 100, Min: time.Millisecond, Max: 40 * time.Millisecond, Sum: time.Second}
+	after := Snapshot{Count: 120, Min: time.Millisecond, Max: 80 * time.Millisecond, Sum: 1350 * time.Millisecond}
+	want := "count 100 -> 120 (+20.0%), mean 10ms -> 11.25ms (+12.5%, +1.25ms), min 1ms -> 1ms (+0.0%, 0s), max 40ms -> 80ms (+100.0%, +40ms)"
+	if got := Diff(before, after); got != want {
+		t.Errorf("Diff() = %q; want %q", got, want)
+	}
+	want = "count 120 -> 100 (-16.7%), mean 11.25ms -> 10ms (-11.1%, -1.25ms), min 1ms -> 1ms (+0.0%, 0s), max 80ms -> 40ms (-50.0%, -40ms)"
+	if got := Diff(after, before); got != want {
+		t.Errorf("Diff() = %q; want %q", got, want)
+	}
+}
+
+func TestDiffEmptyBaseline(t *testing.T) {
+	after := Snapshot{Count: 1, Min: time.Millisecond, Max: time.Millisecond, Sum: time.Millisecond}
+	want := "count 0 -> 1 (n/a), mean 0s -> 1ms (n/a, +1ms), min 0s -> 1ms (n/a, +1ms), max 0s -> 1ms (n/a, +1ms)"
+	if got := Diff(Snapshot{}, after); got != want {
+		t.Errorf("Diff() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package timer
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DigestConfig configures Digest.
+type DigestConfig struct {
+	// Title is the first line of the digest, if not empty.
+	Title string
+	// TopN is the number of timers listed per section. Defaults to 5.
+	TopN int
+	// Metric ranks timers and measures regressions. Defaults to
+	// Snapshot.Mean.
+	Metric func(Snapshot) time.Duration
+	// MetricName labels the metric in the section headings. Defaults to
+	// "mean".
+	MetricName string
+	// Slack marks headings with Slack's *bold* instead of Markdown's
+	// **bold**.
+	Slack bool
+}
+
+// Digest returns a compact Markdown summary of cur for posting to chat:
+// the TopN slowest timers by the configured metric, and the TopN biggest
+// relative regressions against prev, the snapshots of the previous
+// interval:
+//
+//	**Slowest by mean**
+//	1. `db` 12.3ms (n=1200)
+//	**Regressions by mean**
+//	1. `db` 10.1ms → 12.3ms (+21.8%)
+//
+// Timers without observations are skipped, and so are regressions from a
+// timer missing or empty in prev. A section with no entries reads
+// "none".
+func Digest(prev, cur map[string]Snapshot, cfg DigestConfig) string {
+	if cfg.TopN <= 0 {
+		cfg.TopN = 5
+	}
+	if cfg.Metric == nil {
+		cfg.Metric = Snapshot.Mean
+	}
+	if cfg.MetricName == "" {
+		cfg.MetricName = "mean"
+	}
+	bold := "**"
+	if cfg.Slack {
+		bold = "*"
+	}
+
+	type entry struct {
+		name          string
+		before, after time.Duration
+		change        float64
+		count         uint64
+	}
+	var slowest, regressed []entry
+	for _, name := range sortedNames(cur) {
+		s := cur[name]
+		if s.Count == 0 {
+			continue
+		}
+		e := entry{name: name, after: cfg.Metric(s), count: s.Count}
+		slowest = append(slowest, e)
+		if p, ok := prev[name]; ok && p.Count > 0 {
+			e.before = cfg.Metric(p)
+			if e.before > 0 && e.after > e.before {
+				e.change = float64(e.after-e.before) / float64(e.before)
+				regressed = append(regressed, e)
+			}
+		}
+	}
+	slices.SortStableFunc(slowest, func(a, b entry) int { return cmp.Compare(b.after, a.after) })
+	slices.SortStableFunc(regressed, func(a, b entry) int { return cmp.Compare(b.change, a.change) })
+
+	var b []byte
+	if cfg.Title != "" {
+		b = append(b, cfg.Title...)
+		b = append(b, '\n')
+	}
+	b = append(b, bold+"Slowest by "...)
+	b = append(b, cfg.MetricName...)
+	b = append(b, bold+"\n"...)
+	for i, e := range slowest[:min(cfg.TopN, len(slowest))] {
+		b = appendDigestName(b, i, e.name)
+		b = append(b, formatDuration(e.after)...)
+		b = append(b, " (n="...)
+		b = strconv.AppendUint(b, e.count, 10)
+		b = append(b, ")\n"...)
+	}
+	if len(slowest) == 0 {
+		b = append(b, "none\n"...)
+	}
+	b = append(b, bold+"Regressions by "...)
+	b = append(b, cfg.MetricName...)
+	b = append(b, bold+"\n"...)
+	for i, e := range regressed[:min(cfg.TopN, len(regressed))] {
+		b = appendDigestName(b, i, e.name)
+		b = append(b, formatDuration(e.before)...)
+		b = append(b, " → "...)
+		b = append(b, formatDuration(e.after)...)
+		b = append(b, " ("...)
+		b = appendChange(b, float64(e.before), float64(e.after))
+		b = append(b, ")\n"...)
+	}
+	if len(regressed) == 0 {
+		b = append(b, "none\n"...)
+	}
+	return string(b)
+}
+
+// appendDigestName appends "N. `name` " for the i-th entry of a list.
+func appendDigestName(b []byte, i int, name string) []byte {
+	b = strconv.AppendInt(b, int64(i+1), 10)
+	b = append(b, ". `"...)
+	b = append(b, name...)
+	return append(b, "` "...)
+}
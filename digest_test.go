@@ -0,0 +1,42 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDigest(t *testing.T) {
+	snap := func(n uint64, mean time.Duration) Snapshot {
+		return Snapshot{Count: n, Min: mean, Max: mean, Sum: mean * time.Duration(n)}
+	}
+	prev := map[string]Snapshot{
+		"db":    snap(100, 10*time.Millisecond),
+		"cache": snap(100, time.Millisecond),
+		"api":   snap(100, 50*time.Millisecond),
+	}
+	cur := map[string]Snapshot{
+		"db":    snap(120, 12300*time.Microsecond),
+		"cache": snap(90, 2*time.Millisecond),
+		"api":   snap(80, 40*time.Millisecond),
+		"new":   snap(1, time.Second),
+		"idle":  {},
+	}
+	got := Digest(prev, cur, DigestConfig{Title: "Hourly latency", TopN: 3})
+	want := "Hourly latency\n" +
+		"**Slowest by mean**\n" +
+		"1. `new` 1s (n=1)\n" +
+		"2. `api` 40ms (n=80)\n" +
+		"3. `db` 12.3ms (n=120)\n" +
+		"**Regressions by mean**\n" +
+		"1. `cache` 1ms → 2ms (+100.0%)\n" +
+		"2. `db` 10ms → 12.3ms (+23.0%)\n"
+	if got != want {
+		t.Errorf("Expected digest:\n%s\ngot:\n%s", want, got)
+	}
+
+	got = Digest(nil, nil, DigestConfig{Slack: true, Metric: func(s Snapshot) time.Duration { return s.Max }, MetricName: "max"})
+	want = "*Slowest by max*\nnone\n*Regressions by max*\nnone\n"
+	if got != want {
+		t.Errorf("Expected empty digest %q, got %q", want, got)
+	}
+}
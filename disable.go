@@ -0,0 +1,70 @@
+package timer
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrFrozen is returned when updating a timer whose recording is turned
+// off with SetNoop.
+var ErrFrozen = errors.New("timer is frozen")
+
+// disabled turns recording into a no-op for all timers.
+var disabled atomic.Bool
+
+// Disable turns recording by every Timer into a near-free no-op, for
+// latency-sensitive deployments that want no stats collection at all.
+// Reads and exports keep working and return the statistics recorded
+// while enabled.
+func Disable() {
+	disabled.Store(true)
+}
+
+// Enable turns recording back on after Disable.
+func Enable() {
+	disabled.Store(false)
+}
+
+// Enabled reports whether recording is globally enabled.
+func Enabled() bool {
+	return !disabled.Load()
+}
+
+// SetNoop turns recording by t into a no-op if noop is true, regardless
+// of the global state, and back on if it is false. While frozen, Update,
+// UpdateAt and UpdateWithContext return ErrFrozen. Changes are logged as
+// EventFrozen and EventThawed.
+func (t *Timer) SetNoop(noop bool) {
+	if t.noop.Swap(noop) == noop {
+		return
+	}
+	if noop {
+		t.logEvent(EventFrozen)
+	} else {
+		t.logEvent(EventThawed)
+	}
+}
+
+// Noop reports whether recording by t is turned off with SetNoop.
+func (t *Timer) Noop() bool {
+	return t.noop.Load()
+}
+
+// off reports whether recording by t is currently a no-op.
+func (t *Timer) off() bool {
+	return disabled.Load() || t.noop.Load() || t.closed.Load()
+}
+
+// offErr returns the error for an update dropped because t is off:
+// ErrClosed if t was closed with the CloseError policy, ErrFrozen if it
+// is frozen with SetNoop, and nil if it is closed with CloseNoop or
+// recording is disabled globally.
+func (t *Timer) offErr() error {
+	if err := t.closedErr(); err != nil {
+		return err
+	}
+	if t.noop.Load() {
+		return ErrFrozen
+	}
+	return nil
+}
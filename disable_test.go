@@ -0,0 +1,80 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDisable(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(time.Millisecond)
+
+	Disable()
+	defer Enable()
+	if Enabled() {
+		t.Errorf("Expected Enabled to be false after Disable")
+	}
+	timer.Observe(time.Second)
+	_ = timer.Update(time.Now())
+	_ = timer.UpdateWithContext(context.Background(), time.Now())
+	timer.ObservePanic(time.Second)
+	ran := false
+	timer.Time(func() { ran = true })
+	if d := timer.Start().Stop(); d != 0 {
+		t.Errorf("Expected no-op stopwatch, got %v", d)
+	}
+	if !ran {
+		t.Errorf("Expected Time to run fn while disabled")
+	}
+	if err := timer.Update(time.Time{}); err == nil {
+		t.Errorf("Expected zero start to be rejected while disabled")
+	}
+	if timer.Count() != 1 || timer.Max() != time.Millisecond || timer.InFlight() != 0 {
+		t.Errorf("Expected nothing recorded while disabled, got %v", timer)
+	}
+
+	Enable()
+	timer.Observe(time.Second)
+	if timer.Count() != 2 {
+		t.Errorf("Expected recording after Enable, got count %d", timer.Count())
+	}
+}
+
+func TestSetNoop(t *testing.T) {
+	quiet, loud := NewTimer(), NewTimer()
+	quiet.SetNoop(true)
+	if !quiet.Noop() || loud.Noop() {
+		t.Errorf("Unexpected Noop state")
+	}
+	quiet.Observe(time.Millisecond)
+	loud.Observe(time.Millisecond)
+	if quiet.Count() != 0 || loud.Count() != 1 {
+		t.Errorf("Expected only the noop timer to ignore observations")
+	}
+	now := time.Now()
+	if err := quiet.Update(now); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Update() = %v; want ErrFrozen", err)
+	}
+	if err := quiet.UpdateAt(now, now); !errors.Is(err, ErrFrozen) {
+		t.Errorf("UpdateAt() = %v; want ErrFrozen", err)
+	}
+	if err := quiet.UpdateWithContext(context.Background(), now); !errors.Is(err, ErrFrozen) {
+		t.Errorf("UpdateWithContext() = %v; want ErrFrozen", err)
+	}
+	quiet.SetNoop(false)
+	quiet.Observe(time.Millisecond)
+	if quiet.Count() != 1 {
+		t.Errorf("Expected recording after SetNoop(false)")
+	}
+}
+
+func BenchmarkObserveDisabled(b *testing.B) {
+	timer := NewTimer()
+	Disable()
+	defer Enable()
+	for b.Loop() {
+		timer.Observe(time.Millisecond)
+	}
+}
@@ -0,0 +1,135 @@
+package timer
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// AppendText appends the snapshot in LayoutText, the layout of Timer.String, to b.
+// It implements encoding.TextAppender and does not allocate if b has
+// enough capacity.
+func (s Snapshot) AppendText(b []byte) ([]byte, error) {
+	return s.AppendFormat(b, LayoutText), nil
+}
+
+// AppendJSON appends the JSON encoding of the snapshot to b. The output is
+// identical to json.Marshal, but it does not allocate if b has enough
+// capacity.
+func (s Snapshot) AppendJSON(b []byte) []byte {
+	b = append(b, '{')
+	b = s.appendJSONFields(b)
+	return append(b, '}')
+}
+
+// MarshalJSON implements json.Marshaler using AppendJSON. It takes
+// precedence over AppendText, which encodes the human-readable layout.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	return s.AppendJSON(make([]byte, 0, 96)), nil
+}
+
+// appendJSONFields appends the snapshot's JSON fields without braces.
+func (s Snapshot) appendJSONFields(b []byte) []byte {
+	b = append(b, `"count":`...)
+	b = strconv.AppendUint(b, s.Count, 10)
+	b = append(b, `,"min_ns":`...)
+	b = strconv.AppendInt(b, int64(s.Min), 10)
+	b = append(b, `,"max_ns":`...)
+	b = strconv.AppendInt(b, int64(s.Max), 10)
+	b = append(b, `,"sum_ns":`...)
+	b = strconv.AppendInt(b, int64(s.Sum), 10)
+	if s.SumOverflowed {
+		b = append(b, `,"sum_overflowed":true`...)
+	}
+	if s.SumEpoch != 0 {
+		b = append(b, `,"sum_epoch":`...)
+		b = strconv.AppendUint(b, s.SumEpoch, 10)
+	}
+	if s.SumRemainder != 0 {
+		b = append(b, `,"sum_remainder_ns":`...)
+		b = strconv.AppendInt(b, int64(s.SumRemainder), 10)
+	}
+	if s.Panicked != 0 {
+		b = append(b, `,"panicked":`...)
+		b = strconv.AppendUint(b, s.Panicked, 10)
+	}
+	return b
+}
+
+// AppendText appends the snapshot prefixed by its name and a colon to b.
+// It implements encoding.TextAppender.
+func (ns NamedSnapshot) AppendText(b []byte) ([]byte, error) {
+	b = append(b, ns.Name...)
+	b = append(b, ": "...)
+	return ns.Snapshot.AppendText(b)
+}
+
+// AppendJSON appends the JSON encoding of the named snapshot to b,
+// identical to json.Marshal.
+func (ns NamedSnapshot) AppendJSON(b []byte) []byte {
+	b = append(b, `{"name":`...)
+	b = appendJSONString(b, ns.Name)
+	b = append(b, ',')
+	b = ns.Snapshot.appendJSONFields(b)
+	return append(b, '}')
+}
+
+// MarshalJSON implements json.Marshaler using AppendJSON.
+func (ns NamedSnapshot) MarshalJSON() ([]byte, error) {
+	return ns.AppendJSON(make([]byte, 0, 128)), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string, escaped the same way as
+// encoding/json (including HTML-sensitive characters).
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -0,0 +1,102 @@
+package timer
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	_ encoding.TextAppender = Snapshot{}
+	_ encoding.TextAppender = NamedSnapshot{}
+)
+
+// plainSnapshot has the fields of Snapshot but none of its methods, so
+// json.Marshal encodes it by reflection.
+type plainSnapshot Snapshot
+
+type plainNamedSnapshot struct {
+	Name string `json:"name"`
+	plainSnapshot
+}
+
+func TestSnapshotAppendJSON(t *testing.T) {
+	snaps := []Snapshot{
+		{},
+		{Count: 3, Min: time.Millisecond, Max: time.Second, Sum: 2 * time.Second},
+		{Count: math.MaxUint64, Min: -1, Max: math.MaxInt64, Sum: math.MaxInt64, SumOverflowed: true, Panicked: 7},
+	}
+	for _, s := range snaps {
+		want, err := json.Marshal(plainSnapshot(s))
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if got := s.AppendJSON(nil); string(got) != string(want) {
+			t.Errorf("AppendJSON = %s; want %s", got, want)
+		}
+		if got, _ := json.Marshal(s); string(got) != string(want) {
+			t.Errorf("json.Marshal = %s; want %s", got, want)
+		}
+	}
+}
+
+func TestNamedSnapshotAppendJSON(t *testing.T) {
+	names := []string{
+		"db.query",
+		`quote " and \ backslash`,
+		"control \x00\x1f\n\t\r\b\f",
+		"html <a href='x'>&</a>",
+		"unicode µs ☃ \u2028\u2029",
+		"invalid \xff\xfe utf8",
+		`http{method="GET"}`,
+	}
+	for _, name := range names {
+		ns := NamedSnapshot{Name: name, Snapshot: Snapshot{Count: 1, Min: 2, Max: 3, Sum: 4}}
+		want, err := json.Marshal(plainNamedSnapshot{Name: name, plainSnapshot: plainSnapshot(ns.Snapshot)})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if got := ns.AppendJSON(nil); string(got) != string(want) {
+			t.Errorf("AppendJSON(%q) = %s; want %s", name, got, want)
+		}
+		var back NamedSnapshot
+		if err := json.Unmarshal(want, &back); err != nil || back.Snapshot != ns.Snapshot {
+			t.Errorf("Unmarshal(%s) = %+v, %v; want %+v", want, back, err, ns)
+		}
+	}
+}
+
+func TestSnapshotAppendText(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(10 * time.Millisecond)
+	timer.Observe(20 * time.Millisecond)
+
+	got, err := timer.Snapshot().AppendText(nil)
+	if err != nil {
+		t.Fatalf("AppendText failed: %v", err)
+	}
+	if string(got) != timer.String() {
+		t.Errorf("AppendText = %q; want %q", got, timer.String())
+	}
+
+	named, _ := NamedSnapshot{Name: "db", Snapshot: timer.Snapshot()}.AppendText(nil)
+	if want := "db: " + timer.String(); string(named) != want {
+		t.Errorf("NamedSnapshot AppendText = %q; want %q", named, want)
+	}
+}
+
+func TestAppendEncodersZeroAllocs(t *testing.T) {
+	if testing.CoverMode() != "" || raceEnabled {
+		t.Skip("allocation counts are not meaningful under coverage or race instrumentation")
+	}
+	ns := NamedSnapshot{Name: "db.query", Snapshot: Snapshot{Count: 3, Min: time.Millisecond, Max: time.Second, Sum: 2 * time.Second}}
+	buf := make([]byte, 0, 256)
+	if allocs := testing.AllocsPerRun(100, func() { buf = ns.AppendJSON(buf[:0]) }); allocs != 0 {
+		t.Errorf("AppendJSON allocated %v times per run; want 0", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { buf, _ = ns.AppendText(buf[:0]) }); allocs != 0 {
+		t.Errorf("AppendText allocated %v times per run; want 0", allocs)
+	}
+}
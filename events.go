@@ -0,0 +1,135 @@
+package timer
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventKind is the kind of a lifecycle Event.
+type EventKind int
+
+const (
+	// EventReset is logged by Timer.Reset.
+	EventReset EventKind = iota
+	// EventOverflow is logged when the sum of a timer first overflows,
+	// from when on its Mean is an underestimate.
+	EventOverflow
+	// EventFrozen is logged when Timer.SetNoop turns recording off.
+	EventFrozen
+	// EventThawed is logged when Timer.SetNoop turns recording back on.
+	EventThawed
+	// EventClosed is logged by Timer.Close.
+	EventClosed
+	// EventEvicted is logged when an IdleEvictor removes a timer from its
+	// registry, or a TimerVec over its limit drops a child.
+	EventEvicted
+)
+
+// String returns the name of the kind, such as "reset".
+func (k EventKind) String() string {
+	switch k {
+	case EventReset:
+		return "reset"
+	case EventOverflow:
+		return "overflow"
+	case EventFrozen:
+		return "frozen"
+	case EventThawed:
+		return "thawed"
+	case EventClosed:
+		return "closed"
+	case EventEvicted:
+		return "evicted"
+	}
+	return "unknown"
+}
+
+// Event is a lifecycle event of a timer, such as a reset, which explains
+// why its statistics dropped or stopped changing.
+type Event struct {
+	Time time.Time
+	Name string // name of the timer, set by Registry.Events
+	Kind EventKind
+}
+
+// maxTimerEvents is the number of events a timer keeps.
+const maxTimerEvents = 16
+
+// maxRemovedEvents is the number of evictions a registry keeps.
+const maxRemovedEvents = 256
+
+// logEvent appends an event of the given kind to t's log.
+func (t *Timer) logEvent(kind EventKind) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.logEventNoLock(kind)
+}
+
+// logEventNoLock appends an event of the given kind to t's log, dropping
+// the oldest if the log is full.
+// Callers must hold the write lock.
+func (t *Timer) logEventNoLock(kind EventKind) {
+	if len(t.events) == maxTimerEvents {
+		t.events = slices.Delete(t.events, 0, 1)
+	}
+	t.events = append(t.events, Event{Time: t.now(), Kind: kind})
+}
+
+// Events returns the most recent lifecycle events of t, oldest first.
+func (t *Timer) Events() []Event {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return slices.Clone(t.events)
+}
+
+// logRemoval records the eviction of the timer named name, relative to r,
+// so Events still explains its disappearance once it is gone.
+func (r *Registry) logRemoval(name string, at time.Time) {
+	s := r.store
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.removed) == maxRemovedEvents {
+		s.removed = slices.Delete(s.removed, 0, 1)
+	}
+	s.removed = append(s.removed, Event{Time: at, Name: r.prefix + name, Kind: EventEvicted})
+}
+
+// Events returns the lifecycle events of every registered timer, named
+// like Snapshot names them, and the evictions of timers an IdleEvictor
+// removed, ordered by time.
+func (r *Registry) Events() []Event {
+	var events []Event
+	add := func(name string, t *Timer) {
+		for _, e := range t.Events() {
+			e.Name = name
+			events = append(events, e)
+		}
+	}
+	s := r.store
+	s.mutex.RLock()
+	for name, t := range s.timers {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			add(rel, t)
+		}
+	}
+	for name, v := range s.vecs {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			v.eachChild(func(labels string, t *Timer) {
+				add(rel+"{"+labels+"}", t)
+			})
+		}
+	}
+	for _, e := range s.removed {
+		if rel, ok := strings.CutPrefix(e.Name, r.prefix); ok {
+			e.Name = rel
+			events = append(events, e)
+		}
+	}
+	s.mutex.RUnlock()
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return cmp.Or(a.Time.Compare(b.Time), cmp.Compare(a.Name, b.Name))
+	})
+	return events
+}
@@ -0,0 +1,93 @@
+package timer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func eventKinds(events []Event) []EventKind {
+	kinds := make([]EventKind, len(events))
+	for i, e := range events {
+		kinds[i] = e.Kind
+	}
+	return kinds
+}
+
+func TestTimerEvents(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(time.Duration(math.MaxInt64))
+	timer.Observe(time.Duration(math.MaxInt64)) // already overflowed, not logged again
+	timer.Reset()
+	timer.SetNoop(true)
+	timer.SetNoop(true) // no change, not logged
+	timer.SetNoop(false)
+	_ = timer.Close()
+
+	got := eventKinds(timer.Events())
+	want := []EventKind{EventOverflow, EventReset, EventFrozen, EventThawed, EventClosed}
+	if len(got) != len(want) {
+		t.Fatalf("Events() = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Events()[%d] = %v; want %v", i, got[i], want[i])
+		}
+	}
+
+	// the log is bounded
+	for range 2 * maxTimerEvents {
+		timer.Reset()
+	}
+	if n := len(timer.Events()); n != maxTimerEvents {
+		t.Errorf("Expected %d events kept, got %d", maxTimerEvents, n)
+	}
+}
+
+func TestRegistryEvents(t *testing.T) {
+	r := NewRegistry()
+	sub := r.SubRegistry("db")
+	sub.GetOrCreate("query").Reset()
+	idle := r.GetOrCreate("idle")
+	v := NewTimerVec("op")
+	if err := r.RegisterVec("rpc", v); err != nil {
+		t.Fatal(err)
+	}
+	v.WithLabelValues("get").Reset()
+
+	e := NewIdleEvictor(r, time.Minute, nil)
+	now := time.Now()
+	e.sweep(now)
+	r.GetOrCreate("db.query").Observe(time.Millisecond)
+	e.sweep(now.Add(time.Hour))
+	if r.Get("idle") != nil {
+		t.Fatalf("Expected idle to be evicted")
+	}
+	if kinds := eventKinds(idle.Events()); len(kinds) != 1 || kinds[0] != EventEvicted {
+		t.Errorf("Expected the evicted timer to log its eviction, got %v", kinds)
+	}
+
+	names := make(map[string]EventKind)
+	for _, ev := range r.Events() {
+		names[ev.Name] = ev.Kind
+	}
+	want := map[string]EventKind{"db.query": EventReset, `rpc{op="get"}`: EventReset, "idle": EventEvicted}
+	for name, kind := range want {
+		if got, ok := names[name]; !ok || got != kind {
+			t.Errorf("Expected a %v event for %s, got %v", kind, name, names)
+		}
+	}
+
+	// sub-registries only see their own timers
+	for _, ev := range sub.Events() {
+		if ev.Name != "query" {
+			t.Errorf("Expected only the query timer in the sub-registry, got %q", ev.Name)
+		}
+	}
+}
+
+func TestEventKindString(t *testing.T) {
+	if EventEvicted.String() != "evicted" || EventKind(99).String() != "unknown" {
+		t.Errorf("Unexpected event kind names %q, %q", EventEvicted, EventKind(99))
+	}
+}
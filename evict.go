@@ -0,0 +1,122 @@
+package timer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IdleEvictor removes timers from a registry once they have not been
+// observed for a configured TTL, keeping registries bounded in services
+// whose timer names come and go.
+//
+// Activity is detected by comparing each timer's count between sweeps, so
+// observing stays free of extra bookkeeping. A timer is evicted between TTL
+// and TTL plus one sweep interval after its last observation. Vecs are not
+// evicted; use TimerVec.SetLimit to bound them.
+type IdleEvictor struct {
+	registry *Registry
+	ttl      time.Duration
+	interval time.Duration
+	onEvict  func(name string, last Snapshot)
+
+	mutex sync.Mutex
+	seen  map[*Timer]idleState
+	lc    lifecycle
+}
+
+// idleState is what the evictor remembers about a timer between sweeps.
+type idleState struct {
+	count  uint64    // Count at the last sweep
+	active time.Time // When the count was last seen to change
+}
+
+// NewIdleEvictor creates an IdleEvictor removing timers of r idle for at
+// least ttl. If onEvict is non-nil it is called with the name and final
+// snapshot of each evicted timer, after it has been removed. A
+// non-positive ttl disables eviction rather than evicting every timer.
+func NewIdleEvictor(r *Registry, ttl time.Duration, onEvict func(name string, last Snapshot)) *IdleEvictor {
+	return &IdleEvictor{
+		registry: r,
+		ttl:      ttl,
+		interval: max(ttl/4, time.Millisecond),
+		onEvict:  onEvict,
+		seen:     make(map[*Timer]idleState),
+	}
+}
+
+// Start begins sweeping every quarter of the TTL until ctx is done or Close
+// is called.
+func (e *IdleEvictor) Start(ctx context.Context) error {
+	return e.lc.start(ctx, e, e.run)
+}
+
+func (e *IdleEvictor) run(ctx context.Context) {
+	if e.ttl <= 0 {
+		<-ctx.Done()
+		return
+	}
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.sweep(now)
+		}
+	}
+}
+
+// Sweep evicts idle timers immediately and returns how many were removed.
+func (e *IdleEvictor) Sweep() int {
+	return e.sweep(time.Now())
+}
+
+func (e *IdleEvictor) sweep(now time.Time) int {
+	if e.ttl <= 0 {
+		return 0
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	r := e.registry
+	s := r.store
+	s.mutex.RLock()
+	timers := make(map[string]*Timer)
+	for name, t := range s.timers {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			timers[rel] = t
+		}
+	}
+	s.mutex.RUnlock()
+
+	evicted := 0
+	seen := make(map[*Timer]idleState, len(timers))
+	for name, t := range timers {
+		count := t.Count()
+		st, ok := e.seen[t]
+		if !ok || st.count != count {
+			st = idleState{count: count, active: now}
+		}
+		if now.Sub(st.active) < e.ttl || !r.unregisterTimer(name, t) {
+			seen[t] = st
+			continue
+		}
+		evicted++
+		t.logEvent(EventEvicted)
+		r.logRemoval(name, now)
+		if e.onEvict != nil {
+			e.onEvict(name, t.Snapshot())
+		}
+	}
+	e.seen = seen
+	return evicted
+}
+
+// Close stops sweeping.
+func (e *IdleEvictor) Close() error {
+	e.lc.close()
+	return nil
+}
@@ -0,0 +1,99 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIdleEvictorSweep(t *testing.T) {
+	r := NewRegistry()
+	idle := r.GetOrCreate("idle")
+	busy := r.GetOrCreate("busy")
+	idle.Observe(time.Millisecond)
+
+	var evicted []string
+	var last Snapshot
+	e := NewIdleEvictor(r, time.Minute, func(name string, s Snapshot) {
+		evicted = append(evicted, name)
+		last = s
+	})
+
+	now := time.Now()
+	if n := e.sweep(now); n != 0 {
+		t.Errorf("Expected nothing evicted on first sweep, got %d", n)
+	}
+
+	busy.Observe(time.Millisecond)
+	if n := e.sweep(now.Add(59 * time.Second)); n != 0 {
+		t.Errorf("Expected nothing evicted before TTL, got %d", n)
+	}
+
+	// idle has not changed for a full minute, busy was observed 1s ago
+	if n := e.sweep(now.Add(61 * time.Second)); n != 1 {
+		t.Errorf("Expected 1 eviction, got %d", n)
+	}
+	if len(evicted) != 1 || evicted[0] != "idle" || last.Count != 1 {
+		t.Errorf("Expected idle to be evicted with its final snapshot, got %v %+v", evicted, last)
+	}
+	if r.Get("idle") != nil || r.Get("busy") == nil {
+		t.Errorf("Expected only idle to be removed from the registry")
+	}
+
+	if n := e.sweep(now.Add(2 * time.Minute)); n != 1 || r.Get("busy") != nil {
+		t.Errorf("Expected busy to be evicted once idle, got %d", n)
+	}
+}
+
+func TestIdleEvictorNonPositiveTTL(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("db").Observe(time.Millisecond)
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		e := NewIdleEvictor(r, ttl, nil)
+		if err := e.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if n := e.sweep(time.Now().Add(time.Hour)); n != 0 {
+			t.Errorf("Expected ttl %v to disable eviction, got %d evicted", ttl, n)
+		}
+		_ = e.Close()
+	}
+	if r.Get("db") == nil {
+		t.Errorf("Expected db to stay registered")
+	}
+}
+
+func TestIdleEvictorSubRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("outside")
+	db := r.SubRegistry("db")
+	db.GetOrCreate("query")
+
+	e := NewIdleEvictor(db, time.Minute, nil)
+	now := time.Now()
+	e.sweep(now)
+	if n := e.sweep(now.Add(time.Hour)); n != 1 {
+		t.Errorf("Expected 1 eviction, got %d", n)
+	}
+	if r.Get("outside") == nil || r.Get("db.query") != nil {
+		t.Errorf("Expected only timers in the view's namespace to be evicted")
+	}
+}
+
+func TestIdleEvictorStart(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("idle")
+
+	done := make(chan struct{})
+	e := NewIdleEvictor(r, 20*time.Millisecond, func(string, Snapshot) { close(done) })
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for eviction")
+	}
+	_ = e.Close()
+	checkNoLeaks(t)
+}
@@ -0,0 +1,75 @@
+package timer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// exitFlushTimeout bounds how long flushing on a signal may delay exit.
+const exitFlushTimeout = 5 * time.Second
+
+// Sink receives a final Dump of a registry. The sinks in this package
+// write it in the JSON Dump format read by cmd/timerstat and ParseDump,
+// so the distributions of timers with an ExpHistogram are kept.
+type Sink func(d Dump) error
+
+// WriterSink returns a Sink writing the dump to w, e.g. os.Stderr.
+func WriterSink(w io.Writer) Sink {
+	return func(d Dump) error {
+		return json.NewEncoder(w).Encode(d)
+	}
+}
+
+// FileSink returns a Sink writing the dump to the file at path,
+// replacing any previous content.
+func FileSink(path string) Sink {
+	return func(d Dump) error {
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, append(b, '\n'), 0o644)
+	}
+}
+
+// HTTPSink returns a Sink POSTing the dump as JSON to url.
+// Any non-2xx response is an error.
+func HTTPSink(url string) Sink {
+	return func(d Dump) error {
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exitFlushTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("posting dump: %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// FinalFlush shuts down every running component, flushing reporters, and
+// then writes a Dump of r to sink. Short-lived programs can call it
+// with defer from main so their timings are not lost.
+func FinalFlush(ctx context.Context, r *Registry, sink Sink) error {
+	err := Shutdown(ctx)
+	return errors.Join(err, sink(r.Dump()))
+}
@@ -0,0 +1,54 @@
+//go:build !js && !wasip1
+
+package timer
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// exitAfterSignal terminates the process after flushing on sig.
+// Replaced in tests.
+var exitAfterSignal = func(sig os.Signal) {
+	// re-deliver the signal with default handling so the exit status
+	// reflects it; fall back to a plain failure exit
+	signal.Reset(sig)
+	if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+		time.Sleep(time.Second)
+	}
+	os.Exit(1)
+}
+
+// FlushOnSignal calls FinalFlush with r and sink when the process receives
+// one of signals (SIGINT and SIGTERM if none are given), then terminates
+// the process with the signal's default behavior. The returned function
+// removes the handler.
+func FlushOnSignal(r *Registry, sink Sink, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-ch:
+			ctx, cancel := context.WithTimeout(context.Background(), exitFlushTimeout)
+			_ = FinalFlush(ctx, r, sink)
+			cancel()
+			exitAfterSignal(sig)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package timer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSinks(t *testing.T) {
+	r := NewRegistry()
+	db := r.GetOrCreate("db")
+	_ = db.AddAggregator("hist", NewExpHistogram(0))
+	db.Observe(time.Millisecond)
+	d := r.Dump()
+
+	check := func(sink string, b []byte) {
+		t.Helper()
+		got, err := ParseDump(b)
+		if err != nil {
+			t.Errorf("%s wrote %q, err %v", sink, b, err)
+			return
+		}
+		if got.Version != WireVersion || got.Snapshots["db"] != d.Snapshots["db"] {
+			t.Errorf("%s wrote %+v; want %+v", sink, got, d)
+		}
+		if got.Histograms["db"].Count != 1 {
+			t.Errorf("Expected %s to keep the histogram, got %v", sink, got.Histograms)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriterSink(&buf)(d); err != nil {
+		t.Fatalf("WriterSink failed: %v", err)
+	}
+	check("WriterSink", buf.Bytes())
+
+	path := filepath.Join(t.TempDir(), "timers.json")
+	if err := FileSink(path)(d); err != nil {
+		t.Fatalf("FileSink failed: %v", err)
+	}
+	b, _ := os.ReadFile(path)
+	check("FileSink", b)
+
+	var posted []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		posted, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	if err := HTTPSink(srv.URL)(d); err != nil {
+		t.Fatalf("HTTPSink failed: %v", err)
+	}
+	check("HTTPSink", posted)
+
+	failing := httptest.NewServer(http.NotFoundHandler())
+	defer failing.Close()
+	if err := HTTPSink(failing.URL)(d); err == nil {
+		t.Errorf("Expected error for non-2xx response")
+	}
+}
+
+func TestFinalFlush(t *testing.T) {
+	r := NewRegistry()
+	t0 := r.GetOrCreate("db")
+
+	var reported uint64
+	rp := NewReporter(r, time.Hour, func(snaps map[string]Snapshot) { reported = snaps["db"].Count })
+	_ = rp.Start(context.Background())
+	t0.Observe(time.Millisecond)
+
+	var final map[string]Snapshot
+	err := FinalFlush(context.Background(), r, func(d Dump) error {
+		final = d.Snapshots
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FinalFlush failed: %v", err)
+	}
+	if reported != 1 {
+		t.Errorf("Expected reporter to be flushed, reported count %d", reported)
+	}
+	if final["db"].Count != 1 {
+		t.Errorf("Expected final snapshot to be written, got %v", final)
+	}
+	checkNoLeaks(t)
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package timer
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFlushOnSignal(t *testing.T) {
+	exited := make(chan os.Signal, 1)
+	orig := exitAfterSignal
+	exitAfterSignal = func(sig os.Signal) { exited <- sig }
+	defer func() { exitAfterSignal = orig }()
+
+	r := NewRegistry()
+	r.GetOrCreate("db").Observe(time.Millisecond)
+	flushed := make(chan map[string]Snapshot, 1)
+	stop := FlushOnSignal(r, func(d Dump) error {
+		flushed <- d.Snapshots
+		return nil
+	}, syscall.SIGUSR1)
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	select {
+	case snaps := <-flushed:
+		if snaps["db"].Count != 1 {
+			t.Errorf("Unexpected flushed snapshot: %v", snaps)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for flush")
+	}
+	if sig := <-exited; sig != syscall.SIGUSR1 {
+		t.Errorf("Expected exit after SIGUSR1, got %v", sig)
+	}
+}
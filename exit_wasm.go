@@ -0,0 +1,11 @@
+//go:build js || wasip1
+
+package timer
+
+import "os"
+
+// FlushOnSignal does nothing on WebAssembly, which has no process signals,
+// and returns a no-op stop function. Call FinalFlush explicitly instead.
+func FlushOnSignal(r *Registry, sink Sink, signals ...os.Signal) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,316 @@
+package timer
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultExpHistogramSize is the default maximum number of buckets of
+	// an ExpHistogram, matching the OpenTelemetry SDK default.
+	DefaultExpHistogramSize = 160
+	// expMaxScale is the scale a new ExpHistogram starts at.
+	expMaxScale = 20
+	// expMinScale is the coarsest scale, where one bucket spans a factor
+	// of 2^1024 and any range fits.
+	expMinScale = -10
+)
+
+// ExpHistogram is an OpenTelemetry-style base-2 exponential histogram of
+// durations in nanoseconds. Bucket index i at scale s holds values in
+// (base^i, base^(i+1)] with base = 2^(2^-s). The histogram starts at the
+// finest scale and halves its resolution whenever the observed range would
+// need more than its maximum number of buckets, so memory stays bounded
+// without choosing bucket boundaries and the buckets export to OTLP
+// exactly.
+//
+// ExpHistogram implements Aggregator and is safe for concurrent use.
+type ExpHistogram struct {
+	mutex     sync.Mutex
+	maxSize   int
+	scale     int
+	zeroCount uint64
+	count     uint64
+	sum       time.Duration
+	min, max  time.Duration
+	offset    int      // index of buckets[0]
+	buckets   []uint64 // dense counts from offset
+}
+
+// ExpHistogramSnapshot is a copy of an ExpHistogram, laid out like an
+// OTLP ExponentialHistogramDataPoint with only positive buckets.
+type ExpHistogramSnapshot struct {
+	Scale     int32         `json:"scale"`
+	Count     uint64        `json:"count"`
+	Sum       time.Duration `json:"sum_ns"`
+	Min       time.Duration `json:"min_ns"`
+	Max       time.Duration `json:"max_ns"`
+	ZeroCount uint64        `json:"zero_count"`
+	Positive  ExpBuckets    `json:"positive"`
+}
+
+// ExpBuckets are the counts of consecutive buckets starting at index
+// Offset.
+type ExpBuckets struct {
+	Offset       int32    `json:"offset"`
+	BucketCounts []uint64 `json:"bucket_counts"`
+}
+
+// NewExpHistogram creates an ExpHistogram with at most maxSize buckets, or
+// DefaultExpHistogramSize if maxSize is less than 2.
+func NewExpHistogram(maxSize int) *ExpHistogram {
+	if maxSize < 2 {
+		maxSize = DefaultExpHistogramSize
+	}
+	return &ExpHistogram{maxSize: maxSize, scale: expMaxScale}
+}
+
+// Observe records d. Negative durations count as zero.
+func (h *ExpHistogram) Observe(d time.Duration) {
+	d = max(d, 0)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.count == 0 {
+		h.min, h.max = d, d
+	} else {
+		h.min, h.max = min(h.min, d), max(h.max, d)
+	}
+	h.count++
+	h.sum = addCapped(h.sum, d)
+	if d == 0 {
+		h.zeroCount++
+		return
+	}
+	h.addNoLock(expIndex(float64(d), h.scale), 1)
+}
+
+// observeN records n observations of d, such as the imported
+// observations of a timer. Negative durations count as zero.
+func (h *ExpHistogram) observeN(d time.Duration, n uint64) {
+	if n == 0 {
+		return
+	}
+	d = max(d, 0)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.count == 0 {
+		h.min, h.max = d, d
+	} else {
+		h.min, h.max = min(h.min, d), max(h.max, d)
+	}
+	h.count += n
+	h.sum = addCapped(h.sum, mulCapped(d, n))
+	if d == 0 {
+		h.zeroCount += n
+		return
+	}
+	h.addNoLock(expIndex(float64(d), h.scale), n)
+}
+
+// addNoLock adds n to the bucket with index i at the current scale,
+// downscaling first if the bucket range would exceed maxSize.
+func (h *ExpHistogram) addNoLock(i int, n uint64) {
+	if len(h.buckets) == 0 {
+		h.offset, h.buckets = i, append(h.buckets[:0], n)
+		return
+	}
+	lo, hi := min(h.offset, i), max(h.offset+len(h.buckets)-1, i)
+	change := 0
+	for hi-lo+1 > h.maxSize && h.scale-change > expMinScale {
+		lo, hi, i = lo>>1, hi>>1, i>>1
+		change++
+	}
+	h.downscaleNoLock(change)
+	if i < h.offset {
+		grown := make([]uint64, h.offset+len(h.buckets)-i)
+		copy(grown[h.offset-i:], h.buckets)
+		h.offset, h.buckets = i, grown
+	} else if end := h.offset + len(h.buckets); i >= end {
+		h.buckets = append(h.buckets, make([]uint64, i-end+1)...)
+	}
+	h.buckets[i-h.offset] += n
+}
+
+// downscaleNoLock reduces the scale by change, merging buckets.
+func (h *ExpHistogram) downscaleNoLock(change int) {
+	if change <= 0 {
+		return
+	}
+	h.scale -= change
+	if len(h.buckets) == 0 {
+		return
+	}
+	offset := h.offset >> change
+	merged := make([]uint64, ((h.offset+len(h.buckets)-1)>>change)-offset+1)
+	for k, c := range h.buckets {
+		merged[((h.offset+k)>>change)-offset] += c
+	}
+	h.offset, h.buckets = offset, merged
+}
+
+// expIndex returns the index of the bucket holding v > 0 at scale, as
+// specified for OpenTelemetry exponential histograms.
+func expIndex(v float64, scale int) int {
+	frac, exp := math.Frexp(v) // v = frac * 2^exp, frac in [0.5, 1)
+	if frac == 0.5 {
+		// exact powers of two are the inclusive upper bound of a bucket
+		if scale <= 0 {
+			return (exp - 2) >> -scale
+		}
+		return ((exp - 1) << scale) - 1
+	}
+	if scale <= 0 {
+		return (exp - 1) >> -scale
+	}
+	return int(math.Floor(math.Log(v) * math.Ldexp(math.Log2E, scale)))
+}
+
+// ExpLowerBound returns the exclusive lower bound of the bucket with index
+// i at scale.
+func ExpLowerBound(i int32, scale int32) float64 {
+	if scale <= 0 {
+		return math.Ldexp(1, int(i)<<-scale)
+	}
+	return math.Exp(float64(i) * math.Ldexp(math.Ln2, -int(scale)))
+}
+
+// Snapshot returns an ExpHistogramSnapshot, implementing Aggregator.
+func (h *ExpHistogram) Snapshot() any {
+	return h.ExpSnapshot()
+}
+
+// ExpSnapshot returns a copy of the histogram.
+func (h *ExpHistogram) ExpSnapshot() ExpHistogramSnapshot {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return ExpHistogramSnapshot{
+		Scale:     int32(h.scale),
+		Count:     h.count,
+		Sum:       h.sum,
+		Min:       h.min,
+		Max:       h.max,
+		ZeroCount: h.zeroCount,
+		Positive: ExpBuckets{
+			Offset:       int32(h.offset),
+			BucketCounts: append([]uint64(nil), h.buckets...),
+		},
+	}
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) of the observations by
+// linear interpolation within the bucket holding that rank, clamped to
+// [Min, Max]. Returns 0 for an empty snapshot.
+func (s ExpHistogramSnapshot) Quantile(q float64) time.Duration {
+	return s.QuantileWith(q, QuantileLinear)
+}
+
+// QuantileWith estimates the q-quantile (0 <= q <= 1) of the observations
+// using method m, clamped to [Min, Max]. Returns 0 for an empty snapshot.
+func (s ExpHistogramSnapshot) QuantileWith(q float64, m QuantileMethod) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	q = min(max(q, 0), 1)
+	rank := q * float64(s.Count)
+	if m != QuantileLinear {
+		rank = max(math.Ceil(rank), 1)
+	}
+	seen := float64(s.ZeroCount)
+	if rank <= seen && s.ZeroCount > 0 {
+		return s.Min
+	}
+	for k, c := range s.Positive.BucketCounts {
+		if c == 0 {
+			continue
+		}
+		if next := seen + float64(c); rank <= next {
+			i := s.Positive.Offset + int32(k)
+			lo, hi := ExpLowerBound(i, s.Scale), ExpLowerBound(i+1, s.Scale)
+			v := hi
+			if m != QuantileUpperBound {
+				v = lo + (hi-lo)*(rank-seen)/float64(c)
+			}
+			return min(max(time.Duration(v), s.Min), s.Max)
+		}
+		seen += float64(c)
+	}
+	return s.Max
+}
+
+// Reset clears the histogram and restores the finest scale.
+func (h *ExpHistogram) Reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.scale = expMaxScale
+	h.zeroCount, h.count, h.sum, h.min, h.max = 0, 0, 0, 0, 0
+	h.offset, h.buckets = 0, h.buckets[:0]
+}
+
+// Compact clears the histogram and releases its buckets, implementing
+// Compacter.
+func (h *ExpHistogram) Compact() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.scale = expMaxScale
+	h.zeroCount, h.count, h.sum, h.min, h.max = 0, 0, 0, 0, 0
+	h.offset, h.buckets = 0, nil
+}
+
+// Merge adds the observations of other, an *ExpHistogram, downscaling to
+// the coarser of both scales or further if the combined range requires.
+func (h *ExpHistogram) Merge(other Aggregator) error {
+	o, ok := other.(*ExpHistogram)
+	if !ok {
+		return errors.New("can only merge an ExpHistogram")
+	}
+	s := o.ExpSnapshot()
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.mergeNoLock(s)
+	return nil
+}
+
+// mergeNoLock adds the observations of s.
+// Callers must hold the lock.
+func (h *ExpHistogram) mergeNoLock(s ExpHistogramSnapshot) {
+	if s.Count == 0 {
+		return
+	}
+	if h.count == 0 {
+		h.min, h.max = s.Min, s.Max
+	} else {
+		h.min, h.max = min(h.min, s.Min), max(h.max, s.Max)
+	}
+	h.count += s.Count
+	h.sum = addCapped(h.sum, s.Sum)
+	h.zeroCount += s.ZeroCount
+
+	h.downscaleNoLock(h.scale - min(h.scale, int(s.Scale)))
+	for k, c := range s.Positive.BucketCounts {
+		if c > 0 {
+			// addNoLock may downscale further, so map at the current scale
+			h.addNoLock((int(s.Positive.Offset)+k)>>(int(s.Scale)-h.scale), c)
+		}
+	}
+}
+
+// Merge returns the combined distribution of s and o, such as the
+// histograms of one timer in dumps from several processes, downscaled as
+// ExpHistogram.Merge does.
+func (s ExpHistogramSnapshot) Merge(o ExpHistogramSnapshot) ExpHistogramSnapshot {
+	h := NewExpHistogram(max(len(s.Positive.BucketCounts), len(o.Positive.BucketCounts), DefaultExpHistogramSize))
+	h.mergeNoLock(s)
+	h.mergeNoLock(o)
+	return h.ExpSnapshot()
+}
+
+// addCapped returns a+b for non-negative durations, capped at
+// math.MaxInt64.
+func addCapped(a, b time.Duration) time.Duration {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
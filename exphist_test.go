@@ -0,0 +1,150 @@
+package timer
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpIndex(t *testing.T) {
+	tests := []struct {
+		v     float64
+		scale int
+		want  int
+	}{
+		{1, 0, -1}, // (0.5, 1]
+		{3, 0, 1},  // (2, 4]
+		{4, 0, 1},
+		{4.5, 0, 2},
+		{2, 1, 1}, // (√2, 2]
+		{1.5, 1, 1},
+		{1.4, 1, 0},
+		{1024, -1, 4}, // (256, 1024]
+		{1025, -1, 5},
+		{1, 20, -1},
+	}
+	for _, tt := range tests {
+		if got := expIndex(tt.v, tt.scale); got != tt.want {
+			t.Errorf("expIndex(%v, %d) = %d; want %d", tt.v, tt.scale, got, tt.want)
+		}
+	}
+
+	// every value lies within the bounds of its bucket
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		v := float64(r.Int64N(int64(time.Hour))) + 1
+		scale := r.IntN(31) - 10
+		i := expIndex(v, scale)
+		lo, hi := ExpLowerBound(int32(i), int32(scale)), ExpLowerBound(int32(i+1), int32(scale))
+		if !(lo < v*(1+1e-12) && v <= hi*(1+1e-12)) {
+			t.Fatalf("value %v at scale %d in bucket %d with bounds (%v, %v]", v, scale, i, lo, hi)
+		}
+	}
+}
+
+func TestExpHistogramDownscale(t *testing.T) {
+	h := NewExpHistogram(20)
+	values := []time.Duration{0, 1, 999, time.Microsecond, time.Millisecond, time.Second, time.Hour}
+	for _, d := range values {
+		h.Observe(d)
+	}
+	s := h.ExpSnapshot()
+	if len(s.Positive.BucketCounts) > 20 || s.Scale >= expMaxScale {
+		t.Errorf("Expected downscaling to at most 20 buckets, got %d at scale %d", len(s.Positive.BucketCounts), s.Scale)
+	}
+	if s.Count != 7 || s.ZeroCount != 1 || s.Min != 0 || s.Max != time.Hour {
+		t.Errorf("Unexpected totals: %+v", s)
+	}
+	// recomputing the buckets at the final scale gives the same counts
+	want := make([]uint64, len(s.Positive.BucketCounts))
+	for _, d := range values[1:] {
+		want[expIndex(float64(d), int(s.Scale))-int(s.Positive.Offset)]++
+	}
+	if !slices.Equal(s.Positive.BucketCounts, want) {
+		t.Errorf("Buckets = %v; want %v", s.Positive.BucketCounts, want)
+	}
+}
+
+func TestExpHistogramFineScale(t *testing.T) {
+	h := NewExpHistogram(0)
+	for range 5 {
+		h.Observe(time.Millisecond)
+	}
+	if s := h.ExpSnapshot(); s.Scale != expMaxScale || len(s.Positive.BucketCounts) != 1 || s.Positive.BucketCounts[0] != 5 {
+		t.Errorf("Expected one bucket at the finest scale, got %+v", s)
+	}
+}
+
+func TestExpHistogramMerge(t *testing.T) {
+	a, b := NewExpHistogram(32), NewExpHistogram(32)
+	all := NewExpHistogram(32)
+	r := rand.New(rand.NewPCG(3, 4))
+	for i := range 1000 {
+		d := time.Duration(r.Int64N(int64(time.Second)))
+		if i%2 == 0 {
+			a.Observe(d)
+		} else {
+			b.Observe(d * 1000)
+			d *= 1000
+		}
+		all.Observe(d)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	got, want := a.ExpSnapshot(), all.ExpSnapshot()
+	if got.Count != want.Count || got.Sum != want.Sum || got.Min != want.Min || got.Max != want.Max {
+		t.Errorf("Merged totals %+v; want %+v", got, want)
+	}
+	if got.Scale != want.Scale || got.Positive.Offset != want.Positive.Offset || !slices.Equal(got.Positive.BucketCounts, want.Positive.BucketCounts) {
+		t.Errorf("Merged buckets differ from observing all values directly:\n%+v\n%+v", got, want)
+	}
+
+	if err := a.Merge(NewExpHistogram(0)); err != nil || a.ExpSnapshot().Count != 1000 {
+		t.Errorf("Expected merging an empty histogram to be a no-op")
+	}
+	if err := a.Merge(&slowCounter{}); err == nil {
+		t.Errorf("Expected error merging another aggregator type")
+	}
+}
+
+func TestExpHistogramAggregator(t *testing.T) {
+	timer := NewTimer()
+	h := NewExpHistogram(0)
+	_ = timer.AddAggregator("exp", h)
+	timer.Observe(time.Millisecond)
+	snap, _ := timer.AggregatorSnapshot("exp")
+	if s := snap.(ExpHistogramSnapshot); s.Count != 1 {
+		t.Errorf("Expected aggregator snapshot with one observation, got %+v", s)
+	}
+	timer.Reset()
+	if s := h.ExpSnapshot(); s.Count != 0 || len(s.Positive.BucketCounts) != 0 || s.Scale != expMaxScale {
+		t.Errorf("Expected Reset to clear the histogram, got %+v", s)
+	}
+}
+
+func TestExpHistogramQuantile(t *testing.T) {
+	h := NewExpHistogram(0)
+	if q := h.ExpSnapshot().Quantile(0.5); q != 0 {
+		t.Errorf("Quantile of empty histogram = %v; want 0", q)
+	}
+	for i := 1; i <= 1000; i++ {
+		h.Observe(time.Duration(i) * time.Microsecond)
+	}
+	s := h.ExpSnapshot()
+	for _, tc := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0, time.Microsecond},
+		{0.5, 500 * time.Microsecond},
+		{0.99, 990 * time.Microsecond},
+		{1, time.Millisecond},
+	} {
+		got := s.Quantile(tc.q)
+		if diff := (got - tc.want).Abs(); diff > tc.want/100 {
+			t.Errorf("Quantile(%v) = %v; want %v within 1%%", tc.q, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Exporter delivers registry snapshots to an external system. Exporters
+// for third-party backends such as Kafka or CloudWatch implement it in
+// their own packages, so this package never imports their SDKs.
+//
+// Export must not retain snaps after it returns.
+type Exporter interface {
+	Export(snaps map[string]Snapshot) error
+}
+
+// Export calls s with a Dump of snaps, so a Sink can be used as an
+// Exporter.
+func (s Sink) Export(snaps map[string]Snapshot) error {
+	return s(NewDump(snaps))
+}
+
+// ReportTo returns a report function for NewReporter that passes each
+// snapshot to every exporter in order. Export errors do not stop the
+// remaining exporters; each is passed to onError if it is non-nil.
+func ReportTo(onError func(error), exporters ...Exporter) func(map[string]Snapshot) {
+	return func(snaps map[string]Snapshot) {
+		for _, e := range exporters {
+			if err := e.Export(snaps); err != nil && onError != nil {
+				onError(fmt.Errorf("exporting to %T: %w", e, err))
+			}
+		}
+	}
+}
+
+// JSONExporter is a reference Exporter writing one JSON object per timer
+// to a writer, sorted by name. Each object holds the export time as
+// "time" in RFC 3339 format, the timer "name", and the snapshot fields.
+type JSONExporter struct {
+	mutex sync.Mutex
+	w     io.Writer
+	buf   []byte
+	now   func() time.Time
+}
+
+// NewJSONExporter creates a JSONExporter writing to w, e.g. os.Stdout.
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{w: w, now: time.Now}
+}
+
+// Export writes the snapshots to the writer in a single Write call.
+func (e *JSONExporter) Export(snaps map[string]Snapshot) error {
+	if e.w == nil {
+		return errors.New("JSONExporter has no writer")
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	ts := e.now().UTC().AppendFormat(make([]byte, 0, 32), time.RFC3339Nano)
+	b := e.buf[:0]
+	for _, name := range sortedNames(snaps) {
+		b = appendRecordJSON(b, ts, name, snaps[name])
+		b = append(b, '\n')
+	}
+	e.buf = b
+	if len(b) == 0 {
+		return nil
+	}
+	_, err := e.w.Write(b)
+	return err
+}
+
+// appendRecordJSON appends a JSON object with the preformatted time ts,
+// the timer name, and the snapshot fields to b.
+func appendRecordJSON(b, ts []byte, name string, s Snapshot) []byte {
+	b = append(b, `{"time":"`...)
+	b = append(b, ts...)
+	b = append(b, `","name":`...)
+	b = appendJSONString(b, name)
+	b = append(b, ',')
+	b = s.appendJSONFields(b)
+	return append(b, '}')
+}
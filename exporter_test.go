@@ -0,0 +1,50 @@
+package timer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONExporter(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewJSONExporter(&buf)
+	e.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	err := e.Export(map[string]Snapshot{
+		"b": {Count: 1, Min: 5, Max: 5, Sum: 5},
+		"a": {Count: 2, Min: 1, Max: 3, Sum: 4},
+	})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	want := `{"time":"2024-01-02T03:04:05Z","name":"a","count":2,"min_ns":1,"max_ns":3,"sum_ns":4}
+{"time":"2024-01-02T03:04:05Z","name":"b","count":1,"min_ns":5,"max_ns":5,"sum_ns":5}
+`
+	if buf.String() != want {
+		t.Errorf("Export wrote:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestReportTo(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("x").Observe(time.Millisecond)
+
+	var got []map[string]Snapshot
+	ok := Sink(func(d Dump) error {
+		got = append(got, d.Snapshots)
+		return nil
+	})
+	failing := Sink(func(Dump) error { return errors.New("unavailable") })
+
+	var errs []error
+	rp := NewReporter(r, time.Hour, ReportTo(func(err error) { errs = append(errs, err) }, failing, ok))
+	rp.Flush()
+	if len(got) != 1 || got[0]["x"].Count != 1 {
+		t.Errorf("Expected exporter after a failing one to receive the snapshot, got %v", got)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "unavailable") {
+		t.Errorf("Expected one export error, got %v", errs)
+	}
+}
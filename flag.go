@@ -0,0 +1,46 @@
+package timer
+
+import (
+	"flag"
+	"time"
+)
+
+var _ flag.Value = (*DurationFlag)(nil)
+
+// DurationFlag is a time.Duration that reads and writes strings like
+// "250ms", so thresholds, windows, and intervals can be set from flags,
+// JSON, and other text formats:
+//
+//	threshold := timer.DurationFlag(100 * time.Millisecond)
+//	flag.Var(&threshold, "slow", "slow request threshold")
+type DurationFlag time.Duration
+
+// Duration returns d as a time.Duration.
+func (d DurationFlag) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// String formats d like time.Duration.String.
+func (d DurationFlag) String() string {
+	return time.Duration(d).String()
+}
+
+// Set parses s with time.ParseDuration, implementing flag.Value.
+func (d *DurationFlag) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = DurationFlag(v)
+	return nil
+}
+
+// MarshalText formats d like String.
+func (d DurationFlag) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText parses text like Set.
+func (d *DurationFlag) UnmarshalText(text []byte) error {
+	return d.Set(string(text))
+}
@@ -0,0 +1,42 @@
+package timer
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestDurationFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	threshold := DurationFlag(time.Second)
+	fs.Var(&threshold, "slow", "")
+	if err := fs.Parse([]string{"-slow", "250ms"}); err != nil {
+		t.Fatal(err)
+	}
+	if threshold.Duration() != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %v", threshold)
+	}
+	if err := fs.Parse([]string{"-slow", "fast"}); err == nil {
+		t.Errorf("Expected an error for an invalid duration")
+	}
+}
+
+func TestDurationFlagJSON(t *testing.T) {
+	var cfg struct {
+		Window DurationFlag `json:"window"`
+	}
+	if err := json.Unmarshal([]byte(`{"window":"1m30s"}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Window.Duration() != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %v", cfg.Window)
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil || string(b) != `{"window":"1m30s"}` {
+		t.Errorf("Marshal = %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"window":"soon"}`), &cfg); err == nil {
+		t.Errorf("Expected an error for an invalid duration")
+	}
+}
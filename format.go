@@ -0,0 +1,120 @@
+package timer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Layout selects how statistics are rendered by the AppendFormat methods.
+// Every type uses the same layouts, so output from timers, vecs, and
+// registries can be mixed in one log and parsed the same way.
+type Layout int
+
+const (
+	// LayoutText is the human-readable layout of Timer.String:
+	// "Count: X, Max: Xms, Min: Xms, Mean: Xms". Multi-entry types write
+	// one "name: ..." line per entry, with names padded to a common width.
+	LayoutText Layout = iota
+	// LayoutLogfmt writes space-separated key=value pairs:
+	// "count=X max=Xms min=Xms mean=Xms". Durations use time.Duration
+	// syntax. Multi-entry types write one line per entry, starting with
+	// name=NAME.
+	LayoutLogfmt
+	// LayoutJSON writes the JSON encoding of Snapshot.AppendJSON.
+	// Multi-entry types write one object per line, with the entry's name
+	// as a leading "name" field.
+	LayoutJSON
+)
+
+// String returns the snapshot in LayoutText.
+func (s Snapshot) String() string {
+	var buf [150]byte
+	return string(s.AppendFormat(buf[:0], LayoutText))
+}
+
+// AppendFormat appends the snapshot rendered in layout to b.
+func (s Snapshot) AppendFormat(b []byte, layout Layout) []byte {
+	if layout == LayoutJSON {
+		return s.AppendJSON(b)
+	}
+	if layout != LayoutLogfmt {
+		return appendStats(b, s.Count, s.Max, s.Min, s.Mean(), s.SumOverflowed && s.SumEpoch == 0)
+	}
+	b = append(b, "count="...)
+	b = strconv.AppendUint(b, s.Count, 10)
+	b = append(b, " max="...)
+	b = append(b, s.Max.String()...)
+	b = append(b, " min="...)
+	b = append(b, s.Min.String()...)
+	b = append(b, " mean="...)
+	b = append(b, s.Mean().String()...)
+	if s.SumOverflowed {
+		b = append(b, " sum_overflowed=true"...)
+	}
+	if s.Panicked != 0 {
+		b = append(b, " panicked="...)
+		b = strconv.AppendUint(b, s.Panicked, 10)
+	}
+	return b
+}
+
+// appendSnapshots appends one line per entry of snaps in name order.
+func appendSnapshots(b []byte, snaps map[string]Snapshot, layout Layout) []byte {
+	names := sortedNames(snaps)
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	for i, name := range names {
+		if i > 0 {
+			b = append(b, '\n')
+		}
+		switch layout {
+		case LayoutJSON:
+			b = NamedSnapshot{Name: name, Snapshot: snaps[name]}.AppendJSON(b)
+			continue
+		case LayoutLogfmt:
+			b = append(b, "name="...)
+			b = appendLogfmtValue(b, name)
+			b = append(b, ' ')
+		default:
+			b = append(b, name...)
+			b = append(b, ':')
+			b = append(b, strings.Repeat(" ", width-len(name)+1)...)
+		}
+		b = snaps[name].AppendFormat(b, layout)
+	}
+	return b
+}
+
+// appendLogfmtValue appends v, quoted if it contains characters that would
+// break logfmt parsing.
+func appendLogfmtValue(b []byte, v string) []byte {
+	if v == "" || strings.ContainsAny(v, " =\"\\\t\n\r") {
+		return strconv.AppendQuote(b, v)
+	}
+	return append(b, v...)
+}
+
+// String returns the children of the vec in LayoutText, one per line.
+func (v *TimerVec) String() string {
+	return string(v.AppendFormat(nil, LayoutText))
+}
+
+// AppendFormat appends the children of the vec rendered in layout to b,
+// one per line, keyed by their label sets.
+func (v *TimerVec) AppendFormat(b []byte, layout Layout) []byte {
+	return appendSnapshots(b, v.Snapshot(), layout)
+}
+
+// String returns the registered timers in LayoutText, one per line.
+func (r *Registry) String() string {
+	return string(r.AppendFormat(nil, LayoutText))
+}
+
+// AppendFormat appends every exported timer rendered in layout to b, one
+// per line, keyed by name.
+func (r *Registry) AppendFormat(b []byte, layout Layout) []byte {
+	return appendSnapshots(b, r.Snapshot(), layout)
+}
@@ -0,0 +1,84 @@
+package timer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotString(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(10 * time.Millisecond)
+	timer.Observe(20 * time.Millisecond)
+	s := timer.Snapshot()
+
+	if s.String() != timer.String() {
+		t.Errorf("String = %q; want %q", s.String(), timer.String())
+	}
+	if got := fmt.Sprint(s); got != timer.String() {
+		t.Errorf("fmt.Sprint = %q; want %q", got, timer.String())
+	}
+
+	if got := string(s.AppendFormat(nil, LayoutJSON)); got != string(s.AppendJSON(nil)) {
+		t.Errorf("JSON = %q; want %q", got, s.AppendJSON(nil))
+	}
+
+	empty := NewTimer()
+	if empty.String() != empty.Snapshot().String() {
+		t.Errorf("Empty timer String = %q; want %q", empty.String(), empty.Snapshot().String())
+	}
+
+	s.Panicked = 1
+	got := string(s.AppendFormat(nil, LayoutLogfmt))
+	want := "count=2 max=20ms min=10ms mean=15ms panicked=1"
+	if got != want {
+		t.Errorf("Logfmt = %q; want %q", got, want)
+	}
+}
+
+func TestRegistryString(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("db").Observe(time.Millisecond)
+	r.GetOrCreate("http.handler").Observe(2 * time.Millisecond)
+	v := NewTimerVec("method")
+	_ = r.RegisterVec("rpc", v)
+	v.WithLabelValues("Get").Observe(3 * time.Millisecond)
+
+	lines := strings.Split(r.String(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", r.String())
+	}
+	col := strings.Index(lines[0], "Count:")
+	for _, l := range lines {
+		if strings.Index(l, "Count:") != col {
+			t.Errorf("Expected aligned columns, got:\n%s", r.String())
+			break
+		}
+	}
+	if !strings.HasPrefix(lines[0], "db: ") || !strings.HasPrefix(lines[2], `rpc{method="Get"}: `) {
+		t.Errorf("Expected lines sorted by name, got:\n%s", r.String())
+	}
+
+	logfmt := strings.Split(string(r.AppendFormat(nil, LayoutLogfmt)), "\n")
+	if logfmt[0] != "name=db count=1 max=1ms min=1ms mean=1ms" {
+		t.Errorf("Unexpected logfmt line %q", logfmt[0])
+	}
+	if !strings.HasPrefix(logfmt[2], `name="rpc{method=\"Get\"}" count=1`) {
+		t.Errorf("Expected quoted logfmt name, got %q", logfmt[2])
+	}
+
+	jsonl := strings.Split(string(r.AppendFormat(nil, LayoutJSON)), "\n")
+	if want := `{"name":"db","count":1,"min_ns":1000000,"max_ns":1000000,"sum_ns":1000000}`; jsonl[0] != want {
+		t.Errorf("JSON line = %q; want %q", jsonl[0], want)
+	}
+}
+
+func TestTimerVecString(t *testing.T) {
+	v := NewTimerVec("method", "path")
+	v.WithLabelValues("GET", "/").Observe(time.Millisecond)
+	want := `method="GET",path="/": Count: 1, Max: 1ms, Min: 1ms, Mean: 1ms`
+	if v.String() != want {
+		t.Errorf("String = %q; want %q", v.String(), want)
+	}
+}
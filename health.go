@@ -0,0 +1,195 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthConfig configures a HealthMonitor.
+type HealthConfig struct {
+	// Window is the evaluation interval. Required.
+	Window time.Duration
+	// Threshold is the latency above which a window is unhealthy.
+	Threshold time.Duration
+	// Metric reduces a window's observations to the latency compared
+	// against Threshold, reading either the window's Snapshot or its
+	// distribution, such as HealthQuantile(0.99) does. Defaults to the
+	// window's mean.
+	Metric func(Snapshot, Quantiler) time.Duration
+	// TripAfter is the number of consecutive unhealthy windows that turn
+	// the state unhealthy. Defaults to 1.
+	TripAfter int
+	// RecoverAfter is the number of consecutive healthy windows that turn
+	// the state healthy again. Defaults to 1.
+	RecoverAfter int
+}
+
+// HealthMonitor evaluates a timer's latency per window and maintains a
+// healthy/unhealthy state with hysteresis, suitable for driving a circuit
+// breaker. Windows without observations carry no evidence and leave the
+// state and streaks unchanged.
+type HealthMonitor struct {
+	timer   *Timer
+	aggName string
+	window  tickerInterval
+
+	mutex   sync.Mutex
+	cfg     HealthConfig
+	current Snapshot      // observations of the current window
+	hist    *ExpHistogram // distribution of the current window
+	healthy bool
+	streak  int // consecutive windows contradicting the current state
+	changes chan bool
+	lc      lifecycle
+}
+
+// healthChangesBuffer is the capacity of the Changes channel.
+const healthChangesBuffer = 16
+
+// NewHealthMonitor creates a HealthMonitor observing t, starting healthy.
+// It attaches an Aggregator to t, which Close removes again.
+func NewHealthMonitor(t *Timer, cfg HealthConfig) (*HealthMonitor, error) {
+	if cfg.Window <= 0 {
+		return nil, errors.New("health window must be positive")
+	}
+	if cfg.Metric == nil {
+		cfg.Metric = healthMean
+	}
+	cfg.TripAfter = max(cfg.TripAfter, 1)
+	cfg.RecoverAfter = max(cfg.RecoverAfter, 1)
+	h := &HealthMonitor{
+		cfg:     cfg,
+		timer:   t,
+		window:  newTickerInterval(cfg.Window),
+		hist:    NewExpHistogram(0),
+		healthy: true,
+		changes: make(chan bool, healthChangesBuffer),
+	}
+	h.aggName = fmt.Sprintf("timer.HealthMonitor(%p)", h)
+	if err := t.AddAggregator(h.aggName, healthTap{h}); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// HealthQuantile returns a HealthConfig.Metric comparing the q-quantile
+// (0 <= q <= 1) of each window against the threshold.
+func HealthQuantile(q float64) func(Snapshot, Quantiler) time.Duration {
+	return func(_ Snapshot, dist Quantiler) time.Duration {
+		return dist.Quantile(q)
+	}
+}
+
+func healthMean(s Snapshot, _ Quantiler) time.Duration {
+	return s.Mean()
+}
+
+// healthTap feeds a timer's observations into the monitor's window.
+type healthTap struct{ h *HealthMonitor }
+
+func (tap healthTap) Observe(d time.Duration) {
+	tap.h.mutex.Lock()
+	defer tap.h.mutex.Unlock()
+	tap.h.current = tap.h.current.Merge(Snapshot{Count: 1, Min: d, Max: d, Sum: d})
+	tap.h.hist.Observe(d)
+}
+
+func (tap healthTap) Snapshot() any          { return tap.h.Healthy() }
+func (tap healthTap) Reset()                 {}
+func (tap healthTap) Merge(Aggregator) error { return nil }
+
+// Healthy returns the current state.
+func (h *HealthMonitor) Healthy() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.healthy
+}
+
+// Changes returns a channel receiving the new state on every change.
+// Changes are dropped while the channel's buffer is full, so Healthy
+// remains the authoritative state.
+func (h *HealthMonitor) Changes() <-chan bool {
+	return h.changes
+}
+
+// SetThreshold changes the latency above which a window is unhealthy,
+// taking effect from the next evaluation.
+func (h *HealthMonitor) SetThreshold(d time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.cfg.Threshold = d
+}
+
+// SetWindow changes the evaluation interval, also while running. The
+// current window is evaluated at the new interval after the call.
+func (h *HealthMonitor) SetWindow(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	h.mutex.Lock()
+	h.cfg.Window = d
+	h.mutex.Unlock()
+	h.window.set(d)
+}
+
+// Start begins evaluating every window until ctx is done or Close is
+// called.
+func (h *HealthMonitor) Start(ctx context.Context) error {
+	return h.lc.start(ctx, h, h.run)
+}
+
+func (h *HealthMonitor) run(ctx context.Context) {
+	ticker := h.window.start()
+	defer h.window.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.evaluate()
+		}
+	}
+}
+
+// evaluate closes the current window and updates the state. Returns the
+// state after the window.
+func (h *HealthMonitor) evaluate() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	w, dist := h.current, h.hist.ExpSnapshot()
+	h.current = Snapshot{}
+	h.hist.Reset()
+	if w.Count == 0 {
+		return h.healthy
+	}
+
+	good := h.cfg.Metric(w, dist) <= h.cfg.Threshold
+	if good == h.healthy {
+		h.streak = 0
+		return h.healthy
+	}
+	h.streak++
+	need := h.cfg.TripAfter
+	if !h.healthy {
+		need = h.cfg.RecoverAfter
+	}
+	if h.streak >= need {
+		h.healthy, h.streak = good, 0
+		select {
+		case h.changes <- good:
+		default:
+		}
+	}
+	return h.healthy
+}
+
+// Close stops evaluating and detaches the monitor from its timer.
+func (h *HealthMonitor) Close() error {
+	if h.lc.close() {
+		h.timer.RemoveAggregator(h.aggName)
+	}
+	return nil
+}
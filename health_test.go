@@ -0,0 +1,147 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHealthMonitorHysteresis(t *testing.T) {
+	timer := NewTimer()
+	h, err := NewHealthMonitor(timer, HealthConfig{
+		Window:       time.Second,
+		Threshold:    10 * time.Millisecond,
+		Metric:       func(s Snapshot, _ Quantiler) time.Duration { return s.Max },
+		TripAfter:    2,
+		RecoverAfter: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewHealthMonitor failed: %v", err)
+	}
+	defer h.Close()
+
+	window := func(d time.Duration) bool {
+		timer.Observe(d)
+		return h.evaluate()
+	}
+	if !window(20*time.Millisecond) || !h.Healthy() {
+		t.Errorf("Expected one slow window not to trip")
+	}
+	if window(time.Millisecond); !h.Healthy() {
+		t.Errorf("Expected a fast window to reset the streak")
+	}
+	window(20 * time.Millisecond)
+	if window(20 * time.Millisecond) {
+		t.Errorf("Expected two consecutive slow windows to trip")
+	}
+	if got := <-h.Changes(); got {
+		t.Errorf("Expected change to unhealthy, got %v", got)
+	}
+
+	// idle windows carry no evidence
+	if h.evaluate() {
+		t.Errorf("Expected idle window to leave state unhealthy")
+	}
+	window(time.Millisecond)
+	window(time.Millisecond)
+	if h.evaluate(); h.Healthy() {
+		t.Errorf("Expected recovery to need 3 fast windows")
+	}
+	if !window(time.Millisecond) {
+		t.Errorf("Expected recovery after 3 fast windows")
+	}
+	if got := <-h.Changes(); !got {
+		t.Errorf("Expected change to healthy, got %v", got)
+	}
+}
+
+func TestHealthMonitorStart(t *testing.T) {
+	defer checkNoLeaks(t)
+	timer := NewTimer()
+	h, err := NewHealthMonitor(timer, HealthConfig{Window: 5 * time.Millisecond, Threshold: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHealthMonitor failed: %v", err)
+	}
+	if err := h.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	timer.Observe(time.Second)
+	select {
+	case healthy := <-h.Changes():
+		if healthy {
+			t.Errorf("Expected unhealthy state")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for state change")
+	}
+	h.Close()
+	if _, ok := timer.AggregatorSnapshot(h.aggName); ok {
+		t.Errorf("Expected Close to detach the monitor")
+	}
+}
+
+func TestHealthMonitorConfig(t *testing.T) {
+	if _, err := NewHealthMonitor(NewTimer(), HealthConfig{}); err == nil {
+		t.Errorf("Expected error without window")
+	}
+}
+
+func TestHealthMonitorQuantile(t *testing.T) {
+	timer := NewTimer()
+	h, _ := NewHealthMonitor(timer, HealthConfig{
+		Window:    time.Hour,
+		Threshold: 10 * time.Millisecond,
+		Metric:    HealthQuantile(0.99),
+	})
+	defer h.Close()
+
+	// a mean of about 3ms hides the slow tail
+	for i := range 100 {
+		d := time.Millisecond
+		if i%50 == 0 {
+			d = 100 * time.Millisecond
+		}
+		timer.Observe(d)
+	}
+	if h.evaluate() {
+		t.Errorf("Expected p99 of 100ms to trip under a 10ms threshold")
+	}
+	for range 100 {
+		timer.Observe(time.Millisecond)
+	}
+	if !h.evaluate() {
+		t.Errorf("Expected a fast window to recover, the distribution is per window")
+	}
+}
+
+func TestHealthMonitorSetThreshold(t *testing.T) {
+	timer := NewTimer()
+	h, _ := NewHealthMonitor(timer, HealthConfig{Window: time.Hour, Threshold: time.Second})
+	defer h.Close()
+	timer.Observe(100 * time.Millisecond)
+	if !h.evaluate() {
+		t.Errorf("Expected healthy under a 1s threshold")
+	}
+	h.SetThreshold(10 * time.Millisecond)
+	timer.Observe(100 * time.Millisecond)
+	if h.evaluate() {
+		t.Errorf("Expected unhealthy after lowering the threshold")
+	}
+}
+
+func TestHealthMonitorSetWindow(t *testing.T) {
+	defer checkNoLeaks(t)
+	timer := NewTimer()
+	h, _ := NewHealthMonitor(timer, HealthConfig{Window: time.Hour, Threshold: time.Millisecond})
+	if err := h.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer h.Close()
+	timer.Observe(time.Second)
+	h.SetWindow(5 * time.Millisecond)
+	select {
+	case <-h.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected evaluation at the shortened window")
+	}
+}
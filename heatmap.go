@@ -0,0 +1,172 @@
+package timer
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Heatmap is a time × duration-bucket matrix of observation counts, as
+// rendered by heatmap visualizations.
+type Heatmap struct {
+	// Bounds are the inclusive upper bounds of the duration buckets. Each
+	// window has one more count, for observations above the last bound.
+	Bounds []time.Duration `json:"bounds_ns"`
+	// Windows are in chronological order, ending with the current one.
+	Windows []HeatmapWindow `json:"windows"`
+}
+
+// HeatmapWindow is one column of a Heatmap.
+type HeatmapWindow struct {
+	Start  time.Time `json:"start"`
+	Counts []uint64  `json:"counts"`
+}
+
+// HeatmapRecorder counts observations per duration bucket in consecutive
+// wall-clock aligned windows, keeping the last few windows. It implements
+// Aggregator, so it can be attached to a Timer with AddAggregator, and is
+// also safe for standalone concurrent use.
+type HeatmapRecorder struct {
+	bounds  []time.Duration
+	window  time.Duration
+	windows int
+	now     func() time.Time
+	loc     *time.Location
+
+	mutex  sync.Mutex
+	counts [][]uint64 // ring of windows, each len(bounds)+1, nil when compacted
+	head   int        // index of the current window in counts
+	start  time.Time  // start of the current window
+}
+
+// NewHeatmapRecorder creates a HeatmapRecorder with buckets bounded by the
+// sorted upper bounds, keeping windows windows of length window.
+func NewHeatmapRecorder(bounds []time.Duration, window time.Duration, windows int) (*HeatmapRecorder, error) {
+	if !slices.IsSorted(bounds) || len(slices.Compact(slices.Clone(bounds))) != len(bounds) {
+		return nil, errors.New("heatmap bounds must be strictly increasing")
+	}
+	if window <= 0 || windows <= 0 {
+		return nil, errors.New("heatmap window and window count must be positive")
+	}
+	return &HeatmapRecorder{
+		bounds:  slices.Clone(bounds),
+		window:  window,
+		windows: windows,
+		now:     time.Now,
+	}, nil
+}
+
+// SetClock makes h read the time from c instead of time.Now. It must be
+// called before h is used.
+func (h *HeatmapRecorder) SetClock(c Clock) {
+	h.now = c.Now
+}
+
+// SetLocation aligns windows to the wall clock in loc instead of UTC, so
+// windows of a day start at local midnight. It must be called before h is
+// used.
+func (h *HeatmapRecorder) SetLocation(loc *time.Location) {
+	h.loc = loc
+}
+
+// Observe counts d in the current window.
+func (h *HeatmapRecorder) Observe(d time.Duration) {
+	i, _ := slices.BinarySearch(h.bounds, d)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.advanceNoLock(h.now())
+	h.counts[h.head][i]++
+}
+
+// advanceNoLock rotates the ring so the current window contains now.
+func (h *HeatmapRecorder) advanceNoLock(now time.Time) {
+	if h.counts == nil {
+		h.counts = make([][]uint64, h.windows)
+		for i := range h.counts {
+			h.counts[i] = make([]uint64, len(h.bounds)+1)
+		}
+	}
+	start := alignTime(now, h.window, h.loc)
+	if h.start.IsZero() {
+		h.start = start
+		return
+	}
+	// rounded, as local days around daylight saving changes are not 24h
+	n := int((start.Sub(h.start) + h.window/2) / h.window)
+	if n <= 0 {
+		return
+	}
+	for range min(n, len(h.counts)) {
+		h.head = (h.head + 1) % len(h.counts)
+		clear(h.counts[h.head])
+	}
+	h.start = start
+}
+
+// Heatmap returns the matrix of the retained windows, including empty
+// ones.
+func (h *HeatmapRecorder) Heatmap() Heatmap {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.advanceNoLock(h.now())
+	hm := Heatmap{Bounds: slices.Clone(h.bounds), Windows: make([]HeatmapWindow, len(h.counts))}
+	for i := range h.counts {
+		// oldest first
+		j := (h.head + 1 + i) % len(h.counts)
+		hm.Windows[i] = HeatmapWindow{
+			Start:  h.start.Add(-time.Duration(len(h.counts)-1-i) * h.window),
+			Counts: slices.Clone(h.counts[j]),
+		}
+	}
+	return hm
+}
+
+// Snapshot returns the Heatmap, implementing Aggregator.
+func (h *HeatmapRecorder) Snapshot() any {
+	return h.Heatmap()
+}
+
+// Reset clears all windows.
+func (h *HeatmapRecorder) Reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, c := range h.counts {
+		clear(c)
+	}
+}
+
+// Compact releases the windows, discarding their counts, until the next
+// use, implementing Compacter.
+func (h *HeatmapRecorder) Compact() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.counts, h.head, h.start = nil, 0, time.Time{}
+}
+
+// Merge adds the counts of other, a HeatmapRecorder with the same bounds,
+// into the windows with the same start time.
+func (h *HeatmapRecorder) Merge(other Aggregator) error {
+	o, ok := other.(*HeatmapRecorder)
+	if !ok {
+		return errors.New("can only merge a HeatmapRecorder")
+	}
+	if !slices.Equal(h.bounds, o.bounds) {
+		return errors.New("heatmap bounds differ")
+	}
+	theirs := o.Heatmap()
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.advanceNoLock(h.now())
+	for _, w := range theirs.Windows {
+		back := int(h.start.Sub(w.Start) / h.window)
+		if back < 0 || back >= len(h.counts) || !w.Start.Equal(h.start.Add(-time.Duration(back)*h.window)) {
+			continue
+		}
+		dst := h.counts[(h.head-back+len(h.counts))%len(h.counts)]
+		for i, c := range w.Counts {
+			dst[i] += c
+		}
+	}
+	return nil
+}
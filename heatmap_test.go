@@ -0,0 +1,123 @@
+package timer
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestHeatmap(t *testing.T, now *time.Time) *HeatmapRecorder {
+	t.Helper()
+	h, err := NewHeatmapRecorder([]time.Duration{time.Millisecond, 10 * time.Millisecond}, time.Minute, 3)
+	if err != nil {
+		t.Fatalf("NewHeatmapRecorder failed: %v", err)
+	}
+	h.now = func() time.Time { return *now }
+	return h
+}
+
+func TestHeatmapRecorder(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+	h := newTestHeatmap(t, &now)
+	timer := NewTimer()
+	if err := timer.AddAggregator("heatmap", h); err != nil {
+		t.Fatalf("AddAggregator failed: %v", err)
+	}
+
+	timer.Observe(time.Millisecond)     // bucket 0, bounds are inclusive
+	timer.Observe(5 * time.Millisecond) // bucket 1
+	timer.Observe(time.Second)          // overflow bucket
+	now = now.Add(time.Minute)
+	timer.Observe(500 * time.Microsecond) // next window
+
+	hm := h.Heatmap()
+	if len(hm.Windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(hm.Windows))
+	}
+	wantStart := time.Date(2024, 1, 1, 11, 59, 0, 0, time.UTC)
+	want := [][]uint64{{0, 0, 0}, {1, 1, 1}, {1, 0, 0}}
+	for i, w := range hm.Windows {
+		if !w.Start.Equal(wantStart.Add(time.Duration(i)*time.Minute)) || !slices.Equal(w.Counts, want[i]) {
+			t.Errorf("Window %d = %v %v; want %v %v", i, w.Start, w.Counts, wantStart.Add(time.Duration(i)*time.Minute), want[i])
+		}
+	}
+
+	// after a long gap all windows are empty
+	now = now.Add(time.Hour)
+	for _, w := range h.Heatmap().Windows {
+		if !slices.Equal(w.Counts, []uint64{0, 0, 0}) {
+			t.Errorf("Expected empty window after gap, got %v", w.Counts)
+		}
+	}
+}
+
+func TestHeatmapJSON(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newTestHeatmap(t, &now)
+	h.Observe(2 * time.Millisecond)
+	b, err := json.Marshal(h.Heatmap())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"bounds_ns":[1000000,10000000],"windows":[` +
+		`{"start":"2023-12-31T23:58:00Z","counts":[0,0,0]},` +
+		`{"start":"2023-12-31T23:59:00Z","counts":[0,0,0]},` +
+		`{"start":"2024-01-01T00:00:00Z","counts":[0,1,0]}]}`
+	if string(b) != want {
+		t.Errorf("JSON = %s; want %s", b, want)
+	}
+}
+
+func TestHeatmapMerge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a, b := newTestHeatmap(t, &now), newTestHeatmap(t, &now)
+	a.Observe(time.Millisecond)
+	b.Observe(time.Millisecond)
+	b.Observe(time.Hour)
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if got := a.Heatmap().Windows[2].Counts; !slices.Equal(got, []uint64{2, 0, 1}) {
+		t.Errorf("Merged counts = %v; want [2 0 1]", got)
+	}
+
+	other, _ := NewHeatmapRecorder([]time.Duration{time.Second}, time.Minute, 3)
+	if err := a.Merge(other); err == nil {
+		t.Errorf("Expected error merging different bounds")
+	}
+	a.Reset()
+	if got := a.Heatmap().Windows[2].Counts; !slices.Equal(got, []uint64{0, 0, 0}) {
+		t.Errorf("Expected Reset to clear counts, got %v", got)
+	}
+}
+
+func TestNewHeatmapRecorderInvalid(t *testing.T) {
+	if _, err := NewHeatmapRecorder([]time.Duration{2, 1}, time.Second, 1); err == nil {
+		t.Errorf("Expected error for unsorted bounds")
+	}
+	if _, err := NewHeatmapRecorder([]time.Duration{1, 1}, time.Second, 1); err == nil {
+		t.Errorf("Expected error for duplicate bounds")
+	}
+	if _, err := NewHeatmapRecorder(nil, 0, 1); err == nil {
+		t.Errorf("Expected error for zero window")
+	}
+}
+
+func TestHeatmapRecorderLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*3600)
+	now := time.Date(2024, 1, 2, 3, 0, 0, 0, loc) // January 1 22:00 UTC
+	h, err := NewHeatmapRecorder([]time.Duration{time.Millisecond}, 24*time.Hour, 2)
+	if err != nil {
+		t.Fatalf("NewHeatmapRecorder failed: %v", err)
+	}
+	h.now = func() time.Time { return now }
+	h.SetLocation(loc)
+	h.Observe(time.Millisecond)
+
+	hm := h.Heatmap()
+	last := hm.Windows[len(hm.Windows)-1]
+	if !last.Start.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, loc)) || last.Counts[0] != 1 {
+		t.Errorf("Expected the window to start at local midnight, got %v %v", last.Start, last.Counts)
+	}
+}
@@ -0,0 +1,161 @@
+package timer
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrInvalidHistogram is returned by ImportHistogram for buckets that are
+// not sorted by strictly increasing upper bound or have negative bounds.
+var ErrInvalidHistogram = errors.New("invalid histogram buckets")
+
+// Bucket is a histogram bucket holding Count observations greater than the
+// previous bucket's UpperBound (or 0 for the first bucket) and at most
+// UpperBound. An UpperBound of math.MaxInt64 denotes an unbounded bucket.
+type Bucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// importPoint stands for n imported observations of d.
+type importPoint struct {
+	d time.Duration
+	n uint64
+}
+
+// ImportSnapshot merges s into the timer, as if every duration summarized
+// by s had been observed by it. Stats collected elsewhere, such as in a
+// previous run or another process, can seed a timer this way and continue
+// accumulating. Attached ExpHistograms receive the Min and Max of s once
+// each and its remaining observations at their mean; other aggregators
+// only see observations made by the timer. Like Observe, it does nothing
+// if recording is off.
+func (t *Timer) ImportSnapshot(s Snapshot) {
+	if t.off() {
+		return
+	}
+	t.importSnapshot(s, snapshotPoints(s))
+}
+
+// importSnapshot merges s into the statistics and passes points, the
+// observations s summarizes, to the attached ExpHistograms.
+func (t *Timer) importSnapshot(s Snapshot, points []importPoint) {
+	t.mutex.Lock()
+	// aggregators are user code and may panic, so unlock in a defer
+	defer t.mutex.Unlock()
+	overflowed, epoch := t.sumOverflowed, t.sumEpoch
+	t.setSnapshotNoLock(t.snapshotNoLock().Merge(s))
+	if t.sumOverflowed && (!overflowed || t.sumEpoch > epoch) {
+		t.logEventNoLock(EventOverflow)
+	}
+	for _, a := range t.aggregators {
+		if h, ok := a.(*ExpHistogram); ok {
+			for _, p := range points {
+				h.observeN(p.d, p.n)
+			}
+		}
+	}
+}
+
+// snapshotPoints approximates the observations summarized by s: its Min
+// and Max once each and the rest at the mean of the remaining sum.
+func snapshotPoints(s Snapshot) []importPoint {
+	switch s.Count {
+	case 0:
+		return nil
+	case 1:
+		return []importPoint{{s.Max, 1}}
+	}
+	points := []importPoint{{s.Min, 1}, {s.Max, 1}}
+	if rest := s.Count - 2; rest > 0 {
+		mean := s.Mean()
+		if !s.SumOverflowed {
+			mean = (s.Sum - s.Min - s.Max) / time.Duration(rest)
+		}
+		points = append(points, importPoint{min(max(mean, s.Min), s.Max), rest})
+	}
+	return points
+}
+
+// setSnapshotNoLock replaces the duration statistics with s, keeping a
+// sum beyond math.MaxInt64 exact if the overflow policy does.
+// Callers must hold the write lock.
+func (t *Timer) setSnapshotNoLock(s Snapshot) {
+	t.count = s.Count
+	t.max = s.Max
+	t.min = s.Min
+	if s.Count == 0 {
+		t.min = time.Duration(math.MaxInt64)
+	}
+	t.totalSum = int64(s.Sum)
+	t.sumOverflowed = s.SumOverflowed
+	t.sumEpoch, t.bigSum = 0, nil
+	t.exact.Store(false)
+	t.setExactSumNoLock(s)
+	t.panicked = s.Panicked
+	t.publishNoLock()
+}
+
+// ImportHistogram merges the observations summarized by non-cumulative
+// buckets into the timer. Since a histogram only bounds each observation,
+// every observation is assumed to lie at the midpoint of its bucket for the
+// sum, and min and max are the lower bound of the first and the upper
+// bound of the last non-empty bucket. For the unbounded bucket its lower
+// bound is used throughout. Attached ExpHistograms receive the
+// observations of each bucket at its midpoint.
+// Returns ErrInvalidHistogram if the buckets are malformed, without
+// importing anything, ErrClosed if the timer was closed with the
+// CloseError policy, and ErrFrozen if it is frozen with SetNoop. Nothing
+// is imported if recording is off.
+func (t *Timer) ImportHistogram(buckets []Bucket) error {
+	s, points, err := histogramSnapshot(buckets)
+	if err != nil {
+		return err
+	}
+	if t.off() {
+		return t.offErr()
+	}
+	t.importSnapshot(s, points)
+	return nil
+}
+
+// histogramSnapshot estimates a Snapshot from non-cumulative buckets,
+// along with the observations of each non-empty bucket at its midpoint.
+func histogramSnapshot(buckets []Bucket) (Snapshot, []importPoint, error) {
+	var s Snapshot
+	var points []importPoint
+	var lower time.Duration
+	for i, b := range buckets {
+		if b.UpperBound < 0 || (i > 0 && b.UpperBound <= buckets[i-1].UpperBound) {
+			return Snapshot{}, nil, ErrInvalidHistogram
+		}
+		if b.Count > 0 {
+			mid, upper := lower+(b.UpperBound-lower)/2, b.UpperBound
+			if b.UpperBound == math.MaxInt64 {
+				mid, upper = lower, lower
+			}
+			// merge as a snapshot so the sum is capped consistently
+			s = s.Merge(Snapshot{
+				Count: b.Count,
+				Min:   lower,
+				Max:   upper,
+				Sum:   mulCapped(mid, b.Count),
+			})
+			points = append(points, importPoint{mid, b.Count})
+		}
+		lower = b.UpperBound
+	}
+	return s, points, nil
+}
+
+// mulCapped returns d*n, capped at math.MaxInt64.
+func mulCapped(d time.Duration, n uint64) time.Duration {
+	if d == 0 || n == 0 {
+		return 0
+	}
+	if n > uint64(math.MaxInt64/d) {
+		return math.MaxInt64
+	}
+	return d * time.Duration(n)
+}
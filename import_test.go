@@ -0,0 +1,126 @@
+package timer
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestImportSnapshot(t *testing.T) {
+	prev := NewTimer()
+	prev.Observe(10 * time.Millisecond)
+	prev.Observe(30 * time.Millisecond)
+
+	timer := NewTimer()
+	timer.ImportSnapshot(prev.Snapshot())
+	if timer.Snapshot() != prev.Snapshot() {
+		t.Errorf("Expected empty timer to equal imported snapshot, got %+v", timer.Snapshot())
+	}
+
+	timer.Observe(5 * time.Millisecond)
+	if timer.Count() != 3 || timer.Min() != 5*time.Millisecond || timer.Max() != 30*time.Millisecond {
+		t.Errorf("Expected accumulation to continue after import, got %v", timer)
+	}
+	if timer.Mean() != 15*time.Millisecond {
+		t.Errorf("Mean = %v; want 15ms", timer.Mean())
+	}
+
+	timer.ImportSnapshot(Snapshot{})
+	if timer.Count() != 3 {
+		t.Errorf("Expected importing an empty snapshot to be a no-op")
+	}
+}
+
+func TestImportHistogram(t *testing.T) {
+	timer := NewTimer()
+	err := timer.ImportHistogram([]Bucket{
+		{UpperBound: 10 * time.Millisecond, Count: 0},
+		{UpperBound: 20 * time.Millisecond, Count: 2},
+		{UpperBound: 40 * time.Millisecond, Count: 1},
+		{UpperBound: math.MaxInt64, Count: 0},
+	})
+	if err != nil {
+		t.Fatalf("ImportHistogram failed: %v", err)
+	}
+	s := timer.Snapshot()
+	want := Snapshot{Count: 3, Min: 10 * time.Millisecond, Max: 40 * time.Millisecond, Sum: 60 * time.Millisecond}
+	if s != want {
+		t.Errorf("Snapshot = %+v; want %+v", s, want)
+	}
+
+	// observations in the unbounded bucket are placed at its lower bound
+	timer.Reset()
+	_ = timer.ImportHistogram([]Bucket{{UpperBound: time.Second}, {UpperBound: math.MaxInt64, Count: 2}})
+	if s := timer.Snapshot(); s.Max != time.Second || s.Sum != 2*time.Second {
+		t.Errorf("Unexpected snapshot for unbounded bucket: %+v", s)
+	}
+}
+
+func TestImportHistogramInvalid(t *testing.T) {
+	timer := NewTimer()
+	for _, buckets := range [][]Bucket{
+		{{UpperBound: 20, Count: 1}, {UpperBound: 10, Count: 1}},
+		{{UpperBound: 10, Count: 1}, {UpperBound: 10, Count: 1}},
+		{{UpperBound: -1, Count: 1}},
+	} {
+		if err := timer.ImportHistogram(buckets); !errors.Is(err, ErrInvalidHistogram) {
+			t.Errorf("ImportHistogram(%v) = %v; want ErrInvalidHistogram", buckets, err)
+		}
+	}
+	if timer.Count() != 0 {
+		t.Errorf("Expected nothing imported from invalid histograms, got count %d", timer.Count())
+	}
+}
+
+func TestImportAggregators(t *testing.T) {
+	timer := NewTimer()
+	h := NewExpHistogram(0)
+	if err := timer.AddAggregator("hist", h); err != nil {
+		t.Fatal(err)
+	}
+	timer.ImportSnapshot(Snapshot{Count: 4, Min: 10 * time.Millisecond, Max: 40 * time.Millisecond, Sum: 100 * time.Millisecond})
+	s := h.ExpSnapshot()
+	if s.Count != 4 || s.Sum != 100*time.Millisecond || s.Min != 10*time.Millisecond || s.Max != 40*time.Millisecond {
+		t.Errorf("Expected the histogram to receive the imported snapshot, got %+v", s)
+	}
+
+	err := timer.ImportHistogram([]Bucket{
+		{UpperBound: 10 * time.Millisecond},
+		{UpperBound: 30 * time.Millisecond, Count: 6},
+	})
+	if err != nil {
+		t.Fatalf("ImportHistogram failed: %v", err)
+	}
+	if s := h.ExpSnapshot(); s.Count != timer.Count() || s.Sum != 220*time.Millisecond {
+		t.Errorf("Expected the histogram to agree with the timer's count %d, got %+v", timer.Count(), s)
+	}
+	if q := h.ExpSnapshot().Quantile(0.5); q < 19*time.Millisecond || q > 21*time.Millisecond {
+		t.Errorf("Expected the median at the bucket midpoint, got %v", q)
+	}
+}
+
+func TestImportOff(t *testing.T) {
+	buckets := []Bucket{{UpperBound: time.Millisecond, Count: 1}}
+
+	noop := NewTimer()
+	noop.SetNoop(true)
+	noop.ImportSnapshot(Snapshot{Count: 1, Min: 1, Max: 1, Sum: 1})
+	if err := noop.ImportHistogram(buckets); !errors.Is(err, ErrFrozen) {
+		t.Errorf("ImportHistogram = %v; want ErrFrozen", err)
+	}
+	if noop.Count() != 0 {
+		t.Errorf("Expected a noop timer to ignore imports, got count %d", noop.Count())
+	}
+
+	closed := NewTimer()
+	closed.SetClosePolicy(CloseError)
+	_ = closed.Close()
+	closed.ImportSnapshot(Snapshot{Count: 1, Min: 1, Max: 1, Sum: 1})
+	if err := closed.ImportHistogram(buckets); !errors.Is(err, ErrClosed) {
+		t.Errorf("ImportHistogram = %v; want ErrClosed", err)
+	}
+	if closed.Count() != 0 {
+		t.Errorf("Expected a closed timer to ignore imports, got count %d", closed.Count())
+	}
+}
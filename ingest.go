@@ -0,0 +1,113 @@
+package timer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// Int64Reader reads raw int64 values, such as latencies in nanoseconds
+// exported from an eBPF map. ReadInt64s fills p like io.Reader.Read and
+// returns io.EOF once no values are left.
+type Int64Reader interface {
+	ReadInt64s(p []int64) (n int, err error)
+}
+
+// Int64Slice is an Int64Reader over values already in memory.
+type Int64Slice []int64
+
+// ReadInt64s copies the next values into p.
+func (s *Int64Slice) ReadInt64s(p []int64) (int, error) {
+	if len(*s) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, *s)
+	*s = (*s)[n:]
+	return n, nil
+}
+
+// binaryInt64Reader decodes fixed-width int64 values from a byte stream.
+type binaryInt64Reader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   []byte
+}
+
+// NewBinaryInt64Reader returns an Int64Reader decoding consecutive 8-byte
+// values in the given byte order from r, as written by a raw dump of a BPF
+// array or ring buffer. A trailing partial value is reported as
+// io.ErrUnexpectedEOF.
+func NewBinaryInt64Reader(r io.Reader, order binary.ByteOrder) Int64Reader {
+	return &binaryInt64Reader{r: r, order: order}
+}
+
+// ReadInt64s decodes up to len(p) values.
+func (b *binaryInt64Reader) ReadInt64s(p []int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if cap(b.buf) < 8*len(p) {
+		b.buf = make([]byte, 8*len(p))
+	}
+	m, err := io.ReadFull(b.r, b.buf[:8*len(p)])
+	if err == io.ErrUnexpectedEOF && m%8 == 0 {
+		// the stream ended on a value boundary, report io.EOF next call
+		err = nil
+	}
+	n := m / 8
+	for i := range n {
+		p[i] = int64(b.order.Uint64(b.buf[8*i:]))
+	}
+	return n, err
+}
+
+// IngestNanos reads nanosecond latencies from r until io.EOF and records
+// each in t, clamping negative values to zero like Update. It returns the
+// number of values recorded and the first error other than io.EOF.
+func (t *Timer) IngestNanos(r Int64Reader) (int, error) {
+	var buf [256]int64
+	var total int
+	for {
+		n, err := r.ReadInt64s(buf[:])
+		for _, ns := range buf[:n] {
+			t.ObserveNanos(max(ns, 0))
+		}
+		total += n
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// ImportLog2Histogram merges a power-of-two histogram into the timer, in
+// the layout bcc and bpftrace use for kernel latency histograms: slot 0
+// counts values of 0 and slot k counts values in [2^(k-1), 2^k), in the
+// given unit such as time.Nanosecond or time.Microsecond. Slots beyond
+// the range of time.Duration are folded into the unbounded bucket.
+// Returns ErrInvalidHistogram if unit is not positive.
+func (t *Timer) ImportLog2Histogram(slots []uint64, unit time.Duration) error {
+	if unit <= 0 {
+		return ErrInvalidHistogram
+	}
+	buckets := make([]Bucket, 0, len(slots))
+	for k, n := range slots {
+		upper := time.Duration(0)
+		if k > 0 {
+			upper = time.Duration(math.MaxInt64)
+			if unit <= math.MaxInt64>>k {
+				upper = unit << k
+			}
+		}
+		if last := len(buckets) - 1; last >= 0 && buckets[last].UpperBound == math.MaxInt64 {
+			buckets[last].Count += n
+			continue
+		}
+		buckets = append(buckets, Bucket{UpperBound: upper, Count: n})
+	}
+	return t.ImportHistogram(buckets)
+}
@@ -0,0 +1,75 @@
+package timer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestIngestNanos(t *testing.T) {
+	timer := NewTimer()
+	values := make(Int64Slice, 1000)
+	for i := range values {
+		values[i] = int64(i) * int64(time.Microsecond)
+	}
+	values[0] = -5 // clamped like Update
+	n, err := timer.IngestNanos(&values)
+	if err != nil || n != 1000 {
+		t.Fatalf("IngestNanos() = %d, %v; want 1000, nil", n, err)
+	}
+	if timer.Count() != 1000 || timer.Min() != 0 || timer.Max() != 999*time.Microsecond {
+		t.Errorf("Expected 1000 observations from 0 to 999us, got %d from %v to %v",
+			timer.Count(), timer.Min(), timer.Max())
+	}
+}
+
+func TestBinaryInt64Reader(t *testing.T) {
+	var buf bytes.Buffer
+	for _, v := range []int64{100, 200, 300} {
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+	timer := NewTimer()
+	n, err := timer.IngestNanos(NewBinaryInt64Reader(bytes.NewReader(buf.Bytes()), binary.LittleEndian))
+	if err != nil || n != 3 {
+		t.Fatalf("IngestNanos() = %d, %v; want 3, nil", n, err)
+	}
+	if timer.Snapshot().Sum != 600 {
+		t.Errorf("Expected sum 600ns, got %v", timer.Snapshot().Sum)
+	}
+
+	// a trailing partial value is an error, after the whole values
+	buf.WriteByte(1)
+	timer.Reset()
+	n, err = timer.IngestNanos(NewBinaryInt64Reader(&buf, binary.LittleEndian))
+	if err != io.ErrUnexpectedEOF || n != 3 {
+		t.Errorf("IngestNanos() = %d, %v; want 3, io.ErrUnexpectedEOF", n, err)
+	}
+}
+
+func TestImportLog2Histogram(t *testing.T) {
+	timer := NewTimer()
+	// 2 values of 0, 4 in [1,2), 3 in [4,8) microseconds
+	if err := timer.ImportLog2Histogram([]uint64{2, 4, 0, 3}, time.Microsecond); err != nil {
+		t.Fatal(err)
+	}
+	s := timer.Snapshot()
+	if s.Count != 9 || s.Min != 0 || s.Max != 8*time.Microsecond {
+		t.Errorf("Expected 9 observations from 0 to 8us, got %d from %v to %v", s.Count, s.Min, s.Max)
+	}
+
+	// slots past the range of time.Duration fold into one unbounded bucket
+	slots := make([]uint64, 70)
+	slots[1], slots[65], slots[69] = 1, 1, 1
+	timer.Reset()
+	if err := timer.ImportLog2Histogram(slots, time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	if s := timer.Snapshot(); s.Count != 3 || s.Max != time.Duration(1)<<62 {
+		t.Errorf("Expected 3 observations up to 2^62ns, got %d up to %v", s.Count, s.Max)
+	}
+	if err := timer.ImportLog2Histogram(slots, 0); err != ErrInvalidHistogram {
+		t.Errorf("ImportLog2Histogram(unit 0) = %v; want ErrInvalidHistogram", err)
+	}
+}
@@ -0,0 +1,53 @@
+package timer
+
+import (
+	"sync"
+	"time"
+)
+
+// tickerInterval is the period of a component's ticker that can be changed
+// at runtime, taking effect on the running ticker immediately.
+type tickerInterval struct {
+	mutex  sync.Mutex
+	period time.Duration
+	ticker *time.Ticker
+}
+
+func newTickerInterval(d time.Duration) tickerInterval {
+	return tickerInterval{period: d}
+}
+
+func (ti *tickerInterval) get() time.Duration {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	return ti.period
+}
+
+// set changes the period, resetting the running ticker if any. A
+// non-positive period is ignored, as time.Ticker cannot tick at it.
+func (ti *tickerInterval) set(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	ti.period = d
+	if ti.ticker != nil {
+		ti.ticker.Reset(d)
+	}
+}
+
+// start creates the ticker. The caller must call stop when done.
+func (ti *tickerInterval) start() *time.Ticker {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	ti.ticker = time.NewTicker(ti.period)
+	return ti.ticker
+}
+
+func (ti *tickerInterval) stop() {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	ti.ticker.Stop()
+	ti.ticker = nil
+}
@@ -0,0 +1,134 @@
+package timer
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Result label values of a JobTimer.
+const (
+	JobSuccess = "success"
+	JobFailure = "failure"
+)
+
+// JobTimer tracks runs of periodic jobs such as cron tasks. It records run
+// durations in a TimerVec with the labels "job" and "result" ("success" or
+// "failure"), remembers when each job last succeeded, and detects runs
+// that start while a previous run of the same job is still going:
+//
+//	jt := timer.NewJobTimer()
+//	_ = timer.DefaultRegistry.RegisterVec("jobs", jt.Vec())
+//	c.AddFunc("@hourly", func() { _ = jt.Run("cleanup", cleanup) })
+//	...
+//	if stale := jt.Stale(2 * time.Hour); len(stale) > 0 { alert(stale) }
+type JobTimer struct {
+	vec *TimerVec
+
+	mutex sync.Mutex
+	jobs  map[string]*JobStatus
+}
+
+// JobStatus is the state of one job of a JobTimer.
+type JobStatus struct {
+	// Running is the number of runs in progress.
+	Running int
+	// Overlaps counts runs that started while another run was in progress.
+	Overlaps uint64
+	// LastSuccess is when the last successful run ended, zero if none has.
+	LastSuccess time.Time
+	// LastFailure is when the last failed run ended, zero if none has.
+	LastFailure time.Time
+}
+
+// SinceSuccess returns the time since the last successful run ended, or
+// since the zero time if there was none.
+func (s JobStatus) SinceSuccess() time.Duration {
+	return time.Since(s.LastSuccess)
+}
+
+// NewJobTimer creates a JobTimer.
+func NewJobTimer() *JobTimer {
+	return &JobTimer{vec: NewTimerVec("job", "result"), jobs: make(map[string]*JobStatus)}
+}
+
+// Vec returns the TimerVec runs are recorded in, for registration in a
+// Registry.
+func (jt *JobTimer) Vec() *TimerVec {
+	return jt.vec
+}
+
+// Run runs fn as a run of job and records its duration as a success if fn
+// returns nil and a failure otherwise. A panic in fn is recorded as a
+// failure before it continues to unwind. Returns the error of fn.
+func (jt *JobTimer) Run(job string, fn func() error) (err error) {
+	jt.mutex.Lock()
+	st, ok := jt.jobs[job]
+	if !ok {
+		st = &JobStatus{}
+		jt.jobs[job] = st
+	}
+	if st.Running > 0 {
+		st.Overlaps++
+	}
+	st.Running++
+	jt.mutex.Unlock()
+
+	start := time.Now()
+	failed := true
+	defer func() {
+		end := time.Now()
+		result := JobFailure
+		if !failed {
+			result = JobSuccess
+		}
+		jt.vec.WithLabelValues(job, result).Observe(max(end.Sub(start), 0))
+		jt.mutex.Lock()
+		defer jt.mutex.Unlock()
+		st.Running--
+		if failed {
+			st.LastFailure = end
+		} else {
+			st.LastSuccess = end
+		}
+	}()
+	err = fn()
+	failed = err != nil
+	return err
+}
+
+// Status returns the state of job, and false if it has never run.
+func (jt *JobTimer) Status(job string) (JobStatus, bool) {
+	jt.mutex.Lock()
+	defer jt.mutex.Unlock()
+	st, ok := jt.jobs[job]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return *st, true
+}
+
+// Statuses returns the state of every job that has run, by name.
+func (jt *JobTimer) Statuses() map[string]JobStatus {
+	jt.mutex.Lock()
+	defer jt.mutex.Unlock()
+	out := make(map[string]JobStatus, len(jt.jobs))
+	for job, st := range jt.jobs {
+		out[job] = *st
+	}
+	return out
+}
+
+// Stale returns the sorted names of jobs that have not succeeded within
+// maxAge, including jobs that ran but never succeeded.
+func (jt *JobTimer) Stale(maxAge time.Duration) []string {
+	statuses := jt.Statuses()
+	var stale []string
+	for _, job := range slices.Sorted(maps.Keys(statuses)) {
+		if statuses[job].SinceSuccess() > maxAge {
+			stale = append(stale, job)
+		}
+	}
+	return stale
+}
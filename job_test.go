@@ -0,0 +1,69 @@
+package timer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJobTimer(t *testing.T) {
+	jt := NewJobTimer()
+	if err := jt.Run("cleanup", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	errBoom := errors.New("boom")
+	if err := jt.Run("report", func() error { return errBoom }); err != errBoom {
+		t.Errorf("Run() = %v; want %v", err, errBoom)
+	}
+
+	snaps := jt.Vec().Snapshot()
+	if snaps[`job="cleanup",result="success"`].Count != 1 || snaps[`job="report",result="failure"`].Count != 1 {
+		t.Errorf("Unexpected snapshots %v", snaps)
+	}
+	st, ok := jt.Status("cleanup")
+	if !ok || st.LastSuccess.IsZero() || st.Running != 0 || st.SinceSuccess() > time.Minute {
+		t.Errorf("Unexpected status %+v, %v", st, ok)
+	}
+	if _, ok := jt.Status("missing"); ok {
+		t.Errorf("Expected no status for a job that never ran")
+	}
+	if stale := jt.Stale(time.Minute); !slices.Equal(stale, []string{"report"}) {
+		t.Errorf("Stale() = %v; want [report]", stale)
+	}
+}
+
+func TestJobTimerOverlap(t *testing.T) {
+	jt := NewJobTimer()
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = jt.Run("sync", func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	_ = jt.Run("sync", func() error {
+		if st, _ := jt.Status("sync"); st.Running != 2 {
+			t.Errorf("Expected 2 running, got %d", st.Running)
+		}
+		return nil
+	})
+	close(release)
+	if st, _ := jt.Status("sync"); st.Overlaps != 1 {
+		t.Errorf("Overlaps = %d; want 1", st.Overlaps)
+	}
+}
+
+func TestJobTimerPanic(t *testing.T) {
+	jt := NewJobTimer()
+	func() {
+		defer func() { _ = recover() }()
+		_ = jt.Run("crash", func() error { panic("boom") })
+	}()
+	st, _ := jt.Status("crash")
+	if st.LastFailure.IsZero() || st.Running != 0 {
+		t.Errorf("Expected a panicking run to count as a failure, got %+v", st)
+	}
+}
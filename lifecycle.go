@@ -0,0 +1,121 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrAlreadyStarted is returned by Start on a component that is running.
+var ErrAlreadyStarted = errors.New("component already started")
+
+// ErrClosed is returned when using a component after Close, and when
+// updating a Timer closed with the CloseError policy.
+var ErrClosed = errors.New("use after close")
+
+// Component is a background component with a managed lifecycle.
+// Start launches the component's goroutines, which run until ctx is done or
+// Close is called. Close stops them, flushes any pending output, and waits
+// for them to exit. Close is idempotent.
+//
+// Every running component is tracked so Shutdown can stop them all, until
+// it is closed or its context is done.
+type Component interface {
+	Start(ctx context.Context) error
+	Close() error
+}
+
+// running tracks started components in start order.
+var running struct {
+	mutex      sync.Mutex
+	components []Component
+}
+
+func track(c Component) {
+	running.mutex.Lock()
+	defer running.mutex.Unlock()
+	running.components = append(running.components, c)
+}
+
+func untrack(c Component) {
+	running.mutex.Lock()
+	defer running.mutex.Unlock()
+	running.components = slices.DeleteFunc(running.components, func(o Component) bool { return o == c })
+}
+
+// Shutdown closes every running component in reverse start order, so
+// components started later (which may depend on earlier ones) stop first.
+// Returns ctx.Err() if ctx is done before all components have closed, or
+// the joined Close errors otherwise.
+func Shutdown(ctx context.Context) error {
+	running.mutex.Lock()
+	components := slices.Clone(running.components)
+	running.mutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		var errs []error
+		for _, c := range slices.Backward(components) {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		done <- errors.Join(errs...)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lifecycle implements the Start/Close state machine shared by components.
+type lifecycle struct {
+	mutex   sync.Mutex
+	started bool
+	closed  bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// start runs run in a new goroutine with a context derived from ctx, and
+// tracks c until run returns.
+func (l *lifecycle) start(ctx context.Context, c Component, run func(ctx context.Context)) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if l.started {
+		return ErrAlreadyStarted
+	}
+	l.started = true
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.done = make(chan struct{})
+	track(c)
+	go func() {
+		defer close(l.done)
+		defer untrack(c)
+		run(ctx)
+	}()
+	return nil
+}
+
+// close stops the goroutine started by start and waits for it to exit.
+// Returns false if it was already closed.
+func (l *lifecycle) close() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return false
+	}
+	l.closed = true
+	if l.started {
+		l.cancel()
+		<-l.done
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// checkNoLeaks fails t if goroutines started by this package are still
+// running after a short grace period.
+func checkNoLeaks(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		leaked := leakedGoroutines()
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("Leaked goroutines:\n%s", strings.Join(leaked, "\n\n"))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// leakedGoroutines returns the stacks of goroutines created by this package.
+func leakedGoroutines() []string {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	var leaked []string
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(g, "created by github.com/jnpr-pranav/go-timer.") {
+			leaked = append(leaked, g)
+		}
+	}
+	return leaked
+}
+
+type countingComponent struct {
+	lc     lifecycle
+	closed *[]string
+	name   string
+}
+
+func (c *countingComponent) Start(ctx context.Context) error {
+	return c.lc.start(ctx, c, func(ctx context.Context) { <-ctx.Done() })
+}
+
+func (c *countingComponent) Close() error {
+	if c.lc.close() {
+		*c.closed = append(*c.closed, c.name)
+	}
+	return nil
+}
+
+func TestLifecycleStartClose(t *testing.T) {
+	var closed []string
+	c := &countingComponent{closed: &closed, name: "a"}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := c.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("Expected ErrAlreadyStarted, got %v", err)
+	}
+	_ = c.Close()
+	_ = c.Close()
+	if len(closed) != 1 {
+		t.Errorf("Expected Close to be idempotent, got %v", closed)
+	}
+	if err := c.Start(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	checkNoLeaks(t)
+}
+
+func TestShutdown(t *testing.T) {
+	var closed []string
+	a := &countingComponent{closed: &closed, name: "a"}
+	b := &countingComponent{closed: &closed, name: "b"}
+	_ = a.Start(context.Background())
+	_ = b.Start(context.Background())
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if strings.Join(closed, ",") != "b,a" {
+		t.Errorf("Expected components closed in reverse start order, got %v", closed)
+	}
+	checkNoLeaks(t)
+}
+
+func TestLifecycleUntrackOnContextDone(t *testing.T) {
+	var closed []string
+	c := &countingComponent{closed: &closed, name: "a"}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	cancel()
+	<-c.lc.done
+	running.mutex.Lock()
+	tracked := slices.Contains(running.components, Component(c))
+	running.mutex.Unlock()
+	if tracked {
+		t.Errorf("Expected component stopped by its context to be untracked")
+	}
+	_ = c.Close()
+}
+
+type blockingComponent struct {
+	release chan struct{}
+	closed  atomic.Bool
+}
+
+func (c *blockingComponent) Start(context.Context) error { return nil }
+
+func (c *blockingComponent) Close() error {
+	<-c.release
+	c.closed.Store(true)
+	return nil
+}
+
+func TestShutdownContextExpired(t *testing.T) {
+	c := &blockingComponent{release: make(chan struct{})}
+	track(c)
+	defer untrack(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	close(c.release)
+}
@@ -0,0 +1,175 @@
+package timer
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegistryStats describes a registry itself rather than the operations it
+// times, so the health of the instrumentation is visible too.
+type RegistryStats struct {
+	Timers       int     // registered timers
+	Vecs         int     // registered vecs
+	Children     int     // timers of registered vecs
+	Observations uint64  // observations across all timers and children
+	Throughput   float64 // observations per second across all of them
+}
+
+// Stats returns statistics about r. Throughput adds up the throughput of
+// every timer since it was created or last reset.
+func (r *Registry) Stats() RegistryStats {
+	var st RegistryStats
+	s := r.store
+	s.mutex.RLock()
+	for name := range s.timers {
+		if strings.HasPrefix(name, r.prefix) {
+			st.Timers++
+		}
+	}
+	for name := range s.vecs {
+		if strings.HasPrefix(name, r.prefix) {
+			st.Vecs++
+		}
+	}
+	s.mutex.RUnlock()
+	timers := r.allTimers()
+	st.Children = len(timers) - st.Timers
+	for _, t := range timers {
+		st.Observations += t.Count()
+		st.Throughput += t.Throughput()
+	}
+	return st
+}
+
+// MeteredExporter wraps an Exporter, timing every Export call and tracking
+// its delivery, so a failing push exporter does not silently leave gaps.
+// Register its Durations timer to export the exporter's own latency along
+// with everything else:
+//
+//	m := timer.NewMeteredExporter(exporter)
+//	r.MustRegister("timer.export", m.Durations())
+//
+// With SetRetryQueue, snapshots that failed to export are kept and sent
+// again, oldest first, before the next snapshot. This matters for
+// exporters fed by a Reporter in delta mode, whose reports are not
+// repeated.
+type MeteredExporter struct {
+	exporter  Exporter
+	durations *Timer
+
+	// serializes exports, so retried snapshots arrive in order
+	mutex      sync.Mutex
+	stats      DeliveryStats
+	queue      []map[string]Snapshot
+	queueLimit int
+}
+
+// DeliveryStats describes the deliveries of a MeteredExporter.
+type DeliveryStats struct {
+	Successes   uint64    // exports that succeeded, retries included
+	Failures    uint64    // exports that failed, retries included
+	Queued      int       // snapshots waiting in the retry queue
+	Dropped     uint64    // snapshots dropped from a full retry queue
+	LastError   error     // error of the last failed export
+	LastFailure time.Time // time of the last failed export
+	LastSuccess time.Time // time of the last successful export
+}
+
+// NewMeteredExporter creates a MeteredExporter delivering to e.
+func NewMeteredExporter(e Exporter) *MeteredExporter {
+	return &MeteredExporter{exporter: e, durations: NewTimer()}
+}
+
+// SetRetryQueue keeps up to n snapshots that failed to export for
+// retrying, dropping the oldest when full. A limit of 0, the default,
+// disables retries and discards the queue.
+func (m *MeteredExporter) SetRetryQueue(n int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.queueLimit = max(n, 0)
+	m.trimQueueNoLock()
+}
+
+// Export first retries queued snapshots, oldest first, then delivers
+// snaps. If a retry fails, snaps is queued without being attempted.
+// Returns the error of the failed export, if any.
+func (m *MeteredExporter) Export(snaps map[string]Snapshot) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for len(m.queue) > 0 {
+		if err := m.deliverNoLock(m.queue[0]); err != nil {
+			m.enqueueNoLock(snaps)
+			return err
+		}
+		m.queue[0] = nil
+		m.queue = m.queue[1:]
+	}
+	err := m.deliverNoLock(snaps)
+	if err != nil {
+		m.enqueueNoLock(snaps)
+	}
+	return err
+}
+
+// deliverNoLock exports snaps once and records the outcome.
+// Callers must hold the lock.
+func (m *MeteredExporter) deliverNoLock(snaps map[string]Snapshot) error {
+	start := time.Now()
+	err := m.exporter.Export(snaps)
+	end := time.Now()
+	m.durations.Observe(end.Sub(start))
+	if err != nil {
+		m.stats.Failures++
+		m.stats.LastError = err
+		m.stats.LastFailure = end
+	} else {
+		m.stats.Successes++
+		m.stats.LastSuccess = end
+	}
+	return err
+}
+
+// enqueueNoLock queues a copy of snaps for retrying, if retries are on.
+// Callers must hold the lock.
+func (m *MeteredExporter) enqueueNoLock(snaps map[string]Snapshot) {
+	if m.queueLimit == 0 {
+		return
+	}
+	// Export must not retain snaps
+	m.queue = append(m.queue, maps.Clone(snaps))
+	m.trimQueueNoLock()
+}
+
+// trimQueueNoLock drops the oldest queued snapshots beyond the limit.
+// Callers must hold the lock.
+func (m *MeteredExporter) trimQueueNoLock() {
+	if over := len(m.queue) - m.queueLimit; over > 0 {
+		m.stats.Dropped += uint64(over)
+		m.queue = slices.Delete(m.queue, 0, over)
+	}
+}
+
+// Durations returns the timer recording the duration of every export
+// attempt.
+func (m *MeteredExporter) Durations() *Timer {
+	return m.durations
+}
+
+// Failures returns the number of export attempts that failed.
+func (m *MeteredExporter) Failures() uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stats.Failures
+}
+
+// Delivery returns the delivery statistics.
+func (m *MeteredExporter) Delivery() DeliveryStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	st := m.stats
+	st.Queued = len(m.queue)
+	return st
+}
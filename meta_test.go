@@ -0,0 +1,90 @@
+package timer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryStats(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("a").Observe(time.Millisecond)
+	r.GetOrCreate("b").Observe(time.Millisecond)
+	vec := NewTimerVec("route")
+	r.RegisterVec("http", vec)
+	vec.WithLabelValues("/x").Observe(time.Millisecond)
+	vec.WithLabelValues("/y").Observe(time.Millisecond)
+	vec.WithLabelValues("/y").Observe(time.Millisecond)
+	r.SubRegistry("db").GetOrCreate("query").Observe(time.Millisecond)
+
+	st := r.Stats()
+	if st.Timers != 3 || st.Vecs != 1 || st.Children != 2 || st.Observations != 6 {
+		t.Errorf("Expected 3 timers, 1 vec, 2 children, 6 observations, got %+v", st)
+	}
+	if st.Throughput <= 0 {
+		t.Errorf("Expected positive throughput, got %v", st.Throughput)
+	}
+	if st := r.SubRegistry("db").Stats(); st.Timers != 1 || st.Observations != 1 {
+		t.Errorf("Expected a view to count only its own timers, got %+v", st)
+	}
+}
+
+func TestMeteredExporter(t *testing.T) {
+	fail := false
+	m := NewMeteredExporter(Sink(func(Dump) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	}))
+	if err := m.Export(nil); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	if err := m.Export(nil); err == nil {
+		t.Errorf("Expected the wrapped error to be returned")
+	}
+	if m.Durations().Count() != 2 || m.Failures() != 1 {
+		t.Errorf("Expected 2 exports with 1 failure, got %d and %d", m.Durations().Count(), m.Failures())
+	}
+}
+
+func TestMeteredExporterRetryQueue(t *testing.T) {
+	var delivered []uint64
+	fail := true
+	m := NewMeteredExporter(Sink(func(d Dump) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		delivered = append(delivered, d.Snapshots["a"].Count)
+		return nil
+	}))
+	m.SetRetryQueue(2)
+	for i := range 3 {
+		snaps := map[string]Snapshot{"a": {Count: uint64(i + 1)}}
+		if err := m.Export(snaps); err == nil {
+			t.Fatalf("Expected export %d to fail", i)
+		}
+		snaps["a"] = Snapshot{} // the queue must hold a copy
+	}
+	st := m.Delivery()
+	if st.Queued != 2 || st.Dropped != 1 || st.Failures != 3 || st.LastError == nil || !st.LastSuccess.IsZero() {
+		t.Errorf("Expected 2 queued, 1 dropped, 3 failures, got %+v", st)
+	}
+
+	fail = false
+	if err := m.Export(map[string]Snapshot{"a": {Count: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	// the oldest snapshot was dropped, the others arrive in order
+	if len(delivered) != 3 || delivered[0] != 2 || delivered[1] != 3 || delivered[2] != 4 {
+		t.Errorf("Expected snapshots 2, 3, 4 delivered in order, got %v", delivered)
+	}
+	st = m.Delivery()
+	if st.Queued != 0 || st.Successes != 3 || st.LastSuccess.IsZero() {
+		t.Errorf("Expected an empty queue after 3 successes, got %+v", st)
+	}
+	if m.Durations().Count() != 6 {
+		t.Errorf("Expected 6 timed attempts, got %d", m.Durations().Count())
+	}
+}
@@ -0,0 +1,166 @@
+package timer
+
+import (
+	"math"
+	"math/big"
+	"time"
+)
+
+// ObserveNanos records a duration given in nanoseconds, for integrations
+// that produce raw nanosecond counts such as eBPF maps or kernel
+// timestamps. It is small enough to be inlined into the caller.
+func (t *Timer) ObserveNanos(ns int64) {
+	t.Observe(time.Duration(ns))
+}
+
+// ObserveSpanNanos records the duration between two nanosecond timestamps
+// from the same monotonic clock, clamped to non-negative values like
+// Update. It is small enough to be inlined into the caller.
+func (t *Timer) ObserveSpanNanos(start, end int64) {
+	t.Observe(time.Duration(max(end-start, 0)))
+}
+
+// ObserveNanos records a duration given in nanoseconds, see
+// Timer.ObserveNanos.
+func (t *ShardedTimer) ObserveNanos(ns int64) {
+	t.Observe(time.Duration(ns))
+}
+
+// MinNanos returns Min in nanoseconds, 0 if the snapshot is empty.
+func (s Snapshot) MinNanos() int64 {
+	return int64(s.Min)
+}
+
+// MaxNanos returns Max in nanoseconds.
+func (s Snapshot) MaxNanos() int64 {
+	return int64(s.Max)
+}
+
+// SumNanos returns Sum in nanoseconds.
+func (s Snapshot) SumNanos() int64 {
+	return int64(s.Sum)
+}
+
+// MeanNanos returns Mean in nanoseconds.
+func (s Snapshot) MeanNanos() int64 {
+	return int64(s.Mean())
+}
+
+// MinSeconds returns Min in seconds.
+func (s Snapshot) MinSeconds() float64 {
+	return s.Min.Seconds()
+}
+
+// MaxSeconds returns Max in seconds.
+func (s Snapshot) MaxSeconds() float64 {
+	return s.Max.Seconds()
+}
+
+// SumSeconds returns Sum in seconds.
+func (s Snapshot) SumSeconds() float64 {
+	return s.Sum.Seconds()
+}
+
+// MeanSeconds returns the mean in seconds, without the rounding of Mean
+// to whole nanoseconds. Returns 0 if the snapshot is empty.
+func (s Snapshot) MeanSeconds() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	sum := s.Sum.Seconds()
+	if s.SumEpoch > 0 {
+		sum = (float64(s.SumEpoch)*(1<<63) + float64(s.SumRemainder)) / 1e9
+	}
+	return sum / float64(s.Count)
+}
+
+// MinNanos returns the minimum duration observed in nanoseconds. Unlike
+// Min, it returns 0 if no observations have been made.
+func (t *Timer) MinNanos() int64 {
+	return int64(t.minOrZero())
+}
+
+// MaxNanos returns the maximum duration observed in nanoseconds.
+func (t *Timer) MaxNanos() int64 {
+	return int64(t.Max())
+}
+
+// SumNanos returns the sum of all observed durations in nanoseconds,
+// see Snapshot.Sum.
+func (t *Timer) SumNanos() int64 {
+	return int64(t.sum())
+}
+
+// MeanNanos returns the mean of all observed durations in nanoseconds.
+func (t *Timer) MeanNanos() int64 {
+	return int64(t.Mean())
+}
+
+// MinSeconds returns the minimum duration observed in seconds, 0 if no
+// observations have been made.
+func (t *Timer) MinSeconds() float64 {
+	return t.minOrZero().Seconds()
+}
+
+// MaxSeconds returns the maximum duration observed in seconds.
+func (t *Timer) MaxSeconds() float64 {
+	return t.Max().Seconds()
+}
+
+// SumSeconds returns the sum of all observed durations in seconds.
+func (t *Timer) SumSeconds() float64 {
+	return t.sum().Seconds()
+}
+
+// MeanSeconds returns the mean of all observed durations in seconds, see
+// Snapshot.MeanSeconds.
+func (t *Timer) MeanSeconds() float64 {
+	if t.seq.enabled.Load() && !t.exact.Load() {
+		v := t.seq.load()
+		if v.count == 0 {
+			return 0
+		}
+		return time.Duration(v.sum).Seconds() / float64(v.count)
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.count == 0 {
+		return 0
+	}
+	if t.exact.Load() {
+		sum, _ := new(big.Float).SetInt(t.exactSumNoLock()).Float64()
+		return sum / 1e9 / float64(t.count)
+	}
+	return time.Duration(t.totalSum).Seconds() / float64(t.count)
+}
+
+// minOrZero returns the minimum duration observed, or 0 if no
+// observations have been made, like Snapshot.Min.
+func (t *Timer) minOrZero() time.Duration {
+	if t.seq.enabled.Load() {
+		if v := t.seq.load(); v.count > 0 {
+			return v.min
+		}
+		return 0
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.count == 0 {
+		return 0
+	}
+	return t.min
+}
+
+// sum returns the sum of all observed durations, capped like
+// Snapshot.Sum.
+func (t *Timer) sum() time.Duration {
+	if t.seq.enabled.Load() && !t.exact.Load() {
+		return time.Duration(t.seq.load().sum)
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.sumOverflowed {
+		return math.MaxInt64
+	}
+	return time.Duration(t.totalSum)
+}
@@ -0,0 +1,77 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObserveNanos(t *testing.T) {
+	timer := NewTimer()
+	timer.ObserveNanos(1500)
+	timer.ObserveSpanNanos(1000, 3500)
+	timer.ObserveSpanNanos(5000, 4000) // clock went backwards
+	want := Snapshot{Count: 3, Min: 0, Max: 2500, Sum: 4000}
+	if got := timer.Snapshot(); got != want {
+		t.Errorf("Snapshot() = %+v; want %+v", got, want)
+	}
+
+	sharded := NewShardedTimer(2)
+	sharded.ObserveNanos(int64(time.Millisecond))
+	if sharded.Max() != time.Millisecond {
+		t.Errorf("Max() = %v; want 1ms", sharded.Max())
+	}
+}
+
+func TestNanosAccessors(t *testing.T) {
+	timer := NewTimer()
+	if timer.MinNanos() != 0 || timer.MinSeconds() != 0 || timer.MeanSeconds() != 0 {
+		t.Errorf("Expected zeros for an empty timer")
+	}
+	timer.Observe(time.Millisecond)
+	timer.Observe(2 * time.Millisecond)
+	timer.Observe(2 * time.Millisecond)
+
+	if timer.MinNanos() != 1e6 || timer.MaxNanos() != 2e6 || timer.SumNanos() != 5e6 || timer.MeanNanos() != 1666667 {
+		t.Errorf("Unexpected nanoseconds %d %d %d %d", timer.MinNanos(), timer.MaxNanos(), timer.SumNanos(), timer.MeanNanos())
+	}
+	if timer.MinSeconds() != 0.001 || timer.MaxSeconds() != 0.002 || timer.SumSeconds() != 0.005 {
+		t.Errorf("Unexpected seconds %v %v %v", timer.MinSeconds(), timer.MaxSeconds(), timer.SumSeconds())
+	}
+	// not rounded to whole nanoseconds like Mean
+	if got, want := timer.MeanSeconds(), 0.005/3; got != want {
+		t.Errorf("MeanSeconds() = %v; want %v", got, want)
+	}
+	s := timer.Snapshot()
+	if s.MinNanos() != timer.MinNanos() || s.MeanNanos() != timer.MeanNanos() || s.MaxSeconds() != timer.MaxSeconds() {
+		t.Errorf("Expected the snapshot accessors to match the timer's")
+	}
+}
+
+func TestNanosAccessorsMatchSnapshot(t *testing.T) {
+	for _, p := range []OverflowPolicy{OverflowCap, OverflowWrap, OverflowBig} {
+		for _, seqlock := range []bool{false, true} {
+			timer := NewTimer()
+			timer.SetOverflowPolicy(p)
+			timer.SetSeqlock(seqlock)
+			timer.Observe(time.Millisecond)
+			timer.Observe(maxDuration)
+			timer.Observe(maxDuration)
+
+			s := timer.Snapshot()
+			if timer.MinNanos() != s.MinNanos() || timer.SumNanos() != s.SumNanos() ||
+				timer.MinSeconds() != s.MinSeconds() || timer.SumSeconds() != s.SumSeconds() {
+				t.Errorf("%v, seqlock %v: min and sum %d %d differ from snapshot %+v", p, seqlock, timer.MinNanos(), timer.SumNanos(), s)
+			}
+			if got, want := timer.MeanSeconds(), s.MeanSeconds(); got != want {
+				t.Errorf("%v, seqlock %v: MeanSeconds() = %v; want %v", p, seqlock, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkTimerObserveNanos(b *testing.B) {
+	timer := NewTimer()
+	for b.Loop() {
+		timer.ObserveNanos(1000)
+	}
+}
@@ -0,0 +1,42 @@
+package timer
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNegativeDuration is returned when a timer with the NegativeError
+// policy is updated with an end before its start.
+var ErrNegativeDuration = errors.New("negative duration")
+
+// NegativePolicy selects what a Timer does with a negative elapsed time,
+// such as from a start time taken on another machine or a wall clock that
+// was stepped backwards.
+type NegativePolicy int
+
+const (
+	// NegativeClamp records negative durations as 0.
+	NegativeClamp NegativePolicy = iota
+	// NegativeError drops negative durations and makes Update, UpdateAt
+	// and UpdateWithContext return ErrNegativeDuration.
+	NegativeError
+)
+
+// SetNegativePolicy selects what t does with negative elapsed times. The
+// default is NegativeClamp.
+func (t *Timer) SetNegativePolicy(p NegativePolicy) {
+	t.negativePolicy.Store(int32(p))
+}
+
+// elapsed returns end minus start, clamped to 0 or rejected with
+// ErrNegativeDuration according to the negative policy.
+func (t *Timer) elapsed(start, end time.Time) (time.Duration, error) {
+	d := end.Sub(start)
+	if d >= 0 {
+		return d, nil
+	}
+	if NegativePolicy(t.negativePolicy.Load()) == NegativeError {
+		return 0, ErrNegativeDuration
+	}
+	return 0, nil
+}
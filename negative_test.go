@@ -0,0 +1,37 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNegativePolicy(t *testing.T) {
+	timer := NewTimer()
+	now := time.Now()
+	future := now.Add(time.Hour)
+	if err := timer.UpdateAt(future, now); err != nil {
+		t.Fatalf("UpdateAt() = %v; want nil", err)
+	}
+	if timer.Count() != 1 || timer.Max() != 0 {
+		t.Errorf("Expected a negative duration clamped to 0 by default, got %v", timer)
+	}
+
+	timer.SetNegativePolicy(NegativeError)
+	if err := timer.UpdateAt(future, now); !errors.Is(err, ErrNegativeDuration) {
+		t.Errorf("UpdateAt() = %v; want ErrNegativeDuration", err)
+	}
+	if err := timer.Update(future); !errors.Is(err, ErrNegativeDuration) {
+		t.Errorf("Update() = %v; want ErrNegativeDuration", err)
+	}
+	if err := timer.UpdateWithContext(context.Background(), future); !errors.Is(err, ErrNegativeDuration) {
+		t.Errorf("UpdateWithContext() = %v; want ErrNegativeDuration", err)
+	}
+	if err := timer.UpdateAt(now, future); err != nil {
+		t.Errorf("UpdateAt() = %v; want nil for a positive duration", err)
+	}
+	if timer.Count() != 2 {
+		t.Errorf("Expected rejected durations not to be recorded, got count %d", timer.Count())
+	}
+}
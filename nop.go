@@ -0,0 +1,68 @@
+package timer
+
+import "time"
+
+// Interface is the recording and reading surface of a Timer. Libraries can
+// accept an Interface so callers can inject a *Timer, a NopTimer or a mock.
+type Interface interface {
+	Observe(d time.Duration)
+	Update(start time.Time) error
+	UpdateAt(start, now time.Time) error
+	Time(fn func())
+	Count() uint64
+	Max() time.Duration
+	Min() time.Duration
+	Mean() time.Duration
+	Snapshot() Snapshot
+	Reset()
+}
+
+var (
+	_ Interface = (*Timer)(nil)
+	_ Interface = NopTimer{}
+)
+
+// NopTimer is an Interface that records nothing and always reads as empty.
+type NopTimer struct{}
+
+// Observe does nothing.
+func (NopTimer) Observe(time.Duration) {}
+
+// Update does nothing. Like Timer.Update it rejects a zero start.
+func (NopTimer) Update(start time.Time) error {
+	if start.IsZero() {
+		return ErrZeroTime
+	}
+	return nil
+}
+
+// UpdateAt does nothing. Like Timer.UpdateAt it rejects zero times.
+func (NopTimer) UpdateAt(start, now time.Time) error {
+	if start.IsZero() || now.IsZero() {
+		return ErrZeroTime
+	}
+	return nil
+}
+
+// Time calls fn.
+func (NopTimer) Time(fn func()) {
+	fn()
+}
+
+// Count returns 0.
+func (NopTimer) Count() uint64 { return 0 }
+
+// Max returns 0.
+func (NopTimer) Max() time.Duration { return 0 }
+
+// Min returns 0.
+func (NopTimer) Min() time.Duration { return 0 }
+
+// Mean returns 0.
+func (NopTimer) Mean() time.Duration { return 0 }
+
+// Snapshot returns an empty Snapshot.
+func (NopTimer) Snapshot() Snapshot { return Snapshot{} }
+
+// Reset does nothing.
+func (NopTimer) Reset() {}
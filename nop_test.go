@@ -0,0 +1,39 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNopTimer(t *testing.T) {
+	var timer Interface = NopTimer{}
+	timer.Observe(time.Second)
+	if err := timer.Update(time.Now()); err != nil {
+		t.Errorf("Update() = %v; want nil", err)
+	}
+	if err := timer.Update(time.Time{}); err == nil {
+		t.Errorf("Expected zero start to be rejected")
+	}
+	if err := timer.UpdateAt(time.Now(), time.Time{}); err == nil {
+		t.Errorf("Expected zero now to be rejected")
+	}
+	ran := false
+	timer.Time(func() { ran = true })
+	if !ran {
+		t.Errorf("Expected Time to run fn")
+	}
+	if timer.Count() != 0 || timer.Max() != 0 || timer.Min() != 0 || timer.Mean() != 0 {
+		t.Errorf("Expected NopTimer to read as empty")
+	}
+	if s := timer.Snapshot(); s != (Snapshot{}) {
+		t.Errorf("Snapshot() = %+v; want empty", s)
+	}
+}
+
+func TestInterfaceTimer(t *testing.T) {
+	var timer Interface = NewTimer()
+	timer.Observe(time.Millisecond)
+	if timer.Snapshot().Count != 1 {
+		t.Errorf("Expected *Timer to record through Interface")
+	}
+}
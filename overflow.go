@@ -0,0 +1,215 @@
+package timer
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/bits"
+	"time"
+)
+
+// OverflowPolicy selects what a Timer does when the sum of its durations
+// exceeds math.MaxInt64 nanoseconds, about 292 years.
+type OverflowPolicy int
+
+const (
+	// OverflowCap caps the sum at math.MaxInt64 and sets SumOverflowed,
+	// after which Mean is an underestimate. It is the default.
+	OverflowCap OverflowPolicy = iota
+	// OverflowWrap wraps the sum around, counting the wraps in an epoch,
+	// see SumEpoch, so Mean and ExactSum stay exact. Snapshots hold the
+	// capped sum, as with OverflowCap, and the exact sum in their
+	// SumEpoch and SumRemainder. Every wrap is logged as EventOverflow.
+	OverflowWrap
+	// OverflowReset resets the timer before recording the observation
+	// that would overflow, logging EventOverflow and EventReset, so the
+	// statistics stay exact but cover less time. ImportSnapshot caps
+	// instead.
+	OverflowReset
+	// OverflowBig keeps the exact sum in an arbitrary-precision integer
+	// once the sum overflows, see ExactSum, so Mean stays exact at the
+	// cost of slower recording. Snapshots hold the capped sum, as with
+	// OverflowCap, and the exact sum in their SumEpoch and SumRemainder.
+	OverflowBig
+)
+
+// String returns the name of the policy, such as "cap".
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowCap:
+		return "cap"
+	case OverflowWrap:
+		return "wrap"
+	case OverflowReset:
+		return "reset"
+	case OverflowBig:
+		return "big"
+	}
+	return "unknown"
+}
+
+// SetOverflowPolicy selects what t does when its sum overflows. It
+// should be set before the sum overflows; a sum already capped stays
+// capped until Reset.
+func (t *Timer) SetOverflowPolicy(p OverflowPolicy) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.overflow = p
+}
+
+// OverflowPolicy returns the policy set with SetOverflowPolicy.
+func (t *Timer) OverflowPolicy() OverflowPolicy {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.overflow
+}
+
+// SumEpoch returns the number of times the sum wrapped around with
+// OverflowWrap since the last Reset.
+func (t *Timer) SumEpoch() uint64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.sumEpoch
+}
+
+// ExactSum returns the sum of all observed durations in nanoseconds.
+// Returns false if the sum was capped, which only happens with
+// OverflowCap.
+func (t *Timer) ExactSum() (*big.Int, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.sumOverflowed && !t.exact.Load() {
+		return big.NewInt(t.totalSum), false
+	}
+	return t.exactSumNoLock(), true
+}
+
+// exactSumNoLock returns the sum kept beyond math.MaxInt64.
+// Callers must hold at least a read lock.
+func (t *Timer) exactSumNoLock() *big.Int {
+	if t.bigSum != nil {
+		return new(big.Int).Set(t.bigSum)
+	}
+	sum := new(big.Int).SetUint64(t.sumEpoch)
+	sum.Lsh(sum, 63)
+	return sum.Add(sum, big.NewInt(t.totalSum))
+}
+
+// exactMeanNoLock returns the mean of the sum kept beyond math.MaxInt64,
+// rounded like meanNoLock.
+// Callers must hold at least a read lock.
+func (t *Timer) exactMeanNoLock() int64 {
+	count := new(big.Int).SetUint64(t.count)
+	sum := t.exactSumNoLock()
+	sum.Add(sum, new(big.Int).Rsh(count, 1))
+	return sum.Quo(sum, count).Int64()
+}
+
+// addSumNoLock adds ns to the sum, applying the overflow policy.
+// OverflowReset is applied by observeNoLock and caps here.
+// Callers must hold the write lock.
+func (t *Timer) addSumNoLock(ns int64) {
+	if t.bigSum != nil {
+		t.bigSum.Add(t.bigSum, big.NewInt(ns))
+		return
+	}
+	if t.sumOverflowed && !t.exact.Load() {
+		return // capped until Reset
+	}
+	if ns <= 0 || t.totalSum <= math.MaxInt64-ns {
+		t.totalSum += ns
+		return
+	}
+	switch t.overflow {
+	case OverflowWrap:
+		t.logEventNoLock(EventOverflow)
+		t.totalSum = t.totalSum - math.MaxInt64 - 1 + ns
+		t.sumEpoch++
+		t.exact.Store(true)
+	case OverflowBig:
+		if !t.sumOverflowed {
+			t.logEventNoLock(EventOverflow)
+			t.bigSum = big.NewInt(t.totalSum)
+			t.bigSum.Add(t.bigSum, big.NewInt(ns))
+			t.exact.Store(true)
+		}
+		t.totalSum = math.MaxInt64
+	default:
+		if !t.sumOverflowed {
+			t.logEventNoLock(EventOverflow)
+		}
+		t.totalSum = math.MaxInt64
+	}
+	t.sumOverflowed = true
+}
+
+// setExactSumNoLock replaces the sum beyond math.MaxInt64 with the exact
+// sum of s, kept as the overflow policy does, or caps it under the other
+// policies.
+// Callers must hold the write lock.
+func (t *Timer) setExactSumNoLock(s Snapshot) {
+	hi, lo, ok := s.exactSum()
+	if !ok || s.SumEpoch == 0 {
+		return
+	}
+	switch t.overflow {
+	case OverflowWrap:
+		t.sumEpoch, t.totalSum = s.SumEpoch, int64(s.SumRemainder)
+	case OverflowBig:
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:8], hi)
+		binary.BigEndian.PutUint64(b[8:], lo)
+		t.bigSum = new(big.Int).SetBytes(b[:])
+	}
+	t.exact.Store(t.sumEpoch > 0 || t.bigSum != nil)
+}
+
+// exactSum returns the sum of s as the 128-bit integer hi * 2^64 + lo.
+// Returns false if the sum was capped or is negative.
+func (s Snapshot) exactSum() (hi, lo uint64, ok bool) {
+	switch {
+	case s.SumEpoch > 0:
+		lo, carry := bits.Add64(s.SumEpoch<<63, uint64(s.SumRemainder), 0)
+		return s.SumEpoch>>1 + carry, lo, s.SumRemainder >= 0
+	case s.SumOverflowed || s.Sum < 0:
+		return 0, 0, false
+	}
+	return 0, uint64(s.Sum), true
+}
+
+// setExactSum sets the sum of s to hi * 2^64 + lo, capping Sum at
+// math.MaxInt64 and keeping the exact sum in SumEpoch and SumRemainder
+// while SumEpoch can hold it.
+func (s *Snapshot) setExactSum(hi, lo uint64) {
+	s.SumEpoch, s.SumRemainder = 0, 0
+	if hi == 0 && lo <= math.MaxInt64 {
+		s.Sum = time.Duration(lo)
+		return
+	}
+	s.Sum, s.SumOverflowed = math.MaxInt64, true
+	if hi>>63 == 0 {
+		s.SumEpoch, s.SumRemainder = hi<<1|lo>>63, time.Duration(lo&math.MaxInt64)
+	}
+}
+
+// exactMean returns the mean of the exact sum, rounded like Mean.
+func (s Snapshot) exactMean() time.Duration {
+	hi, lo, ok := s.exactSum()
+	lo, carry := bits.Add64(lo, s.Count/2, 0)
+	hi += carry
+	if !ok || hi >= s.Count {
+		return math.MaxInt64 // mean beyond Max, see Validate
+	}
+	mean, _ := bits.Div64(hi, lo, s.Count)
+	return time.Duration(min(mean, math.MaxInt64))
+}
+
+// bigHiLo splits a non-negative x below 2^128 into hi * 2^64 + lo.
+func bigHiLo(x *big.Int) (hi, lo uint64, ok bool) {
+	if x.Sign() < 0 || x.BitLen() > 128 {
+		return 0, 0, false
+	}
+	var b [16]byte
+	x.FillBytes(b[:])
+	return binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:]), true
+}
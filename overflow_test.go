@@ -0,0 +1,147 @@
+package timer
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const maxDuration = time.Duration(math.MaxInt64)
+
+func TestOverflowCap(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(maxDuration)
+	timer.Observe(maxDuration)
+	if s := timer.Snapshot(); s.Sum != maxDuration || !s.SumOverflowed {
+		t.Errorf("Expected a capped sum, got %+v", s)
+	}
+	if _, ok := timer.ExactSum(); ok {
+		t.Errorf("Expected no exact sum after capping")
+	}
+}
+
+func TestOverflowWrap(t *testing.T) {
+	timer := NewTimer()
+	timer.SetOverflowPolicy(OverflowWrap)
+	for range 3 {
+		timer.Observe(maxDuration)
+	}
+	timer.Observe(time.Second)
+
+	s := timer.Snapshot()
+	if timer.SumEpoch() != 3 || !s.SumOverflowed || s.Sum != maxDuration {
+		t.Errorf("Expected three wraps and a capped snapshot, got epoch %d and %+v", timer.SumEpoch(), s)
+	}
+	// 3 * MaxInt64 + 1s = 2 * 2^63 + MaxInt64 - 2 + 1s
+	if s.SumEpoch != 3 || s.SumRemainder != time.Second-3 {
+		t.Errorf("Expected the exact sum in the snapshot, got %+v", s)
+	}
+	want := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(3))
+	want.Add(want, big.NewInt(int64(time.Second)))
+	if sum, ok := timer.ExactSum(); !ok || sum.Cmp(want) != 0 {
+		t.Errorf("ExactSum() = %v, %v; want %v", sum, ok, want)
+	}
+	// 3 * MaxInt64 + 1s over 4 observations
+	if mean, want := timer.Mean(), time.Duration(6917529027891081855); mean != want || s.Mean() != want {
+		t.Errorf("Mean() = %d, snapshot %d; want %d", mean, s.Mean(), want)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	timer.Reset()
+	if timer.SumEpoch() != 0 || timer.Snapshot().SumOverflowed {
+		t.Errorf("Expected Reset to clear the epoch")
+	}
+}
+
+func TestOverflowBig(t *testing.T) {
+	timer := NewTimer()
+	timer.SetOverflowPolicy(OverflowBig)
+	timer.SetSeqlock(true)
+	timer.Observe(maxDuration)
+	timer.Observe(maxDuration - 2)
+	if s := timer.Snapshot(); s.Sum != maxDuration || !s.SumOverflowed {
+		t.Errorf("Expected the snapshot to be capped, got %+v", s)
+	}
+	if mean := timer.Mean(); mean != maxDuration-1 {
+		t.Errorf("Mean() = %d; want %d", mean, maxDuration-1)
+	}
+	timer.ImportSnapshot(Snapshot{Count: 1, Min: 1, Max: 1, Sum: 1})
+	want := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(2))
+	want.Sub(want, big.NewInt(1))
+	if sum, ok := timer.ExactSum(); !ok || sum.Cmp(want) != 0 {
+		t.Errorf("ExactSum() = %v, %v; want %v", sum, ok, want)
+	}
+	if kinds := eventKinds(timer.Events()); len(kinds) != 1 || kinds[0] != EventOverflow {
+		t.Errorf("Expected one overflow event, got %v", kinds)
+	}
+}
+
+func TestOverflowReset(t *testing.T) {
+	timer := NewTimer()
+	timer.SetOverflowPolicy(OverflowReset)
+	timer.Observe(maxDuration - time.Second)
+	timer.Observe(time.Minute)
+	s := timer.Snapshot()
+	if s.Count != 1 || s.Sum != time.Minute || s.Min != time.Minute || s.SumOverflowed {
+		t.Errorf("Expected only the last observation after the reset, got %+v", s)
+	}
+	if kinds := eventKinds(timer.Events()); len(kinds) != 2 || kinds[0] != EventOverflow || kinds[1] != EventReset {
+		t.Errorf("Expected overflow and reset events, got %v", kinds)
+	}
+}
+
+func TestOverflowExactSnapshots(t *testing.T) {
+	wrapped := NewTimer()
+	wrapped.SetOverflowPolicy(OverflowWrap)
+	wrapped.Observe(maxDuration)
+	wrapped.Observe(maxDuration)
+	s := wrapped.Snapshot()
+
+	var buf bytes.Buffer
+	if err := SaveDump(&buf, NewDump(map[string]Snapshot{"wrapped": s})); err != nil {
+		t.Fatal(err)
+	}
+	d, err := LoadDump(&buf)
+	if err != nil || d.Snapshots["wrapped"] != s {
+		t.Errorf("LoadDump = %+v, %v; want %+v", d.Snapshots, err, s)
+	}
+	if mean := s.MeanSeconds(); math.Abs(mean-maxDuration.Seconds()) > 1 {
+		t.Errorf("MeanSeconds() = %v; want %v", mean, maxDuration.Seconds())
+	}
+
+	// Merge carries the exact sum into timers keeping it
+	for _, p := range []OverflowPolicy{OverflowWrap, OverflowBig} {
+		timer := NewTimer()
+		timer.SetOverflowPolicy(p)
+		timer.Observe(time.Second)
+		if err := timer.Merge(wrapped); err != nil {
+			t.Fatal(err)
+		}
+		want := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(2))
+		want.Add(want, big.NewInt(int64(time.Second)))
+		if sum, ok := timer.ExactSum(); !ok || sum.Cmp(want) != 0 {
+			t.Errorf("%v: ExactSum() after Merge = %v, %v; want %v", p, sum, ok, want)
+		}
+		if m := timer.Snapshot(); m != s.Merge(Snapshot{Count: 1, Min: time.Second, Max: time.Second, Sum: time.Second}) {
+			t.Errorf("%v: Snapshot() after Merge = %+v", p, m)
+		}
+	}
+
+	capped := NewTimer()
+	if err := capped.Merge(wrapped); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := capped.ExactSum(); ok || capped.Snapshot().SumEpoch != 0 {
+		t.Errorf("Expected OverflowCap to cap a merged exact sum, got %+v", capped.Snapshot())
+	}
+}
+
+func TestOverflowPolicyString(t *testing.T) {
+	if OverflowWrap.String() != "wrap" || OverflowPolicy(99).String() != "unknown" {
+		t.Errorf("Unexpected policy names %q, %q", OverflowWrap, OverflowPolicy(99))
+	}
+}
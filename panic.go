@@ -0,0 +1,46 @@
+package timer
+
+import "time"
+
+// Time runs fn and records how long it took. If fn panics, the duration is
+// still recorded and counted as panicked before the panic continues to
+// unwind the stack.
+func (t *Timer) Time(fn func()) {
+	if t.off() {
+		fn()
+		return
+	}
+	start := t.now()
+	panicked := true
+	defer func() {
+		d := max(t.now().Sub(start), 0)
+		if panicked {
+			t.ObservePanic(d)
+		} else {
+			t.Observe(d)
+		}
+	}()
+	fn()
+	panicked = false
+}
+
+// ObservePanic records d like Observe and additionally counts it as an
+// operation that panicked. It is intended for wrappers that detect panics
+// themselves, such as HTTP middleware.
+func (t *Timer) ObservePanic(d time.Duration) {
+	if t.off() {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.observeNoLock(d)
+	t.panicked++
+}
+
+// Panicked returns the number of recorded operations that panicked.
+// These observations are included in Count.
+func (t *Timer) Panicked() uint64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.panicked
+}
@@ -0,0 +1,49 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	timer := NewTimer()
+	timer.Time(func() { time.Sleep(time.Millisecond) })
+
+	if timer.Count() != 1 {
+		t.Errorf("Expected count to be 1, got %d", timer.Count())
+	}
+	if timer.Min() < time.Millisecond {
+		t.Errorf("Expected duration of at least 1ms, got %v", timer.Min())
+	}
+	if timer.Panicked() != 0 {
+		t.Errorf("Expected no panics, got %d", timer.Panicked())
+	}
+}
+
+func TestTimePanic(t *testing.T) {
+	timer := NewTimer()
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected panic to be re-raised with original value, got %v", r)
+			}
+		}()
+		timer.Time(func() { panic("boom") })
+	}()
+
+	if timer.Count() != 1 {
+		t.Errorf("Expected panicking call to be recorded, got count %d", timer.Count())
+	}
+	if timer.Panicked() != 1 {
+		t.Errorf("Expected panicked to be 1, got %d", timer.Panicked())
+	}
+	if timer.Snapshot().Panicked != 1 {
+		t.Errorf("Expected snapshot to carry panicked count")
+	}
+
+	timer.Reset()
+	if timer.Panicked() != 0 {
+		t.Errorf("Expected panicked to be cleared by Reset, got %d", timer.Panicked())
+	}
+}
@@ -0,0 +1,39 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhases(t *testing.T) {
+	r := NewRegistry()
+	p := NewPhases(r)
+	p.Mark("config loaded")
+	time.Sleep(time.Millisecond)
+	if d := p.Mark("db connected"); d < time.Millisecond {
+		t.Errorf("Expected db phase of at least 1ms, got %v", d)
+	}
+
+	phases := p.Breakdown()
+	if len(phases) != 2 || phases[0].Name != "config loaded" || phases[1].Name != "db connected" {
+		t.Fatalf("Unexpected breakdown %v", phases)
+	}
+	if total := p.Total(); total != phases[0].Duration+phases[1].Duration {
+		t.Errorf("Total() = %v; want the sum of phases", total)
+	}
+	if r.Get("db connected").Count() != 1 {
+		t.Errorf("Expected the phase to be recorded in the registry")
+	}
+}
+
+func TestPhasesString(t *testing.T) {
+	p := NewPhases(nil)
+	p.start = p.last.Add(-300 * time.Millisecond)
+	p.phases = []Phase{{"config loaded", 120 * time.Millisecond}, {"db", 180 * time.Millisecond}}
+	want := "config loaded  120ms  40.0%\n" +
+		"db             180ms  60.0%\n" +
+		"total          300ms"
+	if got := p.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is the interval of a Poller created with a
+// non-positive interval.
+const DefaultPollInterval = 10 * time.Second
+
+// Poller is a small synthetic prober. It periodically runs a probe, such
+// as a ping of a dependency or a "SELECT 1", and records how long
+// successful probes took in a Timer. Failed probes are counted instead of
+// recorded, so timeouts do not distort the latency statistics.
+//
+//	p := timer.NewPoller(registry.GetOrCreate("db.ping"), 10*time.Second, db.PingContext)
+//	p.Start(ctx)
+type Poller struct {
+	timer    *Timer
+	probe    func(ctx context.Context) error
+	interval tickerInterval
+
+	mutex       sync.Mutex
+	failures    uint64
+	consecutive uint64
+	lastErr     error
+	lc          lifecycle
+}
+
+// NewPoller creates a Poller running probe every interval, or every
+// DefaultPollInterval if interval is not positive, and recording
+// successful probes in t. Each probe gets a context that is canceled after
+// interval.
+func NewPoller(t *Timer, interval time.Duration, probe func(ctx context.Context) error) *Poller {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return &Poller{timer: t, probe: probe, interval: newTickerInterval(interval)}
+}
+
+// Start begins probing every interval until ctx is done or Close is
+// called. The first probe runs immediately.
+func (p *Poller) Start(ctx context.Context) error {
+	return p.lc.start(ctx, p, p.run)
+}
+
+func (p *Poller) run(ctx context.Context) {
+	ticker := p.interval.start()
+	defer p.interval.stop()
+	for {
+		_ = p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops probing and waits for a running probe to return.
+func (p *Poller) Close() error {
+	p.lc.close()
+	return nil
+}
+
+// SetInterval changes the probing interval, also while running.
+// Non-positive intervals are ignored.
+func (p *Poller) SetInterval(d time.Duration) {
+	p.interval.set(d)
+}
+
+// Interval returns the probing interval.
+func (p *Poller) Interval() time.Duration {
+	return p.interval.get()
+}
+
+// Poll runs the probe once, records the outcome, and returns the probe's
+// error.
+func (p *Poller) Poll(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.interval.get())
+	defer cancel()
+	start := time.Now()
+	err := p.probe(ctx)
+	d := max(time.Since(start), 0)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err != nil {
+		p.failures++
+		p.consecutive++
+		p.lastErr = err
+		return err
+	}
+	p.consecutive = 0
+	p.timer.Observe(d)
+	return nil
+}
+
+// Failures returns the number of failed probes.
+func (p *Poller) Failures() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.failures
+}
+
+// ConsecutiveFailures returns the number of probes that failed since the
+// last successful one.
+func (p *Poller) ConsecutiveFailures() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.consecutive
+}
+
+// LastError returns the error of the last failed probe, or nil if no
+// probe failed.
+func (p *Poller) LastError() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.lastErr
+}
@@ -0,0 +1,81 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollerPoll(t *testing.T) {
+	timer := NewTimer()
+	errDown := errors.New("down")
+	fail := true
+	p := NewPoller(timer, time.Second, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("Expected the probe context to have a deadline")
+		}
+		if fail {
+			return errDown
+		}
+		return nil
+	})
+
+	for range 2 {
+		if err := p.Poll(context.Background()); err != errDown {
+			t.Errorf("Poll() = %v; want %v", err, errDown)
+		}
+	}
+	if p.Failures() != 2 || p.ConsecutiveFailures() != 2 || p.LastError() != errDown || timer.Count() != 0 {
+		t.Errorf("Unexpected failure accounting: %d, %d, %v, count %d", p.Failures(), p.ConsecutiveFailures(), p.LastError(), timer.Count())
+	}
+
+	fail = false
+	if err := p.Poll(context.Background()); err != nil {
+		t.Errorf("Poll() = %v; want nil", err)
+	}
+	if p.Failures() != 2 || p.ConsecutiveFailures() != 0 || timer.Count() != 1 {
+		t.Errorf("Expected a success to reset consecutive failures and be recorded")
+	}
+}
+
+func TestPollerStart(t *testing.T) {
+	defer checkNoLeaks(t)
+	timer := NewTimer()
+	var probes atomic.Int64
+	p := NewPoller(timer, time.Millisecond, func(context.Context) error {
+		probes.Add(1)
+		return nil
+	})
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for probes.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if timer.Count() < 3 {
+		t.Errorf("Expected at least 3 probes, got %d", timer.Count())
+	}
+}
+
+func TestPollerInterval(t *testing.T) {
+	defer checkNoLeaks(t)
+	p := NewPoller(NewTimer(), 0, func(context.Context) error { return nil })
+	if p.Interval() != DefaultPollInterval {
+		t.Errorf("Interval of NewPoller(0) = %v; want %v", p.Interval(), DefaultPollInterval)
+	}
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	p.SetInterval(-time.Second)
+	p.SetInterval(0)
+	if p.Interval() != DefaultPollInterval {
+		t.Errorf("Interval after non-positive SetInterval = %v; want %v", p.Interval(), DefaultPollInterval)
+	}
+}
@@ -0,0 +1,70 @@
+package timer
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// QuantileMethod selects how a quantile is picked among or between the
+// observations around its rank. Monitoring systems define p99 differently,
+// so matching theirs keeps numbers consistent during a migration.
+type QuantileMethod int
+
+const (
+	// QuantileLinear interpolates linearly at the fractional rank q*n,
+	// like Prometheus histogram_quantile within a bucket.
+	QuantileLinear QuantileMethod = iota
+	// QuantileNearestRank takes the observation at rank ceil(q*n), like
+	// the classic nearest-rank definition. For a histogram its value is
+	// estimated by its position within the bucket.
+	QuantileNearestRank
+	// QuantileUpperBound takes the upper bound of the bucket holding rank
+	// ceil(q*n), a conservative estimate that never understates latency.
+	// Exact backends return the observation itself, as with
+	// QuantileNearestRank.
+	QuantileUpperBound
+)
+
+// String returns the name of the method.
+func (m QuantileMethod) String() string {
+	switch m {
+	case QuantileLinear:
+		return "linear"
+	case QuantileNearestRank:
+		return "nearest-rank"
+	case QuantileUpperBound:
+		return "upper-bound"
+	}
+	return "QuantileMethod(" + strconv.Itoa(int(m)) + ")"
+}
+
+// Quantiler is implemented by aggregator snapshots that can estimate
+// quantiles, such as ExpHistogramSnapshot.
+type Quantiler interface {
+	// Quantile is QuantileWith using QuantileLinear.
+	Quantile(q float64) time.Duration
+	QuantileWith(q float64, m QuantileMethod) time.Duration
+}
+
+var _ Quantiler = ExpHistogramSnapshot{}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) of the durations
+// observed by t, using the first attached aggregator in name order whose
+// snapshot is a Quantiler. Returns false if no such aggregator is attached,
+// as the timer itself only keeps count, sum, min, and max.
+func (t *Timer) Quantile(q float64) (time.Duration, bool) {
+	return t.QuantileWith(q, QuantileLinear)
+}
+
+// QuantileWith is like Quantile using method m.
+func (t *Timer) QuantileWith(q float64, m QuantileMethod) (time.Duration, bool) {
+	snaps := t.AggregatorSnapshots()
+	for _, name := range slices.Sorted(maps.Keys(snaps)) {
+		if qs, ok := snaps[name].(Quantiler); ok {
+			return qs.QuantileWith(q, m), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,57 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerQuantile(t *testing.T) {
+	timer := NewTimer()
+	if _, ok := timer.Quantile(0.5); ok {
+		t.Errorf("Expected no quantile without an aggregator")
+	}
+	timer.AddAggregator("hist", NewExpHistogram(160))
+	for i := 1; i <= 100; i++ {
+		timer.Observe(time.Duration(i) * time.Millisecond)
+	}
+	p99, ok := timer.Quantile(0.99)
+	if !ok || p99 < 95*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Errorf("Quantile(0.99) = %v, %v; want about 99ms, true", p99, ok)
+	}
+}
+
+func TestQuantileMethods(t *testing.T) {
+	// one bucket (2^20ns, 2^21ns] holding 4 observations
+	s := ExpHistogramSnapshot{
+		Count:    4,
+		Min:      1100 * time.Microsecond,
+		Max:      2 * time.Millisecond,
+		Sum:      6 * time.Millisecond,
+		Positive: ExpBuckets{Offset: 20, BucketCounts: []uint64{4}},
+	}
+	for _, tt := range []struct {
+		m    QuantileMethod
+		want time.Duration
+	}{
+		{QuantileLinear, 1363148},      // rank 1.2 of 4
+		{QuantileNearestRank, 1572864}, // rank 2 of 4
+		{QuantileUpperBound, 2 * time.Millisecond},
+	} {
+		if got := s.QuantileWith(0.3, tt.m); got != tt.want {
+			t.Errorf("QuantileWith(0.3, %v) = %d; want %d", tt.m, got, tt.want)
+		}
+	}
+	if s.Quantile(0.3) != s.QuantileWith(0.3, QuantileLinear) {
+		t.Errorf("Expected Quantile to interpolate linearly")
+	}
+
+	timer := NewTimer()
+	timer.AddAggregator("hist", NewExpHistogram(160))
+	timer.Observe(time.Millisecond)
+	if d, ok := timer.QuantileWith(1, QuantileUpperBound); !ok || d != time.Millisecond {
+		t.Errorf("QuantileWith(1, upper-bound) = %v, %v; want 1ms clamped to max, true", d, ok)
+	}
+	if QuantileNearestRank.String() != "nearest-rank" || QuantileMethod(9).String() != "QuantileMethod(9)" {
+		t.Errorf("Unexpected method names %q, %q", QuantileNearestRank, QuantileMethod(9))
+	}
+}
@@ -0,0 +1,175 @@
+package timer
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultExactLimit is the default number of observations up to which an
+// AdaptiveQuantiles keeps every sample.
+const DefaultExactLimit = 1000
+
+// AdaptiveQuantiles is an Aggregator answering quantiles exactly while
+// few durations were observed, and approximately beyond. It keeps every
+// observation up to a limit, so tests and low-traffic endpoints report
+// precise values, and moves them into an ExpHistogram once the limit is
+// exceeded, bounding memory. Reset returns it to exact mode.
+//
+// AdaptiveQuantiles is safe for concurrent use.
+type AdaptiveQuantiles struct {
+	mutex   sync.Mutex
+	limit   int
+	maxSize int
+	samples []time.Duration
+	hist    *ExpHistogram // set once the limit is exceeded
+}
+
+// AdaptiveQuantilesSnapshot is a copy of an AdaptiveQuantiles: either its
+// sorted samples, or its histogram once the limit was exceeded.
+type AdaptiveQuantilesSnapshot struct {
+	Exact     bool                  `json:"exact"`
+	Samples   []time.Duration       `json:"samples_ns,omitempty"`
+	Histogram *ExpHistogramSnapshot `json:"histogram,omitempty"`
+}
+
+var _ Quantiler = AdaptiveQuantilesSnapshot{}
+
+// NewAdaptiveQuantiles creates an AdaptiveQuantiles keeping up to limit
+// samples, or DefaultExactLimit if limit is not positive, and switching to
+// an ExpHistogram with at most maxSize buckets beyond.
+func NewAdaptiveQuantiles(limit, maxSize int) *AdaptiveQuantiles {
+	if limit <= 0 {
+		limit = DefaultExactLimit
+	}
+	return &AdaptiveQuantiles{limit: limit, maxSize: maxSize}
+}
+
+// Observe records d. Negative durations count as zero.
+func (a *AdaptiveQuantiles) Observe(d time.Duration) {
+	d = max(d, 0)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.hist == nil && len(a.samples) < a.limit {
+		a.samples = append(a.samples, d)
+		return
+	}
+	a.switchNoLock()
+	a.hist.Observe(d)
+}
+
+// switchNoLock moves the samples into the histogram, if not done yet.
+// Callers must hold the lock.
+func (a *AdaptiveQuantiles) switchNoLock() {
+	if a.hist != nil {
+		return
+	}
+	a.hist = NewExpHistogram(a.maxSize)
+	for _, d := range a.samples {
+		a.hist.Observe(d)
+	}
+	a.samples = nil
+}
+
+// Exact reports whether quantiles are still computed from every sample.
+func (a *AdaptiveQuantiles) Exact() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.hist == nil
+}
+
+// Snapshot returns an AdaptiveQuantilesSnapshot, implementing Aggregator.
+func (a *AdaptiveQuantiles) Snapshot() any {
+	return a.QuantilesSnapshot()
+}
+
+// QuantilesSnapshot returns a copy of the samples or the histogram.
+func (a *AdaptiveQuantiles) QuantilesSnapshot() AdaptiveQuantilesSnapshot {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.hist != nil {
+		h := a.hist.ExpSnapshot()
+		return AdaptiveQuantilesSnapshot{Histogram: &h}
+	}
+	samples := slices.Clone(a.samples)
+	slices.Sort(samples)
+	return AdaptiveQuantilesSnapshot{Exact: true, Samples: samples}
+}
+
+// Reset clears the observations and returns to exact mode.
+func (a *AdaptiveQuantiles) Reset() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.samples = a.samples[:0]
+	a.hist = nil
+}
+
+// Merge adds the observations of other, an *AdaptiveQuantiles, switching
+// to the histogram if either has or the combined samples exceed the limit.
+func (a *AdaptiveQuantiles) Merge(other Aggregator) error {
+	o, ok := other.(*AdaptiveQuantiles)
+	if !ok {
+		return errors.New("can only merge an AdaptiveQuantiles")
+	}
+	o.mutex.Lock()
+	samples, hist := slices.Clone(o.samples), o.hist
+	o.mutex.Unlock()
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.hist == nil && hist == nil && len(a.samples)+len(samples) <= a.limit {
+		a.samples = append(a.samples, samples...)
+		return nil
+	}
+	a.switchNoLock()
+	for _, d := range samples {
+		a.hist.Observe(d)
+	}
+	if hist != nil {
+		return a.hist.Merge(hist)
+	}
+	return nil
+}
+
+// Quantile is QuantileWith using QuantileLinear.
+func (s AdaptiveQuantilesSnapshot) Quantile(q float64) time.Duration {
+	return s.QuantileWith(q, QuantileLinear)
+}
+
+// QuantileWith returns the q-quantile (0 <= q <= 1) using method m, exact
+// while the snapshot holds samples. Exact linear interpolation is between
+// the samples at ranks floor and ceil of (n-1)*q, as in NumPy's default,
+// and both QuantileNearestRank and QuantileUpperBound return the sample at
+// rank ceil(q*n). Returns 0 for an empty snapshot.
+func (s AdaptiveQuantilesSnapshot) QuantileWith(q float64, m QuantileMethod) time.Duration {
+	if !s.Exact {
+		if s.Histogram == nil {
+			return 0
+		}
+		return s.Histogram.QuantileWith(q, m)
+	}
+	return sortedQuantile(s.Samples, q, m)
+}
+
+// sortedQuantile returns the q-quantile of sorted samples using method m,
+// see AdaptiveQuantilesSnapshot.QuantileWith.
+func sortedQuantile(samples []time.Duration, q float64, m QuantileMethod) time.Duration {
+	n := len(samples)
+	if n == 0 {
+		return 0
+	}
+	q = min(max(q, 0), 1)
+	if m != QuantileLinear {
+		rank := max(int(math.Ceil(q*float64(n))), 1)
+		return samples[rank-1]
+	}
+	h := q * float64(n-1)
+	lo := int(h)
+	if lo+1 >= n {
+		return samples[n-1]
+	}
+	frac := h - float64(lo)
+	return samples[lo] + time.Duration(math.Round(frac*float64(samples[lo+1]-samples[lo])))
+}
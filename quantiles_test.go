@@ -0,0 +1,79 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveQuantiles(t *testing.T) {
+	a := NewAdaptiveQuantiles(10, 0)
+	timer := NewTimer()
+	timer.AddAggregator("q", a)
+	for _, ms := range []time.Duration{5, 1, 4, 2, 3} {
+		timer.Observe(ms * time.Millisecond)
+	}
+	if !a.Exact() {
+		t.Fatalf("Expected exact mode below the limit")
+	}
+	for _, tt := range []struct {
+		q    float64
+		m    QuantileMethod
+		want time.Duration
+	}{
+		{0.5, QuantileLinear, 3 * time.Millisecond},
+		{0.3, QuantileLinear, 2200 * time.Microsecond},
+		{0.3, QuantileNearestRank, 2 * time.Millisecond},
+		{0.3, QuantileUpperBound, 2 * time.Millisecond},
+		{1, QuantileLinear, 5 * time.Millisecond},
+		{0, QuantileNearestRank, time.Millisecond},
+	} {
+		if got, ok := timer.QuantileWith(tt.q, tt.m); !ok || got != tt.want {
+			t.Errorf("QuantileWith(%v, %v) = %v, %v; want %v", tt.q, tt.m, got, ok, tt.want)
+		}
+	}
+
+	// beyond the limit the samples move into a histogram
+	for i := range 20 {
+		timer.Observe(time.Duration(i+6) * time.Millisecond)
+	}
+	if a.Exact() {
+		t.Fatalf("Expected histogram mode beyond the limit")
+	}
+	s := a.QuantilesSnapshot()
+	if s.Histogram == nil || s.Histogram.Count != 25 || s.Histogram.Max != 25*time.Millisecond {
+		t.Fatalf("Expected a histogram of all 25 observations, got %+v", s)
+	}
+	if p50 := s.Quantile(0.5); p50 < 12*time.Millisecond || p50 > 14*time.Millisecond {
+		t.Errorf("Expected p50 of about 13ms, got %v", p50)
+	}
+
+	timer.Reset()
+	if !a.Exact() || a.QuantilesSnapshot().Quantile(0.5) != 0 {
+		t.Errorf("Expected Reset to return to an empty exact mode")
+	}
+}
+
+func TestAdaptiveQuantilesMerge(t *testing.T) {
+	a, b := NewAdaptiveQuantiles(4, 0), NewAdaptiveQuantiles(4, 0)
+	a.Observe(time.Millisecond)
+	b.Observe(2 * time.Millisecond)
+	if err := a.Merge(b); err != nil || !a.Exact() {
+		t.Fatalf("Expected an exact merge, got %v, exact %v", err, a.Exact())
+	}
+	if got := a.QuantilesSnapshot().Samples; len(got) != 2 || got[1] != 2*time.Millisecond {
+		t.Errorf("Expected merged samples, got %v", got)
+	}
+
+	for range 5 {
+		b.Observe(3 * time.Millisecond)
+	}
+	if err := a.Merge(b); err != nil || a.Exact() {
+		t.Fatalf("Expected merging a histogram to switch modes, got %v, exact %v", err, a.Exact())
+	}
+	if n := a.QuantilesSnapshot().Histogram.Count; n != 8 {
+		t.Errorf("Expected 8 observations after merging, got %d", n)
+	}
+	if err := a.Merge(NewExpHistogram(0)); err == nil {
+		t.Errorf("Expected merging another aggregator type to fail")
+	}
+}
@@ -0,0 +1,88 @@
+package timer
+
+import "time"
+
+// queueStats holds the decomposition of EnqueueToken durations.
+type queueStats struct {
+	wait    *Timer
+	service *Timer
+}
+
+// EnqueueToken tracks a request from the moment it is queued until it is
+// done, separating the time spent waiting for service from the time spent
+// being served. High wait times point at saturation, high service times
+// at slow handlers. An EnqueueToken is not safe for concurrent use.
+type EnqueueToken struct {
+	timer    *Timer
+	enqueued time.Time
+	started  time.Time
+	done     bool
+}
+
+// Enqueue starts tracking a queued request.
+func (t *Timer) Enqueue() *EnqueueToken {
+	t.mutex.Lock()
+	if t.queue == nil {
+		t.queue = &queueStats{wait: NewTimer(), service: NewTimer()}
+	}
+	t.mutex.Unlock()
+	return &EnqueueToken{timer: t, enqueued: t.now()}
+}
+
+// StartService marks the end of the wait and the start of service. Only
+// the first call has an effect.
+func (tok *EnqueueToken) StartService() {
+	if tok.started.IsZero() {
+		tok.started = tok.timer.now()
+	}
+}
+
+// Done records the wait time in the timer's wait statistics, the service
+// time in its service statistics, and their total as a regular
+// observation, and returns wait and service time. Without a prior
+// StartService the whole duration counts as wait. Only the first call
+// records.
+func (tok *EnqueueToken) Done() (wait, service time.Duration) {
+	now := tok.timer.now()
+	started := tok.started
+	if started.IsZero() {
+		started = now
+	}
+	wait = max(started.Sub(tok.enqueued), 0)
+	service = max(now.Sub(started), 0)
+	if tok.done {
+		return wait, service
+	}
+	tok.done = true
+
+	t := tok.timer
+	t.mutex.RLock()
+	q := t.queue
+	t.mutex.RUnlock()
+	q.wait.Observe(wait)
+	q.service.Observe(service)
+	t.Observe(wait + service)
+	return wait, service
+}
+
+// WaitSnapshot returns statistics of the time EnqueueTokens spent waiting
+// for service.
+func (t *Timer) WaitSnapshot() Snapshot {
+	return t.queuePhase(func(q *queueStats) *Timer { return q.wait })
+}
+
+// ServiceSnapshot returns statistics of the time EnqueueTokens spent
+// being served.
+func (t *Timer) ServiceSnapshot() Snapshot {
+	return t.queuePhase(func(q *queueStats) *Timer { return q.service })
+}
+
+func (t *Timer) queuePhase(phase func(*queueStats) *Timer) Snapshot {
+	t.mutex.RLock()
+	q := t.queue
+	t.mutex.RUnlock()
+	if q == nil {
+		return Snapshot{}
+	}
+	return phase(q).Snapshot()
+}
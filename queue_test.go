@@ -0,0 +1,66 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnqueueToken(t *testing.T) {
+	timer := NewTimer()
+	if s := timer.WaitSnapshot(); s.Count != 0 {
+		t.Errorf("Expected empty wait stats before Enqueue, got %+v", s)
+	}
+
+	tok := timer.Enqueue()
+	time.Sleep(3 * time.Millisecond)
+	tok.StartService()
+	tok.StartService()
+	time.Sleep(2 * time.Millisecond)
+	wait, service := tok.Done()
+	if wait < 3*time.Millisecond || service < 2*time.Millisecond {
+		t.Errorf("Done = %v, %v; want >= 3ms, >= 2ms", wait, service)
+	}
+	tok.Done()
+
+	if s := timer.WaitSnapshot(); s.Count != 1 || s.Max != wait {
+		t.Errorf("WaitSnapshot = %+v; want one observation of %v", s, wait)
+	}
+	if s := timer.ServiceSnapshot(); s.Count != 1 || s.Max != service {
+		t.Errorf("ServiceSnapshot = %+v; want one observation of %v", s, service)
+	}
+	if timer.Count() != 1 || timer.Max() != wait+service {
+		t.Errorf("Expected total %v recorded once, got %v", wait+service, timer)
+	}
+
+	timer.Reset()
+	if timer.WaitSnapshot().Count != 0 || timer.ServiceSnapshot().Count != 0 {
+		t.Errorf("Expected Reset to clear wait and service stats")
+	}
+}
+
+func TestEnqueueTokenWithoutService(t *testing.T) {
+	timer := NewTimer()
+	tok := timer.Enqueue()
+	time.Sleep(time.Millisecond)
+	wait, service := tok.Done()
+	if wait < time.Millisecond || service != 0 {
+		t.Errorf("Done = %v, %v; want all wait and no service", wait, service)
+	}
+}
+
+func TestEnqueueTokenClock(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	timer := NewTimer()
+	timer.SetClock(clock)
+
+	tok := timer.Enqueue()
+	clock.t = clock.t.Add(30 * time.Millisecond)
+	tok.StartService()
+	clock.t = clock.t.Add(20 * time.Millisecond)
+	if wait, service := tok.Done(); wait != 30*time.Millisecond || service != 20*time.Millisecond {
+		t.Errorf("Done = %v, %v; want 30ms, 20ms", wait, service)
+	}
+	if timer.Max() != 50*time.Millisecond {
+		t.Errorf("Expected 50ms recorded, got %v", timer.Max())
+	}
+}
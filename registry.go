@@ -0,0 +1,347 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrAlreadyRegistered is matched by errors.Is for every
+// *AlreadyRegisteredError.
+var ErrAlreadyRegistered = errors.New("timer already registered")
+
+// ErrNilTimer is returned when registering a nil *Timer.
+var ErrNilTimer = errors.New("cannot register nil timer")
+
+// AlreadyRegisteredError is returned when a timer or vec is registered under
+// a name that is already taken. Existing or ExistingVec holds the current
+// owner of the name.
+type AlreadyRegisteredError struct {
+	Name        string
+	Existing    *Timer
+	ExistingVec *TimerVec
+}
+
+func (e *AlreadyRegisteredError) Error() string {
+	return fmt.Sprintf("timer %q is already registered", e.Name)
+}
+
+// Is reports whether target is ErrAlreadyRegistered.
+func (e *AlreadyRegisteredError) Is(target error) bool {
+	return target == ErrAlreadyRegistered
+}
+
+// CollisionPolicy selects what RegisterWithPolicy does when the name is
+// already taken.
+type CollisionPolicy int
+
+const (
+	// CollisionError returns an *AlreadyRegisteredError.
+	CollisionError CollisionPolicy = iota
+	// CollisionReturnExisting keeps and returns the existing timer.
+	CollisionReturnExisting
+	// CollisionPanic panics with an *AlreadyRegisteredError.
+	CollisionPanic
+)
+
+// Registry is a named collection of timers.
+// All methods are safe for concurrent use.
+//
+// A Registry may be a view of a parent registry created with SubRegistry,
+// in which case every name it is given or returns is relative to the view's
+// prefix.
+type Registry struct {
+	prefix string // Prepended to every name, "" for a root registry
+	store  *registryStore
+	rules  *ExportRules // Applied by Snapshot, nil to export everything
+}
+
+// registryStore holds the timers shared by a root registry and its views.
+type registryStore struct {
+	mutex   sync.RWMutex
+	timers  map[string]*Timer
+	vecs    map[string]*TimerVec
+	removed []Event // evictions of timers no longer registered, oldest first
+}
+
+// DefaultRegistry is the registry used by package-level helpers.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		store: &registryStore{
+			timers: make(map[string]*Timer),
+			vecs:   make(map[string]*TimerVec),
+		},
+	}
+}
+
+// SubRegistry returns a view of r in which every name is prefixed with
+// name followed by a dot. Timers registered through the view are visible in
+// r under their full dotted name, e.g.
+//
+//	r.SubRegistry("db").GetOrCreate("query") // registered in r as "db.query"
+//
+// Libraries can accept a *Registry and register their timers without
+// knowing the namespace chosen by the caller. Views can be nested.
+func (r *Registry) SubRegistry(name string) *Registry {
+	return &Registry{
+		prefix: r.prefix + name + ".",
+		store:  r.store,
+		rules:  r.rules,
+	}
+}
+
+// Prefix returns the prefix prepended to names by r, "" for a root registry.
+func (r *Registry) Prefix() string {
+	return r.prefix
+}
+
+// Register adds t to the registry under name.
+// Returns an *AlreadyRegisteredError if the name is taken.
+func (r *Registry) Register(name string, t *Timer) error {
+	_, err := r.RegisterWithPolicy(name, t, CollisionError)
+	return err
+}
+
+// MustRegister is like Register but panics if the name is taken or t is nil.
+func (r *Registry) MustRegister(name string, t *Timer) {
+	if _, err := r.RegisterWithPolicy(name, t, CollisionPanic); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterWithPolicy adds t to the registry under name, resolving a taken
+// name according to policy. Returns the timer registered under name once
+// the call completes, which is the existing timer when policy is
+// CollisionReturnExisting and the name was taken.
+// Returns ErrNilTimer if t is nil.
+func (r *Registry) RegisterWithPolicy(name string, t *Timer, policy CollisionPolicy) (*Timer, error) {
+	if t == nil {
+		return nil, ErrNilTimer
+	}
+	name = r.prefix + name
+	s := r.store
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if existing, ok := s.timers[name]; ok {
+		switch policy {
+		case CollisionReturnExisting:
+			return existing, nil
+		case CollisionPanic:
+			panic(&AlreadyRegisteredError{Name: name, Existing: existing})
+		default:
+			return nil, &AlreadyRegisteredError{Name: name, Existing: existing}
+		}
+	}
+	s.timers[name] = t
+	return t, nil
+}
+
+// RegisterVec adds v to the registry under name. Each child of v is
+// exported as `name{label="value",...}`.
+// Returns an *AlreadyRegisteredError if a vec is already registered under
+// name.
+func (r *Registry) RegisterVec(name string, v *TimerVec) error {
+	name = r.prefix + name
+	s := r.store
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if existing, ok := s.vecs[name]; ok {
+		return &AlreadyRegisteredError{Name: name, ExistingVec: existing}
+	}
+	s.vecs[name] = v
+	return nil
+}
+
+// GetVec returns the vec registered under name, or nil if there is none.
+func (r *Registry) GetVec(name string) *TimerVec {
+	s := r.store
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.vecs[r.prefix+name]
+}
+
+// Unregister removes the timer and the vec registered under name.
+// Returns false if neither was registered.
+func (r *Registry) Unregister(name string) bool {
+	name = r.prefix + name
+	s := r.store
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, okTimer := s.timers[name]
+	_, okVec := s.vecs[name]
+	delete(s.timers, name)
+	delete(s.vecs, name)
+	return okTimer || okVec
+}
+
+// unregisterTimer removes the timer registered under name only if it is t.
+// Returns false if name is registered to another timer or not at all.
+func (r *Registry) unregisterTimer(name string, t *Timer) bool {
+	name = r.prefix + name
+	s := r.store
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.timers[name] != t {
+		return false
+	}
+	delete(s.timers, name)
+	return true
+}
+
+// Get returns the timer registered under name, or nil if there is none.
+func (r *Registry) Get(name string) *Timer {
+	s := r.store
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.timers[r.prefix+name]
+}
+
+// GetOrCreate returns the timer registered under name, atomically
+// registering a new one if there is none. It is safe to call concurrently
+// on first use, so callers need no synchronization of their own.
+func (r *Registry) GetOrCreate(name string) *Timer {
+	name = r.prefix + name
+	s := r.store
+	s.mutex.RLock()
+	t, ok := s.timers[name]
+	s.mutex.RUnlock()
+	if ok {
+		return t
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if t, ok := s.timers[name]; ok {
+		return t
+	}
+	t = NewTimer()
+	s.timers[name] = t
+	return t
+}
+
+// Len returns the number of registered timers and vecs.
+func (r *Registry) Len() int {
+	s := r.store
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if r.prefix == "" {
+		return len(s.timers) + len(s.vecs)
+	}
+	n := 0
+	for name := range s.timers {
+		if strings.HasPrefix(name, r.prefix) {
+			n++
+		}
+	}
+	for name := range s.vecs {
+		if strings.HasPrefix(name, r.prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// allTimers returns every registered timer and the children of every
+// registered vec.
+func (r *Registry) allTimers() []*Timer {
+	s := r.store
+	var timers []*Timer
+	var vecs []*TimerVec
+	s.mutex.RLock()
+	for name, t := range s.timers {
+		if strings.HasPrefix(name, r.prefix) {
+			timers = append(timers, t)
+		}
+	}
+	for name, v := range s.vecs {
+		if strings.HasPrefix(name, r.prefix) {
+			vecs = append(vecs, v)
+		}
+	}
+	s.mutex.RUnlock()
+	for _, v := range vecs {
+		timers = append(timers, v.timers()...)
+	}
+	return timers
+}
+
+// Snapshot returns a snapshot of every registered timer, keyed by name.
+// Children of registered vecs are keyed as `name{label="value",...}`.
+// Each timer is snapshotted individually, so the result is consistent per
+// timer but not across timers. If r was created by WithExportRules, the
+// rules are applied to the result.
+func (r *Registry) Snapshot() map[string]Snapshot {
+	s := r.store
+	s.mutex.RLock()
+	snaps := make(map[string]Snapshot, len(s.timers))
+	for name, t := range s.timers {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			snaps[rel] = t.Snapshot()
+		}
+	}
+	for name, v := range s.vecs {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			for labels, vs := range v.Snapshot() {
+				snaps[rel+"{"+labels+"}"] = vs
+			}
+		}
+	}
+	s.mutex.RUnlock()
+	if r.rules != nil {
+		snaps = r.rules.Apply(snaps)
+	}
+	return snaps
+}
+
+// Names returns the names Snapshot would return, in sorted order.
+func (r *Registry) Names() []string {
+	return sortedNames(r.Snapshot())
+}
+
+// Each calls fn for every exported timer in name order, stopping early if
+// fn returns false. All snapshots are taken before the first call, so fn
+// sees a single pass over the registry even while timers are updated or
+// registered concurrently, and may itself use the registry freely.
+func (r *Registry) Each(fn func(name string, s Snapshot) bool) {
+	snaps := r.Snapshot()
+	for _, name := range sortedNames(snaps) {
+		if !fn(name, snaps[name]) {
+			return
+		}
+	}
+}
+
+// Aggregate merges the snapshots of every vec child that has the label
+// named label, grouped by the label's value, such as the overall latency
+// per tenant across every endpoint:
+//
+//	perTenant := r.Aggregate("tenant")
+//
+// Timers and vec children without the label are left out.
+func (r *Registry) Aggregate(label string) map[string]Snapshot {
+	groups := make(map[string]Snapshot)
+	for name, snap := range r.Snapshot() {
+		_, labels, ok := SplitName(name)
+		if !ok {
+			continue
+		}
+		for _, l := range labels {
+			if l.Name == label {
+				groups[l.Value] = groups[l.Value].Merge(snap)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// sortedNames returns the keys of m in sorted order.
+func sortedNames[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,247 @@
+package timer
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	t0 := NewTimer()
+
+	if err := r.Register("db", t0); err != nil {
+		t.Fatalf("Unexpected error on register: %v", err)
+	}
+	if err := r.Register("db", NewTimer()); err == nil {
+		t.Errorf("Expected error registering duplicate name")
+	}
+	if got := r.Get("db"); got != t0 {
+		t.Errorf("Get returned %p; want %p", got, t0)
+	}
+	if got := r.Get("missing"); got != nil {
+		t.Errorf("Expected nil for missing timer, got %p", got)
+	}
+	if r.Len() != 1 {
+		t.Errorf("Len = %d; want 1", r.Len())
+	}
+
+	if !r.Unregister("db") {
+		t.Errorf("Expected Unregister to report removal")
+	}
+	if r.Unregister("db") {
+		t.Errorf("Expected second Unregister to report nothing removed")
+	}
+	if r.Len() != 0 {
+		t.Errorf("Len = %d; want 0", r.Len())
+	}
+}
+
+func TestRegistrySnapshot(t *testing.T) {
+	r := NewRegistry()
+	a, b := NewTimer(), NewTimer()
+	_ = r.Register("a", a)
+	_ = r.Register("b", b)
+	a.Observe(10 * time.Millisecond)
+
+	snaps := r.Snapshot()
+	if len(snaps) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snaps))
+	}
+	if snaps["a"].Count != 1 {
+		t.Errorf("Expected a to have count 1, got %d", snaps["a"].Count)
+	}
+	if snaps["b"].Count != 0 {
+		t.Errorf("Expected b to have count 0, got %d", snaps["b"].Count)
+	}
+}
+
+func TestRegistryRegisterWithPolicy(t *testing.T) {
+	r := NewRegistry()
+	first, second := NewTimer(), NewTimer()
+	_ = r.Register("db", first)
+
+	err := r.Register("db", second)
+	if !errors.Is(err, ErrAlreadyRegistered) {
+		t.Errorf("Expected ErrAlreadyRegistered, got %v", err)
+	}
+	var are *AlreadyRegisteredError
+	if !errors.As(err, &are) || are.Name != "db" || are.Existing != first {
+		t.Errorf("Expected *AlreadyRegisteredError for db with existing timer, got %#v", err)
+	}
+
+	got, err := r.RegisterWithPolicy("db", second, CollisionReturnExisting)
+	if err != nil || got != first {
+		t.Errorf("RegisterWithPolicy(ReturnExisting) = %p, %v; want %p, nil", got, err, first)
+	}
+
+	got, err = r.RegisterWithPolicy("cache", second, CollisionReturnExisting)
+	if err != nil || got != second {
+		t.Errorf("RegisterWithPolicy on free name = %p, %v; want %p, nil", got, err, second)
+	}
+
+	if _, err := r.RegisterWithPolicy("nil", nil, CollisionError); !errors.Is(err, ErrNilTimer) {
+		t.Errorf("Expected ErrNilTimer, got %v", err)
+	}
+}
+
+func TestRegistryMustRegister(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister("db", NewTimer())
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, ErrAlreadyRegistered) {
+			t.Errorf("Expected panic with ErrAlreadyRegistered, got %v", err)
+		}
+	}()
+	r.MustRegister("db", NewTimer())
+}
+
+func TestRegistryGetOrCreate(t *testing.T) {
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	timers := make([]*Timer, 50)
+	for i := range timers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timers[i] = r.GetOrCreate("db")
+		}()
+	}
+	wg.Wait()
+
+	for _, t0 := range timers {
+		if t0 != timers[0] {
+			t.Fatalf("Expected every caller to get the same timer")
+		}
+	}
+	if r.Len() != 1 {
+		t.Errorf("Len = %d; want 1", r.Len())
+	}
+}
+
+func TestSubRegistry(t *testing.T) {
+	r := NewRegistry()
+	db := r.SubRegistry("db")
+	pg := db.SubRegistry("pg")
+
+	if pg.Prefix() != "db.pg." {
+		t.Errorf("Prefix = %q; want %q", pg.Prefix(), "db.pg.")
+	}
+
+	query := pg.GetOrCreate("query")
+	if got := r.Get("db.pg.query"); got != query {
+		t.Errorf("Expected timer to be visible in root as db.pg.query")
+	}
+	if got := db.Get("pg.query"); got != query {
+		t.Errorf("Expected timer to be visible in db view as pg.query")
+	}
+
+	_ = r.Register("http", NewTimer())
+	if err := db.Register("conn", NewTimer()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err := pg.Register("query", NewTimer())
+	var are *AlreadyRegisteredError
+	if !errors.As(err, &are) || are.Name != "db.pg.query" {
+		t.Errorf("Expected collision on full name db.pg.query, got %v", err)
+	}
+
+	if r.Len() != 3 || db.Len() != 2 || pg.Len() != 1 {
+		t.Errorf("Len = %d/%d/%d; want 3/2/1", r.Len(), db.Len(), pg.Len())
+	}
+
+	snaps := db.Snapshot()
+	if _, ok := snaps["pg.query"]; !ok || len(snaps) != 2 {
+		t.Errorf("Expected db view snapshot keyed by relative names, got %v", snaps)
+	}
+
+	if !db.Unregister("conn") || r.Get("db.conn") != nil {
+		t.Errorf("Expected Unregister through view to remove db.conn from root")
+	}
+}
+
+func TestRegistryVec(t *testing.T) {
+	r := NewRegistry()
+	v := NewTimerVec("method")
+	if err := r.SubRegistry("http").RegisterVec("requests", v); err != nil {
+		t.Fatalf("RegisterVec failed: %v", err)
+	}
+	if err := r.RegisterVec("http.requests", NewTimerVec("method")); !errors.Is(err, ErrAlreadyRegistered) {
+		t.Errorf("Expected ErrAlreadyRegistered, got %v", err)
+	}
+	if r.GetVec("http.requests") != v {
+		t.Errorf("Expected GetVec to return the registered vec")
+	}
+
+	v.WithLabelValues("GET").Observe(time.Millisecond)
+	snaps := r.Snapshot()
+	if snaps[`http.requests{method="GET"}`].Count != 1 {
+		t.Errorf("Expected vec child in registry snapshot, got %v", snaps)
+	}
+	if r.Len() != 1 {
+		t.Errorf("Len = %d; want 1", r.Len())
+	}
+
+	if !r.Unregister("http.requests") || r.GetVec("http.requests") != nil {
+		t.Errorf("Expected Unregister to remove the vec")
+	}
+}
+
+func TestRegistryNamesAndEach(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"c", "a", "b"} {
+		r.GetOrCreate(name).Observe(time.Millisecond)
+	}
+	v := NewTimerVec("k")
+	_ = r.RegisterVec("a", v)
+	v.WithLabelValues("x")
+
+	want := []string{"a", `a{k="x"}`, "b", "c"}
+	if got := r.Names(); !slices.Equal(got, want) {
+		t.Errorf("Names = %q; want %q", got, want)
+	}
+
+	var visited []string
+	r.Each(func(name string, s Snapshot) bool {
+		visited = append(visited, name)
+		// registering during iteration must neither deadlock nor be visited
+		r.GetOrCreate("z" + name)
+		return name != "b"
+	})
+	if want := []string{"a", `a{k="x"}`, "b"}; !slices.Equal(visited, want) {
+		t.Errorf("Each visited %q; want %q", visited, want)
+	}
+}
+
+func TestRegistryAggregate(t *testing.T) {
+	r := NewRegistry()
+	http := NewTimerVec("tenant", "route")
+	db := NewTimerVec("tenant")
+	_ = r.RegisterVec("http", http)
+	_ = r.RegisterVec("db", db)
+	r.GetOrCreate("unlabeled").Observe(time.Hour)
+
+	http.WithLabelValues("acme", "/a").Observe(10 * time.Millisecond)
+	http.WithLabelValues("acme", "/b").Observe(30 * time.Millisecond)
+	http.WithLabelValues("globex", "/a").Observe(5 * time.Millisecond)
+	db.WithLabelValues("acme").Observe(2 * time.Millisecond)
+
+	groups := r.Aggregate("tenant")
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %v", groups)
+	}
+	acme := groups["acme"]
+	if acme.Count != 3 || acme.Min != 2*time.Millisecond || acme.Max != 30*time.Millisecond {
+		t.Errorf("acme = %+v; want 3 observations from 2ms to 30ms", acme)
+	}
+	if groups["globex"].Count != 1 {
+		t.Errorf("globex = %+v; want 1 observation", groups["globex"])
+	}
+	if n := len(r.Aggregate("region")); n != 0 {
+		t.Errorf("Expected no groups for an unknown label, got %d", n)
+	}
+}
@@ -0,0 +1,156 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultReportInterval is the interval of a Reporter created with a
+// non-positive interval.
+const DefaultReportInterval = 10 * time.Second
+
+// Reporter periodically passes snapshots of a registry to a report
+// function, such as an exporter's write method.
+type Reporter struct {
+	registry *Registry
+	interval tickerInterval
+	report   func(map[string]Snapshot)
+
+	// serializes calls to report between the loop, Flush, and Close
+	reportMutex sync.Mutex
+	delta       bool                // report only changed timers
+	previous    map[string]Snapshot // last reported snapshots in delta mode
+	lc          lifecycle
+
+	aligned  bool           // report at wall-clock multiples of the interval
+	location *time.Location // zone the reports are aligned in, nil for UTC
+}
+
+// NewReporter creates a Reporter calling report with a snapshot of r every
+// interval once started, or every DefaultReportInterval if interval is not
+// positive.
+func NewReporter(r *Registry, interval time.Duration, report func(map[string]Snapshot)) *Reporter {
+	if interval <= 0 {
+		interval = DefaultReportInterval
+	}
+	return &Reporter{
+		registry: r,
+		interval: newTickerInterval(interval),
+		report:   report,
+	}
+}
+
+// Start begins periodic reporting until ctx is done or Close is called.
+func (rp *Reporter) Start(ctx context.Context) error {
+	return rp.lc.start(ctx, rp, rp.run)
+}
+
+func (rp *Reporter) run(ctx context.Context) {
+	if rp.aligned {
+		rp.runAligned(ctx)
+		return
+	}
+	ticker := rp.interval.start()
+	defer rp.interval.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rp.Flush()
+		}
+	}
+}
+
+// runAligned reports at the wall-clock boundaries of the interval,
+// reading the interval anew for every report.
+func (rp *Reporter) runAligned(ctx context.Context) {
+	var last time.Time
+	for {
+		d := rp.interval.get()
+		next := nextAligned(time.Now(), d, rp.location)
+		if !next.After(last) {
+			// woken early by a wall clock step, skip the reported boundary
+			next = nextAligned(last, d, rp.location)
+		}
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+			last = next
+			rp.Flush()
+		}
+	}
+}
+
+// SetAligned makes the reporter report at wall-clock multiples of the
+// interval, such as on every full minute for an interval of a minute,
+// instead of an interval after Start, so reports of many hosts cover the
+// same periods. Intervals of whole days start at midnight, see
+// SetLocation. It must be called before Start. In aligned mode, a change
+// of the interval takes effect after the next report.
+func (rp *Reporter) SetAligned(on bool) {
+	rp.aligned = on
+}
+
+// SetLocation aligns reports in loc instead of UTC, which matters for
+// intervals of a day, starting at local midnight, and for zones whose
+// offset is not a whole number of hours. It must be called before Start.
+func (rp *Reporter) SetLocation(loc *time.Location) {
+	rp.location = loc
+}
+
+// SetInterval changes the reporting interval, also while running.
+// Non-positive intervals are ignored.
+func (rp *Reporter) SetInterval(d time.Duration) {
+	rp.interval.set(d)
+}
+
+// Interval returns the reporting interval.
+func (rp *Reporter) Interval() time.Duration {
+	return rp.interval.get()
+}
+
+// SetDeltaMode makes the reporter pass only the timers whose snapshot
+// changed since the previous report, and skip reports in which nothing
+// changed, reducing export volume for registries of mostly idle timers.
+// The first report after turning delta mode on includes every timer.
+func (rp *Reporter) SetDeltaMode(on bool) {
+	rp.reportMutex.Lock()
+	defer rp.reportMutex.Unlock()
+	rp.delta = on
+	rp.previous = nil
+}
+
+// Flush reports a snapshot immediately.
+func (rp *Reporter) Flush() {
+	rp.reportMutex.Lock()
+	defer rp.reportMutex.Unlock()
+	snaps := rp.registry.Snapshot()
+	if !rp.delta {
+		rp.report(snaps)
+		return
+	}
+	changed := make(map[string]Snapshot)
+	for name, s := range snaps {
+		if prev, ok := rp.previous[name]; !ok || prev != s {
+			changed[name] = s
+		}
+	}
+	rp.previous = snaps
+	if len(changed) > 0 {
+		rp.report(changed)
+	}
+}
+
+// Close stops periodic reporting and reports a final snapshot, so no
+// observations made since the last tick are lost.
+func (rp *Reporter) Close() error {
+	if rp.lc.close() {
+		rp.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReporter(t *testing.T) {
+	r := NewRegistry()
+	t0 := r.GetOrCreate("db")
+
+	var mu sync.Mutex
+	var reports []map[string]Snapshot
+	rp := NewReporter(r, 10*time.Millisecond, func(snaps map[string]Snapshot) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, snaps)
+	})
+	if err := rp.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	t0.Observe(time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	n := len(reports)
+	mu.Unlock()
+	if n == 0 {
+		t.Errorf("Expected periodic reports")
+	}
+
+	// observations after the last tick are flushed by Close
+	t0.Observe(time.Millisecond)
+	if err := rp.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	mu.Lock()
+	last := reports[len(reports)-1]
+	mu.Unlock()
+	if last["db"].Count != 2 {
+		t.Errorf("Expected final report to include all observations, got %+v", last["db"])
+	}
+	checkNoLeaks(t)
+}
+
+func TestReporterStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rp := NewReporter(NewRegistry(), time.Millisecond, func(map[string]Snapshot) {})
+	if err := rp.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	cancel()
+	checkNoLeaks(t)
+	_ = rp.Close()
+}
+
+func TestReporterDefaultInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		rp := NewReporter(NewRegistry(), d, func(map[string]Snapshot) {})
+		if rp.Interval() != DefaultReportInterval {
+			t.Errorf("Interval of NewReporter(%v) = %v; want %v", d, rp.Interval(), DefaultReportInterval)
+		}
+		if err := rp.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		_ = rp.Close()
+	}
+}
+
+func TestReporterSetInterval(t *testing.T) {
+	defer checkNoLeaks(t)
+	r := NewRegistry()
+	reports := make(chan struct{}, 100)
+	rp := NewReporter(r, time.Hour, func(map[string]Snapshot) { reports <- struct{}{} })
+	if err := rp.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer rp.Close()
+
+	rp.SetInterval(5 * time.Millisecond)
+	if rp.Interval() != 5*time.Millisecond {
+		t.Errorf("Interval = %v; want 5ms", rp.Interval())
+	}
+	select {
+	case <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected report at the shortened interval")
+	}
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		rp.SetInterval(d)
+		if rp.Interval() != 5*time.Millisecond {
+			t.Errorf("Interval after SetInterval(%v) = %v; want 5ms", d, rp.Interval())
+		}
+	}
+}
+
+func TestReporterDeltaMode(t *testing.T) {
+	r := NewRegistry()
+	busy, idle := r.GetOrCreate("busy"), r.GetOrCreate("idle")
+	busy.Observe(time.Millisecond)
+	idle.Observe(time.Millisecond)
+
+	var reports []map[string]Snapshot
+	rp := NewReporter(r, time.Hour, func(snaps map[string]Snapshot) {
+		reports = append(reports, snaps)
+	})
+	rp.SetDeltaMode(true)
+
+	rp.Flush()
+	busy.Observe(2 * time.Millisecond)
+	rp.Flush()
+	rp.Flush()
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d: %v", len(reports), reports)
+	}
+	if len(reports[0]) != 2 {
+		t.Errorf("Expected the first report to include every timer, got %v", reports[0])
+	}
+	if len(reports[1]) != 1 || reports[1]["busy"].Count != 2 {
+		t.Errorf("Expected only the changed timer in the second report, got %v", reports[1])
+	}
+
+	rp.SetDeltaMode(false)
+	rp.Flush()
+	if len(reports) != 3 || len(reports[2]) != 2 {
+		t.Errorf("Expected full reports after turning delta mode off, got %v", reports)
+	}
+}
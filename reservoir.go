@@ -0,0 +1,213 @@
+package timer
+
+import (
+	"errors"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultReservoirSize is the default number of samples a Reservoir keeps.
+const DefaultReservoirSize = 1024
+
+// bootstrapResamples is the number of resamples drawn for a confidence
+// interval.
+const bootstrapResamples = 1000
+
+// Reservoir is an Aggregator keeping a uniform random sample of bounded
+// size of all observed durations, for estimates that need raw values such
+// as confidence intervals. Reset clears it.
+//
+// Reservoir is safe for concurrent use.
+type Reservoir struct {
+	mutex   sync.Mutex
+	size    int
+	count   uint64
+	samples []time.Duration
+}
+
+// ReservoirSnapshot is a copy of a Reservoir: Count observations were
+// made, of which Samples, sorted, are a uniform random sample.
+type ReservoirSnapshot struct {
+	Count   uint64          `json:"count"`
+	Samples []time.Duration `json:"samples_ns"`
+}
+
+var _ Quantiler = ReservoirSnapshot{}
+
+// NewReservoir creates a Reservoir keeping up to size samples, or
+// DefaultReservoirSize if size is not positive.
+func NewReservoir(size int) *Reservoir {
+	if size <= 0 {
+		size = DefaultReservoirSize
+	}
+	return &Reservoir{size: size}
+}
+
+// Observe records d, keeping it with probability size/count.
+func (r *Reservoir) Observe(d time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.count++
+	if len(r.samples) < r.size {
+		r.samples = append(r.samples, d)
+		return
+	}
+	if i := rand.Uint64N(r.count); i < uint64(r.size) {
+		r.samples[i] = d
+	}
+}
+
+// Snapshot returns a ReservoirSnapshot, implementing Aggregator.
+func (r *Reservoir) Snapshot() any {
+	return r.ReservoirSnapshot()
+}
+
+// ReservoirSnapshot returns a copy of the samples.
+func (r *Reservoir) ReservoirSnapshot() ReservoirSnapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	samples := slices.Clone(r.samples)
+	slices.Sort(samples)
+	return ReservoirSnapshot{Count: r.count, Samples: samples}
+}
+
+// Reset clears the samples.
+func (r *Reservoir) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.count = 0
+	r.samples = r.samples[:0]
+}
+
+// Merge adds the observations of other, a *Reservoir, drawing each kept
+// sample from either reservoir in proportion to its count.
+func (r *Reservoir) Merge(other Aggregator) error {
+	o, ok := other.(*Reservoir)
+	if !ok {
+		return errors.New("can only merge a Reservoir")
+	}
+	snap := o.ReservoirSnapshot()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.samples)+len(snap.Samples) <= r.size {
+		r.samples = append(r.samples, snap.Samples...)
+		r.count += snap.Count
+		return nil
+	}
+	// take samples in random order from either side, by count
+	mine, theirs := slices.Clone(r.samples), snap.Samples
+	rand.Shuffle(len(mine), func(i, j int) { mine[i], mine[j] = mine[j], mine[i] })
+	rand.Shuffle(len(theirs), func(i, j int) { theirs[i], theirs[j] = theirs[j], theirs[i] })
+	total := r.count + snap.Count
+	merged := r.samples[:0]
+	for len(merged) < r.size && (len(mine) > 0 || len(theirs) > 0) {
+		if len(theirs) == 0 || (len(mine) > 0 && rand.Uint64N(total) < r.count) {
+			merged, mine = append(merged, mine[0]), mine[1:]
+		} else {
+			merged, theirs = append(merged, theirs[0]), theirs[1:]
+		}
+	}
+	r.samples = merged
+	r.count = total
+	return nil
+}
+
+// Quantile is QuantileWith using QuantileLinear.
+func (s ReservoirSnapshot) Quantile(q float64) time.Duration {
+	return s.QuantileWith(q, QuantileLinear)
+}
+
+// QuantileWith estimates the q-quantile (0 <= q <= 1) as the quantile of
+// the samples, see AdaptiveQuantilesSnapshot.QuantileWith for the methods.
+// Returns 0 for an empty snapshot.
+func (s ReservoirSnapshot) QuantileWith(q float64, m QuantileMethod) time.Duration {
+	return sortedQuantile(s.Samples, q, m)
+}
+
+// MeanCI returns a bootstrap confidence interval for the mean at the
+// given confidence level, such as 0.95. Returns zeros for an empty
+// snapshot. The resampling is seeded from the samples, so a snapshot
+// always yields the same interval.
+func (s ReservoirSnapshot) MeanCI(confidence float64) (lo, hi time.Duration) {
+	return s.bootstrap(confidence, func(resample []time.Duration) time.Duration {
+		var sum float64
+		for _, d := range resample {
+			sum += float64(d)
+		}
+		return time.Duration(sum / float64(len(resample)))
+	})
+}
+
+// PercentileCI returns a bootstrap confidence interval for the
+// p-quantile (0 <= p <= 1) at the given confidence level, such as 0.95,
+// using QuantileLinear. Returns zeros for an empty snapshot.
+func (s ReservoirSnapshot) PercentileCI(p, confidence float64) (lo, hi time.Duration) {
+	return s.bootstrap(confidence, func(resample []time.Duration) time.Duration {
+		slices.Sort(resample)
+		return sortedQuantile(resample, p, QuantileLinear)
+	})
+}
+
+// bootstrap returns the percentile bootstrap interval of stat.
+func (s ReservoirSnapshot) bootstrap(confidence float64, stat func([]time.Duration) time.Duration) (lo, hi time.Duration) {
+	n := len(s.Samples)
+	if n == 0 {
+		return 0, 0
+	}
+	confidence = min(max(confidence, 0), 1)
+	var seed uint64
+	for _, d := range s.Samples {
+		seed = seed*31 + uint64(d)
+	}
+	rng := rand.New(rand.NewPCG(seed, s.Count))
+	stats := make([]time.Duration, bootstrapResamples)
+	resample := make([]time.Duration, n)
+	for i := range stats {
+		for j := range resample {
+			resample[j] = s.Samples[rng.IntN(n)]
+		}
+		stats[i] = stat(resample)
+	}
+	slices.Sort(stats)
+	alpha := (1 - confidence) / 2
+	return sortedQuantile(stats, alpha, QuantileLinear), sortedQuantile(stats, 1-alpha, QuantileLinear)
+}
+
+// MeanCI returns a bootstrap confidence interval for the mean duration
+// observed by t, see ReservoirSnapshot.MeanCI. It uses the first attached
+// Reservoir in name order and returns false if there is none.
+func (t *Timer) MeanCI(confidence float64) (lo, hi time.Duration, ok bool) {
+	s, ok := t.reservoir()
+	if !ok {
+		return 0, 0, false
+	}
+	lo, hi = s.MeanCI(confidence)
+	return lo, hi, true
+}
+
+// PercentileCI returns a bootstrap confidence interval for the
+// p-quantile of the durations observed by t, see
+// ReservoirSnapshot.PercentileCI. It uses the first attached Reservoir in
+// name order and returns false if there is none.
+func (t *Timer) PercentileCI(p, confidence float64) (lo, hi time.Duration, ok bool) {
+	s, ok := t.reservoir()
+	if !ok {
+		return 0, 0, false
+	}
+	lo, hi = s.PercentileCI(p, confidence)
+	return lo, hi, true
+}
+
+// reservoir returns the snapshot of the first attached Reservoir.
+func (t *Timer) reservoir() (ReservoirSnapshot, bool) {
+	snaps := t.AggregatorSnapshots()
+	for _, name := range slices.Sorted(maps.Keys(snaps)) {
+		if s, ok := snaps[name].(ReservoirSnapshot); ok {
+			return s, true
+		}
+	}
+	return ReservoirSnapshot{}, false
+}
@@ -0,0 +1,69 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReservoir(t *testing.T) {
+	r := NewReservoir(100)
+	for i := range 1000 {
+		r.Observe(time.Duration(i) * time.Microsecond)
+	}
+	s := r.ReservoirSnapshot()
+	if s.Count != 1000 || len(s.Samples) != 100 {
+		t.Fatalf("Expected 100 samples of 1000 observations, got %d of %d", len(s.Samples), s.Count)
+	}
+	// the sample of a uniform distribution has its median near the middle
+	if p50 := s.Quantile(0.5); p50 < 300*time.Microsecond || p50 > 700*time.Microsecond {
+		t.Errorf("Expected p50 near 500us, got %v", p50)
+	}
+
+	o := NewReservoir(100)
+	o.Observe(time.Second)
+	if err := r.Merge(o); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.ReservoirSnapshot(); s.Count != 1001 || len(s.Samples) != 100 {
+		t.Errorf("Expected 100 samples of 1001 observations after merging, got %d of %d", len(s.Samples), s.Count)
+	}
+	if err := r.Merge(NewExpHistogram(0)); err == nil {
+		t.Errorf("Expected merging another aggregator type to fail")
+	}
+	r.Reset()
+	if s := r.ReservoirSnapshot(); s.Count != 0 || len(s.Samples) != 0 {
+		t.Errorf("Expected an empty reservoir after Reset, got %+v", s)
+	}
+}
+
+func TestBootstrapCI(t *testing.T) {
+	timer := NewTimer()
+	if _, _, ok := timer.MeanCI(0.95); ok {
+		t.Errorf("Expected no interval without a reservoir")
+	}
+	timer.AddAggregator("sample", NewReservoir(0))
+	for i := range 200 {
+		timer.Observe(time.Duration(i%20+1) * time.Millisecond)
+	}
+	mean := timer.Mean()
+	lo, hi, ok := timer.MeanCI(0.95)
+	if !ok || lo > mean || hi < mean || hi-lo > 2*time.Millisecond || lo == hi {
+		t.Errorf("MeanCI(0.95) = [%v, %v]; want a narrow interval around %v", lo, hi, mean)
+	}
+	if lo2, hi2, _ := timer.MeanCI(0.95); lo2 != lo || hi2 != hi {
+		t.Errorf("Expected the same interval on every call, got [%v, %v] and [%v, %v]", lo, hi, lo2, hi2)
+	}
+	if lo99, hi99, _ := timer.MeanCI(0.99); lo99 > lo || hi99 < hi {
+		t.Errorf("Expected a 99%% interval [%v, %v] to contain the 95%% one [%v, %v]", lo99, hi99, lo, hi)
+	}
+
+	p90, _ := timer.Quantile(0.9)
+	lo, hi, ok = timer.PercentileCI(0.9, 0.95)
+	if !ok || lo > p90 || hi < p90 {
+		t.Errorf("PercentileCI(0.9, 0.95) = [%v, %v]; want an interval around %v", lo, hi, p90)
+	}
+
+	if lo, hi := (ReservoirSnapshot{}).MeanCI(0.95); lo != 0 || hi != 0 {
+		t.Errorf("Expected a zero interval for an empty snapshot, got [%v, %v]", lo, hi)
+	}
+}
@@ -0,0 +1,118 @@
+package timer
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// RetryTimer times operations wrapped in retry loops. It records every
+// attempt, first attempts alone, and the end-to-end duration of each
+// operation separately, plus how many operations needed each number of
+// attempts, so first-attempt latency can be told apart from latency
+// including retries and backoff.
+//
+//	op := rt.Start()
+//	for i := range 3 {
+//		if err = op.Attempt(call); err == nil {
+//			break
+//		}
+//		time.Sleep(100 * time.Millisecond << i)
+//	}
+//	op.Done()
+type RetryTimer struct {
+	attempts *Timer
+	first    *Timer
+	total    *Timer
+
+	mutex  sync.Mutex
+	counts map[int]uint64 // operations by number of attempts
+}
+
+// RetryOp is a single operation of a RetryTimer. It is not safe for
+// concurrent use.
+type RetryOp struct {
+	rt       *RetryTimer
+	start    time.Time
+	attempts int
+	done     bool
+}
+
+// NewRetryTimer creates a RetryTimer.
+func NewRetryTimer() *RetryTimer {
+	return &RetryTimer{
+		attempts: NewTimer(),
+		first:    NewTimer(),
+		total:    NewTimer(),
+		counts:   make(map[int]uint64),
+	}
+}
+
+// Attempts returns the timer recording every attempt.
+func (rt *RetryTimer) Attempts() *Timer {
+	return rt.attempts
+}
+
+// FirstAttempts returns the timer recording only first attempts.
+func (rt *RetryTimer) FirstAttempts() *Timer {
+	return rt.first
+}
+
+// Total returns the timer recording end-to-end operation durations,
+// including backoff between attempts.
+func (rt *RetryTimer) Total() *Timer {
+	return rt.total
+}
+
+// AttemptCounts returns how many completed operations took each number of
+// attempts.
+func (rt *RetryTimer) AttemptCounts() map[int]uint64 {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	return maps.Clone(rt.counts)
+}
+
+// Reset clears all statistics.
+func (rt *RetryTimer) Reset() {
+	rt.attempts.Reset()
+	rt.first.Reset()
+	rt.total.Reset()
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	clear(rt.counts)
+}
+
+// Start begins an operation.
+func (rt *RetryTimer) Start() *RetryOp {
+	return &RetryOp{rt: rt, start: time.Now()}
+}
+
+// Attempt runs fn as the next attempt of the operation, records its
+// duration, and returns its error.
+func (op *RetryOp) Attempt(fn func() error) error {
+	start := time.Now()
+	defer func() {
+		d := max(time.Since(start), 0)
+		op.attempts++
+		op.rt.attempts.Observe(d)
+		if op.attempts == 1 {
+			op.rt.first.Observe(d)
+		}
+	}()
+	return fn()
+}
+
+// Done ends the operation, records its total duration and number of
+// attempts, and returns them. Only the first call records.
+func (op *RetryOp) Done() (attempts int, total time.Duration) {
+	total = max(time.Since(op.start), 0)
+	if op.done {
+		return op.attempts, total
+	}
+	op.done = true
+	op.rt.total.Observe(total)
+	op.rt.mutex.Lock()
+	op.rt.counts[op.attempts]++
+	op.rt.mutex.Unlock()
+	return op.attempts, total
+}
@@ -0,0 +1,69 @@
+package timer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryTimer(t *testing.T) {
+	rt := NewRetryTimer()
+	errTransient := errors.New("transient")
+
+	// succeeds on the third attempt
+	op := rt.Start()
+	calls := 0
+	for {
+		err := op.Attempt(func() error {
+			calls++
+			time.Sleep(time.Millisecond)
+			if calls < 3 {
+				return errTransient
+			}
+			return nil
+		})
+		if err == nil {
+			break
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	attempts, total := op.Done()
+	if attempts != 3 || total < 7*time.Millisecond {
+		t.Errorf("Done = %d, %v; want 3 attempts, >= 7ms", attempts, total)
+	}
+	op.Done()
+
+	// succeeds immediately
+	op = rt.Start()
+	_ = op.Attempt(func() error { return nil })
+	op.Done()
+
+	if rt.Attempts().Count() != 4 || rt.FirstAttempts().Count() != 2 || rt.Total().Count() != 2 {
+		t.Errorf("Counts = %d attempts, %d first, %d total; want 4, 2, 2",
+			rt.Attempts().Count(), rt.FirstAttempts().Count(), rt.Total().Count())
+	}
+	if rt.Total().Max() < rt.Attempts().Max() {
+		t.Errorf("Expected total to include retries and backoff")
+	}
+	counts := rt.AttemptCounts()
+	if len(counts) != 2 || counts[1] != 1 || counts[3] != 1 {
+		t.Errorf("AttemptCounts = %v; want map[1:1 3:1]", counts)
+	}
+
+	rt.Reset()
+	if rt.Total().Count() != 0 || len(rt.AttemptCounts()) != 0 {
+		t.Errorf("Expected Reset to clear all statistics")
+	}
+}
+
+func TestRetryOpAttemptPanics(t *testing.T) {
+	rt := NewRetryTimer()
+	op := rt.Start()
+	func() {
+		defer func() { recover() }()
+		_ = op.Attempt(func() error { panic("boom") })
+	}()
+	if rt.Attempts().Count() != 1 {
+		t.Errorf("Expected panicking attempt to be recorded")
+	}
+}
@@ -0,0 +1,165 @@
+package timer
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RollupConfig configures a RollupRecorder.
+type RollupConfig struct {
+	// Period is the length of a rollup, such as a day or a week. Periods
+	// of whole days start at midnight and weeks on Mondays. Defaults to a
+	// day.
+	Period time.Duration
+	// Location is the time zone periods are aligned in. Defaults to UTC.
+	Location *time.Location
+	// Retention is the number of completed periods kept per timer.
+	// Defaults to 90.
+	Retention int
+	// Metric reduces an interval to the value whose 99th percentile a
+	// rollup reports. Defaults to Snapshot.Mean.
+	Metric func(Snapshot) time.Duration
+}
+
+// Rollup summarizes the intervals of a timer within one period.
+type Rollup struct {
+	Name       string
+	Start, End time.Time
+	Intervals  int           // intervals with observations
+	Count      uint64        // observations
+	Mean       time.Duration // mean of all observations
+	Max        time.Duration // largest interval Max
+	P99        time.Duration // 99th percentile of the interval metric
+}
+
+// RollupRecorder keeps daily or weekly summaries of interval history for
+// long-running processes that want long-term context without external
+// storage. Use it as the Exporter of a Reporter, or feed it the records
+// of an IntervalStore with Add. Snapshots are cumulative, as a Reporter
+// exports them; a timer whose count went down is taken to have been
+// reset. The Max of an interval of cumulative snapshots is the maximum
+// since the last reset, so a rollup's Max may predate its period.
+type RollupRecorder struct {
+	mutex  sync.Mutex
+	cfg    RollupConfig
+	series map[string]*rollupSeries
+	now    func() time.Time
+}
+
+// rollupSeries is the state of one timer.
+type rollupSeries struct {
+	last    Snapshot // cumulative snapshot of the previous interval
+	current *rollupPeriod
+	done    []Rollup // completed periods, oldest first
+}
+
+// rollupPeriod accumulates the intervals of the current period.
+type rollupPeriod struct {
+	start  time.Time
+	stats  Snapshot
+	values []time.Duration // interval metrics
+}
+
+// NewRollupRecorder creates a RollupRecorder.
+func NewRollupRecorder(cfg RollupConfig) *RollupRecorder {
+	if cfg.Period <= 0 {
+		cfg.Period = oneDay
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 90
+	}
+	if cfg.Metric == nil {
+		cfg.Metric = Snapshot.Mean
+	}
+	return &RollupRecorder{cfg: cfg, series: make(map[string]*rollupSeries), now: time.Now}
+}
+
+// Export adds one interval of every timer in snaps, stamped with the
+// current time, implementing Exporter.
+func (rr *RollupRecorder) Export(snaps map[string]Snapshot) error {
+	now := rr.now()
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	for name, s := range snaps {
+		rr.addNoLock(now, name, s)
+	}
+	return nil
+}
+
+// Add adds one interval of a single timer, such as a Record returned by
+// IntervalStore.Query. Records of a timer must be added in time order;
+// records older than the current period are ignored.
+func (rr *RollupRecorder) Add(rec Record) {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	rr.addNoLock(rec.Time, rec.Name, rec.Snapshot)
+}
+
+// Rollups returns the retained rollups of every timer, including the
+// partial one of the current period, sorted by name and start.
+func (rr *RollupRecorder) Rollups() []Rollup {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	var out []Rollup
+	for name, ser := range rr.series {
+		out = append(out, ser.done...)
+		if ser.current != nil {
+			out = append(out, rr.rollup(name, ser.current))
+		}
+	}
+	slices.SortFunc(out, func(a, b Rollup) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), a.Start.Compare(b.Start))
+	})
+	return out
+}
+
+// addNoLock adds the interval ending with the cumulative snapshot s.
+// Callers must hold the lock.
+func (rr *RollupRecorder) addNoLock(t time.Time, name string, s Snapshot) {
+	ser := rr.series[name]
+	if ser == nil {
+		ser = &rollupSeries{}
+		rr.series[name] = ser
+	}
+	interval := intervalSnapshot(ser.last, s)
+	ser.last = s
+	if interval.Count == 0 {
+		return
+	}
+	start := alignTime(t, rr.cfg.Period, rr.cfg.Location)
+	if cur := ser.current; cur != nil && !cur.start.Equal(start) {
+		if start.Before(cur.start) {
+			return
+		}
+		ser.done = append(ser.done, rr.rollup(name, cur))
+		if len(ser.done) > rr.cfg.Retention {
+			ser.done = slices.Delete(ser.done, 0, len(ser.done)-rr.cfg.Retention)
+		}
+		ser.current = nil
+	}
+	if ser.current == nil {
+		ser.current = &rollupPeriod{start: start}
+	}
+	ser.current.stats = ser.current.stats.Merge(interval)
+	ser.current.values = append(ser.current.values, rr.cfg.Metric(interval))
+}
+
+// rollup summarizes p.
+func (rr *RollupRecorder) rollup(name string, p *rollupPeriod) Rollup {
+	values := slices.Sorted(slices.Values(p.values))
+	return Rollup{
+		Name:      name,
+		Start:     p.start,
+		End:       nextAligned(p.start, rr.cfg.Period, rr.cfg.Location),
+		Intervals: len(p.values),
+		Count:     p.stats.Count,
+		Mean:      p.stats.Mean(),
+		Max:       p.stats.Max,
+		P99:       sortedQuantile(values, 0.99, QuantileLinear),
+	}
+}
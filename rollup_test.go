@@ -0,0 +1,49 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupRecorder(t *testing.T) {
+	rr := NewRollupRecorder(RollupConfig{Retention: 1})
+	t0 := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+	var cum Snapshot
+	// three days of hourly intervals, each of 10 observations with a mean
+	// of the hour in milliseconds
+	for h := range 72 {
+		d := time.Duration(h%24+1) * time.Millisecond
+		cum = cum.Merge(Snapshot{Count: 10, Min: d, Max: d, Sum: 10 * d})
+		rr.Add(Record{Time: t0.Add(time.Duration(h) * time.Hour), Name: "db", Snapshot: cum})
+	}
+
+	got := rr.Rollups()
+	// the first day was dropped by the retention
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 rollups, got %+v", got)
+	}
+	day2 := got[0]
+	if !day2.Start.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) || day2.End.Sub(day2.Start) != 24*time.Hour {
+		t.Errorf("Expected the second day, got %v..%v", day2.Start, day2.End)
+	}
+	if day2.Intervals != 24 || day2.Count != 240 || day2.Mean != 12500*time.Microsecond || day2.Max != 24*time.Millisecond {
+		t.Errorf("Unexpected rollup %+v", day2)
+	}
+	if day2.P99 != 23770*time.Microsecond {
+		t.Errorf("P99 = %v; want 23.77ms", day2.P99)
+	}
+	if got[1].Count != 240 {
+		t.Errorf("Expected the current day to be partial, got %+v", got[1])
+	}
+}
+
+func TestRollupRecorderWeekly(t *testing.T) {
+	rr := NewRollupRecorder(RollupConfig{Period: 7 * 24 * time.Hour})
+	now := time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC) // a Wednesday
+	rr.now = func() time.Time { return now }
+	_ = rr.Export(map[string]Snapshot{"a": {Count: 1, Min: 1, Max: 1, Sum: 1}})
+	got := rr.Rollups()
+	if len(got) != 1 || !got[0].Start.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a week starting on Monday, got %+v", got)
+	}
+}
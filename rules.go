@@ -0,0 +1,196 @@
+package timer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ExportRules filter, relabel and rename timers before their snapshots
+// leave the process. Patterns use path.Match syntax, except that "*" and
+// "?" also match slashes, and "*" matches across dots ("db.*" matches
+// "db.pg.query"). A name pattern matches a TimerVec child if it matches
+// its base name, the part before the labels, or its full name: "http*"
+// matches `http{route="/users/42"}`.
+type ExportRules struct {
+	// Allow, if non-empty, exports only names matching at least one pattern.
+	Allow []string
+	// Deny drops names matching any pattern. Deny takes precedence over Allow.
+	Deny []string
+	// AllowLabels, if non-empty, exports a timer carrying a label named
+	// by a matcher only if one of the matchers for that label matches its
+	// value. Timers without such labels are unaffected.
+	AllowLabels []LabelMatcher
+	// DenyLabels drops timers with a label matching any matcher. It takes
+	// precedence over AllowLabels.
+	DenyLabels []LabelMatcher
+	// DropLabels removes the named labels from the names of timers that
+	// pass the filters, such as a high-cardinality user ID. Timers left
+	// with the same name are merged.
+	DropLabels []string
+	// Rename rules are applied in order to names that pass the filters.
+	// Timers renamed to the same name are merged.
+	Rename []RenameRule
+}
+
+// LabelMatcher matches timers with the label Name whose value matches the
+// pattern Value, in the syntax of the ExportRules name patterns.
+type LabelMatcher struct {
+	Name  string
+	Value string
+}
+
+// matches reports whether l is the label of m with a matching value.
+func (m LabelMatcher) matches(l Label) bool {
+	return l.Name == m.Name && globMatch(m.Value, l.Value)
+}
+
+// RenameRule replaces matches of Pattern in a timer name with Replacement,
+// which may refer to submatches as in regexp.Regexp.ReplaceAllString.
+type RenameRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// validate reports the first malformed glob pattern in the rules.
+func (e ExportRules) validate() error {
+	for _, patterns := range [][]string{e.Allow, e.Deny} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid export pattern %q: %w", p, err)
+			}
+		}
+	}
+	for _, matchers := range [][]LabelMatcher{e.AllowLabels, e.DenyLabels} {
+		for _, m := range matchers {
+			if m.Name == "" {
+				return fmt.Errorf("label matcher without label name")
+			}
+			if _, err := path.Match(m.Value, ""); err != nil {
+				return fmt.Errorf("invalid label pattern %q: %w", m.Value, err)
+			}
+		}
+	}
+	for _, rr := range e.Rename {
+		if rr.Pattern == nil {
+			return fmt.Errorf("rename rule with nil pattern")
+		}
+	}
+	return nil
+}
+
+// allowed reports whether name passes the name and label filters.
+func (e *ExportRules) allowed(name string) bool {
+	base, labels, _ := SplitName(name)
+	match := func(p string) bool {
+		return globMatch(p, base) || globMatch(p, name)
+	}
+	for _, p := range e.Deny {
+		if match(p) {
+			return false
+		}
+	}
+	for _, l := range labels {
+		for _, m := range e.DenyLabels {
+			if m.matches(l) {
+				return false
+			}
+		}
+		if !e.labelAllowed(l) {
+			return false
+		}
+	}
+	if len(e.Allow) == 0 {
+		return true
+	}
+	for _, p := range e.Allow {
+		if match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelAllowed reports whether l passes the AllowLabels matchers for its
+// name, if there are any.
+func (e *ExportRules) labelAllowed(l Label) bool {
+	constrained := false
+	for _, m := range e.AllowLabels {
+		if m.Name != l.Name {
+			continue
+		}
+		if globMatch(m.Value, l.Value) {
+			return true
+		}
+		constrained = true
+	}
+	return !constrained
+}
+
+// globMatch reports whether name matches the path.Match pattern, with
+// slashes treated like any other character.
+func globMatch(pattern, name string) bool {
+	ok, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(name, "/", "\x00"))
+	return ok
+}
+
+// rename drops the DropLabels from name and applies the Rename rules.
+func (e *ExportRules) rename(name string) string {
+	if len(e.DropLabels) > 0 {
+		if base, labels, ok := SplitName(name); ok && len(labels) > 0 {
+			var names, values []string
+			for _, l := range labels {
+				if !slices.Contains(e.DropLabels, l.Name) {
+					names, values = append(names, l.Name), append(values, l.Value)
+				}
+			}
+			name = base
+			if len(names) > 0 {
+				name += "{" + formatLabels(names, values) + "}"
+			}
+		}
+	}
+	for _, rr := range e.Rename {
+		name = rr.Pattern.ReplaceAllString(name, rr.Replacement)
+	}
+	return name
+}
+
+// Apply returns the snapshots in snaps that pass the filters, keyed by
+// their renamed names.
+func (e *ExportRules) Apply(snaps map[string]Snapshot) map[string]Snapshot {
+	return applyRules(e, snaps, Snapshot.Merge)
+}
+
+// applyRules returns the values in m that pass the filters of e, keyed by
+// their renamed names and combined with merge where names collide.
+func applyRules[V any](e *ExportRules, m map[string]V, merge func(V, V) V) map[string]V {
+	out := make(map[string]V, len(m))
+	for name, v := range m {
+		if !e.allowed(name) {
+			continue
+		}
+		name = e.rename(name)
+		if prev, ok := out[name]; ok {
+			v = merge(prev, v)
+		}
+		out[name] = v
+	}
+	return out
+}
+
+// WithExportRules returns a view of r whose Snapshot, and therefore every
+// exporter given the view, applies rules. Registration and lookups through
+// the view are unaffected. Returns an error if a pattern is malformed.
+func (r *Registry) WithExportRules(rules ExportRules) (*Registry, error) {
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	return &Registry{
+		prefix: r.prefix,
+		store:  r.store,
+		rules:  &rules,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package timer
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExportRulesApply(t *testing.T) {
+	snaps := map[string]Snapshot{
+		"db.query":          {Count: 1, Min: 1, Max: 1, Sum: 1},
+		"db.internal.lock":  {Count: 1, Min: 2, Max: 2, Sum: 2},
+		"http.users.123":    {Count: 1, Min: 3, Max: 3, Sum: 3},
+		"http.users.456":    {Count: 2, Min: 4, Max: 5, Sum: 9},
+		"cache.get":         {Count: 1, Min: 6, Max: 6, Sum: 6},
+		"debug.gc.internal": {Count: 1, Min: 7, Max: 7, Sum: 7},
+	}
+	rules := ExportRules{
+		Allow: []string{"db.*", "http.*"},
+		Deny:  []string{"*.internal.*"},
+		Rename: []RenameRule{
+			{Pattern: regexp.MustCompile(`\.[0-9]+$`), Replacement: ".id"},
+		},
+	}
+
+	out := rules.Apply(snaps)
+	if len(out) != 2 {
+		t.Fatalf("Expected 2 exported timers, got %v", out)
+	}
+	if _, ok := out["db.query"]; !ok {
+		t.Errorf("Expected db.query to be exported")
+	}
+	want := Snapshot{Count: 3, Min: 3, Max: 5, Sum: 12}
+	if got := out["http.users.id"]; got != want {
+		t.Errorf("Expected renamed timers to merge into %+v, got %+v", want, got)
+	}
+}
+
+func TestWithExportRules(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("db.query").Observe(time.Millisecond)
+	r.GetOrCreate("db.internal.lock").Observe(time.Millisecond)
+
+	view, err := r.WithExportRules(ExportRules{Deny: []string{"*.internal.*"}})
+	if err != nil {
+		t.Fatalf("WithExportRules failed: %v", err)
+	}
+	snaps := view.Snapshot()
+	if len(snaps) != 1 {
+		t.Errorf("Expected 1 exported timer, got %v", snaps)
+	}
+	if view.Get("db.internal.lock") == nil {
+		t.Errorf("Expected lookups through the view to be unfiltered")
+	}
+	if len(r.Snapshot()) != 2 {
+		t.Errorf("Expected original registry to be unfiltered")
+	}
+
+	if _, err := r.WithExportRules(ExportRules{Allow: []string{"["}}); err == nil {
+		t.Errorf("Expected error for malformed pattern")
+	}
+	if _, err := r.WithExportRules(ExportRules{Rename: []RenameRule{{}}}); err == nil {
+		t.Errorf("Expected error for rename rule without pattern")
+	}
+}
+
+func TestExportRulesVecChildren(t *testing.T) {
+	r := NewRegistry()
+	routes := NewTimerVec("route", "user")
+	_ = r.RegisterVec("http", routes)
+	routes.WithLabelValues("/users/42", "alice").Observe(time.Millisecond)
+	routes.WithLabelValues("/users/42", "bob").Observe(2 * time.Millisecond)
+	routes.WithLabelValues("/internal/health", "probe").Observe(time.Millisecond)
+	r.GetOrCreate("db.query").Observe(time.Millisecond)
+
+	for _, tc := range []struct {
+		rules ExportRules
+		want  []string
+	}{
+		{ExportRules{Deny: []string{"http*"}}, []string{"db.query"}},
+		{ExportRules{Allow: []string{"http"}, DenyLabels: []LabelMatcher{{Name: "route", Value: "/internal/*"}}},
+			[]string{`http{route="/users/42",user="alice"}`, `http{route="/users/42",user="bob"}`}},
+		{ExportRules{AllowLabels: []LabelMatcher{{Name: "user", Value: "a*"}, {Name: "user", Value: "probe"}}},
+			[]string{"db.query", `http{route="/internal/health",user="probe"}`, `http{route="/users/42",user="alice"}`}},
+		{ExportRules{Allow: []string{"http"}, DropLabels: []string{"user"}},
+			[]string{`http{route="/internal/health"}`, `http{route="/users/42"}`}},
+	} {
+		view, err := r.WithExportRules(tc.rules)
+		if err != nil {
+			t.Fatalf("WithExportRules(%+v) failed: %v", tc.rules, err)
+		}
+		if got := view.Names(); !slices.Equal(got, tc.want) {
+			t.Errorf("Names with %+v = %q; want %q", tc.rules, got, tc.want)
+		}
+	}
+
+	view, _ := r.WithExportRules(ExportRules{DropLabels: []string{"user"}})
+	if s := view.Snapshot()[`http{route="/users/42"}`]; s.Count != 2 || s.Sum != 3*time.Millisecond {
+		t.Errorf("Expected children without the user label merged, got %+v", s)
+	}
+	if _, err := r.WithExportRules(ExportRules{DenyLabels: []LabelMatcher{{Value: "x"}}}); err == nil {
+		t.Errorf("Expected error for label matcher without name")
+	}
+}
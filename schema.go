@@ -0,0 +1,106 @@
+package timer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the $id of the schema returned by SnapshotSchema.
+const SchemaID = "https://github.com/jnpr-pranav/go-timer/schema/snapshot.schema.json"
+
+// snapshotFieldDocs describes the JSON fields of Snapshot in the schema.
+var snapshotFieldDocs = map[string]string{
+	"count":            "Number of durations observed.",
+	"min_ns":           "Minimum observed duration in nanoseconds, 0 if count is 0.",
+	"max_ns":           "Maximum observed duration in nanoseconds, 0 if count is 0.",
+	"sum_ns":           "Sum of all observed durations in nanoseconds, capped at 9223372036854775807 if sum_overflowed is true.",
+	"sum_overflowed":   "Whether sum_ns overflowed, in which case the mean computed from it is an underestimate. Omitted if false.",
+	"sum_epoch":        "If sum_ns overflowed and the sum was kept exact, the number of times 2^63 fits into the exact sum. Omitted if 0.",
+	"sum_remainder_ns": "If sum_epoch is set, the exact sum modulo 2^63 in nanoseconds, so the exact sum is sum_epoch * 2^63 + sum_remainder_ns. Omitted if 0.",
+	"panicked":         "Number of observations whose operation panicked, included in count. Omitted if 0.",
+}
+
+// SnapshotSchema returns a JSON Schema (draft 2020-12) of the JSON
+// encoding of a Snapshot, generated from its struct fields, so tooling in
+// other languages can validate and decode exported data. The schema
+// describes a Snapshot; its $defs describe a NamedSnapshot as
+// "named_snapshot" and a line of JSONExporter or IntervalStore as
+// "record". Integers are 64-bit and may exceed the precision of
+// JavaScript numbers.
+func SnapshotSchema() []byte {
+	props, required := snapshotSchemaProperties()
+	object := func(description string, extra map[string]any, extraRequired ...string) map[string]any {
+		p := make(map[string]any, len(props)+len(extra))
+		for k, v := range props {
+			p[k] = v
+		}
+		for k, v := range extra {
+			p[k] = v
+		}
+		return map[string]any{
+			"description":          description,
+			"type":                 "object",
+			"properties":           p,
+			"required":             append(extraRequired, required...),
+			"additionalProperties": false,
+		}
+	}
+	name := map[string]any{
+		"description": "Name of the timer. Children of a TimerVec are named name{label=\"value\",...}.",
+		"type":        "string",
+	}
+	schema := object("Statistics of the durations observed by a timer.", nil)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = "go-timer snapshot"
+	schema["$defs"] = map[string]any{
+		"named_snapshot": object("A snapshot with the name of its timer.",
+			map[string]any{"name": name}, "name"),
+		"record": object("A snapshot exported at a point in time, one per line of JSONExporter output.",
+			map[string]any{
+				"time": map[string]any{
+					"description": "Export time in RFC 3339 format with nanoseconds, in UTC.",
+					"type":        "string",
+					"format":      "date-time",
+				},
+				"name": name,
+			}, "time", "name"),
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		panic(err) // plain values always encode
+	}
+	return append(b, '\n')
+}
+
+// snapshotSchemaProperties returns the schema of every JSON field of
+// Snapshot and the names of those always present.
+func snapshotSchemaProperties() (map[string]any, []string) {
+	props := make(map[string]any)
+	var required []string
+	st := reflect.TypeFor[Snapshot]()
+	for i := range st.NumField() {
+		f := st.Field(i)
+		tag, ok := f.Tag.Lookup("json")
+		if !ok || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		p := map[string]any{"description": snapshotFieldDocs[name]}
+		switch f.Type.Kind() {
+		case reflect.Bool:
+			p["type"] = "boolean"
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			p["type"] = "integer"
+			p["minimum"] = 0
+		default:
+			p["type"] = "integer"
+		}
+		props[name] = p
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return props, required
+}
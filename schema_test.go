@@ -0,0 +1,191 @@
+package timer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the files in schema/")
+
+// conformanceSnapshots are encoded into schema/conformance.jsonl.
+var conformanceSnapshots = map[string]Snapshot{
+	"empty":              {},
+	"db.query":           {Count: 3, Min: time.Millisecond, Max: 5 * time.Millisecond, Sum: 9 * time.Millisecond},
+	"overflowed":         {Count: 2, Min: 1, Max: math.MaxInt64, Sum: math.MaxInt64, SumOverflowed: true},
+	"handler":            {Count: 4, Min: 10, Max: 40, Sum: 100, Panicked: 1},
+	"wrapped":            {Count: 2, Min: math.MaxInt64, Max: math.MaxInt64, Sum: math.MaxInt64, SumOverflowed: true, SumEpoch: 1, SumRemainder: math.MaxInt64 - 1},
+	`rpc{op="get"}`:      {Count: math.MaxUint64, Min: 1, Max: 1, Sum: math.MaxInt64, SumOverflowed: true},
+	"quote\"and<html>& ": {Count: 1, Min: 7, Max: 7, Sum: 7},
+}
+
+// checkGolden compares got with the file at path, or writes it with -update.
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date, run go test -run %s -update", path, t.Name())
+	}
+}
+
+func TestSnapshotSchemaFile(t *testing.T) {
+	checkGolden(t, "schema/snapshot.schema.json", SnapshotSchema())
+}
+
+func TestSnapshotSchemaDocumented(t *testing.T) {
+	props, _ := snapshotSchemaProperties()
+	for name, p := range props {
+		if p.(map[string]any)["description"] == "" {
+			t.Errorf("Expected a description of field %s in snapshotFieldDocs", name)
+		}
+	}
+	if len(props) != len(snapshotFieldDocs) {
+		t.Errorf("Expected %d documented fields, got %d", len(props), len(snapshotFieldDocs))
+	}
+}
+
+func TestSnapshotSchemaConformance(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(SnapshotSchema(), &schema); err != nil {
+		t.Fatal(err)
+	}
+	defs := schema["$defs"].(map[string]any)
+
+	var buf bytes.Buffer
+	e := NewJSONExporter(&buf)
+	e.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC) }
+	if err := e.Export(conformanceSnapshots); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "schema/conformance.jsonl", buf.Bytes())
+
+	// every line of the corpus is a valid record and decodes to its snapshot
+	f, err := os.Open("schema/conformance.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	lines := 0
+	for sc.Scan() {
+		lines++
+		if err := validateSchema(defs["record"], decodeJSON(t, sc.Bytes()), "record"); err != nil {
+			t.Errorf("Line %d: %v", lines, err)
+		}
+		var rec Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("Line %d: %v", lines, err)
+		}
+		if want, ok := conformanceSnapshots[rec.Name]; !ok || rec.Snapshot != want {
+			t.Errorf("Line %d decoded to %q %+v; want %+v", lines, rec.Name, rec.Snapshot, want)
+		}
+	}
+	if lines != len(conformanceSnapshots) {
+		t.Errorf("Expected %d lines, got %d", len(conformanceSnapshots), lines)
+	}
+
+	for name, s := range conformanceSnapshots {
+		if err := validateSchema(schema, decodeJSON(t, s.AppendJSON(nil)), "snapshot"); err != nil {
+			t.Errorf("Snapshot %s: %v", name, err)
+		}
+		ns := NamedSnapshot{Name: name, Snapshot: s}
+		if err := validateSchema(defs["named_snapshot"], decodeJSON(t, ns.AppendJSON(nil)), "named snapshot"); err != nil {
+			t.Errorf("Named snapshot %s: %v", name, err)
+		}
+	}
+
+	// the validator rejects what the schema excludes
+	bad := []string{
+		`{"count":1,"min_ns":1,"max_ns":1}`,
+		`{"count":-1,"min_ns":1,"max_ns":1,"sum_ns":1}`,
+		`{"count":1,"min_ns":1,"max_ns":1,"sum_ns":1,"mean_ns":1}`,
+		`{"count":1.5,"min_ns":1,"max_ns":1,"sum_ns":1}`,
+	}
+	for _, b := range bad {
+		if err := validateSchema(schema, decodeJSON(t, []byte(b)), "snapshot"); err == nil {
+			t.Errorf("Expected %s to be invalid", b)
+		}
+	}
+}
+
+func decodeJSON(t *testing.T, b []byte) any {
+	t.Helper()
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		t.Fatalf("Decoding %s: %v", b, err)
+	}
+	return v
+}
+
+// validateSchema checks v against the subset of JSON Schema that
+// SnapshotSchema uses.
+func validateSchema(schema, v any, path string) error {
+	s := schema.(map[string]any)
+	switch s["type"] {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: not an object", path)
+		}
+		props := s["properties"].(map[string]any)
+		for _, name := range s["required"].([]any) {
+			if _, ok := obj[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, name)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(obj)) {
+			p, ok := props[name]
+			if !ok {
+				return fmt.Errorf("%s: unexpected %s", path, name)
+			}
+			if err := validateSchema(p, obj[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok || strings.ContainsAny(n.String(), ".eE") {
+			return fmt.Errorf("%s: not an integer", path)
+		}
+		if _, ok := s["minimum"]; ok && strings.HasPrefix(n.String(), "-") {
+			return fmt.Errorf("%s: negative", path)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: not a boolean", path)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: not a string", path)
+		}
+		if s["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %v", path, s["type"])
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package timer
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// seqStats mirrors count, sum, min, and max for readers that skip the
+// mutex, see SetSeqlock. Writers still serialize on the timer mutex and
+// make seq odd while they publish, so readers retry until they see the same
+// even seq before and after loading the fields. The fields are atomics so
+// the reader path stays free of data races.
+type seqStats struct {
+	enabled atomic.Bool
+	seq     atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Int64
+	min     atomic.Int64
+	max     atomic.Int64
+}
+
+// seqValues is a consistent copy of the fields mirrored by seqStats.
+type seqValues struct {
+	count    uint64
+	sum      int64
+	min, max time.Duration
+}
+
+// SetSeqlock switches Count, Min, Max, and Mean to a seqlock read path if
+// on is true, and back to the read lock if it is false. Readers then never
+// block writers or each other, which pays off under heavy read load, such
+// as more than 100k reads per second, where even RLock contention shows.
+// In exchange every recording publishes a copy of the statistics.
+func (t *Timer) SetSeqlock(on bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.seq.enabled.Store(on)
+	t.publishNoLock()
+}
+
+// Seqlock reports whether reads use the seqlock path, see SetSeqlock.
+func (t *Timer) Seqlock() bool {
+	return t.seq.enabled.Load()
+}
+
+// publishNoLock copies the statistics to the seqlock mirror if enabled.
+// Callers must hold the write lock.
+func (t *Timer) publishNoLock() {
+	if !t.seq.enabled.Load() {
+		return
+	}
+	t.seq.seq.Add(1)
+	t.seq.count.Store(t.count)
+	t.seq.sum.Store(t.totalSum)
+	t.seq.min.Store(int64(t.min))
+	t.seq.max.Store(int64(t.max))
+	t.seq.seq.Add(1)
+}
+
+// load returns a consistent copy of the mirrored statistics, retrying
+// while a writer is publishing.
+func (s *seqStats) load() seqValues {
+	for {
+		seq := s.seq.Load()
+		if seq%2 == 0 {
+			v := seqValues{
+				count: s.count.Load(),
+				sum:   s.sum.Load(),
+				min:   time.Duration(s.min.Load()),
+				max:   time.Duration(s.max.Load()),
+			}
+			if s.seq.Load() == seq {
+				return v
+			}
+		}
+		// the writer may have been preempted mid-publish
+		runtime.Gosched()
+	}
+}
+
+// mean mirrors meanNoLock.
+func (v seqValues) mean() time.Duration {
+	if v.count == 0 {
+		return 0
+	}
+	return time.Duration((v.sum + int64(v.count)/2) / int64(v.count))
+}
@@ -0,0 +1,111 @@
+package timer
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSeqlock(t *testing.T) {
+	timer := NewTimer()
+	timer.Observe(10 * time.Millisecond)
+	timer.SetSeqlock(true)
+	if !timer.Seqlock() {
+		t.Fatalf("Expected Seqlock to report true after SetSeqlock(true)")
+	}
+	// the mirror is seeded with what was recorded before enabling
+	if timer.Count() != 1 || timer.Mean() != 10*time.Millisecond {
+		t.Errorf("Expected 1 observation of 10ms, got %d of %v", timer.Count(), timer.Mean())
+	}
+
+	timer.Observe(20 * time.Millisecond)
+	timer.Observe(30 * time.Millisecond)
+	if timer.Count() != 3 || timer.Min() != 10*time.Millisecond ||
+		timer.Max() != 30*time.Millisecond || timer.Mean() != 20*time.Millisecond {
+		t.Errorf("Expected count 3, min 10ms, max 30ms, mean 20ms, got %d, %v, %v, %v",
+			timer.Count(), timer.Min(), timer.Max(), timer.Mean())
+	}
+
+	timer.ImportSnapshot(Snapshot{Count: 1, Sum: 40 * time.Millisecond, Min: 40 * time.Millisecond, Max: 40 * time.Millisecond})
+	if timer.Count() != 4 || timer.Max() != 40*time.Millisecond {
+		t.Errorf("Expected imported snapshot to be visible, got count %d, max %v", timer.Count(), timer.Max())
+	}
+
+	timer.Reset()
+	if timer.Count() != 0 || timer.Min() != time.Duration(math.MaxInt64) || timer.Max() != 0 || timer.Mean() != 0 {
+		t.Errorf("Expected reset stats, got %d, %v, %v, %v", timer.Count(), timer.Min(), timer.Max(), timer.Mean())
+	}
+
+	// switching back reads under the lock again
+	timer.SetSeqlock(false)
+	timer.Observe(time.Second)
+	if timer.Count() != 1 || timer.Max() != time.Second {
+		t.Errorf("Expected 1 observation of 1s, got %d of %v", timer.Count(), timer.Max())
+	}
+}
+
+// TestSeqlockLitmus checks readers never observe a torn copy while writers
+// record and reset concurrently. Every observation is d, so a consistent
+// copy is either empty or has sum = count*d and min = max = d.
+func TestSeqlockLitmus(t *testing.T) {
+	const d = 7 * time.Microsecond
+	timer := NewTimer()
+	timer.SetSeqlock(true)
+
+	var stop atomic.Bool
+	var writers, readers sync.WaitGroup
+	for range 4 {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for i := range 20000 {
+				if i%1000 == 999 {
+					timer.Reset()
+				}
+				timer.Observe(d)
+			}
+		}()
+	}
+	var torn atomic.Int64
+	for range 4 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for !stop.Load() {
+				v := timer.seq.load()
+				empty := v.count == 0 && v.sum == 0 && v.max == 0 && v.min == time.Duration(math.MaxInt64)
+				full := v.count > 0 && v.sum == int64(v.count)*int64(d) && v.min == d && v.max == d
+				if !empty && !full {
+					torn.Add(1)
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	stop.Store(true)
+	readers.Wait()
+	if n := torn.Load(); n != 0 {
+		t.Errorf("Expected no torn reads, got %d", n)
+	}
+}
+
+func BenchmarkTimerConcurrentRead(b *testing.B) {
+	for _, seqlock := range []bool{false, true} {
+		name := "RWMutex"
+		if seqlock {
+			name = "Seqlock"
+		}
+		b.Run(name, func(b *testing.B) {
+			timer := NewTimer()
+			timer.SetSeqlock(seqlock)
+			timer.Observe(time.Millisecond)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = timer.Mean()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package timer
+
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// cacheLineSize is what shards are padded to. It is 128 rather than 64 to
+// cover arm64 and the adjacent-line prefetcher of x86.
+const cacheLineSize = 128
+
+// shard is one independently locked part of a ShardedTimer. The padding
+// keeps the hot fields of neighbouring shards on different cache lines
+// however the slice happens to be aligned.
+type shard struct {
+	mutex sync.Mutex
+	stats Snapshot
+	_     [cacheLineSize - (unsafe.Sizeof(sync.Mutex{})+unsafe.Sizeof(Snapshot{}))%cacheLineSize]byte
+}
+
+// observe records d in the shard.
+func (s *shard) observe(d time.Duration, panicked bool) {
+	o := Snapshot{Count: 1, Min: d, Max: d, Sum: d}
+	if panicked {
+		o.Panicked = 1
+	}
+	s.mutex.Lock()
+	s.stats = s.stats.Merge(o)
+	s.mutex.Unlock()
+}
+
+// ShardedTimer is a Timer for write-heavy workloads on many cores. Each
+// observation goes to one of several padded shards picked at random, so
+// concurrent writers rarely share a lock or a cache line. Reads merge all
+// shards and are correspondingly slower; a read racing with writers may
+// see some shards before and others after a concurrent observation.
+// All methods are safe for concurrent use.
+type ShardedTimer struct {
+	shards []shard
+}
+
+var _ Interface = (*ShardedTimer)(nil)
+
+// DefaultShards returns the shard count NewShardedTimer uses by default,
+// which is GOMAXPROCS.
+func DefaultShards() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// NewShardedTimer creates a ShardedTimer with n shards, or DefaultShards
+// if n is not positive.
+func NewShardedTimer(n int) *ShardedTimer {
+	if n <= 0 {
+		n = DefaultShards()
+	}
+	return &ShardedTimer{shards: make([]shard, n)}
+}
+
+// Shards returns the number of shards.
+func (t *ShardedTimer) Shards() int {
+	return len(t.shards)
+}
+
+// shard picks the shard for the next observation.
+func (t *ShardedTimer) shard() *shard {
+	return &t.shards[rand.IntN(len(t.shards))]
+}
+
+// Observe records a duration.
+func (t *ShardedTimer) Observe(d time.Duration) {
+	t.shard().observe(d, false)
+}
+
+// Update records the duration since start, clamped to non-negative values.
+// Returns ErrZeroTime if start is a zero time value.
+func (t *ShardedTimer) Update(start time.Time) error {
+	return t.UpdateAt(start, time.Now())
+}
+
+// UpdateAt records the duration between start and now, clamped to
+// non-negative values.
+// Returns ErrZeroTime if start or now is a zero time value.
+func (t *ShardedTimer) UpdateAt(start, now time.Time) error {
+	if start.IsZero() || now.IsZero() {
+		return ErrZeroTime
+	}
+	t.Observe(max(now.Sub(start), 0))
+	return nil
+}
+
+// Time calls fn and records how long it took. Like Timer.Time, a panic
+// is counted and propagated.
+func (t *ShardedTimer) Time(fn func()) {
+	start := time.Now()
+	panicked := true
+	defer func() {
+		t.shard().observe(max(time.Since(start), 0), panicked)
+	}()
+	fn()
+	panicked = false
+}
+
+// Snapshot returns the statistics of all shards merged.
+func (t *ShardedTimer) Snapshot() Snapshot {
+	var s Snapshot
+	for i := range t.shards {
+		sh := &t.shards[i]
+		sh.mutex.Lock()
+		s = s.Merge(sh.stats)
+		sh.mutex.Unlock()
+	}
+	return s
+}
+
+// Count returns the number of observations recorded.
+func (t *ShardedTimer) Count() uint64 {
+	return t.Snapshot().Count
+}
+
+// Max returns the maximum duration observed.
+// Returns 0 if no observations have been made.
+func (t *ShardedTimer) Max() time.Duration {
+	return t.Snapshot().Max
+}
+
+// Min returns the minimum duration observed.
+// Returns a very large value if no observations have been made, like
+// Timer.Min.
+func (t *ShardedTimer) Min() time.Duration {
+	s := t.Snapshot()
+	if s.Count == 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return s.Min
+}
+
+// Mean returns the average of all observed durations.
+// Returns 0 if no observations have been made.
+func (t *ShardedTimer) Mean() time.Duration {
+	return t.Snapshot().Mean()
+}
+
+// Reset clears the statistics of every shard.
+func (t *ShardedTimer) Reset() {
+	for i := range t.shards {
+		sh := &t.shards[i]
+		sh.mutex.Lock()
+		sh.stats = Snapshot{}
+		sh.mutex.Unlock()
+	}
+}
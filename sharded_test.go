@@ -0,0 +1,97 @@
+package timer
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestShardedTimer(t *testing.T) {
+	timer := NewShardedTimer(4)
+	if timer.Shards() != 4 {
+		t.Errorf("Shards() = %d; want 4", timer.Shards())
+	}
+	if timer.Min() != time.Duration(math.MaxInt64) || timer.Count() != 0 {
+		t.Errorf("Expected empty timer, got count %d, min %v", timer.Count(), timer.Min())
+	}
+	for _, d := range []time.Duration{10, 20, 30, 40} {
+		timer.Observe(d * time.Millisecond)
+	}
+	if err := timer.Update(time.Time{}); err != ErrZeroTime {
+		t.Errorf("Update(zero) = %v; want ErrZeroTime", err)
+	}
+	want := Snapshot{Count: 4, Min: 10 * time.Millisecond, Max: 40 * time.Millisecond, Sum: 100 * time.Millisecond}
+	if got := timer.Snapshot(); got != want {
+		t.Errorf("Snapshot() = %+v; want %+v", got, want)
+	}
+	if timer.Mean() != 25*time.Millisecond {
+		t.Errorf("Mean() = %v; want 25ms", timer.Mean())
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		timer.Time(func() { panic("boom") })
+	}()
+	if s := timer.Snapshot(); s.Count != 5 || s.Panicked != 1 {
+		t.Errorf("Expected 5 observations with 1 panicked, got %d and %d", s.Count, s.Panicked)
+	}
+
+	timer.Reset()
+	if got := timer.Snapshot(); got != (Snapshot{}) {
+		t.Errorf("Expected empty snapshot after Reset, got %+v", got)
+	}
+}
+
+func TestShardedTimerDefaultShards(t *testing.T) {
+	if got := NewShardedTimer(0).Shards(); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("Shards() = %d; want GOMAXPROCS %d", got, runtime.GOMAXPROCS(0))
+	}
+	if got := DefaultShards(); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("DefaultShards() = %d; want %d", got, runtime.GOMAXPROCS(0))
+	}
+	if size := unsafe.Sizeof(shard{}); size%cacheLineSize != 0 {
+		t.Errorf("Expected shard size to be a multiple of %d, got %d", cacheLineSize, size)
+	}
+}
+
+func TestShardedTimerConcurrent(t *testing.T) {
+	timer := NewShardedTimer(0)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				timer.Observe(time.Microsecond)
+			}
+		}()
+	}
+	wg.Wait()
+	if s := timer.Snapshot(); s.Count != 8000 || s.Sum != 8*time.Millisecond {
+		t.Errorf("Expected 8000 observations summing to 8ms, got %d and %v", s.Count, s.Sum)
+	}
+}
+
+// BenchmarkShardedObserve compares parallel writers on a Timer and a
+// ShardedTimer. Run with e.g. -cpu 1,8,64 to see how each scales.
+func BenchmarkShardedObserve(b *testing.B) {
+	b.Run("Timer", func(b *testing.B) {
+		timer := NewTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				timer.Observe(time.Microsecond)
+			}
+		})
+	})
+	b.Run("Sharded", func(b *testing.B) {
+		timer := NewShardedTimer(0)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				timer.Observe(time.Microsecond)
+			}
+		})
+	})
+}
@@ -0,0 +1,275 @@
+package timer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ErrSharedFull is returned when a shared registry has no free slot for a
+// new timer name.
+var ErrSharedFull = errors.New("shared registry is full")
+
+// ErrSharedName is returned when a timer name is too long for a shared
+// registry slot.
+var ErrSharedName = errors.New("shared timer name longer than 64 bytes")
+
+// ErrSharedSlots is returned when creating a shared registry with no
+// slots.
+var ErrSharedSlots = errors.New("shared registry needs a positive slot count")
+
+// ErrSharedFormat is returned when opening a file that is not a shared
+// registry or was created with an incompatible layout.
+var ErrSharedFormat = errors.New("invalid shared registry file")
+
+// Shared registry file layout. All fields are native-endian and every
+// 64-bit field is 8-byte aligned so it can be updated atomically.
+const (
+	shmMagic      = "GOTIMSHM"
+	shmVersion    = 1
+	shmHeaderSize = 64
+	shmSlotSize   = 128
+	shmNameLen    = 64
+
+	// header offsets
+	shmOffVersion = 8
+	shmOffSlots   = 12
+
+	// slot offsets
+	shmOffState    = 0
+	shmOffNameLen  = 4
+	shmOffName     = 8
+	shmOffCount    = 72
+	shmOffSum      = 80
+	shmOffMin      = 88
+	shmOffMax      = 96
+	shmOffOverflow = 104
+
+	// slot states; a reclaimed slot is claimed with the next state after
+	// the one it was stuck in, skipping free and ready
+	shmSlotFree    = 0
+	shmSlotClaimed = 1
+	shmSlotReady   = 2
+)
+
+// shmClaimTimeout is how long a slot may stay claimed before it is taken
+// to belong to a process that crashed while initializing it, and is
+// reclaimed. Initializing takes nanoseconds.
+var shmClaimTimeout = time.Second
+
+// SharedRegistry stores timers in a memory-mapped file so that several
+// processes, such as the workers of a prefork server, can record into the
+// same timers and a single exporter process can read the merged result.
+// Every process opens the same file; a timer name maps to the same slot in
+// all of them and is updated with atomic operations.
+//
+// Statistics of a slot are read field by field, so a snapshot taken during
+// concurrent updates may be off by the in-flight observations.
+type SharedRegistry struct {
+	mem   []byte
+	slots int
+	unmap func() error
+}
+
+// SharedTimer is a timer stored in a SharedRegistry slot.
+// All methods are safe for concurrent use from any process.
+type SharedTimer struct {
+	slot []byte
+}
+
+func shmUint32(b []byte, off int) *uint32 {
+	return (*uint32)(unsafe.Pointer(&b[off]))
+}
+
+func shmUint64(b []byte, off int) *uint64 {
+	return (*uint64)(unsafe.Pointer(&b[off]))
+}
+
+func shmInt64(b []byte, off int) *int64 {
+	return (*int64)(unsafe.Pointer(&b[off]))
+}
+
+// shmFileSize returns the size of a shared registry file with n slots.
+func shmFileSize(n int) int {
+	return shmHeaderSize + n*shmSlotSize
+}
+
+// initShared writes a fresh header into mem.
+func initShared(mem []byte, slots int) {
+	copy(mem, shmMagic)
+	*shmUint32(mem, shmOffVersion) = shmVersion
+	*shmUint32(mem, shmOffSlots) = uint32(slots)
+}
+
+// newSharedRegistry validates the header of mem and wraps it.
+func newSharedRegistry(mem []byte, unmap func() error) (*SharedRegistry, error) {
+	if len(mem) < shmHeaderSize || string(mem[:len(shmMagic)]) != shmMagic ||
+		*shmUint32(mem, shmOffVersion) != shmVersion {
+		return nil, ErrSharedFormat
+	}
+	slots := int(*shmUint32(mem, shmOffSlots))
+	if len(mem) < shmFileSize(slots) {
+		return nil, ErrSharedFormat
+	}
+	return &SharedRegistry{mem: mem, slots: slots, unmap: unmap}, nil
+}
+
+func (s *SharedRegistry) slot(i int) []byte {
+	off := shmHeaderSize + i*shmSlotSize
+	return s.mem[off : off+shmSlotSize : off+shmSlotSize]
+}
+
+// Timer returns the shared timer for name, claiming a free slot if no
+// process has used the name yet. A slot left claimed by a process that
+// crashed while initializing it is reclaimed after a second.
+// Returns ErrSharedName if name is longer than 64 bytes, and
+// ErrSharedFull if every slot is taken by other names.
+func (s *SharedRegistry) Timer(name string) (*SharedTimer, error) {
+	if len(name) > shmNameLen {
+		return nil, ErrSharedName
+	}
+	for i := range s.slots {
+		slot := s.slot(i)
+		state := shmUint32(slot, shmOffState)
+		if atomic.CompareAndSwapUint32(state, shmSlotFree, shmSlotClaimed) &&
+			initSlot(slot, shmSlotClaimed, name) {
+			return &SharedTimer{slot: slot}, nil
+		}
+		// another process may be initializing this slot
+		deadline := time.Now().Add(shmClaimTimeout)
+		for {
+			cur := atomic.LoadUint32(state)
+			if cur == shmSlotFree || cur == shmSlotReady {
+				break
+			}
+			if time.Now().Before(deadline) {
+				time.Sleep(time.Microsecond)
+				continue
+			}
+			next := cur + 1
+			for next == shmSlotFree || next == shmSlotReady {
+				next++
+			}
+			if atomic.CompareAndSwapUint32(state, cur, next) && initSlot(slot, next, name) {
+				return &SharedTimer{slot: slot}, nil
+			}
+			deadline = time.Now().Add(shmClaimTimeout)
+		}
+		if slotName(slot) == name {
+			return &SharedTimer{slot: slot}, nil
+		}
+	}
+	return nil, ErrSharedFull
+}
+
+// initSlot initializes a slot claimed with the state claim for name and
+// marks it ready. The compare-and-swap that set the state to claim made
+// the caller the owner, and each write first checks that the state still
+// holds that claim, so a process that stalls past shmClaimTimeout stops
+// writing once its slot is reclaimed instead of overwriting the new
+// owner's name and statistics. Returns false if the slot was reclaimed.
+func initSlot(slot []byte, claim uint32, name string) bool {
+	state := shmUint32(slot, shmOffState)
+	owned := func() bool { return atomic.LoadUint32(state) == claim }
+
+	var buf [shmNameLen]byte
+	copy(buf[:], name)
+	for off := 0; off < shmNameLen; off += 8 {
+		if !owned() {
+			return false
+		}
+		atomic.StoreUint64(shmUint64(slot, shmOffName+off), binary.NativeEndian.Uint64(buf[off:]))
+	}
+	writes := []func(){
+		func() { atomic.StoreUint32(shmUint32(slot, shmOffNameLen), uint32(len(name))) },
+		func() { atomic.StoreUint64(shmUint64(slot, shmOffCount), 0) },
+		func() { atomic.StoreInt64(shmInt64(slot, shmOffSum), 0) },
+		func() { atomic.StoreInt64(shmInt64(slot, shmOffMin), math.MaxInt64) },
+		func() { atomic.StoreInt64(shmInt64(slot, shmOffMax), 0) },
+		func() { atomic.StoreUint32(shmUint32(slot, shmOffOverflow), 0) },
+	}
+	for _, write := range writes {
+		if !owned() {
+			return false
+		}
+		write()
+	}
+	return atomic.CompareAndSwapUint32(state, claim, shmSlotReady)
+}
+
+// slotName returns the name of a ready slot.
+func slotName(slot []byte) string {
+	n := min(int(*shmUint32(slot, shmOffNameLen)), shmNameLen)
+	return string(bytes.Clone(slot[shmOffName : shmOffName+n]))
+}
+
+// Snapshot returns a snapshot of every timer in the file, keyed by name.
+func (s *SharedRegistry) Snapshot() map[string]Snapshot {
+	snaps := make(map[string]Snapshot)
+	for i := range s.slots {
+		slot := s.slot(i)
+		if atomic.LoadUint32(shmUint32(slot, shmOffState)) != shmSlotReady {
+			continue
+		}
+		snaps[slotName(slot)] = (&SharedTimer{slot: slot}).Snapshot()
+	}
+	return snaps
+}
+
+// Close unmaps the file. Timers obtained from s must not be used afterwards.
+func (s *SharedRegistry) Close() error {
+	return s.unmap()
+}
+
+// Observe records a duration in the shared timer.
+func (t *SharedTimer) Observe(d time.Duration) {
+	durNano := d.Nanoseconds()
+	atomic.AddUint64(shmUint64(t.slot, shmOffCount), 1)
+
+	minp := shmInt64(t.slot, shmOffMin)
+	for cur := atomic.LoadInt64(minp); durNano < cur; cur = atomic.LoadInt64(minp) {
+		if atomic.CompareAndSwapInt64(minp, cur, durNano) {
+			break
+		}
+	}
+	maxp := shmInt64(t.slot, shmOffMax)
+	for cur := atomic.LoadInt64(maxp); durNano > cur; cur = atomic.LoadInt64(maxp) {
+		if atomic.CompareAndSwapInt64(maxp, cur, durNano) {
+			break
+		}
+	}
+
+	// cap at MaxInt64, set overflow flag if needed
+	sump := shmInt64(t.slot, shmOffSum)
+	for {
+		cur := atomic.LoadInt64(sump)
+		next, capped := cur+durNano, false
+		if durNano > 0 && cur > math.MaxInt64-durNano {
+			next, capped = math.MaxInt64, true
+		}
+		if atomic.CompareAndSwapInt64(sump, cur, next) {
+			if capped {
+				atomic.StoreUint32(shmUint32(t.slot, shmOffOverflow), 1)
+			}
+			return
+		}
+	}
+}
+
+// Snapshot returns the current statistics of the shared timer.
+func (t *SharedTimer) Snapshot() Snapshot {
+	s := Snapshot{
+		Count:         atomic.LoadUint64(shmUint64(t.slot, shmOffCount)),
+		Max:           time.Duration(atomic.LoadInt64(shmInt64(t.slot, shmOffMax))),
+		Sum:           time.Duration(atomic.LoadInt64(shmInt64(t.slot, shmOffSum))),
+		SumOverflowed: atomic.LoadUint32(shmUint32(t.slot, shmOffOverflow)) != 0,
+	}
+	if s.Count > 0 {
+		s.Min = time.Duration(atomic.LoadInt64(shmInt64(t.slot, shmOffMin)))
+	}
+	return s
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package timer
+
+import "errors"
+
+// OpenSharedRegistry is not supported on this platform and always returns
+// errors.ErrUnsupported.
+func OpenSharedRegistry(path string, slots int) (*SharedRegistry, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,190 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package timer
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSharedRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timers.shm")
+
+	// two mappings of the same file stand in for two worker processes
+	w1, err := OpenSharedRegistry(path, 4)
+	if err != nil {
+		t.Fatalf("OpenSharedRegistry failed: %v", err)
+	}
+	defer w1.Close()
+	w2, err := OpenSharedRegistry(path, 100)
+	if err != nil {
+		t.Fatalf("OpenSharedRegistry failed: %v", err)
+	}
+	defer w2.Close()
+	if w2.slots != 4 {
+		t.Errorf("Expected existing file's slot count 4, got %d", w2.slots)
+	}
+
+	a1, _ := w1.Timer("db")
+	a2, _ := w2.Timer("db")
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a1.Observe(time.Duration(i+1) * time.Millisecond)
+		}()
+		go func() {
+			defer wg.Done()
+			a2.Observe(time.Duration(i+1) * time.Microsecond)
+		}()
+	}
+	wg.Wait()
+
+	snaps := w1.Snapshot()
+	s := snaps["db"]
+	if s.Count != 200 {
+		t.Errorf("Count = %d; want 200", s.Count)
+	}
+	if s.Min != time.Microsecond {
+		t.Errorf("Min = %v; want 1µs", s.Min)
+	}
+	if s.Max != 100*time.Millisecond {
+		t.Errorf("Max = %v; want 100ms", s.Max)
+	}
+	if want := 5050*time.Millisecond + 5050*time.Microsecond; s.Sum != want {
+		t.Errorf("Sum = %v; want %v", s.Sum, want)
+	}
+
+	b, _ := w2.Timer("cache")
+	if b.Snapshot() != (Snapshot{}) {
+		t.Errorf("Expected empty snapshot for unused timer, got %+v", b.Snapshot())
+	}
+	if len(w1.Snapshot()) != 2 {
+		t.Errorf("Expected timer created by one worker to be visible to the other")
+	}
+
+	_, _ = w1.Timer("c")
+	_, _ = w1.Timer("d")
+	if _, err := w1.Timer("e"); !errors.Is(err, ErrSharedFull) {
+		t.Errorf("Expected ErrSharedFull, got %v", err)
+	}
+}
+
+func TestSharedTimerOverflow(t *testing.T) {
+	s, err := OpenSharedRegistry(filepath.Join(t.TempDir(), "timers.shm"), 1)
+	if err != nil {
+		t.Fatalf("OpenSharedRegistry failed: %v", err)
+	}
+	defer s.Close()
+	t0, _ := s.Timer("db")
+	t0.Observe(math.MaxInt64 / 2)
+	if t0.Snapshot().SumOverflowed {
+		t.Errorf("Expected no overflow after one large observation")
+	}
+	t0.Observe(math.MaxInt64/2 + 1000)
+	snap := t0.Snapshot()
+	if !snap.SumOverflowed || snap.Sum != math.MaxInt64 {
+		t.Errorf("Expected capped sum with overflow flag, got %+v", snap)
+	}
+}
+
+func TestSharedRegistryInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-shm")
+	if err := os.WriteFile(path, []byte("definitely not a shared registry file, but long enough for a header"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSharedRegistry(path, 1); !errors.Is(err, ErrSharedFormat) {
+		t.Errorf("Expected ErrSharedFormat, got %v", err)
+	}
+}
+
+func TestSharedRegistrySlots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timers.shm")
+	for _, n := range []int{0, -1} {
+		if _, err := OpenSharedRegistry(path, n); !errors.Is(err, ErrSharedSlots) {
+			t.Errorf("OpenSharedRegistry(%d) = %v; want ErrSharedSlots", n, err)
+		}
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no file to be created, got %v", err)
+	}
+}
+
+func TestSharedRegistryStaleOwner(t *testing.T) {
+	s, err := OpenSharedRegistry(filepath.Join(t.TempDir(), "timers.shm"), 1)
+	if err != nil {
+		t.Fatalf("OpenSharedRegistry failed: %v", err)
+	}
+	defer s.Close()
+	tm, err := s.Timer("db")
+	if err != nil {
+		t.Fatalf("Timer failed: %v", err)
+	}
+	tm.Observe(time.Millisecond)
+	// a process that claimed the slot before it was reclaimed resumes
+	if initSlot(s.slot(0), shmSlotClaimed, "stale") {
+		t.Errorf("Expected a stale claim to fail")
+	}
+	if got := s.Snapshot(); len(got) != 1 || got["db"].Count != 1 {
+		t.Errorf("Expected the stale owner to leave the slot untouched, got %v", got)
+	}
+}
+
+func TestSharedRegistryNames(t *testing.T) {
+	s, err := OpenSharedRegistry(filepath.Join(t.TempDir(), "timers.shm"), 2)
+	if err != nil {
+		t.Fatalf("OpenSharedRegistry failed: %v", err)
+	}
+	defer s.Close()
+	long := strings.Repeat("x", shmNameLen)
+	if _, err := s.Timer(long); err != nil {
+		t.Errorf("Expected a 64-byte name to fit, got %v", err)
+	}
+	if _, err := s.Timer(long + "y"); !errors.Is(err, ErrSharedName) {
+		t.Errorf("Expected ErrSharedName for a 65-byte name, got %v", err)
+	}
+}
+
+func TestSharedRegistryReclaim(t *testing.T) {
+	defer func(d time.Duration) { shmClaimTimeout = d }(shmClaimTimeout)
+	shmClaimTimeout = 10 * time.Millisecond
+
+	s, err := OpenSharedRegistry(filepath.Join(t.TempDir(), "timers.shm"), 1)
+	if err != nil {
+		t.Fatalf("OpenSharedRegistry failed: %v", err)
+	}
+	defer s.Close()
+	// a process claimed the slot, wrote part of a name and crashed
+	slot := s.slot(0)
+	*shmUint32(slot, shmOffState) = shmSlotClaimed
+	copy(slot[shmOffName:], "half")
+	atomic.StoreUint64(shmUint64(slot, shmOffCount), 7)
+
+	done := make(chan error, 1)
+	go func() {
+		tm, err := s.Timer("db")
+		if err == nil {
+			tm.Observe(time.Millisecond)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Timer failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the stuck slot to be reclaimed")
+	}
+	if got := s.Snapshot(); len(got) != 1 || got["db"].Count != 1 {
+		t.Errorf("Expected a fresh db timer in the reclaimed slot, got %v", got)
+	}
+}
@@ -0,0 +1,56 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package timer
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenSharedRegistry opens the shared registry file at path, creating it
+// with room for slots timers if it does not exist. Processes opening an
+// existing file use its slot count and ignore slots. Returns
+// ErrSharedSlots if slots is not positive.
+func OpenSharedRegistry(path string, slots int) (*SharedRegistry, error) {
+	if slots <= 0 {
+		return nil, ErrSharedSlots
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// serialize initialization between processes opening the file together
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return nil, err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := int(fi.Size())
+	fresh := size == 0
+	if fresh {
+		size = shmFileSize(slots)
+		if err := f.Truncate(int64(size)); err != nil {
+			return nil, err
+		}
+	}
+
+	mem, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	if fresh {
+		initShared(mem, slots)
+	}
+	s, err := newSharedRegistry(mem, func() error { return syscall.Munmap(mem) })
+	if err != nil {
+		syscall.Munmap(mem)
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,124 @@
+package timer
+
+import (
+	"sync"
+	"time"
+)
+
+// ClockSkew estimates the offset of a remote peer's wall clock relative to
+// the local one and corrects one-way latencies computed from tokens the
+// peer issued. Use one ClockSkew per remote peer.
+//
+// The offset is either set explicitly with SetOffset or estimated from
+// round trips with ObserveRoundTrip, keeping the sample with the smallest
+// round-trip time as the most accurate. Every offset estimate is also
+// recorded, by magnitude, in separate skew statistics.
+type ClockSkew struct {
+	mutex   sync.RWMutex
+	offset  time.Duration
+	minRTT  time.Duration
+	clamped uint64
+	stats   *Timer
+}
+
+// NewClockSkew returns a ClockSkew with a zero offset.
+func NewClockSkew() *ClockSkew {
+	return &ClockSkew{minRTT: -1, stats: NewTimer()}
+}
+
+// SetOffset sets the remote clock's offset, i.e. remote minus local time.
+// It replaces any round-trip estimate.
+func (c *ClockSkew) SetOffset(offset time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.offset = offset
+	c.minRTT = -1
+	c.stats.Observe(absDuration(offset))
+}
+
+// ObserveRoundTrip updates the offset estimate from a request sent at
+// sent and answered at received, both local times, carrying the peer's
+// clock reading remote. Assuming symmetric network delays, the offset is
+// remote minus the midpoint of the round trip, with an uncertainty of half
+// the round-trip time. The estimate from the shortest round trip wins.
+func (c *ClockSkew) ObserveRoundTrip(sent, remote, received time.Time) {
+	rtt := max(received.Sub(sent), 0)
+	offset := remote.Sub(sent.Add(rtt / 2))
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stats.Observe(absDuration(offset))
+	if c.minRTT < 0 || rtt <= c.minRTT {
+		c.offset = offset
+		c.minRTT = rtt
+	}
+}
+
+// Offset returns the current offset estimate, remote minus local time.
+func (c *ClockSkew) Offset() time.Duration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.offset
+}
+
+// Uncertainty returns half the round-trip time of the current estimate,
+// or 0 if the offset was set explicitly or not estimated yet.
+func (c *ClockSkew) Uncertainty() time.Duration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return max(c.minRTT/2, 0)
+}
+
+// Stats returns statistics on the magnitude of all offset estimates.
+func (c *ClockSkew) Stats() Snapshot {
+	return c.stats.Snapshot()
+}
+
+// Clamped returns how many corrected latencies were negative and
+// recorded as 0, which indicates a stale or wrong offset.
+func (c *ClockSkew) Clamped() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.clamped
+}
+
+// Elapsed returns the one-way latency since tok was issued by the peer,
+// corrected for the estimated offset and clamped to 0.
+func (c *ClockSkew) Elapsed(tok StopwatchToken) time.Duration {
+	return c.elapsedAt(tok, time.Now())
+}
+
+func (c *ClockSkew) elapsedAt(tok StopwatchToken, now time.Time) time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	d := now.Sub(tok.Start) + c.offset
+	if d < 0 {
+		c.clamped++
+		return 0
+	}
+	return d
+}
+
+// CompleteWithSkew is like Complete but corrects the elapsed time for the
+// clock offset estimated by c. Tokens issued by this process use the
+// monotonic clock and are not corrected. Like Complete, it records only
+// into timers already registered in r, returning ErrUnknownTimer and
+// recording nothing if there is no such timer.
+func (tok StopwatchToken) CompleteWithSkew(r *Registry, c *ClockSkew) (time.Duration, error) {
+	d := tok.Elapsed()
+	if tok.Origin != processOrigin {
+		d = c.Elapsed(tok)
+	}
+	t := r.Get(tok.Name)
+	if t == nil {
+		return d, ErrUnknownTimer
+	}
+	t.Observe(d)
+	return d, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,71 @@
+package timer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClockSkewRoundTrip(t *testing.T) {
+	c := NewClockSkew()
+	local := time.Unix(1000, 0)
+	// peer runs 2s ahead; the 10ms round trip is symmetric
+	c.ObserveRoundTrip(local, local.Add(2*time.Second+5*time.Millisecond), local.Add(10*time.Millisecond))
+	if c.Offset() != 2*time.Second || c.Uncertainty() != 5*time.Millisecond {
+		t.Errorf("Offset = %v ± %v; want 2s ± 5ms", c.Offset(), c.Uncertainty())
+	}
+
+	// a slower round trip does not replace the estimate
+	c.ObserveRoundTrip(local, local.Add(3*time.Second), local.Add(time.Second))
+	if c.Offset() != 2*time.Second {
+		t.Errorf("Expected shortest round trip to win, got offset %v", c.Offset())
+	}
+	if s := c.Stats(); s.Count != 2 || s.Max != 2500*time.Millisecond {
+		t.Errorf("Unexpected skew stats: %+v", s)
+	}
+}
+
+func TestClockSkewElapsed(t *testing.T) {
+	c := NewClockSkew()
+	c.SetOffset(-time.Second) // peer runs 1s behind
+	now := time.Unix(1000, 0)
+	tok := StopwatchToken{Name: "x", Start: now.Add(-1100 * time.Millisecond), Origin: processOrigin + 1}
+	if d := c.elapsedAt(tok, now); d != 100*time.Millisecond {
+		t.Errorf("Elapsed = %v; want 100ms", d)
+	}
+	if c.Uncertainty() != 0 {
+		t.Errorf("Expected no uncertainty for explicit offset, got %v", c.Uncertainty())
+	}
+
+	tok.Start = now
+	if d := c.elapsedAt(tok, now); d != 0 || c.Clamped() != 1 {
+		t.Errorf("Expected negative latency clamped, got %v (clamped %d)", d, c.Clamped())
+	}
+}
+
+func TestCompleteWithSkew(t *testing.T) {
+	r := NewRegistry()
+	remote, local := r.GetOrCreate("remote"), r.GetOrCreate("local")
+	c := NewClockSkew()
+	c.SetOffset(time.Hour)
+	tok := StopwatchToken{Name: "remote", Start: time.Now(), Origin: processOrigin + 1}
+	if d, err := tok.CompleteWithSkew(r, c); err != nil || d < time.Hour {
+		t.Errorf("Expected offset applied, got %v, %v", d, err)
+	}
+	// local tokens ignore the offset
+	if d, err := NewStopwatchToken("local").CompleteWithSkew(r, c); err != nil || d >= time.Hour {
+		t.Errorf("Expected local token uncorrected, got %v, %v", d, err)
+	}
+	if remote.Count() != 1 || local.Count() != 1 {
+		t.Errorf("Expected one observation each, got %d and %d", remote.Count(), local.Count())
+	}
+
+	// untrusted names do not create timers
+	tok.Name = "unregistered"
+	if _, err := tok.CompleteWithSkew(r, c); !errors.Is(err, ErrUnknownTimer) {
+		t.Errorf("Expected ErrUnknownTimer, got %v", err)
+	}
+	if r.Len() != 2 {
+		t.Errorf("Expected 2 timers, got %d", r.Len())
+	}
+}
@@ -0,0 +1,104 @@
+package timer
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of a Timer's statistics.
+// Snapshots are plain values and can be compared, merged, and encoded
+// without holding any lock on the originating Timer.
+type Snapshot struct {
+	Count uint64        `json:"count"`  // Number of durations observed
+	Min   time.Duration `json:"min_ns"` // Minimum observed duration, 0 if Count is 0
+	Max   time.Duration `json:"max_ns"` // Maximum observed duration, 0 if Count is 0
+	// Total sum of all durations (may be capped at MaxInt64)
+	Sum time.Duration `json:"sum_ns"`
+	// Indicates if Sum reached MaxInt64 and was capped
+	SumOverflowed bool `json:"sum_overflowed,omitempty"`
+	// The exact sum beyond MaxInt64 as SumEpoch * 2^63 + SumRemainder,
+	// kept by the OverflowWrap and OverflowBig policies and by Merge; both
+	// are 0 if the sum was capped or did not overflow
+	SumEpoch     uint64        `json:"sum_epoch,omitempty"`
+	SumRemainder time.Duration `json:"sum_remainder_ns,omitempty"`
+	// Number of observations whose operation panicked, included in Count
+	Panicked uint64 `json:"panicked,omitempty"`
+}
+
+// Snapshot returns a consistent copy of the timer's current statistics.
+func (t *Timer) Snapshot() Snapshot {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.snapshotNoLock()
+}
+
+// snapshotNoLock builds a Snapshot without acquiring a lock.
+// Callers must hold at least a read lock.
+func (t *Timer) snapshotNoLock() Snapshot {
+	s := Snapshot{
+		Count:         t.count,
+		Max:           t.max,
+		Sum:           time.Duration(t.totalSum),
+		SumOverflowed: t.sumOverflowed,
+		Panicked:      t.panicked,
+	}
+	if t.count > 0 {
+		s.Min = t.min
+	}
+	if t.sumOverflowed && t.exact.Load() {
+		s.Sum = math.MaxInt64
+		if hi, lo, ok := bigHiLo(t.exactSumNoLock()); ok {
+			s.setExactSum(hi, lo)
+		}
+	}
+	return s
+}
+
+// Mean returns the average of the durations in the snapshot, rounded to the
+// nearest nanosecond, from the exact sum if the snapshot keeps one.
+// Returns 0 if the snapshot is empty.
+func (s Snapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	if s.SumEpoch > 0 {
+		return s.exactMean()
+	}
+	// add half a count to round and not floor
+	return time.Duration((int64(s.Sum) + int64(s.Count)/2) / int64(s.Count))
+}
+
+// Merge returns the combination of s and o, as if every duration observed by
+// either had been observed by a single timer. An empty snapshot is an
+// identity for Merge. A sum exceeding MaxInt64 is capped, and kept exact
+// in SumEpoch and SumRemainder unless either sum was capped already.
+func (s Snapshot) Merge(o Snapshot) Snapshot {
+	if o.Count == 0 {
+		return s
+	}
+	if s.Count == 0 {
+		return o
+	}
+	m := Snapshot{
+		Count:         s.Count + o.Count,
+		Min:           min(s.Min, o.Min),
+		Max:           max(s.Max, o.Max),
+		SumOverflowed: s.SumOverflowed || o.SumOverflowed,
+		Panicked:      s.Panicked + o.Panicked,
+	}
+	if s.SumEpoch == 0 && o.SumEpoch == 0 && (o.Sum <= 0 || s.Sum <= math.MaxInt64-o.Sum) {
+		m.Sum = s.Sum + o.Sum
+		return m
+	}
+	// keep the sum exact beyond MaxInt64 unless either side is capped
+	sh, sl, sok := s.exactSum()
+	oh, ol, ook := o.exactSum()
+	if !sok || !ook {
+		m.Sum, m.SumOverflowed = math.MaxInt64, true
+		return m
+	}
+	lo, carry := bits.Add64(sl, ol, 0)
+	m.setExactSum(sh+oh+carry, lo)
+	return m
+}
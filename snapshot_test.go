@@ -0,0 +1,82 @@
+package timer
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	timer := NewTimer()
+
+	s := timer.Snapshot()
+	if s != (Snapshot{}) {
+		t.Errorf("Expected empty snapshot for new timer, got %+v", s)
+	}
+
+	timer.Observe(10 * time.Millisecond)
+	timer.Observe(20 * time.Millisecond)
+	timer.Observe(5 * time.Millisecond)
+
+	s = timer.Snapshot()
+	if s.Count != 3 {
+		t.Errorf("Count = %d; want 3", s.Count)
+	}
+	if s.Min != 5*time.Millisecond {
+		t.Errorf("Min = %v; want 5ms", s.Min)
+	}
+	if s.Max != 20*time.Millisecond {
+		t.Errorf("Max = %v; want 20ms", s.Max)
+	}
+	if s.Sum != 35*time.Millisecond {
+		t.Errorf("Sum = %v; want 35ms", s.Sum)
+	}
+	if s.Mean() != timer.Mean() {
+		t.Errorf("Snapshot Mean = %v; want %v", s.Mean(), timer.Mean())
+	}
+}
+
+func TestSnapshotMerge(t *testing.T) {
+	a := Snapshot{Count: 2, Min: 10 * time.Millisecond, Max: 30 * time.Millisecond, Sum: 40 * time.Millisecond}
+	b := Snapshot{Count: 1, Min: 5 * time.Millisecond, Max: 5 * time.Millisecond, Sum: 5 * time.Millisecond}
+
+	m := a.Merge(b)
+	want := Snapshot{Count: 3, Min: 5 * time.Millisecond, Max: 30 * time.Millisecond, Sum: 45 * time.Millisecond}
+	if m != want {
+		t.Errorf("Merge = %+v; want %+v", m, want)
+	}
+
+	if got := a.Merge(Snapshot{}); got != a {
+		t.Errorf("Merge with empty = %+v; want %+v", got, a)
+	}
+	if got := (Snapshot{}).Merge(b); got != b {
+		t.Errorf("Empty merge = %+v; want %+v", got, b)
+	}
+
+	big := Snapshot{Count: 1, Min: math.MaxInt64 / 2, Max: math.MaxInt64 / 2, Sum: math.MaxInt64/2 + 1}
+	o := big.Merge(big)
+	if !o.SumOverflowed || o.Sum != math.MaxInt64 {
+		t.Errorf("Expected merged sum to be capped with overflow flag, got %+v", o)
+	}
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	s := Snapshot{Count: 2, Min: time.Millisecond, Max: 3 * time.Millisecond, Sum: 4 * time.Millisecond}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"count":2,"min_ns":1000000,"max_ns":3000000,"sum_ns":4000000}`
+	if string(b) != want {
+		t.Errorf("Marshal = %s; want %s", b, want)
+	}
+
+	var got Snapshot
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got != s {
+		t.Errorf("Round trip = %+v; want %+v", got, s)
+	}
+}
@@ -0,0 +1,106 @@
+package timer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// socketWriteTimeout bounds how long a slow client can hold a connection.
+const socketWriteTimeout = 5 * time.Second
+
+// NamedSnapshot is a Snapshot paired with the name it is registered under.
+// It encodes to JSON as a flat object with a "name" field.
+type NamedSnapshot struct {
+	Name string `json:"name"`
+	Snapshot
+}
+
+// WriteJSONLines writes one JSON object per registered timer to w, sorted by
+// name and separated by newlines.
+func (r *Registry) WriteJSONLines(w io.Writer) error {
+	snaps := r.Snapshot()
+	names := sortedNames(snaps)
+
+	bw := bufio.NewWriter(w)
+	var line []byte
+	for _, name := range names {
+		line = NamedSnapshot{Name: name, Snapshot: snaps[name]}.AppendJSON(line[:0])
+		line = append(line, '\n')
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// SnapshotServer serves registry snapshots as JSON lines to every client
+// that connects, closing the connection once all timers are written.
+type SnapshotServer struct {
+	registry *Registry
+	listener net.Listener
+	conns    sync.WaitGroup
+	lc       lifecycle
+}
+
+// NewSnapshotServer creates a SnapshotServer for r that accepts connections
+// on l once started. The server takes ownership of l and closes it on Close.
+func NewSnapshotServer(r *Registry, l net.Listener) *SnapshotServer {
+	return &SnapshotServer{registry: r, listener: l}
+}
+
+// Serve starts a SnapshotServer for r accepting connections on l.
+// The server runs in the background until Close is called.
+func (r *Registry) Serve(l net.Listener) *SnapshotServer {
+	s := NewSnapshotServer(r, l)
+	_ = s.Start(context.Background())
+	return s
+}
+
+// Addr returns the address the server is listening on.
+func (s *SnapshotServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Start begins accepting connections until ctx is done or Close is called.
+func (s *SnapshotServer) Start(ctx context.Context) error {
+	return s.lc.start(ctx, s, s.acceptLoop)
+}
+
+// Close stops accepting connections and waits for in-progress writes to
+// finish. For Unix sockets the socket file is removed.
+func (s *SnapshotServer) Close() error {
+	err := s.listener.Close()
+	s.lc.close()
+	s.conns.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *SnapshotServer) acceptLoop(ctx context.Context) {
+	stop := context.AfterFunc(ctx, func() { s.listener.Close() })
+	defer stop()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return
+		}
+		s.conns.Add(1)
+		go func() {
+			defer s.conns.Done()
+			defer conn.Close()
+			_ = conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+			_ = s.registry.WriteJSONLines(conn)
+		}()
+	}
+}
@@ -0,0 +1,55 @@
+//go:build !js && !wasip1
+
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// ErrSocketInUse is returned by ListenUnix when another process is still
+// serving on the socket at path.
+var ErrSocketInUse = errors.New("socket in use")
+
+// staleDialTimeout bounds the probe of an existing socket file.
+const staleDialTimeout = time.Second
+
+// ListenUnix starts a SnapshotServer for r on a Unix domain socket at path.
+// A stale socket file left at path by a previous process is removed first;
+// if a process still accepts connections on it, ListenUnix returns
+// ErrSocketInUse instead of taking over its endpoint.
+// The server runs in the background until Close is called.
+func (r *Registry) ListenUnix(path string) (*SnapshotServer, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode().Type() == fs.ModeSocket {
+		conn, err := net.DialTimeout("unix", path, staleDialTimeout)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: %s", ErrSocketInUse, path)
+		}
+		if !connRefused(err) {
+			return nil, err
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return r.Serve(l), nil
+}
+
+// connRefused reports whether err says nothing listens on a socket, which
+// Windows reports as WSAECONNREFUSED rather than syscall.ECONNREFUSED.
+func connRefused(err error) bool {
+	var errno syscall.Errno
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		runtime.GOOS == "windows" && errors.As(err, &errno) && errno == 10061
+}
@@ -0,0 +1,32 @@
+package timer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONLines(t *testing.T) {
+	r := NewRegistry()
+	a, b := NewTimer(), NewTimer()
+	_ = r.Register("b", b)
+	_ = r.Register("a", a)
+	a.Observe(time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := r.WriteJSONLines(&buf); err != nil {
+		t.Fatalf("WriteJSONLines failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	want := `{"name":"a","count":1,"min_ns":1000000,"max_ns":1000000,"sum_ns":1000000}`
+	if lines[0] != want {
+		t.Errorf("First line = %s; want %s", lines[0], want)
+	}
+	if !strings.HasPrefix(lines[1], `{"name":"b"`) {
+		t.Errorf("Expected second line for b, got %s", lines[1])
+	}
+}
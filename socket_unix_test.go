@@ -0,0 +1,107 @@
+//go:build unix
+
+package timer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListenUnix(t *testing.T) {
+	r := NewRegistry()
+	t0 := NewTimer()
+	_ = r.Register("db", t0)
+	t0.Observe(5 * time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), "timer.sock")
+	s, err := r.ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix failed: %v", err)
+	}
+	defer s.Close()
+
+	for range 2 {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		sc := bufio.NewScanner(conn)
+		var got []NamedSnapshot
+		for sc.Scan() {
+			var ns NamedSnapshot
+			if err := json.Unmarshal(sc.Bytes(), &ns); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			got = append(got, ns)
+		}
+		conn.Close()
+
+		if len(got) != 1 || got[0].Name != "db" || got[0].Snapshot != t0.Snapshot() {
+			t.Errorf("Unexpected snapshots from socket: %+v", got)
+		}
+	}
+}
+
+func TestListenUnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timer.sock")
+	// leave a socket file behind without removing it
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	s, err := NewRegistry().ListenUnix(path)
+	if err != nil {
+		t.Fatalf("Expected stale socket to be replaced, got %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	checkNoLeaks(t)
+}
+
+func TestListenUnixKeepsLiveSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timer.sock")
+	first, err := NewRegistry().ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix failed: %v", err)
+	}
+	defer first.Close()
+
+	if s, err := NewRegistry().ListenUnix(path); !errors.Is(err, ErrSocketInUse) {
+		if s != nil {
+			s.Close()
+		}
+		t.Fatalf("Second ListenUnix = %v; want ErrSocketInUse", err)
+	}
+	if conn, err := net.Dial("unix", path); err != nil {
+		t.Errorf("Expected the first server to keep its socket, got %v", err)
+	} else {
+		conn.Close()
+	}
+}
+
+func TestSnapshotServerStopsWithContext(t *testing.T) {
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "timer.sock"))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	s := NewSnapshotServer(NewRegistry(), l)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	cancel()
+	checkNoLeaks(t)
+	if err := s.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build js || wasip1
+
+package timer
+
+import "errors"
+
+// ListenUnix is not supported on WebAssembly and always returns
+// errors.ErrUnsupported. Use Serve with a listener provided by the host.
+func (r *Registry) ListenUnix(path string) (*SnapshotServer, error) {
+	return nil, errors.ErrUnsupported
+}
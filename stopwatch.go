@@ -0,0 +1,157 @@
+package timer
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrStopped is returned when stopping a Stopwatch again with the
+	// StopError policy.
+	ErrStopped = errors.New("stopwatch already stopped")
+	// ErrNotStarted is returned when stopping a Stopwatch that was not
+	// returned by Timer.Start, such as its zero value.
+	ErrNotStarted = errors.New("stopwatch not started")
+)
+
+// StopPolicy selects what a Stopwatch does when it is stopped again.
+type StopPolicy int
+
+const (
+	// StopIgnore silently ignores later Stops, which return the duration
+	// of the first one. It is the default.
+	StopIgnore StopPolicy = iota
+	// StopCount ignores later Stops like StopIgnore and counts them, see
+	// Timer.DuplicateStops, to find double-Stop bugs in production.
+	StopCount
+	// StopError counts later Stops like StopCount and makes StopErr
+	// return ErrStopped, to catch them in tests.
+	StopError
+)
+
+// String returns the name of the policy, such as "ignore".
+func (p StopPolicy) String() string {
+	switch p {
+	case StopIgnore:
+		return "ignore"
+	case StopCount:
+		return "count"
+	case StopError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Stopwatch times a single operation and records it in its Timer on Stop.
+// A Stopwatch is not safe for concurrent use.
+type Stopwatch struct {
+	timer   *Timer
+	start   time.Time
+	elapsed time.Duration
+	stopped bool
+	off     bool // started while recording was disabled
+}
+
+// Start starts a Stopwatch recording into t. The stopwatch counts as in
+// flight until Stop is called. If recording is disabled, the stopwatch is
+// a no-op whose Stop returns 0.
+func (t *Timer) Start() *Stopwatch {
+	if t.off() {
+		return &Stopwatch{timer: t, off: true}
+	}
+	t.mutex.Lock()
+	t.inFlight++
+	t.maxInFlight = max(t.maxInFlight, t.inFlight)
+	t.mutex.Unlock()
+	return &Stopwatch{timer: t, start: t.now()}
+}
+
+// Stop records the time since Start in the timer and returns it. Only the
+// first call records; later calls return the same duration and are
+// handled by the timer's StopPolicy. Stopping a Stopwatch that was not
+// started returns 0.
+func (s *Stopwatch) Stop() time.Duration {
+	d, _ := s.StopErr()
+	return d
+}
+
+// StopErr is Stop that also reports misuse. Returns ErrNotStarted if the
+// stopwatch was not returned by Timer.Start, and ErrStopped if it was
+// stopped before and the timer has the StopError policy. While recording
+// by the timer is off, Stops are neither recorded nor counted as
+// duplicates, and StopErr returns ErrClosed or ErrFrozen like Update.
+func (s *Stopwatch) StopErr() (time.Duration, error) {
+	if s == nil || s.timer == nil {
+		return 0, ErrNotStarted
+	}
+	t := s.timer
+	if t.off() {
+		if !s.stopped && !s.off {
+			// closed or frozen since Start
+			t.mutex.Lock()
+			t.inFlight--
+			t.mutex.Unlock()
+		}
+		s.stopped = true
+		return s.elapsed, t.offErr()
+	}
+	if s.stopped {
+		return s.elapsed, t.duplicateStop()
+	}
+	s.stopped = true
+	if s.off {
+		return 0, nil
+	}
+	s.elapsed = max(t.now().Sub(s.start), 0)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.inFlight--
+	t.observeNoLock(s.elapsed)
+	return s.elapsed, nil
+}
+
+// SetStopPolicy selects what stopwatches of t do when stopped again. The
+// default is StopIgnore.
+func (t *Timer) SetStopPolicy(p StopPolicy) {
+	t.stopPolicy.Store(int32(p))
+}
+
+// StopPolicy returns the policy set with SetStopPolicy.
+func (t *Timer) StopPolicy() StopPolicy {
+	return StopPolicy(t.stopPolicy.Load())
+}
+
+// DuplicateStops returns the number of times a stopwatch of t was stopped
+// again with the StopCount or StopError policy since t was created. It is
+// not cleared by Reset.
+func (t *Timer) DuplicateStops() uint64 {
+	return t.duplicateStops.Load()
+}
+
+// duplicateStop applies the stop policy to a repeated Stop.
+func (t *Timer) duplicateStop() error {
+	switch t.StopPolicy() {
+	case StopCount:
+		t.duplicateStops.Add(1)
+	case StopError:
+		t.duplicateStops.Add(1)
+		return ErrStopped
+	}
+	return nil
+}
+
+// InFlight returns the number of stopwatches started on the timer and not
+// yet stopped.
+func (t *Timer) InFlight() int64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.inFlight
+}
+
+// MaxInFlight returns the highest number of stopwatches running at once
+// since the timer was created or last reset.
+func (t *Timer) MaxInFlight() int64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.maxInFlight
+}
@@ -0,0 +1,151 @@
+package timer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStopwatch(t *testing.T) {
+	timer := NewTimer()
+	sw := timer.Start()
+	time.Sleep(2 * time.Millisecond)
+	d := sw.Stop()
+	if d < 2*time.Millisecond {
+		t.Errorf("Expected elapsed >= 2ms, got %v", d)
+	}
+	if again := sw.Stop(); again != d {
+		t.Errorf("Second Stop = %v; want %v", again, d)
+	}
+	if timer.Count() != 1 || timer.Max() != d {
+		t.Errorf("Expected one observation of %v, got %v", d, timer)
+	}
+}
+
+func TestStopwatchInFlight(t *testing.T) {
+	timer := NewTimer()
+	a, b := timer.Start(), timer.Start()
+	if timer.InFlight() != 2 || timer.MaxInFlight() != 2 {
+		t.Errorf("InFlight = %d, MaxInFlight = %d; want 2, 2", timer.InFlight(), timer.MaxInFlight())
+	}
+	a.Stop()
+	a.Stop()
+	if timer.InFlight() != 1 || timer.MaxInFlight() != 2 {
+		t.Errorf("InFlight = %d, MaxInFlight = %d; want 1, 2", timer.InFlight(), timer.MaxInFlight())
+	}
+
+	timer.Reset()
+	if timer.InFlight() != 1 || timer.MaxInFlight() != 1 {
+		t.Errorf("Expected Reset to keep running stopwatches, got InFlight %d MaxInFlight %d", timer.InFlight(), timer.MaxInFlight())
+	}
+	b.Stop()
+	if timer.InFlight() != 0 || timer.Count() != 1 {
+		t.Errorf("Expected no stopwatch in flight and 1 observation, got %d and %d", timer.InFlight(), timer.Count())
+	}
+}
+
+func TestStopwatchConcurrent(t *testing.T) {
+	timer := NewTimer()
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timer.Start().Stop()
+		}()
+	}
+	wg.Wait()
+	if timer.InFlight() != 0 || timer.Count() != 50 || timer.MaxInFlight() < 1 {
+		t.Errorf("Unexpected state after concurrent stopwatches: InFlight %d Count %d MaxInFlight %d",
+			timer.InFlight(), timer.Count(), timer.MaxInFlight())
+	}
+}
+
+func TestStopwatchStopPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy     StopPolicy
+		duplicates uint64
+		err        error
+	}{
+		{StopIgnore, 0, nil},
+		{StopCount, 2, nil},
+		{StopError, 2, ErrStopped},
+	} {
+		timer := NewTimer()
+		timer.SetStopPolicy(tc.policy)
+		if timer.StopPolicy() != tc.policy {
+			t.Errorf("StopPolicy = %v; want %v", timer.StopPolicy(), tc.policy)
+		}
+		sw := timer.Start()
+		d, err := sw.StopErr()
+		if err != nil {
+			t.Errorf("%v: first StopErr = %v; want nil", tc.policy, err)
+		}
+		if again := sw.Stop(); again != d {
+			t.Errorf("%v: second Stop = %v; want %v", tc.policy, again, d)
+		}
+		if again, err := sw.StopErr(); again != d || err != tc.err {
+			t.Errorf("%v: third StopErr = %v, %v; want %v, %v", tc.policy, again, err, d, tc.err)
+		}
+		if timer.Count() != 1 || timer.DuplicateStops() != tc.duplicates {
+			t.Errorf("%v: Expected 1 observation and %d duplicates, got %d and %d",
+				tc.policy, tc.duplicates, timer.Count(), timer.DuplicateStops())
+		}
+	}
+}
+
+func TestStopwatchNotStarted(t *testing.T) {
+	var sw Stopwatch
+	if d := sw.Stop(); d != 0 {
+		t.Errorf("Stop of zero Stopwatch = %v; want 0", d)
+	}
+	if _, err := sw.StopErr(); err != ErrNotStarted {
+		t.Errorf("Expected ErrNotStarted, got %v", err)
+	}
+	var nilSW *Stopwatch
+	if _, err := nilSW.StopErr(); err != ErrNotStarted {
+		t.Errorf("Expected ErrNotStarted for nil Stopwatch, got %v", err)
+	}
+}
+
+func TestStopwatchNoopDuplicate(t *testing.T) {
+	timer := NewTimer()
+	timer.SetStopPolicy(StopError)
+	timer.SetNoop(true)
+	sw := timer.Start()
+	if d, err := sw.StopErr(); d != 0 || !errors.Is(err, ErrFrozen) {
+		t.Errorf("First StopErr of no-op stopwatch = %v, %v; want 0, ErrFrozen", d, err)
+	}
+	if _, err := sw.StopErr(); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Expected ErrFrozen, got %v", err)
+	}
+	if timer.Count() != 0 || timer.DuplicateStops() != 0 {
+		t.Errorf("Expected no observations or duplicates, got %d and %d", timer.Count(), timer.DuplicateStops())
+	}
+}
+
+func TestStopwatchStoppedAfterClose(t *testing.T) {
+	timer := NewTimer()
+	timer.SetStopPolicy(StopError)
+	timer.SetClosePolicy(CloseError)
+	sw := timer.Start()
+	_ = timer.Close()
+	if d, err := sw.StopErr(); d != 0 || !errors.Is(err, ErrClosed) {
+		t.Errorf("StopErr after Close = %v, %v; want 0, ErrClosed", d, err)
+	}
+	if _, err := sw.StopErr(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed for a duplicate Stop after Close, got %v", err)
+	}
+	if timer.Count() != 0 || timer.InFlight() != 0 || timer.DuplicateStops() != 0 {
+		t.Errorf("Expected nothing recorded after Close, got count %d, in flight %d, duplicates %d",
+			timer.Count(), timer.InFlight(), timer.DuplicateStops())
+	}
+
+	frozen := NewTimer()
+	sw = frozen.Start()
+	frozen.SetNoop(true)
+	if _, err := sw.StopErr(); !errors.Is(err, ErrFrozen) || frozen.Count() != 0 {
+		t.Errorf("Expected a stopwatch of a frozen timer to record nothing, got %v and count %d", err, frozen.Count())
+	}
+}
@@ -0,0 +1,258 @@
+package timer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record is a timer snapshot stored at a point in time.
+type Record struct {
+	Time     time.Time `json:"time"`
+	Name     string    `json:"name"`
+	Snapshot           // fields are inlined in JSON
+}
+
+// MarshalJSON encodes the record in the line format of JSONExporter.
+// It overrides the method promoted from Snapshot.
+func (rec Record) MarshalJSON() ([]byte, error) {
+	ts := rec.Time.UTC().AppendFormat(make([]byte, 0, 32), time.RFC3339Nano)
+	return appendRecordJSON(make([]byte, 0, 160), ts, rec.Name, rec.Snapshot), nil
+}
+
+// IntervalStore keeps a queryable history of registry snapshots in an
+// append-only JSON lines file, so single-binary applications get latency
+// history without external infrastructure. Use it as the Exporter of a
+// Reporter to store one record per timer and interval.
+//
+// Records older than the retention are removed by rewriting the file once
+// the oldest record exceeds the retention by a quarter, so compaction is
+// infrequent.
+//
+// Lines that are not valid records, such as one cut short by a crash or a
+// full disk, are skipped when reading and dropped when compacting. An
+// incomplete last line is removed when the store is opened, so new
+// records start on a line of their own.
+type IntervalStore struct {
+	mutex     sync.Mutex
+	path      string
+	retention time.Duration
+	file      *os.File
+	oldest    time.Time
+	now       func() time.Time
+}
+
+// OpenIntervalStore opens or creates the store at path, keeping records
+// for retention. A retention of 0 keeps records forever.
+func OpenIntervalStore(path string, retention time.Duration) (*IntervalStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := truncatePartialLine(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &IntervalStore{path: path, retention: retention, file: f, now: time.Now}
+	_, err = s.readNoLock(func(rec Record) bool {
+		if s.oldest.IsZero() {
+			s.oldest = rec.Time
+		}
+		return false
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Export appends one record per timer, stamped with the current time,
+// and compacts the file if records have expired.
+func (s *IntervalStore) Export(snaps map[string]Snapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	now := s.now()
+	ts := now.UTC().AppendFormat(make([]byte, 0, 32), time.RFC3339Nano)
+	var b []byte
+	for _, name := range sortedNames(snaps) {
+		b = appendRecordJSON(b, ts, name, snaps[name])
+		b = append(b, '\n')
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if _, err := s.file.Write(b); err != nil {
+		return err
+	}
+	if s.oldest.IsZero() {
+		s.oldest = now
+	}
+	if s.retention > 0 && now.Sub(s.oldest) > s.retention+s.retention/4 {
+		return s.compactNoLock(now)
+	}
+	return nil
+}
+
+// Query returns the records of the timer named name (or of all timers if
+// name is empty) stored at or after from and before to, in storage order.
+func (s *IntervalStore) Query(name string, from, to time.Time) ([]Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil, ErrClosed
+	}
+	return s.readNoLock(func(rec Record) bool {
+		return (name == "" || rec.Name == name) && !rec.Time.Before(from) && rec.Time.Before(to)
+	})
+}
+
+// Compact removes expired records immediately.
+func (s *IntervalStore) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	return s.compactNoLock(s.now())
+}
+
+// readNoLock returns the records for which keep returns true.
+func (s *IntervalStore) readNoLock(keep func(Record) bool) ([]Record, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var recs []Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		if rec, ok := decodeRecordLine(sc.Bytes()); ok && keep(rec) {
+			recs = append(recs, rec)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// decodeRecordLine decodes a line of the store. Returns false for blank
+// lines and lines that are not valid records.
+func decodeRecordLine(line []byte) (Record, bool) {
+	var rec Record
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || json.Unmarshal(line, &rec) != nil {
+		return rec, false
+	}
+	return rec, true
+}
+
+// truncatePartialLine removes the bytes after the last newline of f, the
+// rest of a line whose write was cut short. A last line that is a valid
+// record but lacks its newline gets one instead.
+func truncatePartialLine(f *os.File) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	var tail []byte
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		i := bytes.LastIndexByte(buf[:n], '\n')
+		tail = append(bytes.Clone(buf[i+1:n]), tail...)
+		if i >= 0 {
+			break
+		}
+		end = start
+	}
+	if len(tail) == 0 {
+		return nil
+	}
+	if _, ok := decodeRecordLine(tail); ok {
+		_, err := f.Write([]byte{'\n'})
+		return err
+	}
+	return f.Truncate(size - int64(len(tail)))
+}
+
+// compactNoLock rewrites the file without records older than the
+// retention.
+func (s *IntervalStore) compactNoLock(now time.Time) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-s.retention)
+	src, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".intervals-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var oldest time.Time
+	w := bufio.NewWriter(tmp)
+	sc := bufio.NewScanner(src)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		rec, ok := decodeRecordLine(sc.Bytes())
+		if !ok || rec.Time.Before(cutoff) {
+			continue
+		}
+		if oldest.IsZero() {
+			oldest = rec.Time
+		}
+		w.Write(sc.Bytes())
+		w.WriteByte('\n')
+	}
+	err = errors.Join(sc.Err(), w.Flush(), tmp.Close())
+	if err != nil {
+		return err
+	}
+	// close before renaming, which fails on Windows while the file is open
+	s.file.Close()
+	renameErr := os.Rename(tmp.Name(), s.path)
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		s.file = nil
+		return errors.Join(renameErr, err)
+	}
+	s.file = f
+	if renameErr != nil {
+		return renameErr
+	}
+	s.oldest = oldest
+	return nil
+}
+
+// Close closes the store's file.
+func (s *IntervalStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,155 @@
+package timer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntervalStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intervals.jsonl")
+	s, err := OpenIntervalStore(path, 0)
+	if err != nil {
+		t.Fatalf("OpenIntervalStore failed: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		s.now = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+		err := s.Export(map[string]Snapshot{
+			"a": {Count: uint64(i + 1)},
+			"b": {Count: 10},
+		})
+		if err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+
+	recs, err := s.Query("a", start.Add(time.Minute), start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(recs) != 2 || recs[0].Count != 2 || recs[1].Count != 3 || !recs[1].Time.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("Unexpected records: %+v", recs)
+	}
+	if all, _ := s.Query("", start, start.Add(time.Minute)); len(all) != 2 {
+		t.Errorf("Expected both timers of the first interval, got %+v", all)
+	}
+	s.Close()
+	if _, err := s.Query("a", start, start); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+
+	// reopening keeps the history
+	s, err = OpenIntervalStore(path, 0)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer s.Close()
+	if recs, _ := s.Query("b", start, start.Add(time.Hour)); len(recs) != 3 {
+		t.Errorf("Expected 3 records after reopening, got %d", len(recs))
+	}
+}
+
+func TestIntervalStoreDamagedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intervals.jsonl")
+	good := `{"time":"2024-01-01T00:00:00Z","name":"a","count":1,"min_ns":1,"max_ns":1,"sum_ns":1}`
+	// a corrupted line in the middle and a write cut short by a crash
+	data := good + "\n" + "garbage\n" + good + "\n" + good[:30]
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenIntervalStore(path, 0)
+	if err != nil {
+		t.Fatalf("OpenIntervalStore failed on a damaged file: %v", err)
+	}
+	defer s.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return start.Add(time.Minute) }
+	if err := s.Export(map[string]Snapshot{"a": {Count: 2}}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	recs, err := s.Query("a", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(recs) != 3 || recs[2].Count != 2 {
+		t.Errorf("Expected the 3 valid records, got %+v", recs)
+	}
+
+	// a valid last line without its newline is kept
+	if err := os.WriteFile(path, []byte(good), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := OpenIntervalStore(path, 0)
+	if err != nil {
+		t.Fatalf("OpenIntervalStore failed: %v", err)
+	}
+	defer s2.Close()
+	s2.now = s.now
+	_ = s2.Export(map[string]Snapshot{"a": {Count: 2}})
+	if recs, _ := s2.Query("a", start, start.Add(time.Hour)); len(recs) != 2 {
+		t.Errorf("Expected the unterminated record and the new one, got %+v", recs)
+	}
+}
+
+func TestIntervalStoreRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "intervals.jsonl")
+	s, err := OpenIntervalStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenIntervalStore failed: %v", err)
+	}
+	defer s.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	export := func(at time.Duration) {
+		s.now = func() time.Time { return start.Add(at) }
+		if err := s.Export(map[string]Snapshot{"a": {Count: 1}}); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+	export(0)
+	export(30 * time.Minute)
+	export(70 * time.Minute)
+	if lines := countLines(t, path); lines != 3 {
+		t.Errorf("Expected no compaction within the grace period, got %d lines", lines)
+	}
+	export(80 * time.Minute)
+	if lines := countLines(t, path); lines != 3 {
+		t.Errorf("Expected expired record removed, got %d lines", lines)
+	}
+	s.now = func() time.Time { return start.Add(95 * time.Minute) }
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if lines := countLines(t, path); lines != 2 {
+		t.Errorf("Expected 2 records within the last hour, got %d lines", lines)
+	}
+	// appending continues after compaction
+	export(96 * time.Minute)
+	if lines := countLines(t, path); lines != 3 {
+		t.Errorf("Expected append after compaction, got %d lines", lines)
+	}
+}
+
+func TestRecordMarshalJSON(t *testing.T) {
+	rec := Record{Time: time.Unix(0, 0), Name: "x", Snapshot: Snapshot{Count: 1, Min: 2, Max: 3, Sum: 4}}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got Record
+	if err := json.Unmarshal(b, &got); err != nil || got.Name != "x" || got.Snapshot != rec.Snapshot || !got.Time.Equal(rec.Time) {
+		t.Errorf("Round trip of %s = %+v, %v", b, got, err)
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(b), "\n")
+}
@@ -0,0 +1,173 @@
+package timer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Producer publishes a keyed message, e.g. to a Kafka topic. Adapters for
+// client libraries are a few lines and keep their SDKs out of this package.
+// Every message gets its own key and value, so asynchronous clients may
+// keep them after Produce returns.
+type Producer interface {
+	Produce(ctx context.Context, key, value []byte) error
+}
+
+// ObservationEvent is a single observation streamed by ObservationStream.
+type ObservationEvent struct {
+	Timer    string
+	Time     time.Time
+	Duration time.Duration
+}
+
+// AppendJSON appends the event as a JSON object with the fields "timer",
+// "time" (RFC 3339) and "duration_ns" to b.
+func (ev ObservationEvent) AppendJSON(b []byte) []byte {
+	b = append(b, `{"timer":`...)
+	b = appendJSONString(b, ev.Timer)
+	b = append(b, `,"time":"`...)
+	b = ev.Time.UTC().AppendFormat(b, time.RFC3339Nano)
+	b = append(b, `","duration_ns":`...)
+	b = strconv.AppendInt(b, int64(ev.Duration), 10)
+	return append(b, '}')
+}
+
+// ObservationStream streams a sample of individual observations of
+// attached timers to a Producer, keyed by timer name, so exact percentiles
+// can be computed offline, for example in a data warehouse.
+//
+// Observations are buffered and produced by a background goroutine, so
+// a slow producer never blocks Observe; observations arriving while the
+// buffer is full are dropped and counted.
+type ObservationStream struct {
+	producer Producer
+	aggName  string
+	rate     atomic.Uint64 // math.Float64bits of the sample rate
+	events   chan ObservationEvent
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+	lc       lifecycle
+
+	mutex    sync.Mutex
+	attached []*Timer // detached on Close
+}
+
+// NewObservationStream creates an ObservationStream sampling observations
+// with probability rate (1 streams all of them) into a buffer of size
+// buffer.
+func NewObservationStream(p Producer, rate float64, buffer int) *ObservationStream {
+	s := &ObservationStream{
+		producer: p,
+		events:   make(chan ObservationEvent, max(buffer, 1)),
+	}
+	s.aggName = fmt.Sprintf("timer.ObservationStream(%p)", s)
+	s.SetSampleRate(rate)
+	return s
+}
+
+// SetSampleRate changes the probability of streaming an observation,
+// also while running, e.g. to raise fidelity during an incident.
+func (s *ObservationStream) SetSampleRate(rate float64) {
+	s.rate.Store(math.Float64bits(rate))
+}
+
+// SampleRate returns the probability of streaming an observation.
+func (s *ObservationStream) SampleRate() float64 {
+	return math.Float64frombits(s.rate.Load())
+}
+
+// Attach streams the observations of t under name from now on, until
+// Close. A timer can feed several streams.
+func (s *ObservationStream) Attach(name string, t *Timer) error {
+	if err := t.AddAggregator(s.aggName, streamTap{s, name}); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.attached = append(s.attached, t)
+	return nil
+}
+
+// streamTap feeds a timer's observations into an ObservationStream.
+type streamTap struct {
+	stream *ObservationStream
+	name   string
+}
+
+func (tap streamTap) Observe(d time.Duration) { tap.stream.offer(tap.name, d) }
+func (tap streamTap) Snapshot() any           { return nil }
+func (tap streamTap) Reset()                  {}
+func (tap streamTap) Merge(Aggregator) error  { return nil }
+
+func (s *ObservationStream) offer(name string, d time.Duration) {
+	if rate := s.SampleRate(); rate < 1 && rand.Float64() >= rate {
+		return
+	}
+	select {
+	case s.events <- ObservationEvent{Timer: name, Time: time.Now(), Duration: d}:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Start begins producing buffered observations until ctx is done or Close
+// is called.
+func (s *ObservationStream) Start(ctx context.Context) error {
+	return s.lc.start(ctx, s, s.run)
+}
+
+func (s *ObservationStream) run(ctx context.Context) {
+	produce := func(ctx context.Context, ev ObservationEvent) {
+		if err := s.producer.Produce(ctx, []byte(ev.Timer), ev.AppendJSON(nil)); err != nil {
+			s.failed.Add(1)
+		}
+	}
+	for {
+		select {
+		case ev := <-s.events:
+			produce(ctx, ev)
+		case <-ctx.Done():
+			// drain what was buffered before stopping
+			ctx = context.WithoutCancel(ctx)
+			for {
+				select {
+				case ev := <-s.events:
+					produce(ctx, ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Dropped returns how many sampled observations were dropped because the
+// buffer was full.
+func (s *ObservationStream) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Failed returns how many observations the producer failed to publish.
+func (s *ObservationStream) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// Close detaches the stream from its timers and stops it after producing
+// the buffered observations.
+func (s *ObservationStream) Close() error {
+	s.mutex.Lock()
+	attached := s.attached
+	s.attached = nil
+	s.mutex.Unlock()
+	for _, t := range attached {
+		t.RemoveAggregator(s.aggName)
+	}
+	s.lc.close()
+	return nil
+}
@@ -0,0 +1,160 @@
+package timer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingProducer struct {
+	mutex  sync.Mutex
+	keys   []string
+	values []string
+	fail   bool
+}
+
+func (p *recordingProducer) Produce(ctx context.Context, key, value []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.keys = append(p.keys, string(key))
+	p.values = append(p.values, string(value))
+	return nil
+}
+
+func TestObservationStream(t *testing.T) {
+	defer checkNoLeaks(t)
+	p := &recordingProducer{}
+	s := NewObservationStream(p, 1, 16)
+	timer := NewTimer()
+	if err := s.Attach("db.query", timer); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	timer.Observe(3 * time.Millisecond)
+	timer.Observe(5 * time.Millisecond)
+	s.Close()
+
+	if len(p.keys) != 2 || p.keys[0] != "db.query" {
+		t.Fatalf("Expected 2 events keyed by timer name, got %v", p.keys)
+	}
+	var ev struct {
+		Timer      string    `json:"timer"`
+		Time       time.Time `json:"time"`
+		DurationNS int64     `json:"duration_ns"`
+	}
+	if err := json.Unmarshal([]byte(p.values[1]), &ev); err != nil {
+		t.Fatalf("Invalid event JSON %q: %v", p.values[1], err)
+	}
+	if ev.Timer != "db.query" || ev.DurationNS != int64(5*time.Millisecond) || ev.Time.IsZero() {
+		t.Errorf("Unexpected event: %+v", ev)
+	}
+}
+
+func TestObservationStreamSamplingAndDrops(t *testing.T) {
+	p := &recordingProducer{}
+	none := NewObservationStream(p, 0, 1)
+	timer := NewTimer()
+	_ = none.Attach("x", timer)
+	timer.Observe(time.Millisecond)
+	if len(none.events) != 0 {
+		t.Errorf("Expected rate 0 to sample nothing")
+	}
+
+	// not started, so the buffer of one fills up
+	s := NewObservationStream(p, 1, 1)
+	other := NewTimer()
+	_ = s.Attach("y", other)
+	for range 3 {
+		other.Observe(time.Millisecond)
+	}
+	if s.Dropped() != 2 {
+		t.Errorf("Dropped = %d; want 2", s.Dropped())
+	}
+	if other.Count() != 3 {
+		t.Errorf("Expected drops not to affect timer stats, got count %d", other.Count())
+	}
+}
+
+func TestObservationStreamProducerFailure(t *testing.T) {
+	p := &recordingProducer{fail: true}
+	s := NewObservationStream(p, 1, 4)
+	timer := NewTimer()
+	_ = s.Attach("x", timer)
+	timer.Observe(time.Millisecond)
+	_ = s.Start(context.Background())
+	s.Close()
+	if s.Failed() != 1 {
+		t.Errorf("Failed = %d; want 1", s.Failed())
+	}
+}
+
+func TestObservationStreamSetSampleRate(t *testing.T) {
+	s := NewObservationStream(&recordingProducer{}, 0, 4)
+	timer := NewTimer()
+	_ = s.Attach("x", timer)
+	timer.Observe(time.Millisecond)
+	s.SetSampleRate(1)
+	if s.SampleRate() != 1 {
+		t.Errorf("SampleRate = %v; want 1", s.SampleRate())
+	}
+	timer.Observe(time.Millisecond)
+	if len(s.events) != 1 {
+		t.Errorf("Expected only the observation after raising the rate to be sampled, got %d", len(s.events))
+	}
+}
+
+// retainingProducer keeps the values it is given without copying them, as
+// asynchronous clients do.
+type retainingProducer struct {
+	mutex  sync.Mutex
+	values [][]byte
+}
+
+func (p *retainingProducer) Produce(ctx context.Context, key, value []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.values = append(p.values, value)
+	return nil
+}
+
+func TestObservationStreamRetainedValues(t *testing.T) {
+	defer checkNoLeaks(t)
+	p1, p2 := &retainingProducer{}, &retainingProducer{}
+	s1, s2 := NewObservationStream(p1, 1, 16), NewObservationStream(p2, 1, 16)
+	timer := NewTimer()
+	if err := s1.Attach("a", timer); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if err := s2.Attach("b", timer); err != nil {
+		t.Fatalf("Expected a second stream on the same timer, got %v", err)
+	}
+	for range 3 {
+		timer.Observe(time.Millisecond)
+	}
+	timer.Observe(time.Second)
+	_ = s1.Start(context.Background())
+	_ = s2.Start(context.Background())
+	s1.Close()
+	s2.Close()
+
+	if len(p1.values) != 4 || len(p2.values) != 4 {
+		t.Fatalf("Expected 4 events per stream, got %d and %d", len(p1.values), len(p2.values))
+	}
+	var ev struct {
+		DurationNS int64 `json:"duration_ns"`
+	}
+	if err := json.Unmarshal(p1.values[0], &ev); err != nil || ev.DurationNS != int64(time.Millisecond) {
+		t.Errorf("Expected the first retained value intact, got %q", p1.values[0])
+	}
+	if len(timer.AggregatorSnapshots()) != 0 {
+		t.Errorf("Expected Close to detach the streams, got %v", timer.AggregatorSnapshots())
+	}
+}
@@ -0,0 +1,70 @@
+package timer
+
+import "time"
+
+// TaskRunner runs tasks asynchronously. golang.org/x/sync/errgroup.Group
+// implements it, as do most worker pools after a small adapter.
+type TaskRunner interface {
+	Go(f func() error)
+}
+
+// TaskTimer times tasks submitted to a TaskRunner or worker pool. It
+// records how long each task waited between submission and the start of
+// its execution separately from how long it executed, in a TimerVec with
+// the labels "task" and "phase" ("wait" or "exec"):
+//
+//	tt := timer.NewTaskTimer()
+//	_ = timer.DefaultRegistry.RegisterVec("tasks", tt.Vec())
+//	var g errgroup.Group
+//	g.SetLimit(4)
+//	tt.Go(&g, "resize", func() error { return resize(img) })
+type TaskTimer struct {
+	vec *TimerVec
+}
+
+// Phase label values of a TaskTimer.
+const (
+	PhaseWait = "wait"
+	PhaseExec = "exec"
+)
+
+// NewTaskTimer creates a TaskTimer.
+func NewTaskTimer() *TaskTimer {
+	return &TaskTimer{vec: NewTimerVec("task", "phase")}
+}
+
+// Vec returns the TimerVec tasks are recorded in, for registration in a
+// Registry.
+func (tt *TaskTimer) Vec() *TimerVec {
+	return tt.vec
+}
+
+// Wrap returns a function running f that records the time from the call
+// to Wrap until it starts as wait time and the run of f as execution time
+// of task. Submit the result to a pool right after wrapping.
+func (tt *TaskTimer) Wrap(task string, f func() error) func() error {
+	wait, exec := tt.vec.WithLabelValues(task, PhaseWait), tt.vec.WithLabelValues(task, PhaseExec)
+	submitted := time.Now()
+	return func() error {
+		start := time.Now()
+		wait.Observe(max(start.Sub(submitted), 0))
+		defer func() { exec.Observe(max(time.Since(start), 0)) }()
+		return f()
+	}
+}
+
+// WrapFunc is like Wrap for tasks that return no error, as submitted to
+// channel-based worker pools.
+func (tt *TaskTimer) WrapFunc(task string, f func()) func() {
+	wrapped := tt.Wrap(task, func() error {
+		f()
+		return nil
+	})
+	return func() { wrapped() }
+}
+
+// Go submits f to r as task. With an errgroup.Group limited by SetLimit,
+// the time Go blocks waiting for a free slot counts as wait time.
+func (tt *TaskTimer) Go(r TaskRunner, task string, f func() error) {
+	r.Go(tt.Wrap(task, f))
+}
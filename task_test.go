@@ -0,0 +1,78 @@
+package timer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// limitedGroup is a minimal errgroup-like runner executing one task at a
+// time.
+type limitedGroup struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+	err error
+	mu  sync.Mutex
+}
+
+func (g *limitedGroup) Go(f func() error) {
+	g.sem <- struct{}{}
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			<-g.sem
+			g.wg.Done()
+		}()
+		if err := f(); err != nil {
+			g.mu.Lock()
+			g.err = err
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func TestTaskTimerGo(t *testing.T) {
+	tt := NewTaskTimer()
+	g := &limitedGroup{sem: make(chan struct{}, 1)}
+	errBoom := errors.New("boom")
+	for range 3 {
+		tt.Go(g, "sleep", func() error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		})
+	}
+	tt.Go(g, "fail", func() error { return errBoom })
+	g.wg.Wait()
+	if g.err != errBoom {
+		t.Errorf("Expected task error to reach the runner, got %v", g.err)
+	}
+
+	exec := tt.Vec().WithLabelValues("sleep", PhaseExec)
+	wait := tt.Vec().WithLabelValues("sleep", PhaseWait)
+	if exec.Count() != 3 || exec.Min() < 5*time.Millisecond {
+		t.Errorf("Unexpected exec stats: %v", exec)
+	}
+	// only one runs at a time, so the last waited for the two before it
+	if wait.Count() != 3 || wait.Max() < 5*time.Millisecond {
+		t.Errorf("Unexpected wait stats: %v", wait)
+	}
+	if tt.Vec().WithLabelValues("fail", PhaseExec).Count() != 1 {
+		t.Errorf("Expected failed task to be timed")
+	}
+}
+
+func TestTaskTimerWrapFunc(t *testing.T) {
+	tt := NewTaskTimer()
+	jobs := make(chan func(), 1)
+	jobs <- tt.WrapFunc("job", func() {})
+	time.Sleep(2 * time.Millisecond)
+	(<-jobs)()
+
+	if w := tt.Vec().WithLabelValues("job", PhaseWait); w.Count() != 1 || w.Max() < 2*time.Millisecond {
+		t.Errorf("Expected queue wait >= 2ms, got %v", w)
+	}
+	if tt.Vec().WithLabelValues("job", PhaseExec).Count() != 1 {
+		t.Errorf("Expected one execution")
+	}
+}
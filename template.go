@@ -0,0 +1,62 @@
+package timer
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FuncMap returns functions for text/template and html/template reports
+// over r, to be installed with Template.Funcs:
+//
+//   - timerSnapshot NAME returns the Snapshot of the named timer or vec
+//     child, and fails if there is none.
+//   - timerSnapshots returns the snapshots of all timers by name, which
+//     range visits in name order.
+//   - formatDuration D returns D rounded to three significant digits,
+//     e.g. "1.23ms".
+//   - percentile NAME Q returns the Q-quantile (0 <= Q <= 1) of the named
+//     timer, see Timer.Quantile, and fails if it has no quantile
+//     aggregator.
+//
+// For example:
+//
+//	{{range $name, $s := timerSnapshots}}{{$name}}: {{formatDuration $s.Mean}}
+//	{{end}}
+func FuncMap(r *Registry) map[string]any {
+	return map[string]any{
+		"timerSnapshot": func(name string) (Snapshot, error) {
+			if t := r.Get(name); t != nil {
+				return t.Snapshot(), nil
+			}
+			if s, ok := r.Snapshot()[name]; ok {
+				return s, nil
+			}
+			return Snapshot{}, fmt.Errorf("timer %q not registered", name)
+		},
+		"timerSnapshots": r.Snapshot,
+		"formatDuration": formatDuration,
+		"percentile": func(name string, q float64) (time.Duration, error) {
+			t := r.Get(name)
+			if t == nil {
+				return 0, fmt.Errorf("timer %q not registered", name)
+			}
+			d, ok := t.Quantile(q)
+			if !ok {
+				return 0, fmt.Errorf("timer %q has no quantile aggregator", name)
+			}
+			return d, nil
+		},
+	}
+}
+
+// formatDuration returns d rounded to three significant digits.
+func formatDuration(d time.Duration) string {
+	abs := d.Abs()
+	if abs < 1000 {
+		return d.String()
+	}
+	// round to the unit three digits below the leading one
+	unit := time.Duration(math.Pow10(int(math.Log10(float64(abs))) - 2))
+	return d.Round(unit).String()
+}
@@ -0,0 +1,50 @@
+package timer
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestFuncMap(t *testing.T) {
+	r := NewRegistry()
+	db := r.GetOrCreate("db")
+	db.AddAggregator("hist", NewExpHistogram(160))
+	db.Observe(1234567 * time.Nanosecond)
+	r.GetOrCreate("cache").Observe(time.Microsecond)
+
+	tmpl := template.Must(template.New("report").Funcs(FuncMap(r)).Parse(
+		`{{range $name, $s := timerSnapshots}}{{$name}}={{formatDuration $s.Mean}} {{end}}` +
+			`db.count={{(timerSnapshot "db").Count}} p50={{formatDuration (percentile "db" 0.5)}}`))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "cache=1µs db=1.23ms db.count=1 p50=1.23ms"
+	if b.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.String())
+	}
+
+	for _, text := range []string{`{{timerSnapshot "missing"}}`, `{{percentile "cache" 0.5}}`} {
+		tmpl := template.Must(template.New("").Funcs(FuncMap(r)).Parse(text))
+		if err := tmpl.Execute(&b, nil); err == nil {
+			t.Errorf("Expected %s to fail", text)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                         "0s",
+		999:                       "999ns",
+		1234567:                   "1.23ms",
+		-1234567:                  "-1.23ms",
+		time.Hour + 5*time.Minute: "1h5m0s",
+		1999 * time.Millisecond:   "2s",
+	} {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%d) = %q; want %q", d, got, want)
+		}
+	}
+}
@@ -1,16 +1,25 @@
 // Package timer provides a concurrent-safe utility for tracking execution durations
 // and calculating statistics like min, max, and mean times.
+//
+// The package builds for every Go platform, including GOOS=js and wasip1.
+// OS-specific exporters degrade on platforms that lack the facility they
+// need: ListenUnix, OpenSharedRegistry, and NewTraceEmitter return
+// errors.ErrUnsupported, and FlushOnSignal is a no-op on WebAssembly.
 package timer
 
 import (
-	"fmt"
+	"errors"
 	"math"
+	"math/big"
 	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrZeroTime is returned when a timer is updated with a zero time value.
+var ErrZeroTime = errors.New("cannot update timer with zero time value")
+
 // Timer tracks execution durations with thread-safe statistics collection.
 // All methods are safe for concurrent use.
 type Timer struct {
@@ -20,25 +29,88 @@ type Timer struct {
 	min   time.Duration // Minimum observed duration
 	// Total sum of all durations in nanoseconds (may be capped at MaxInt64)
 	totalSum int64
-	// Indicates if totalSum reached MaxInt64 and was capped
+	// Indicates if totalSum overflowed, and was capped unless the overflow
+	// policy keeps the sum exact
 	sumOverflowed bool
+	// What to do when totalSum overflows, see SetOverflowPolicy
+	overflow OverflowPolicy
+	// Wraps of totalSum with OverflowWrap, or the exact sum with
+	// OverflowBig, and whether either is in use, for lock-free readers
+	sumEpoch uint64
+	bigSum   *big.Int
+	exact    atomic.Bool
+	// Budget utilization of observations made with a context deadline
+	deadline deadlineStats
+	// Number of observations whose operation panicked
+	panicked uint64
+	// Running stopwatches, and the most seen at once since the last Reset
+	inFlight    int64
+	maxInFlight int64
+	// When the timer was created or last reset, for throughput
+	since time.Time
+	// User aggregators fed with every observation, by name
+	aggregators map[string]Aggregator
+	// Queue wait and service time of EnqueueTokens, created on first use
+	queue *queueStats
+	// Turns recording into a no-op, see SetNoop
+	noop atomic.Bool
+	// Time source, nil for time.Now, see SetClock
+	clock Clock
+	// Set by Close, and what recording does afterwards
+	closed      atomic.Bool
+	closePolicy atomic.Int32
+	// What Update does with negative elapsed times, see SetNegativePolicy
+	negativePolicy atomic.Int32
+	// What Stop does when called again, and how often it was, see
+	// SetStopPolicy
+	stopPolicy     atomic.Int32
+	duplicateStops atomic.Uint64
+	// Lock-free copy of the statistics for readers, see SetSeqlock
+	seq seqStats
+	// Recent lifecycle events, oldest first, see Events
+	events []Event
 }
 
 // NewTimer creates a new Timer with initialized min/max values.
 func NewTimer() *Timer {
 	return &Timer{
-		max: 0,
-		min: time.Duration(math.MaxInt64),
+		max:   0,
+		min:   time.Duration(math.MaxInt64),
+		since: time.Now(),
 	}
 }
 
 // Observe records a duration in the timer statistics.
 // Thread-safe and can be called concurrently from multiple goroutines.
 func (t *Timer) Observe(d time.Duration) {
-	durNano := d.Nanoseconds()
+	if t.off() {
+		return
+	}
+	t.record(d)
+}
+
+// record takes the write lock and records d, skipping the checks of
+// Observe that its callers have already made.
+func (t *Timer) record(d time.Duration) {
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
+	if len(t.aggregators) != 0 {
+		// aggregators are user code and may panic, so unlock in a defer
+		defer t.mutex.Unlock()
+		t.observeNoLock(d)
+		return
+	}
+	t.observeNoLock(d)
+	t.mutex.Unlock()
+}
 
+// observeNoLock records a duration without acquiring a lock.
+// Callers must hold the write lock.
+func (t *Timer) observeNoLock(d time.Duration) {
+	durNano := d.Nanoseconds()
+	if t.overflow == OverflowReset && durNano > 0 && t.totalSum > math.MaxInt64-durNano {
+		t.logEventNoLock(EventOverflow)
+		t.resetNoLock()
+	}
 	if t.count == 0 {
 		t.min, t.max = d, d
 	} else {
@@ -50,31 +122,66 @@ func (t *Timer) Observe(d time.Duration) {
 		}
 	}
 
-	// cap at MaxInt64, set overflow flag if needed
-	if durNano > 0 && t.totalSum > math.MaxInt64-durNano {
-		t.totalSum = math.MaxInt64
-		t.sumOverflowed = true
-	} else if !t.sumOverflowed {
-		t.totalSum += durNano
-	}
+	t.addSumNoLock(durNano)
 
 	t.count++
+	t.publishNoLock()
+	if len(t.aggregators) == 0 {
+		// skip starting a map iteration on the hot path
+		return
+	}
+	for _, a := range t.aggregators {
+		a.Observe(d)
+	}
 }
 
 // Update calculates the duration since the provided start time and records it.
-// Returns an error if start is a zero time value.
-// The duration is clamped to non-negative values.
+// Returns ErrZeroTime if start is a zero time value, ErrClosed if the
+// timer was closed with the CloseError policy, and ErrFrozen if it is
+// frozen with SetNoop.
+// Negative durations are clamped to 0, or rejected with
+// ErrNegativeDuration under the NegativeError policy.
 func (t *Timer) Update(start time.Time) error {
 	if start.IsZero() {
-		return fmt.Errorf("cannot update timer with zero time value")
+		return ErrZeroTime
 	}
-	d := max(time.Since(start), 0)
-	t.Observe(d)
+	return t.recordElapsed(start, t.now())
+}
+
+// UpdateAt records the duration between start and now, letting replayed
+// logs, simulations, or batch jobs supply historical timestamps instead of
+// the wall clock.
+// Returns ErrZeroTime if start or now is a zero time value, ErrClosed if
+// the timer was closed with the CloseError policy, and ErrFrozen if it is
+// frozen with SetNoop.
+// Negative durations are clamped to 0, or rejected with
+// ErrNegativeDuration under the NegativeError policy.
+func (t *Timer) UpdateAt(start, now time.Time) error {
+	if start.IsZero() || now.IsZero() {
+		return ErrZeroTime
+	}
+	return t.recordElapsed(start, now)
+}
+
+// recordElapsed records now minus start for Update and UpdateAt, which
+// have checked both for zero values.
+func (t *Timer) recordElapsed(start, now time.Time) error {
+	if t.off() {
+		return t.offErr()
+	}
+	d, err := t.elapsed(start, now)
+	if err != nil {
+		return err
+	}
+	t.record(d)
 	return nil
 }
 
 // Count returns the number of observations recorded.
 func (t *Timer) Count() uint64 {
+	if t.seq.enabled.Load() {
+		return t.seq.load().count
+	}
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 	return t.count
@@ -83,6 +190,9 @@ func (t *Timer) Count() uint64 {
 // Max returns the maximum duration observed.
 // Returns 0 if no observations have been made.
 func (t *Timer) Max() time.Duration {
+	if t.seq.enabled.Load() {
+		return t.seq.load().max
+	}
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 	return t.max
@@ -91,6 +201,9 @@ func (t *Timer) Max() time.Duration {
 // Min returns the minimum duration observed.
 // Returns a very large value if no observations have been made.
 func (t *Timer) Min() time.Duration {
+	if t.seq.enabled.Load() {
+		return t.seq.load().min
+	}
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 	return t.min
@@ -104,6 +217,9 @@ func (t *Timer) meanNoLock() time.Duration {
 	if t.count == 0 {
 		return 0
 	}
+	if t.exact.Load() {
+		return time.Duration(t.exactMeanNoLock())
+	}
 	// add half a count to round and not floor
 	meanNano := (t.totalSum + int64(t.count)/2) / int64(t.count)
 	return time.Duration(meanNano)
@@ -113,6 +229,9 @@ func (t *Timer) meanNoLock() time.Duration {
 // Uses integer division with rounding to calculate the average.
 // Returns 0 if no observations have been made.
 func (t *Timer) Mean() time.Duration {
+	if t.seq.enabled.Load() && !t.exact.Load() {
+		return t.seq.load().mean()
+	}
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 	return t.meanNoLock()
@@ -122,15 +241,37 @@ func (t *Timer) Mean() time.Duration {
 func (t *Timer) Reset() {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
+	t.resetNoLock()
+}
+
+// resetNoLock clears all statistics.
+// Callers must hold the write lock.
+func (t *Timer) resetNoLock() {
 	t.count = 0
 	t.totalSum = 0
 	t.max = 0
 	t.min = time.Duration(math.MaxInt64)
 	t.sumOverflowed = false // Reset the flag
+	t.sumEpoch, t.bigSum = 0, nil
+	t.exact.Store(false)
+	t.deadline.reset()
+	t.panicked = 0
+	t.maxInFlight = t.inFlight // stopwatches still running are not reset
+	t.since = t.now()
+	t.publishNoLock()
+	t.logEventNoLock(EventReset)
+	for _, a := range t.aggregators {
+		a.Reset()
+	}
+	if t.queue != nil {
+		t.queue.wait.Reset()
+		t.queue.service.Reset()
+	}
 }
 
 // SumOverflowed returns true if the total sum of durations has exceeded
-// math.MaxInt64 nanoseconds, causing the mean to be an underestimate.
+// math.MaxInt64 nanoseconds, causing the mean to be an underestimate
+// unless the overflow policy keeps the sum exact.
 func (t *Timer) SumOverflowed() bool {
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
@@ -141,22 +282,37 @@ func (t *Timer) SumOverflowed() bool {
 // Format: "Count: X, Max: Xms, Min: Xms, Mean: Xms"
 // Includes an overflow indicator if applicable.
 func (t *Timer) String() string {
+	var buf [150]byte
+	return string(t.AppendString(buf[:0]))
+}
+
+// AppendString appends the String representation of the timer's statistics
+// to b and returns the extended buffer. It does not allocate if b has
+// enough capacity, which makes it suitable for allocation-sensitive logging.
+// Like Snapshot, it shows a Min of 0 for a timer without observations.
+func (t *Timer) AppendString(b []byte) []byte {
 	t.mutex.RLock()
-	defer t.mutex.RUnlock()
-	c, mx, mn, mean, overflowed := t.count, t.max, t.min, t.meanNoLock(), t.sumOverflowed
-
-	var sb strings.Builder
-	sb.Grow(150)
-	sb.WriteString("Count: ")
-	sb.WriteString(strconv.FormatUint(c, 10))
-	sb.WriteString(", Max: ")
-	sb.WriteString(mx.String())
-	sb.WriteString(", Min: ")
-	sb.WriteString(mn.String())
-	sb.WriteString(", Mean: ")
-	sb.WriteString(mean.String())
+	c, mx, mn, mean, overflowed := t.count, t.max, t.min, t.meanNoLock(), t.sumOverflowed && !t.exact.Load()
+	t.mutex.RUnlock()
+	if c == 0 {
+		mn = 0
+	}
+	return appendStats(b, c, mx, mn, mean, overflowed)
+}
+
+// appendStats appends the "Count: X, Max: Xms, Min: Xms, Mean: Xms" layout
+// shared by Timer and Snapshot.
+func appendStats(b []byte, c uint64, mx, mn, mean time.Duration, overflowed bool) []byte {
+	b = append(b, "Count: "...)
+	b = strconv.AppendUint(b, c, 10)
+	b = append(b, ", Max: "...)
+	b = append(b, mx.String()...)
+	b = append(b, ", Min: "...)
+	b = append(b, mn.String()...)
+	b = append(b, ", Mean: "...)
+	b = append(b, mean.String()...)
 	if overflowed {
-		sb.WriteString(" (sum overflowed, mean is approximate)")
+		b = append(b, " (sum overflowed, mean is approximate)"...)
 	}
-	return sb.String()
+	return b
 }
@@ -1,6 +1,7 @@
 package timer
 
 import (
+	"errors"
 	"math"
 	"strings"
 	"sync"
@@ -238,8 +239,12 @@ func TestSumOverflow(t *testing.T) {
 		t.Errorf("Expected SumOverflowed to be false for a new timer")
 	}
 
+	// Pin the clock so the durations are exact
+	clock := &fakeClock{t: time.Now()}
+	timer.SetClock(clock)
+
 	// Simulate a large duration that doesn't overflow yet
-	err := timer.Update(time.Now().Add(-time.Duration(math.MaxInt64 / 2)))
+	err := timer.Update(clock.t.Add(-time.Duration(math.MaxInt64 / 2)))
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -251,7 +256,7 @@ func TestSumOverflow(t *testing.T) {
 	}
 
 	// Simulate another large duration that causes overflow
-	err = timer.Update(time.Now().Add(-time.Duration(math.MaxInt64/2 + 1000))) // 1000ns more to ensure overflow
+	err = timer.Update(clock.t.Add(-time.Duration(math.MaxInt64/2 + 1000))) // 1000ns more to ensure overflow
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -265,7 +270,7 @@ func TestSumOverflow(t *testing.T) {
 
 	// Add another small duration, sum should remain capped
 	currentSum := timer.totalSum
-	err = timer.Update(time.Now().Add(-time.Nanosecond))
+	err = timer.Update(clock.t.Add(-time.Nanosecond))
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
@@ -293,6 +298,42 @@ func TestUpdateWithZeroTime(t *testing.T) {
 	}
 }
 
+func TestUpdateAt(t *testing.T) {
+	timer := NewTimer()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := timer.UpdateAt(start, start.Add(250*time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := timer.UpdateAt(start, start.Add(-time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if timer.Max() != 250*time.Millisecond {
+		t.Errorf("Expected max to be exactly 250ms, got %v", timer.Max())
+	}
+	if timer.Min() != 0 {
+		t.Errorf("Expected negative duration to be clamped to 0, got %v", timer.Min())
+	}
+
+	if err := timer.UpdateAt(start, time.Time{}); !errors.Is(err, ErrZeroTime) {
+		t.Errorf("Expected ErrZeroTime when now is a zero time value, got %v", err)
+	}
+	if err := timer.UpdateAt(time.Time{}, start); !errors.Is(err, ErrZeroTime) {
+		t.Errorf("Expected ErrZeroTime when start is a zero time value, got %v", err)
+	}
+	if timer.Count() != 2 {
+		t.Errorf("Expected count to be 2, got %d", timer.Count())
+	}
+
+	// the sum of historical durations is capped like that of Update
+	if err := timer.UpdateAt(start.Add(-time.Duration(math.MaxInt64)), start); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !timer.SumOverflowed() || timer.totalSum != math.MaxInt64 {
+		t.Errorf("Expected the sum to be capped at math.MaxInt64, got %d", timer.totalSum)
+	}
+}
+
 func TestUpdateWithNegativeDuration(t *testing.T) {
 	timer := NewTimer()
 	// time.Now() is later than start, so duration is positive
@@ -444,3 +485,10 @@ func BenchmarkTimerConcurrentUpdate(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkTimerObserve(b *testing.B) {
+	timer := NewTimer()
+	for b.Loop() {
+		timer.Observe(time.Microsecond)
+	}
+}
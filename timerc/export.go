@@ -0,0 +1,42 @@
+//go:build cgo
+
+package timerc
+
+/*
+#include "timer_types.h"
+*/
+import "C"
+
+//export gotimer_create
+func gotimer_create(name *C.char) C.uintptr_t {
+	return C.uintptr_t(create(C.GoString(name)))
+}
+
+//export gotimer_observe_ns
+func gotimer_observe_ns(h C.uintptr_t, ns C.int64_t) {
+	observe(uintptr(h), int64(ns))
+}
+
+//export gotimer_snapshot_get
+func gotimer_snapshot_get(h C.uintptr_t, out *C.gotimer_snapshot) C.int {
+	s, ok := snapshot(uintptr(h))
+	if !ok || out == nil {
+		return -1
+	}
+	out.count = C.uint64_t(s.Count)
+	out.min_ns = C.int64_t(s.MinNanos())
+	out.max_ns = C.int64_t(s.MaxNanos())
+	out.sum_ns = C.int64_t(s.SumNanos())
+	out.mean_ns = C.int64_t(s.MeanNanos())
+	out.panicked = C.uint64_t(s.Panicked)
+	out.sum_overflowed = 0
+	if s.SumOverflowed {
+		out.sum_overflowed = 1
+	}
+	return 0
+}
+
+//export gotimer_free
+func gotimer_free(h C.uintptr_t) {
+	handles.remove(uintptr(h))
+}
@@ -0,0 +1,83 @@
+// Package timerc exports a C API for timers, so C and C++ components
+// linked into a Go program can feed the same timers as the Go code.
+// Import it for its side effect and include timer.h in the C sources:
+//
+//	import _ "github.com/jnpr-pranav/go-timer/timerc"
+//
+//	#include "timer.h"
+//
+//	gotimer_handle h = gotimer_create("db.query");
+//	gotimer_observe_ns(h, elapsed_ns);
+//	gotimer_free(h);
+//
+// Timers created with a name are those of timer.DefaultRegistry. Handles
+// are plain integers rather than Go pointers, which C code must not keep.
+package timerc
+
+import (
+	"sync"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// handles maps the handles given out to C code to their timers.
+var handles = handleTable{timers: make(map[uintptr]*timer.Timer)}
+
+// handleTable is a table of timers by handle. Handles start at 1, so 0
+// is never valid.
+type handleTable struct {
+	mutex  sync.RWMutex
+	next   uintptr
+	timers map[uintptr]*timer.Timer
+}
+
+// add returns a new handle for t.
+func (h *handleTable) add(t *timer.Timer) uintptr {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.next++
+	h.timers[h.next] = t
+	return h.next
+}
+
+// get returns the timer of handle, or nil if it is not valid.
+func (h *handleTable) get(handle uintptr) *timer.Timer {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.timers[handle]
+}
+
+// remove invalidates handle.
+func (h *handleTable) remove(handle uintptr) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.timers, handle)
+}
+
+// create returns a handle for the timer registered in
+// timer.DefaultRegistry under name, creating it if needed, or for a new
+// unregistered timer if name is empty.
+func create(name string) uintptr {
+	if name == "" {
+		return handles.add(timer.NewTimer())
+	}
+	return handles.add(timer.DefaultRegistry.GetOrCreate(name))
+}
+
+// observe records ns nanoseconds in the timer of handle. Invalid handles
+// are ignored.
+func observe(handle uintptr, ns int64) {
+	if t := handles.get(handle); t != nil {
+		t.ObserveNanos(ns)
+	}
+}
+
+// snapshot returns the snapshot of the timer of handle.
+// Returns false if the handle is not valid.
+func snapshot(handle uintptr) (timer.Snapshot, bool) {
+	t := handles.get(handle)
+	if t == nil {
+		return timer.Snapshot{}, false
+	}
+	return t.Snapshot(), true
+}
@@ -0,0 +1,45 @@
+package timerc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestHandles(t *testing.T) {
+	h := create("timerc.test")
+	if h == 0 {
+		t.Fatalf("Expected a valid handle")
+	}
+	observe(h, int64(time.Millisecond))
+	// the timer is shared with Go code through the default registry
+	if n := timer.DefaultRegistry.Get("timerc.test").Count(); n != 1 {
+		t.Errorf("Expected 1 observation in the registry, got %d", n)
+	}
+	s, ok := snapshot(h)
+	if !ok || s.Count != 1 || s.Max != time.Millisecond {
+		t.Errorf("Unexpected snapshot %+v, %v", s, ok)
+	}
+
+	anon := create("")
+	if anon == h {
+		t.Errorf("Expected distinct handles")
+	}
+	observe(anon, 5)
+	if s, _ := snapshot(anon); s.Count != 1 {
+		t.Errorf("Expected an unregistered timer with 1 observation, got %+v", s)
+	}
+
+	handles.remove(h)
+	observe(h, 1) // ignored
+	if _, ok := snapshot(h); ok {
+		t.Errorf("Expected a freed handle to be invalid")
+	}
+	if _, ok := snapshot(0); ok {
+		t.Errorf("Expected handle 0 to be invalid")
+	}
+	if n := timer.DefaultRegistry.Get("timerc.test").Count(); n != 1 {
+		t.Errorf("Expected freeing to leave the timer alone, got %d observations", n)
+	}
+}
@@ -0,0 +1,29 @@
+#ifndef GOTIMER_H
+#define GOTIMER_H
+
+#include "timer_types.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// Returns a handle for the timer registered under name in the default
+// registry, creating it if needed, or for a new unregistered timer if name
+// is NULL or empty. Free it with gotimer_free.
+gotimer_handle gotimer_create(const char *name);
+
+// Records a duration in nanoseconds. Invalid handles are ignored.
+void gotimer_observe_ns(gotimer_handle h, int64_t ns);
+
+// Stores the statistics of the timer in out. Returns 0 on success and -1
+// if the handle is invalid or out is NULL.
+int gotimer_snapshot_get(gotimer_handle h, gotimer_snapshot *out);
+
+// Releases the handle. The timer stays registered and usable from Go.
+void gotimer_free(gotimer_handle h);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
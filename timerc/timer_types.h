@@ -0,0 +1,20 @@
+#ifndef GOTIMER_TYPES_H
+#define GOTIMER_TYPES_H
+
+#include <stdint.h>
+
+// A timer handle, 0 if invalid.
+typedef uintptr_t gotimer_handle;
+
+// The statistics of a timer, with durations in nanoseconds.
+typedef struct {
+	uint64_t count;
+	int64_t min_ns; // 0 if count is 0
+	int64_t max_ns;
+	int64_t sum_ns; // capped at INT64_MAX if sum_overflowed
+	int64_t mean_ns;
+	uint64_t panicked;
+	int sum_overflowed;
+} gotimer_snapshot;
+
+#endif
@@ -0,0 +1,287 @@
+// Package timerconfig builds timers, vecs, reporters, and exporters from a
+// declarative JSON configuration or environment variables, so
+// instrumentation can differ between environments without code changes.
+//
+// A configuration file looks like:
+//
+//	{
+//	  "prefix": "shop",
+//	  "timers": ["checkout", "db.query"],
+//	  "vecs": [{"name": "http", "labels": ["method", "route"], "limit": 500, "policy": "overflow"}],
+//	  "allow": ["shop.http*", "shop.checkout"],
+//	  "reporters": [{
+//	    "interval": "10s",
+//	    "exporters": [{"type": "emf", "namespace": "Shop"}, {"type": "file", "path": "/tmp/timers.json"}]
+//	  }]
+//	}
+package timerconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+	"github.com/jnpr-pranav/go-timer/timeremf"
+)
+
+// Duration is a time.Duration encoded in JSON as a string like "10s".
+type Duration = timer.DurationFlag
+
+// Config is a declarative instrumentation setup.
+type Config struct {
+	// Prefix scopes the configured names to a sub-registry.
+	Prefix string `json:"prefix,omitempty"`
+	// Timers are registered up front so they are exported before first use.
+	Timers []string `json:"timers,omitempty"`
+	// Vecs are registered with their labels and limits.
+	Vecs []VecConfig `json:"vecs,omitempty"`
+	// Allow and Deny are glob export rules as in timer.ExportRules,
+	// matched against full names including the prefix.
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	// Reporters periodically export the whole registry passed to Build.
+	Reporters []ReporterConfig `json:"reporters,omitempty"`
+}
+
+// VecConfig describes a TimerVec.
+type VecConfig struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	// Limit bounds the number of children, 0 for no limit.
+	Limit int `json:"limit,omitempty"`
+	// Policy is "lru" (default) or "overflow".
+	Policy string `json:"policy,omitempty"`
+}
+
+// ReporterConfig describes a Reporter and its exporters.
+type ReporterConfig struct {
+	Interval  Duration         `json:"interval"`
+	Exporters []ExporterConfig `json:"exporters"`
+	// Delta reports only timers that changed since the previous report.
+	Delta bool `json:"delta,omitempty"`
+}
+
+// ExporterConfig describes an exporter. Type selects it and determines
+// which other fields apply:
+//
+//	"json"      JSON lines to Path, or stdout if Path is empty or "-"
+//	"file"      the latest snapshot written to Path as a timer.Dump
+//	"http"      the snapshot POSTed to URL as a timer.Dump
+//	"emf"       CloudWatch EMF lines to stdout in Namespace
+//	"intervals" a timer.IntervalStore at Path keeping Retention
+type ExporterConfig struct {
+	Type      string   `json:"type"`
+	Path      string   `json:"path,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Retention Duration `json:"retention,omitempty"`
+}
+
+// Load reads a JSON configuration file. Unknown fields are errors, so
+// typos do not silently disable instrumentation.
+func Load(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+	var c Config
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// FromEnv builds a configuration from environment variables starting with
+// prefix, e.g. "TIMER_":
+//
+//	TIMER_CONFIG     path of a JSON file loaded first
+//	TIMER_PREFIX     overrides the name prefix
+//	TIMER_TIMERS     comma-separated timer names to add
+//	TIMER_INTERVAL   adds a reporter with this interval...
+//	TIMER_EXPORTERS  ...and these comma-separated exporters, each a type
+//	                 optionally followed by "=" and its path, URL, or
+//	                 namespace, e.g. "json,file=/tmp/t.json,emf=Shop"
+func FromEnv(prefix string) (Config, error) {
+	var c Config
+	if path := os.Getenv(prefix + "CONFIG"); path != "" {
+		var err error
+		if c, err = Load(path); err != nil {
+			return Config{}, err
+		}
+	}
+	if v, ok := os.LookupEnv(prefix + "PREFIX"); ok {
+		c.Prefix = v
+	}
+	c.Timers = append(c.Timers, splitList(os.Getenv(prefix+"TIMERS"))...)
+
+	interval, exporters := os.Getenv(prefix+"INTERVAL"), os.Getenv(prefix+"EXPORTERS")
+	if interval == "" && exporters == "" {
+		return c, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return Config{}, fmt.Errorf("%sINTERVAL: %w", prefix, err)
+	}
+	rc := ReporterConfig{Interval: Duration(d)}
+	for _, spec := range splitList(exporters) {
+		typ, arg, _ := strings.Cut(spec, "=")
+		ec := ExporterConfig{Type: typ}
+		switch typ {
+		case "http":
+			ec.URL = arg
+		case "emf":
+			ec.Namespace = arg
+		default:
+			ec.Path = arg
+		}
+		rc.Exporters = append(rc.Exporters, ec)
+	}
+	c.Reporters = append(c.Reporters, rc)
+	return c, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Setup is the instrumentation built from a Config.
+type Setup struct {
+	// Registry holds the configured timers and vecs, scoped to the
+	// prefix.
+	Registry *timer.Registry
+	// Vecs are the configured vecs by name.
+	Vecs map[string]*timer.TimerVec
+	// Reporters are the configured reporters, not yet started.
+	Reporters []*timer.Reporter
+
+	closers []func() error
+}
+
+// Build creates the configured timers, vecs, and reporters in r.
+func (c Config) Build(r *timer.Registry) (*Setup, error) {
+	export := r
+	if len(c.Allow) > 0 || len(c.Deny) > 0 {
+		var err error
+		if export, err = r.WithExportRules(timer.ExportRules{Allow: c.Allow, Deny: c.Deny}); err != nil {
+			return nil, err
+		}
+	}
+	if c.Prefix != "" {
+		r = r.SubRegistry(c.Prefix)
+	}
+	s := &Setup{Registry: r, Vecs: make(map[string]*timer.TimerVec)}
+	for _, name := range c.Timers {
+		r.GetOrCreate(name)
+	}
+	for _, vc := range c.Vecs {
+		v := timer.NewTimerVec(vc.Labels...)
+		switch vc.Policy {
+		case "", "lru":
+			v.SetLimit(vc.Limit, timer.EvictLRU)
+		case "overflow":
+			v.SetLimit(vc.Limit, timer.OverflowBucket)
+		default:
+			return nil, fmt.Errorf("vec %q: unknown policy %q", vc.Name, vc.Policy)
+		}
+		if err := r.RegisterVec(vc.Name, v); err != nil {
+			return nil, err
+		}
+		s.Vecs[vc.Name] = v
+	}
+	for i, rc := range c.Reporters {
+		if rc.Interval <= 0 {
+			s.Close()
+			return nil, fmt.Errorf("reporter %d: interval must be positive", i)
+		}
+		var exporters []timer.Exporter
+		for _, ec := range rc.Exporters {
+			e, err := s.exporter(ec)
+			if err != nil {
+				s.Close()
+				return nil, fmt.Errorf("reporter %d: %w", i, err)
+			}
+			exporters = append(exporters, e)
+		}
+		report := timer.ReportTo(func(err error) {
+			fmt.Fprintf(os.Stderr, "timerconfig: %v\n", err)
+		}, exporters...)
+		rp := timer.NewReporter(export, time.Duration(rc.Interval), report)
+		rp.SetDeltaMode(rc.Delta)
+		s.Reporters = append(s.Reporters, rp)
+	}
+	return s, nil
+}
+
+// exporter creates the exporter described by ec.
+func (s *Setup) exporter(ec ExporterConfig) (timer.Exporter, error) {
+	switch ec.Type {
+	case "json":
+		if ec.Path == "" || ec.Path == "-" {
+			return timer.NewJSONExporter(os.Stdout), nil
+		}
+		f, err := os.OpenFile(ec.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, f.Close)
+		return timer.NewJSONExporter(f), nil
+	case "file":
+		if ec.Path == "" {
+			return nil, errors.New("file exporter needs a path")
+		}
+		return timer.FileSink(ec.Path), nil
+	case "http":
+		if ec.URL == "" {
+			return nil, errors.New("http exporter needs a url")
+		}
+		return timer.HTTPSink(ec.URL), nil
+	case "emf":
+		return timeremf.NewExporter(os.Stdout, timeremf.Config{Namespace: ec.Namespace}), nil
+	case "intervals":
+		store, err := timer.OpenIntervalStore(ec.Path, time.Duration(ec.Retention))
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, store.Close)
+		return store, nil
+	}
+	return nil, fmt.Errorf("unknown exporter type %q", ec.Type)
+}
+
+// Start starts all reporters.
+func (s *Setup) Start(ctx context.Context) error {
+	for _, rp := range s.Reporters {
+		if err := rp.Start(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops all reporters, which report a final time, and closes the
+// files opened by exporters.
+func (s *Setup) Close() error {
+	var errs []error
+	for _, rp := range s.Reporters {
+		errs = append(errs, rp.Close())
+	}
+	for _, c := range s.closers {
+		errs = append(errs, c())
+	}
+	s.closers = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package timerconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "timers.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAndBuild(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.json")
+	path := writeConfig(t, `{
+		"prefix": "shop",
+		"timers": ["checkout", "internal"],
+		"vecs": [{"name": "http", "labels": ["method"], "limit": 1, "policy": "overflow"}],
+		"deny": ["shop.internal"],
+		"reporters": [{"interval": "1h", "exporters": [{"type": "file", "path": "`+filepath.ToSlash(out)+`"}]}]
+	}`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	root := timer.NewRegistry()
+	s, err := c.Build(root)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	s.Registry.GetOrCreate("checkout").Observe(time.Millisecond)
+	v := s.Vecs["http"]
+	v.WithLabelValues("GET").Observe(time.Millisecond)
+	v.WithLabelValues("POST").Observe(time.Millisecond)
+	if root.Get("shop.checkout") == nil {
+		t.Errorf("Expected timers under the prefix in the root registry")
+	}
+	if v.EvictedCount() != 1 {
+		t.Errorf("Expected vec limit with overflow policy, got %d evicted", v.EvictedCount())
+	}
+
+	// Close reports a final time
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected file exporter output: %v", err)
+	}
+	d, err := timer.ParseDump(b)
+	if err != nil {
+		t.Fatalf("Invalid output %q: %v", b, err)
+	}
+	snaps := d.Snapshots
+	if snaps["shop.checkout"].Count != 1 {
+		t.Errorf("Expected checkout in output, got %v", snaps)
+	}
+	if _, ok := snaps["shop.internal"]; ok {
+		t.Errorf("Expected denied timer to be filtered out")
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	if _, err := Load(writeConfig(t, `{"timer": ["typo"]}`)); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+	if _, err := Load(writeConfig(t, `{"reporters": [{"interval": "soon"}]}`)); err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	for name, c := range map[string]Config{
+		"policy":   {Vecs: []VecConfig{{Name: "v", Policy: "random"}}},
+		"interval": {Reporters: []ReporterConfig{{}}},
+		"exporter": {Reporters: []ReporterConfig{{Interval: Duration(time.Second), Exporters: []ExporterConfig{{Type: "carrier-pigeon"}}}}},
+		"file":     {Reporters: []ReporterConfig{{Interval: Duration(time.Second), Exporters: []ExporterConfig{{Type: "file"}}}}},
+	} {
+		if _, err := c.Build(timer.NewRegistry()); err == nil {
+			t.Errorf("%s: expected Build error", name)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	path := writeConfig(t, `{"prefix": "file", "timers": ["a"]}`)
+	t.Setenv("APP_TIMER_CONFIG", path)
+	t.Setenv("APP_TIMER_PREFIX", "env")
+	t.Setenv("APP_TIMER_TIMERS", "b, c")
+	t.Setenv("APP_TIMER_INTERVAL", "30s")
+	t.Setenv("APP_TIMER_EXPORTERS", "json,http=http://collector:8080/ingest,emf=Shop")
+
+	c, err := FromEnv("APP_TIMER_")
+	if err != nil {
+		t.Fatalf("FromEnv failed: %v", err)
+	}
+	if c.Prefix != "env" || strings.Join(c.Timers, ",") != "a,b,c" {
+		t.Errorf("Unexpected prefix or timers: %+v", c)
+	}
+	if len(c.Reporters) != 1 || time.Duration(c.Reporters[0].Interval) != 30*time.Second {
+		t.Fatalf("Unexpected reporters: %+v", c.Reporters)
+	}
+	ex := c.Reporters[0].Exporters
+	if len(ex) != 3 || ex[0].Type != "json" || ex[1].URL != "http://collector:8080/ingest" || ex[2].Namespace != "Shop" {
+		t.Errorf("Unexpected exporters: %+v", ex)
+	}
+
+	t.Setenv("APP_TIMER_INTERVAL", "often")
+	if _, err := FromEnv("APP_TIMER_"); err == nil {
+		t.Errorf("Expected error for invalid interval")
+	}
+}
@@ -0,0 +1,162 @@
+// Package timeremf exports timer snapshots in the AWS CloudWatch Embedded
+// Metric Format (EMF). Writing the JSON lines to stdout is enough for
+// Lambda and for ECS with FireLens to turn them into CloudWatch metrics,
+// without running an agent or importing the AWS SDK.
+package timeremf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+var _ timer.Exporter = (*Exporter)(nil)
+
+// maxDimensions is the CloudWatch limit of dimensions per metric.
+const maxDimensions = 30
+
+// Config configures an Exporter.
+type Config struct {
+	// Namespace is the CloudWatch namespace of all metrics.
+	Namespace string
+	// Dimensions are added to every metric, e.g. {"Service": "api"}.
+	Dimensions map[string]string
+	// DimensionName maps a vec label name to a dimension name. Returning
+	// false drops the label. If nil, labels are used unchanged.
+	DimensionName func(label string) (string, bool)
+}
+
+// Exporter writes one EMF JSON line per timer. Vec labels become
+// dimensions, and each timer yields the metrics "<name>.count" (Count) and
+// "<name>.mean", "<name>.min", "<name>.max" (Milliseconds).
+// It implements timer.Exporter.
+type Exporter struct {
+	mutex sync.Mutex
+	w     io.Writer
+	cfg   Config
+	now   func() time.Time
+}
+
+// NewExporter creates an Exporter writing to w, typically os.Stdout.
+func NewExporter(w io.Writer, cfg Config) *Exporter {
+	return &Exporter{w: w, cfg: cfg, now: time.Now}
+}
+
+type metricDirective struct {
+	Namespace  string       `json:"Namespace"`
+	Dimensions [][]string   `json:"Dimensions"`
+	Metrics    []metricInfo `json:"Metrics"`
+}
+
+type metricInfo struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type metadata struct {
+	Timestamp         int64             `json:"Timestamp"`
+	CloudWatchMetrics []metricDirective `json:"CloudWatchMetrics"`
+}
+
+// Export writes the snapshots, sorted by name, in a single Write call.
+// Timers with malformed labels, too many dimensions, or a dimension named
+// like one of their metrics or the "_aws" metadata key are reported in
+// the returned error and skipped.
+func (e *Exporter) Export(snaps map[string]timer.Snapshot) error {
+	if e.w == nil {
+		return errors.New("timeremf: Exporter has no writer")
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	ts := e.now().UnixMilli()
+
+	var out []byte
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(snaps)) {
+		line, err := e.line(name, snaps[name], ts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(append(out, line...), '\n')
+	}
+	if len(out) > 0 {
+		if _, err := e.w.Write(out); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("timeremf: %d timers skipped: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// line encodes one timer as an EMF object.
+func (e *Exporter) line(name string, s timer.Snapshot, ts int64) ([]byte, error) {
+	base, labels, ok := timer.SplitName(name)
+	if !ok {
+		return nil, fmt.Errorf("malformed timer name %q", name)
+	}
+
+	metrics := []metricInfo{
+		{base + ".count", "Count"},
+		{base + ".mean", "Milliseconds"},
+		{base + ".min", "Milliseconds"},
+		{base + ".max", "Milliseconds"},
+	}
+	// dimensions share the root object with the metrics and metadata
+	reserved := func(dim string) bool {
+		return dim == "_aws" || slices.ContainsFunc(metrics, func(m metricInfo) bool { return m.Name == dim })
+	}
+
+	root := make(map[string]any)
+	for dim, value := range e.cfg.Dimensions {
+		root[dim] = value
+	}
+	for _, l := range labels {
+		dim := l.Name
+		if e.cfg.DimensionName != nil {
+			if dim, ok = e.cfg.DimensionName(l.Name); !ok {
+				continue
+			}
+		}
+		root[dim] = l.Value
+	}
+	dims := slices.Sorted(maps.Keys(root))
+	if len(dims) > maxDimensions {
+		return nil, fmt.Errorf("timer %q has %d dimensions, more than %d", name, len(dims), maxDimensions)
+	}
+	if i := slices.IndexFunc(dims, reserved); i >= 0 {
+		return nil, fmt.Errorf("timer %q has dimension %q colliding with a metric or metadata key", name, dims[i])
+	}
+
+	root[metrics[0].Name] = s.Count
+	root[metrics[1].Name] = millis(s.Mean())
+	root[metrics[2].Name] = millis(s.Min)
+	root[metrics[3].Name] = millis(s.Max)
+
+	dimSets := [][]string{}
+	if len(dims) > 0 {
+		dimSets = append(dimSets, dims)
+	}
+	root["_aws"] = metadata{
+		Timestamp: ts,
+		CloudWatchMetrics: []metricDirective{{
+			Namespace:  e.cfg.Namespace,
+			Dimensions: dimSets,
+			Metrics:    metrics,
+		}},
+	}
+	return json.Marshal(root)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
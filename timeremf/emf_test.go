@@ -0,0 +1,100 @@
+package timeremf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestExporter(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewExporter(&buf, Config{
+		Namespace:  "shop",
+		Dimensions: map[string]string{"Service": "api"},
+		DimensionName: func(label string) (string, bool) {
+			return strings.ToUpper(label[:1]) + label[1:], label != "method"
+		},
+	})
+	e.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	r := timer.NewRegistry()
+	v := timer.NewTimerVec("method", "route")
+	_ = r.RegisterVec("http", v)
+	v.WithLabelValues("GET", "/cart").Observe(3 * time.Millisecond)
+	v.WithLabelValues("GET", "/cart").Observe(5 * time.Millisecond)
+
+	if err := e.Export(r.Snapshot()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", buf.String(), err)
+	}
+	if got["Service"] != "api" || got["Route"] != "/cart" || got["method"] != nil || got["Method"] != nil {
+		t.Errorf("Unexpected dimensions in %v", got)
+	}
+	if got["http.count"] != 2.0 || got["http.mean"] != 4.0 || got["http.min"] != 3.0 || got["http.max"] != 5.0 {
+		t.Errorf("Unexpected metric values in %v", got)
+	}
+
+	meta := got["_aws"].(map[string]any)
+	if meta["Timestamp"] != 1700000000000.0 {
+		t.Errorf("Timestamp = %v", meta["Timestamp"])
+	}
+	directive := meta["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	if directive["Namespace"] != "shop" {
+		t.Errorf("Namespace = %v", directive["Namespace"])
+	}
+	dims, _ := json.Marshal(directive["Dimensions"])
+	if string(dims) != `[["Route","Service"]]` {
+		t.Errorf("Dimensions = %s", dims)
+	}
+	if metrics := directive["Metrics"].([]any); len(metrics) != 4 {
+		t.Errorf("Expected 4 metrics, got %v", metrics)
+	}
+}
+
+func TestExporterSkipsMalformed(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewExporter(&buf, Config{Namespace: "ns"})
+	err := e.Export(map[string]timer.Snapshot{
+		"ok":       {Count: 1, Min: time.Millisecond, Max: time.Millisecond, Sum: time.Millisecond},
+		`bad{x=1}`: {Count: 1},
+	})
+	if err == nil {
+		t.Errorf("Expected error for malformed name")
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 1 {
+		t.Errorf("Expected the valid timer to be written, got %d lines", lines)
+	}
+	if !strings.Contains(buf.String(), `"Dimensions":[]`) {
+		t.Errorf("Expected an empty dimension list, got %s", buf.String())
+	}
+}
+
+func TestExporterRejectsReservedDimensions(t *testing.T) {
+	for _, snaps := range []map[string]timer.Snapshot{
+		{`db{_aws="x"}`: {Count: 1}},
+		{`db{db.count="x"}`: {Count: 1}},
+	} {
+		var buf bytes.Buffer
+		if err := NewExporter(&buf, Config{Namespace: "ns"}).Export(snaps); err == nil || buf.Len() != 0 {
+			t.Errorf("Expected %v to be rejected, got %v and %q", snaps, err, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	e := NewExporter(&buf, Config{Namespace: "ns", Dimensions: map[string]string{"_aws": "x"}})
+	if err := e.Export(map[string]timer.Snapshot{"db": {Count: 1}}); err == nil {
+		t.Errorf("Expected a configured _aws dimension to be rejected")
+	}
+
+	if err := NewExporter(nil, Config{}).Export(map[string]timer.Snapshot{"db": {Count: 1}}); err == nil {
+		t.Errorf("Expected error for a nil writer")
+	}
+}
@@ -0,0 +1,242 @@
+// Package timergcm exports timer snapshots to Google Cloud Monitoring as
+// gauge time series, for GKE and Cloud Run users who don't run Prometheus.
+// It talks to the Cloud Monitoring REST API and the metadata server
+// directly, so it adds no dependencies.
+package timergcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+var _ timer.Exporter = (*Exporter)(nil)
+
+const (
+	// DefaultEndpoint is the Cloud Monitoring API endpoint.
+	DefaultEndpoint = "https://monitoring.googleapis.com"
+	// DefaultMetricPrefix is prepended to metric types.
+	DefaultMetricPrefix = "custom.googleapis.com/timer/"
+	// maxSeriesPerRequest is the API limit of time series per request.
+	maxSeriesPerRequest = 200
+	// exportTimeout bounds an Export call.
+	exportTimeout = 30 * time.Second
+)
+
+// Config configures an Exporter. Only Resource is required.
+type Config struct {
+	// Resource is the monitored resource, usually from DetectResource.
+	// Its "project_id" label is the project written to.
+	Resource Resource
+	// MetricPrefix defaults to DefaultMetricPrefix.
+	MetricPrefix string
+	// Endpoint defaults to DefaultEndpoint.
+	Endpoint string
+	// Token returns an OAuth2 access token. It defaults to the token of
+	// the default service account from the metadata server.
+	Token func(ctx context.Context) (string, error)
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Exporter writes each timer as the double gauges "<prefix><name>/mean",
+// "/min" and "/max" in milliseconds and the int64 gauge "/count". Vec
+// labels become metric labels. It implements timer.Exporter.
+type Exporter struct {
+	cfg    Config
+	tokens *metadataTokens
+	now    func() time.Time
+}
+
+// NewExporter creates an Exporter.
+func NewExporter(cfg Config) (*Exporter, error) {
+	if cfg.Resource.Labels["project_id"] == "" {
+		return nil, errors.New("timergcm: resource has no project_id label")
+	}
+	if cfg.MetricPrefix == "" {
+		cfg.MetricPrefix = DefaultMetricPrefix
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	e := &Exporter{cfg: cfg, now: time.Now}
+	if cfg.Token == nil {
+		e.tokens = &metadataTokens{md: metadataClient{client: &http.Client{Timeout: metadataTimeout}}}
+		e.cfg.Token = e.tokens.token
+	}
+	return e, nil
+}
+
+type timeSeries struct {
+	Metric     metric   `json:"metric"`
+	Resource   Resource `json:"resource"`
+	MetricKind string   `json:"metricKind"`
+	ValueType  string   `json:"valueType"`
+	Points     []point  `json:"points"`
+}
+
+type metric struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type point struct {
+	Interval struct {
+		EndTime string `json:"endTime"`
+	} `json:"interval"`
+	Value value `json:"value"`
+}
+
+type value struct {
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	Int64Value  *string  `json:"int64Value,omitempty"`
+}
+
+// Export writes the snapshots, in batches of up to 200 time series.
+// Timers with malformed names are skipped and reported in the error.
+func (e *Exporter) Export(snaps map[string]timer.Snapshot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	end := e.now().UTC().Format(time.RFC3339Nano)
+	var series []timeSeries
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(snaps)) {
+		ts, err := e.series(name, snaps[name], end)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		series = append(series, ts...)
+	}
+	for batch := range slices.Chunk(series, maxSeriesPerRequest) {
+		if err := e.create(ctx, batch); err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// series converts one timer into its time series.
+func (e *Exporter) series(name string, s timer.Snapshot, end string) ([]timeSeries, error) {
+	base, labels, ok := timer.SplitName(name)
+	if !ok {
+		return nil, fmt.Errorf("timergcm: malformed timer name %q", name)
+	}
+	var metricLabels map[string]string
+	for _, l := range labels {
+		if metricLabels == nil {
+			metricLabels = make(map[string]string, len(labels))
+		}
+		metricLabels[sanitize(strings.ToLower(l.Name))] = l.Value
+	}
+
+	count := fmt.Sprint(s.Count)
+	newSeries := func(suffix, valueType string, v value) timeSeries {
+		ts := timeSeries{
+			Metric:     metric{Type: e.cfg.MetricPrefix + sanitize(base) + "/" + suffix, Labels: metricLabels},
+			Resource:   e.cfg.Resource,
+			MetricKind: "GAUGE",
+			ValueType:  valueType,
+			Points:     []point{{Value: v}},
+		}
+		ts.Points[0].Interval.EndTime = end
+		return ts
+	}
+	doubleSeries := func(suffix string, d time.Duration) timeSeries {
+		ms := float64(d) / float64(time.Millisecond)
+		return newSeries(suffix, "DOUBLE", value{DoubleValue: &ms})
+	}
+	return []timeSeries{
+		newSeries("count", "INT64", value{Int64Value: &count}),
+		doubleSeries("mean", s.Mean()),
+		doubleSeries("min", s.Min),
+		doubleSeries("max", s.Max),
+	}, nil
+}
+
+// create posts a batch to the timeSeries.create method.
+func (e *Exporter) create(ctx context.Context, series []timeSeries) error {
+	body, err := json.Marshal(map[string]any{"timeSeries": series})
+	if err != nil {
+		return err
+	}
+	token, err := e.cfg.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("timergcm: getting token: %w", err)
+	}
+	url := e.cfg.Endpoint + "/v3/projects/" + e.cfg.Resource.Labels["project_id"] + "/timeSeries"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := e.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("timergcm: creating time series: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// sanitize replaces characters not allowed in metric types and label keys
+// with underscores.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '/':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// metadataTokens caches access tokens of the default service account.
+type metadataTokens struct {
+	md      metadataClient
+	mutex   sync.Mutex
+	value   string
+	expires time.Time
+}
+
+func (t *metadataTokens) token(ctx context.Context) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.value != "" && time.Until(t.expires) > time.Minute {
+		return t.value, nil
+	}
+	b, err := t.md.getBytes(ctx, "instance/service-accounts/default/token")
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return "", err
+	}
+	t.value = resp.AccessToken
+	t.expires = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	return t.value, nil
+}
@@ -0,0 +1,122 @@
+package timergcm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// fakeGoogle serves the metadata server and the monitoring API.
+type fakeGoogle struct {
+	metadata map[string]string
+	requests []map[string][]timeSeries
+	auth     []string
+}
+
+func (f *fakeGoogle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if path, ok := strings.CutPrefix(r.URL.Path, "/computeMetadata/v1/"); ok {
+		v, found := f.metadata[path]
+		if !found || r.Header.Get("Metadata-Flavor") != "Google" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, v)
+		return
+	}
+	if r.URL.Path != "/v3/projects/proj/timeSeries" {
+		http.NotFound(w, r)
+		return
+	}
+	var body map[string][]timeSeries
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.requests = append(f.requests, body)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+}
+
+func newFake(t *testing.T, metadata map[string]string) (*fakeGoogle, *httptest.Server) {
+	f := &fakeGoogle{metadata: metadata}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	old := metadataURL
+	metadataURL = srv.URL + "/computeMetadata/v1/"
+	t.Cleanup(func() { metadataURL = old })
+	return f, srv
+}
+
+func TestExporter(t *testing.T) {
+	f, srv := newFake(t, map[string]string{
+		"instance/service-accounts/default/token": `{"access_token":"tok","expires_in":3600}`,
+	})
+	e, err := NewExporter(Config{
+		Resource: Resource{Type: "global", Labels: map[string]string{"project_id": "proj"}},
+		Endpoint: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+	e.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	r := timer.NewRegistry()
+	v := timer.NewTimerVec("Method")
+	_ = r.RegisterVec("http.server", v)
+	v.WithLabelValues("GET").Observe(2 * time.Millisecond)
+	if err := e.Export(r.Snapshot()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if err := e.Export(r.Snapshot()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if len(f.requests) != 2 || f.auth[1] != "Bearer tok" {
+		t.Fatalf("Expected 2 authorized requests, got %d (%v)", len(f.requests), f.auth)
+	}
+	series := f.requests[0]["timeSeries"]
+	if len(series) != 4 {
+		t.Fatalf("Expected 4 time series, got %d", len(series))
+	}
+	count, mean := series[0], series[1]
+	if count.Metric.Type != "custom.googleapis.com/timer/http_server/count" || *count.Points[0].Value.Int64Value != "1" {
+		t.Errorf("Unexpected count series: %+v", count)
+	}
+	if mean.Metric.Labels["method"] != "GET" || *mean.Points[0].Value.DoubleValue != 2 || mean.ValueType != "DOUBLE" {
+		t.Errorf("Unexpected mean series: %+v", mean)
+	}
+	if mean.Points[0].Interval.EndTime != "2024-01-02T03:04:05Z" || mean.Resource.Type != "global" {
+		t.Errorf("Unexpected point or resource: %+v", mean)
+	}
+}
+
+func TestExporterBatches(t *testing.T) {
+	f, srv := newFake(t, nil)
+	e, _ := NewExporter(Config{
+		Resource: Resource{Type: "global", Labels: map[string]string{"project_id": "proj"}},
+		Endpoint: srv.URL,
+		Token:    func(ctx context.Context) (string, error) { return "static", nil },
+	})
+	snaps := make(map[string]timer.Snapshot)
+	for i := range 60 {
+		snaps[fmt.Sprint("t", i)] = timer.Snapshot{Count: 1}
+	}
+	if err := e.Export(snaps); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(f.requests) != 2 || len(f.requests[0]["timeSeries"]) != 200 || len(f.requests[1]["timeSeries"]) != 40 {
+		t.Errorf("Expected batches of 200 and 40 series, got %d requests", len(f.requests))
+	}
+}
+
+func TestNewExporterRequiresProject(t *testing.T) {
+	if _, err := NewExporter(Config{Resource: Resource{Type: "global"}}); err == nil {
+		t.Errorf("Expected error without project_id")
+	}
+}
@@ -0,0 +1,116 @@
+package timergcm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// metadataURL is the base URL of the GCE metadata server, which also
+// serves GKE and Cloud Run.
+var metadataURL = "http://metadata.google.internal/computeMetadata/v1/"
+
+// metadataTimeout bounds each metadata server request.
+const metadataTimeout = 2 * time.Second
+
+// Resource is the monitored resource time series are written against.
+type Resource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+}
+
+// DetectResource determines the monitored resource the process runs on:
+// "cloud_run_revision" on Cloud Run, "k8s_container" on GKE, and
+// "gce_instance" on Compute Engine, using environment variables and the
+// metadata server. Elsewhere it returns the "global" resource with the
+// project ID from the GOOGLE_CLOUD_PROJECT environment variable, and an
+// error if that is unset.
+func DetectResource(ctx context.Context) (Resource, error) {
+	md := metadataClient{client: &http.Client{Timeout: metadataTimeout}}
+	project, err := md.get(ctx, "project/project-id")
+	if err != nil {
+		if project = os.Getenv("GOOGLE_CLOUD_PROJECT"); project == "" {
+			return Resource{}, fmt.Errorf("detecting project: %w", err)
+		}
+		return Resource{Type: "global", Labels: map[string]string{"project_id": project}}, nil
+	}
+
+	switch {
+	case os.Getenv("K_SERVICE") != "":
+		region, _ := md.get(ctx, "instance/region")
+		return Resource{Type: "cloud_run_revision", Labels: map[string]string{
+			"project_id":         project,
+			"service_name":       os.Getenv("K_SERVICE"),
+			"revision_name":      os.Getenv("K_REVISION"),
+			"configuration_name": os.Getenv("K_CONFIGURATION"),
+			"location":           lastSegment(region),
+		}}, nil
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "":
+		cluster, _ := md.get(ctx, "instance/attributes/cluster-name")
+		location, _ := md.get(ctx, "instance/attributes/cluster-location")
+		namespace := os.Getenv("NAMESPACE")
+		if b, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil && namespace == "" {
+			namespace = strings.TrimSpace(string(b))
+		}
+		pod := os.Getenv("POD_NAME")
+		if pod == "" {
+			pod, _ = os.Hostname()
+		}
+		return Resource{Type: "k8s_container", Labels: map[string]string{
+			"project_id":     project,
+			"location":       location,
+			"cluster_name":   cluster,
+			"namespace_name": namespace,
+			"pod_name":       pod,
+			"container_name": os.Getenv("CONTAINER_NAME"),
+		}}, nil
+	default:
+		id, err := md.get(ctx, "instance/id")
+		if err != nil {
+			return Resource{}, fmt.Errorf("detecting instance: %w", err)
+		}
+		zone, _ := md.get(ctx, "instance/zone")
+		return Resource{Type: "gce_instance", Labels: map[string]string{
+			"project_id":  project,
+			"instance_id": id,
+			"zone":        lastSegment(zone),
+		}}, nil
+	}
+}
+
+// lastSegment returns the part of s after its last slash, as metadata
+// returns zones and regions as "projects/<number>/zones/<zone>".
+func lastSegment(s string) string {
+	return s[strings.LastIndexByte(s, '/')+1:]
+}
+
+// metadataClient reads values from the metadata server.
+type metadataClient struct {
+	client *http.Client
+}
+
+func (m metadataClient) get(ctx context.Context, path string) (string, error) {
+	b, err := m.getBytes(ctx, path)
+	return strings.TrimSpace(string(b)), err
+}
+
+func (m metadataClient) getBytes(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata %s: %s", path, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,83 @@
+package timergcm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDetectResourceCloudRun(t *testing.T) {
+	newFake(t, map[string]string{
+		"project/project-id": "proj",
+		"instance/region":    "projects/123/regions/europe-west1",
+	})
+	t.Setenv("K_SERVICE", "api")
+	t.Setenv("K_REVISION", "api-00001")
+	t.Setenv("K_CONFIGURATION", "api")
+
+	res, err := DetectResource(context.Background())
+	if err != nil {
+		t.Fatalf("DetectResource failed: %v", err)
+	}
+	if res.Type != "cloud_run_revision" || res.Labels["location"] != "europe-west1" || res.Labels["service_name"] != "api" {
+		t.Errorf("Unexpected resource: %+v", res)
+	}
+}
+
+func TestDetectResourceGKE(t *testing.T) {
+	newFake(t, map[string]string{
+		"project/project-id":                   "proj",
+		"instance/attributes/cluster-name":     "prod",
+		"instance/attributes/cluster-location": "us-central1",
+	})
+	t.Setenv("K_SERVICE", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	t.Setenv("NAMESPACE", "shop")
+	t.Setenv("POD_NAME", "api-7d9")
+	t.Setenv("CONTAINER_NAME", "app")
+
+	res, err := DetectResource(context.Background())
+	if err != nil {
+		t.Fatalf("DetectResource failed: %v", err)
+	}
+	want := map[string]string{
+		"project_id": "proj", "location": "us-central1", "cluster_name": "prod",
+		"namespace_name": "shop", "pod_name": "api-7d9", "container_name": "app",
+	}
+	if res.Type != "k8s_container" || len(res.Labels) != len(want) {
+		t.Fatalf("Unexpected resource: %+v", res)
+	}
+	for k, v := range want {
+		if res.Labels[k] != v {
+			t.Errorf("Label %s = %q; want %q", k, res.Labels[k], v)
+		}
+	}
+}
+
+func TestDetectResourceGCE(t *testing.T) {
+	newFake(t, map[string]string{
+		"project/project-id": "proj",
+		"instance/id":        "42",
+		"instance/zone":      "projects/123/zones/us-east1-b",
+	})
+	t.Setenv("K_SERVICE", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	res, err := DetectResource(context.Background())
+	if err != nil || res.Type != "gce_instance" || res.Labels["zone"] != "us-east1-b" || res.Labels["instance_id"] != "42" {
+		t.Errorf("DetectResource = %+v, %v", res, err)
+	}
+}
+
+func TestDetectResourceGlobal(t *testing.T) {
+	newFake(t, nil)
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "local")
+	res, err := DetectResource(context.Background())
+	if err != nil || res.Type != "global" || res.Labels["project_id"] != "local" {
+		t.Errorf("DetectResource = %+v, %v", res, err)
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	if _, err := DetectResource(context.Background()); err == nil {
+		t.Errorf("Expected error without a project")
+	}
+}
@@ -0,0 +1,46 @@
+package timergrpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Client calls the timer.v1.Registry service.
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient creates a Client using cc.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// Snapshot fetches the current state of the remote registry.
+func (c *Client) Snapshot(ctx context.Context, opts ...grpc.CallOption) (*RegistrySnapshot, error) {
+	out := new(RegistrySnapshot)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	if err := c.cc.Invoke(ctx, "/"+ServiceName+"/Snapshot", &SnapshotRequest{}, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Watch opens a stream receiving a snapshot of the remote registry every
+// interval. The stream ends when ctx is canceled.
+func (c *Client) Watch(ctx context.Context, interval time.Duration, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RegistrySnapshot], error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], "/"+ServiceName+"/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &grpc.GenericClientStream[WatchRequest, RegistrySnapshot]{ClientStream: stream}
+	if err := s.SendMsg(&WatchRequest{Interval: interval}); err != nil {
+		return nil, err
+	}
+	if err := s.CloseSend(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,31 @@
+package timergrpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the gRPC content-subtype used by the service.
+// Requests are sent as "application/grpc+json".
+const codecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec encodes messages as JSON so the service needs no generated
+// protobuf code and clients in any language can decode snapshots directly.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
@@ -0,0 +1,20 @@
+module github.com/jnpr-pranav/go-timer/timergrpc
+
+go 1.24.3
+
+require (
+	github.com/jnpr-pranav/go-timer v0.0.0-20261016145803-d6869ae7bd4a
+	google.golang.org/grpc v1.72.0
+)
+
+require (
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
+
+// Local development builds against the parent directory; modules
+// depending on timergrpc ignore this and resolve the version above.
+replace github.com/jnpr-pranav/go-timer => ../
@@ -0,0 +1,138 @@
+// Package timergrpc exposes a timer.Registry over gRPC.
+//
+// The service is named "timer.v1.Registry" and has two methods:
+//
+//	rpc Snapshot(SnapshotRequest) returns (RegistrySnapshot);
+//	rpc Watch(WatchRequest) returns (stream RegistrySnapshot);
+//
+// Messages are encoded as JSON using the "json" content-subtype
+// ("application/grpc+json"), so no protobuf code generation is needed on
+// either side. Go clients can use Client, which selects the codec
+// automatically.
+package timergrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the fully-qualified gRPC service name.
+const ServiceName = "timer.v1.Registry"
+
+// MinWatchInterval is the shortest interval accepted by Watch.
+const MinWatchInterval = 100 * time.Millisecond
+
+// SnapshotRequest is the request message for Snapshot.
+type SnapshotRequest struct{}
+
+// WatchRequest is the request message for Watch.
+type WatchRequest struct {
+	// Interval between snapshots sent on the stream.
+	Interval time.Duration `json:"interval_ns"`
+}
+
+// RegistrySnapshot is a snapshot of every timer in a registry.
+type RegistrySnapshot struct {
+	Time   time.Time                 `json:"time"`
+	Timers map[string]timer.Snapshot `json:"timers"`
+}
+
+// Server implements the timer.v1.Registry service for a single registry.
+type Server struct {
+	registry *timer.Registry
+}
+
+// NewServer creates a Server serving snapshots of r.
+// If r is nil, timer.DefaultRegistry is used.
+func NewServer(r *timer.Registry) *Server {
+	if r == nil {
+		r = timer.DefaultRegistry
+	}
+	return &Server{registry: r}
+}
+
+// Register registers the service on s.
+func (srv *Server) Register(s grpc.ServiceRegistrar) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// Snapshot returns the current state of the registry.
+func (srv *Server) Snapshot(context.Context, *SnapshotRequest) (*RegistrySnapshot, error) {
+	return srv.snapshot(), nil
+}
+
+// Watch sends a snapshot immediately and then once per requested interval
+// until the client cancels the stream.
+func (srv *Server) Watch(req *WatchRequest, stream grpc.ServerStreamingServer[RegistrySnapshot]) error {
+	if req.Interval < MinWatchInterval {
+		return status.Errorf(codes.InvalidArgument, "interval must be at least %v", MinWatchInterval)
+	}
+	ticker := time.NewTicker(req.Interval)
+	defer ticker.Stop()
+	for {
+		if err := stream.Send(srv.snapshot()); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (srv *Server) snapshot() *RegistrySnapshot {
+	return &RegistrySnapshot{
+		Time:   time.Now(),
+		Timers: srv.registry.Snapshot(),
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Snapshot",
+			Handler:    snapshotHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       watchHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+func snapshotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/Snapshot",
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func watchHandler(srv any, stream grpc.ServerStream) error {
+	in := new(WatchRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(*Server).Watch(in, &grpc.GenericServerStream[WatchRequest, RegistrySnapshot]{ServerStream: stream})
+}
@@ -0,0 +1,98 @@
+package timergrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, r *timer.Registry) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	NewServer(r).Register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return NewClient(cc)
+}
+
+func TestSnapshot(t *testing.T) {
+	r := timer.NewRegistry()
+	t0 := timer.NewTimer()
+	_ = r.Register("db", t0)
+	t0.Observe(10 * time.Millisecond)
+
+	c := newTestClient(t, r)
+	snap, err := c.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if got, want := snap.Timers["db"], t0.Snapshot(); got != want {
+		t.Errorf("Snapshot db = %+v; want %+v", got, want)
+	}
+	if snap.Time.IsZero() {
+		t.Errorf("Expected snapshot time to be set")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	r := timer.NewRegistry()
+	t0 := timer.NewTimer()
+	_ = r.Register("db", t0)
+
+	c := newTestClient(t, r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.Watch(ctx, MinWatchInterval)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if first.Timers["db"].Count != 0 {
+		t.Errorf("Expected first snapshot count 0, got %d", first.Timers["db"].Count)
+	}
+
+	t0.Observe(time.Millisecond)
+	second, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if second.Timers["db"].Count != 1 {
+		t.Errorf("Expected second snapshot count 1, got %d", second.Timers["db"].Count)
+	}
+}
+
+func TestWatchRejectsShortInterval(t *testing.T) {
+	c := newTestClient(t, timer.NewRegistry())
+	stream, err := c.Watch(context.Background(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	_, err = stream.Recv()
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
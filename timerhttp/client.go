@@ -0,0 +1,175 @@
+package timerhttp
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// Phases of a client request, as values of the "phase" label of a
+// Transport. DNS, connect, and TLS are only recorded when a new connection
+// is made. TTFB runs from getting a connection to the first response
+// byte, and transfer from there until the body is read or closed.
+const (
+	PhaseDNS      = "dns"
+	PhaseConnect  = "connect"
+	PhaseTLS      = "tls"
+	PhaseTTFB     = "ttfb"
+	PhaseTransfer = "transfer"
+)
+
+// Transport is an http.RoundTripper recording the phases of every request
+// it sends into a TimerVec labeled "host" and "phase":
+//
+//	tr := timerhttp.NewTransport(nil)
+//	timer.DefaultRegistry.RegisterVec("http_client", tr.Vec())
+//	client := &http.Client{Transport: tr}
+type Transport struct {
+	base http.RoundTripper
+	vec  *timer.TimerVec
+}
+
+// NewTransport creates a Transport sending requests with base, or
+// http.DefaultTransport if base is nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base, vec: timer.NewTimerVec("host", "phase")}
+}
+
+// Vec returns the TimerVec phases are recorded in, for registration in a
+// timer.Registry.
+func (t *Transport) Vec() *timer.TimerVec {
+	return t.vec
+}
+
+// RoundTrip sends req with the base transport, timing its phases. Hooks of
+// a httptrace.ClientTrace already in the request context still run.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := &phaseTrace{vec: t.vec, host: req.URL.Host}
+	ctx := httptrace.WithClientTrace(req.Context(), p.clientTrace())
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil || resp.StatusCode == http.StatusSwitchingProtocols {
+		// the body of an upgraded connection must stay writable
+		return resp, err
+	}
+	resp.Body = &timedBody{ReadCloser: resp.Body, done: p.transferDone}
+	return resp, nil
+}
+
+// phaseTrace collects the phase timestamps of one request. Trace hooks
+// may run on other goroutines, such as parallel dials.
+type phaseTrace struct {
+	vec  *timer.TimerVec
+	host string
+
+	mutex       sync.Mutex
+	dnsStart    time.Time
+	connStart   time.Time
+	connected   bool
+	tlsStart    time.Time
+	gotConn     time.Time
+	firstByte   time.Time
+	transferred bool
+}
+
+// observe records the time since start in phase.
+func (p *phaseTrace) observe(phase string, start time.Time) {
+	p.vec.WithLabelValues(p.host, phase).Observe(max(time.Since(start), 0))
+}
+
+// clientTrace returns the hooks recording into p.
+func (p *phaseTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if info.Err == nil && !p.dnsStart.IsZero() {
+				p.observe(PhaseDNS, p.dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if p.connStart.IsZero() {
+				p.connStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			// with parallel dials only the first success counts
+			if err == nil && !p.connected && !p.connStart.IsZero() {
+				p.connected = true
+				p.observe(PhaseConnect, p.connStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if err == nil && !p.tlsStart.IsZero() {
+				p.observe(PhaseTLS, p.tlsStart)
+			}
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.gotConn = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			p.firstByte = time.Now()
+			if !p.gotConn.IsZero() {
+				p.observe(PhaseTTFB, p.gotConn)
+			}
+		},
+	}
+}
+
+// transferDone records the transfer phase once the body is finished.
+func (p *phaseTrace) transferDone() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.transferred && !p.firstByte.IsZero() {
+		p.transferred = true
+		p.observe(PhaseTransfer, p.firstByte)
+	}
+}
+
+// timedBody calls done when the body is read to the end or closed.
+type timedBody struct {
+	io.ReadCloser
+	done func()
+}
+
+// Read reads from the body.
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+// Close closes the body.
+func (b *timedBody) Close() error {
+	b.done()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,59 @@
+package timerhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+	tr := NewTransport(srv.Client().Transport)
+	client := &http.Client{Transport: tr}
+
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello" {
+			t.Fatalf("Expected body %q, got %q", "hello", body)
+		}
+	}
+
+	u, _ := url.Parse(srv.URL)
+	snaps := tr.Vec().Snapshot()
+	count := func(phase string) uint64 {
+		return snaps[`host="`+u.Host+`",phase="`+phase+`"`].Count
+	}
+	// the second request reuses the connection
+	if count(PhaseConnect) != 1 || count(PhaseTLS) != 1 {
+		t.Errorf("Expected 1 connect and 1 TLS handshake, got %v", snaps)
+	}
+	if count(PhaseTTFB) != 2 || count(PhaseTransfer) != 2 {
+		t.Errorf("Expected 2 TTFB and 2 transfer phases, got %v", snaps)
+	}
+	if count(PhaseDNS) != 0 {
+		t.Errorf("Expected no DNS lookup for an IP address, got %v", snaps)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	tr := NewTransport(nil)
+	client := &http.Client{Transport: tr}
+	if _, err := client.Get("http://127.0.0.1:1"); err == nil {
+		t.Errorf("Expected dialing a closed port to fail")
+	}
+	for name, s := range tr.Vec().Snapshot() {
+		if s.Count != 0 {
+			t.Errorf("Expected nothing recorded for a failed request, got %s: %v", name, s)
+		}
+	}
+}
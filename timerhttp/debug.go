@@ -0,0 +1,49 @@
+package timerhttp
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// DebugHandler returns a handler exposing the snapshots of r for
+// operators. Requests for a path ending in "/json" get a JSON object
+// mapping names to snapshots, and those ending in "/events" get the same
+// objects streamed by EventsHandler every 5 seconds. All other paths get
+// a self-contained HTML dashboard polling the JSON endpoint and drawing a
+// sparkline of the mean latency per refresh interval for each timer, so
+// trends are visible without Grafana. The refresh interval in seconds can
+// be set with the "refresh" query parameter and defaults to 5.
+//
+// Mount it under a prefix with http.StripPrefix or a trailing-slash
+// pattern:
+//
+//	mux.Handle("/debug/timers/", timerhttp.DebugHandler(timer.DefaultRegistry))
+func DebugHandler(r *timer.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.HasSuffix(req.URL.Path, "/events") {
+			EventsHandler(r, 5*time.Second).ServeHTTP(w, req)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		if strings.HasSuffix(req.URL.Path, "/json") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(r.Snapshot())
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboardHTML)
+	})
+}
@@ -0,0 +1,48 @@
+package timerhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestDebugHandler(t *testing.T) {
+	r := timer.NewRegistry()
+	r.GetOrCreate("db").Observe(time.Millisecond)
+	mux := http.NewServeMux()
+	mux.Handle("/debug/timers/", DebugHandler(r))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/timers/json", nil))
+	var snaps map[string]timer.Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snaps); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if rec.Header().Get("Content-Type") != "application/json" || snaps["db"].Count != 1 {
+		t.Errorf("Unexpected JSON response: %v %q", rec.Header(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/timers/", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") || !strings.Contains(rec.Body.String(), "<svg>") {
+		t.Errorf("Expected HTML dashboard, got %q", rec.Header().Get("Content-Type"))
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/debug/timers/events", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" || rec.Body.Len() != 0 {
+		t.Errorf("HEAD /events = %d %q with %d body bytes; want 200 text/event-stream without body",
+			rec.Code, rec.Header().Get("Content-Type"), rec.Body.Len())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/timers/json", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d; want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
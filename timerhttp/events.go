@@ -0,0 +1,70 @@
+package timerhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// MinEventsInterval is the shortest interval EventsHandler streams at.
+const MinEventsInterval = 100 * time.Millisecond
+
+// EventsHandler returns a handler streaming snapshots of r as Server-Sent
+// Events, for live dashboards and watch-style tools. Each event is named
+// "snapshot" and carries the same JSON object as DebugHandler's JSON
+// endpoint. The first event is sent immediately, then one every interval
+// until the client disconnects. Clients can override the interval with
+// the "interval" query parameter, e.g. "?interval=1s", which is raised to
+// MinEventsInterval if shorter. HEAD requests get the headers of the
+// stream without events.
+func EventsHandler(r *timer.Registry, interval time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		every := interval
+		if v := req.URL.Query().Get("interval"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				http.Error(w, "invalid interval: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			every = d
+		}
+		every = max(every, MinEventsInterval)
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodHead {
+			return
+		}
+
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			b, err := json.Marshal(r.Snapshot())
+			if err != nil {
+				return
+			}
+			w.Write([]byte("event: snapshot\ndata: "))
+			w.Write(b)
+			if _, err := w.Write([]byte("\n\n")); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			select {
+			case <-req.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
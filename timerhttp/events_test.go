@@ -0,0 +1,69 @@
+package timerhttp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestEventsHandler(t *testing.T) {
+	r := timer.NewRegistry()
+	db := r.GetOrCreate("db")
+	db.Observe(time.Millisecond)
+	srv := httptest.NewServer(DebugHandler(r))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events?interval=10ms", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q; want text/event-stream", ct)
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	var counts []uint64
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line == "event: snapshot" {
+			continue
+		}
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			t.Fatalf("Unexpected line %q", line)
+		}
+		var snaps map[string]timer.Snapshot
+		if err := json.Unmarshal([]byte(data), &snaps); err != nil {
+			t.Fatalf("Invalid event data %q: %v", data, err)
+		}
+		counts = append(counts, snaps["db"].Count)
+		if len(counts) == 1 {
+			db.Observe(time.Millisecond)
+		}
+		if snaps["db"].Count == 2 {
+			break
+		}
+	}
+	if len(counts) < 2 || counts[0] != 1 || counts[len(counts)-1] != 2 {
+		t.Errorf("Expected streamed counts from 1 to 2, got %v", counts)
+	}
+}
+
+func TestEventsHandlerBadInterval(t *testing.T) {
+	rec := httptest.NewRecorder()
+	EventsHandler(timer.NewRegistry(), time.Second).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?interval=soon", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d; want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,168 @@
+// Package timerhttp provides net/http instrumentation built on timer.
+package timerhttp
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// UnmatchedRoute is the route label used when a normalizer cannot map a
+// request to a route template, so unknown paths share one timer.
+const UnmatchedRoute = "other"
+
+// OtherMethod is the method label of requests with a method other than
+// the standard ones, which clients are free to invent.
+const OtherMethod = "OTHER"
+
+// DefaultChildLimit is the number of method and route label sets a
+// Middleware keeps timers for before recording new ones in the overflow
+// bucket of its TimerVec. Change it with Vec().SetLimit.
+const DefaultChildLimit = 1000
+
+// Normalizer maps a request to the route label it is timed under.
+// It runs after the wrapped handler returns, on the request the
+// Middleware received. Routers such as chi and gorilla/mux record the
+// matched route on a copy of the request made with WithContext, which
+// only the middleware and handlers they call see, so adapters for them
+// only find the route if the Middleware is installed with the router's
+// own Use:
+//
+//	router.Use(m.Handler)
+//
+// Wrapping the router from outside, they find no route, so they must
+// guard against nil and fall back to UnmatchedRoute:
+//
+//	// chi
+//	func(r *http.Request) string {
+//		if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+//			return rc.RoutePattern()
+//		}
+//		return timerhttp.UnmatchedRoute
+//	}
+//	// gorilla/mux
+//	func(r *http.Request) string {
+//		if route := mux.CurrentRoute(r); route != nil {
+//			if t, err := route.GetPathTemplate(); err == nil {
+//				return t
+//			}
+//		}
+//		return timerhttp.UnmatchedRoute
+//	}
+//
+// For gin, record c.FullPath() from a gin middleware instead. An empty
+// route is labeled UnmatchedRoute.
+type Normalizer func(r *http.Request) string
+
+// PatternNormalizer labels requests with the net/http.ServeMux pattern that
+// matched them, without the method or host, e.g. "/users/{id}".
+// Requests no pattern matched are labeled UnmatchedRoute.
+func PatternNormalizer(r *http.Request) string {
+	if r.Pattern == "" {
+		return UnmatchedRoute
+	}
+	p := r.Pattern
+	// strip an optional "METHOD " prefix
+	if i := strings.IndexByte(p, ' '); i >= 0 {
+		p = strings.TrimLeft(p[i:], " \t")
+	}
+	// strip an optional host prefix
+	if i := strings.IndexByte(p, '/'); i > 0 {
+		p = p[i:]
+	}
+	return p
+}
+
+// RawPathNormalizer labels requests with their URL path unchanged.
+// Only use it when the set of paths is known to be small.
+func RawPathNormalizer(r *http.Request) string {
+	return r.URL.Path
+}
+
+// RegexpRule rewrites URL paths matching Pattern to Template, which may
+// refer to submatches as in regexp.Regexp.ReplaceAllString.
+type RegexpRule struct {
+	Pattern  *regexp.Regexp
+	Template string
+}
+
+// RegexpNormalizer returns a Normalizer applying the first rule whose
+// pattern matches the URL path. Paths matching no rule are labeled
+// UnmatchedRoute.
+func RegexpNormalizer(rules ...RegexpRule) Normalizer {
+	return func(r *http.Request) string {
+		for _, rule := range rules {
+			if rule.Pattern.MatchString(r.URL.Path) {
+				return rule.Pattern.ReplaceAllString(r.URL.Path, rule.Template)
+			}
+		}
+		return UnmatchedRoute
+	}
+}
+
+// Middleware times HTTP requests into a TimerVec labeled by method and
+// normalized route.
+type Middleware struct {
+	vec       *timer.TimerVec
+	normalize Normalizer
+}
+
+// NewMiddleware creates a Middleware whose timers are labeled "method" and
+// "route", with routes produced by normalize. If normalize is nil,
+// PatternNormalizer is used. Non-standard methods are labeled OtherMethod,
+// and the vec is limited to DefaultChildLimit label sets with the
+// timer.OverflowBucket policy.
+func NewMiddleware(normalize Normalizer) *Middleware {
+	if normalize == nil {
+		normalize = PatternNormalizer
+	}
+	vec := timer.NewTimerVec("method", "route")
+	vec.SetLimit(DefaultChildLimit, timer.OverflowBucket)
+	return &Middleware{vec: vec, normalize: normalize}
+}
+
+// methodLabel returns method if it is a standard HTTP method, and
+// OtherMethod otherwise.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodConnect,
+		http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return OtherMethod
+}
+
+// Vec returns the TimerVec requests are recorded in, for registration in a
+// timer.Registry.
+func (m *Middleware) Vec() *timer.TimerVec {
+	return m.vec
+}
+
+// Handler wraps next so the duration of every request is recorded.
+// Requests whose handler panics are recorded and counted as panicked
+// before the panic continues.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		panicked := true
+		defer func() {
+			d := max(time.Since(start), 0)
+			route := m.normalize(r)
+			if route == "" {
+				route = UnmatchedRoute
+			}
+			t := m.vec.WithLabelValues(methodLabel(r.Method), route)
+			if panicked {
+				t.ObservePanic(d)
+			} else {
+				t.Observe(d)
+			}
+		}()
+		next.ServeHTTP(w, r)
+		panicked = false
+	})
+}
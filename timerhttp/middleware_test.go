@@ -0,0 +1,152 @@
+package timerhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func serve(h http.Handler, method, path string) {
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+}
+
+func TestMiddlewarePatternNormalizer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("example.com/static/", func(http.ResponseWriter, *http.Request) {})
+
+	m := NewMiddleware(nil)
+	h := m.Handler(mux)
+	serve(h, "GET", "/users/1")
+	serve(h, "GET", "/users/2")
+	serve(h, "GET", "http://example.com/static/app.js")
+	serve(h, "GET", "/nope")
+
+	snaps := m.Vec().Snapshot()
+	if snaps[`method="GET",route="/users/{id}"`].Count != 2 {
+		t.Errorf("Expected 2 requests under /users/{id}, got %v", snaps)
+	}
+	if snaps[`method="GET",route="/static/"`].Count != 1 {
+		t.Errorf("Expected host to be stripped from pattern, got %v", snaps)
+	}
+	if snaps[`method="GET",route="other"`].Count != 1 {
+		t.Errorf("Expected unmatched request under %q, got %v", UnmatchedRoute, snaps)
+	}
+	if len(snaps) != 3 {
+		t.Errorf("Expected 3 children, got %v", snaps)
+	}
+}
+
+func TestMiddlewareBoundedLabels(t *testing.T) {
+	m := NewMiddleware(RawPathNormalizer)
+	h := m.Handler(http.NotFoundHandler())
+	serve(h, "BREW", "/")
+	serve(h, "PURGE", "/")
+	serve(h, "DELETE", "/")
+
+	snaps := m.Vec().Snapshot()
+	if snaps[`method="OTHER",route="/"`].Count != 2 || snaps[`method="DELETE",route="/"`].Count != 1 {
+		t.Errorf("Expected invented methods under %q, got %v", OtherMethod, snaps)
+	}
+
+	for i := range DefaultChildLimit + 10 {
+		serve(h, "GET", "/"+strconv.Itoa(i))
+	}
+	if n := m.Vec().Len(); n != DefaultChildLimit+1 {
+		t.Errorf("Len = %d; want %d including the overflow bucket", n, DefaultChildLimit+1)
+	}
+}
+
+// routeKey is the context key of contextRouter.
+type routeKey struct{}
+
+// contextRouter mimics chi and gorilla/mux: it records the matched route
+// on a copy of the request and runs its Use middleware on that copy.
+type contextRouter struct {
+	use []func(http.Handler) http.Handler
+}
+
+func (rt *contextRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var h http.Handler = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	for _, mw := range rt.use {
+		h = mw(h)
+	}
+	h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, "/users/{id}")))
+}
+
+func TestMiddlewareRouterContext(t *testing.T) {
+	// a guarded adapter as documented on Normalizer
+	normalize := func(r *http.Request) string {
+		if route, ok := r.Context().Value(routeKey{}).(string); ok {
+			return route
+		}
+		return UnmatchedRoute
+	}
+
+	inner := NewMiddleware(normalize)
+	serve(&contextRouter{use: []func(http.Handler) http.Handler{inner.Handler}}, "GET", "/users/1")
+	if snaps := inner.Vec().Snapshot(); snaps[`method="GET",route="/users/{id}"`].Count != 1 {
+		t.Errorf("Expected the route through the router's Use, got %v", snaps)
+	}
+
+	outer := NewMiddleware(normalize)
+	serve(outer.Handler(&contextRouter{}), "GET", "/users/1")
+	if snaps := outer.Vec().Snapshot(); snaps[`method="GET",route="other"`].Count != 1 {
+		t.Errorf("Expected %q wrapping the router from outside, got %v", UnmatchedRoute, snaps)
+	}
+
+	empty := NewMiddleware(func(*http.Request) string { return "" })
+	serve(empty.Handler(http.NotFoundHandler()), "GET", "/")
+	if snaps := empty.Vec().Snapshot(); snaps[`method="GET",route="other"`].Count != 1 {
+		t.Errorf("Expected an empty route under %q, got %v", UnmatchedRoute, snaps)
+	}
+}
+
+func TestRegexpNormalizer(t *testing.T) {
+	n := RegexpNormalizer(
+		RegexpRule{Pattern: regexp.MustCompile(`^/users/[0-9]+$`), Template: "/users/{id}"},
+		RegexpRule{Pattern: regexp.MustCompile(`^/orgs/([a-z]+)/repos/.*$`), Template: "/orgs/$1/repos/{repo}"},
+	)
+	tests := map[string]string{
+		"/users/42":            "/users/{id}",
+		"/orgs/acme/repos/x/y": "/orgs/acme/repos/{repo}",
+		"/users/abc":           UnmatchedRoute,
+	}
+	for path, want := range tests {
+		if got := n(httptest.NewRequest("GET", path, nil)); got != want {
+			t.Errorf("normalize(%q) = %q; want %q", path, got, want)
+		}
+	}
+}
+
+func TestRawPathNormalizer(t *testing.T) {
+	m := NewMiddleware(RawPathNormalizer)
+	serve(m.Handler(http.NotFoundHandler()), "POST", "/a/b")
+	if m.Vec().Snapshot()[`method="POST",route="/a/b"`].Count != 1 {
+		t.Errorf("Expected raw path label, got %v", m.Vec().Snapshot())
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	m := NewMiddleware(RawPathNormalizer)
+	h := m.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("Expected panic to propagate, got %v", r)
+			}
+		}()
+		serve(h, "GET", "/boom")
+	}()
+
+	s := m.Vec().Snapshot()[`method="GET",route="/boom"`]
+	if s.Count != 1 || s.Panicked != 1 {
+		t.Errorf("Expected 1 panicked request to be recorded, got %+v", s)
+	}
+}
@@ -0,0 +1,72 @@
+package timerio
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Conn is a net.Conn whose reads and writes are timed. A read includes
+// the time spent waiting for the peer to send data.
+type Conn struct {
+	net.Conn
+	ops     *Ops
+	created time.Time
+	// Time of the first write as an offset from created plus one, 0 if
+	// nothing was written yet
+	firstWrite atomic.Int64
+	gotByte    atomic.Bool
+}
+
+// NewConn returns c timing its reads and writes into ops.
+func NewConn(c net.Conn, ops *Ops) *Conn {
+	return &Conn{Conn: c, ops: ops, created: time.Now()}
+}
+
+// Read reads from the connection and records the time it took in
+// ops.Read. The first read returning data also records ops.FirstByte.
+func (c *Conn) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := c.Conn.Read(p)
+	observe(c.ops.Read, start)
+	if n > 0 && c.ops.FirstByte != nil && !c.gotByte.Swap(true) {
+		ref := c.created
+		if w := c.firstWrite.Load(); w != 0 {
+			ref = c.created.Add(time.Duration(w - 1))
+		}
+		observe(c.ops.FirstByte, ref)
+	}
+	return n, err
+}
+
+// Write writes to the connection and records the time it took in
+// ops.Write.
+func (c *Conn) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := c.Conn.Write(p)
+	observe(c.ops.Write, start)
+	if c.firstWrite.Load() == 0 {
+		c.firstWrite.CompareAndSwap(0, int64(start.Sub(c.created))+1)
+	}
+	return n, err
+}
+
+// DialFunc returns a function dialing with d, recording the time each
+// successful dial took in ops.Dial and wrapping the connection with
+// NewConn. It fits http.Transport.DialContext. A nil d dials with a zero
+// net.Dialer.
+func DialFunc(d *net.Dialer, ops *Ops) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d == nil {
+		d = &net.Dialer{}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		c, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		observe(ops.Dial, start)
+		return NewConn(c, ops), nil
+	}
+}
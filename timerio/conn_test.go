@@ -0,0 +1,70 @@
+package timerio
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestConn(t *testing.T) {
+	r := timer.NewRegistry()
+	ops := NewOps(r, "net")
+	client, server := net.Pipe()
+	c := NewConn(client, ops)
+	go func() {
+		buf := make([]byte, 4)
+		io.ReadFull(server, buf)
+		server.Write(buf)
+		server.Close()
+	}()
+	if _, err := c.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("Expected echo %q, got %q, %v", "ping", buf, err)
+	}
+	c.Close()
+	if snaps := r.Snapshot(); snaps["net.write"].Count != 1 || snaps["net.read"].Count == 0 {
+		t.Errorf("Expected 1 write and some reads, got %v", snaps)
+	}
+}
+
+func TestDialFunc(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on loopback: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+
+	r := timer.NewRegistry()
+	dial := DialFunc(nil, NewOps(r, "upstream"))
+	c, err := dial(context.Background(), "tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if _, ok := c.(*Conn); !ok {
+		t.Errorf("Expected a *Conn, got %T", c)
+	}
+	if n := r.Get("upstream.dial").Count(); n != 1 {
+		t.Errorf("Expected 1 dial, got %d", n)
+	}
+
+	// failed dials are not recorded
+	l.Close()
+	if _, err := dial(context.Background(), "tcp", l.Addr().String()); err == nil {
+		t.Errorf("Expected dialing a closed listener to fail")
+	}
+	if n := r.Get("upstream.dial").Count(); n != 1 {
+		t.Errorf("Expected still 1 dial, got %d", n)
+	}
+}
@@ -0,0 +1,88 @@
+package timerio
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// File is an os.File whose reads, writes, and syncs are timed. Methods
+// that are not overridden are those of the embedded file and not timed.
+type File struct {
+	*os.File
+	ops *Ops
+}
+
+// WrapFile returns f timing its operations into ops.
+func WrapFile(f *os.File, ops *Ops) *File {
+	return &File{File: f, ops: ops}
+}
+
+// Open opens the named file for reading like os.Open and wraps it.
+func Open(name string, ops *Ops) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return WrapFile(f, ops), nil
+}
+
+// Read reads from the file and records the time it took in ops.Read.
+func (f *File) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Read(p)
+	observe(f.ops.Read, start)
+	return n, err
+}
+
+// ReadAt reads from the file at off and records the time it took in
+// ops.Read.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	start := time.Now()
+	n, err := f.File.ReadAt(p, off)
+	observe(f.ops.Read, start)
+	return n, err
+}
+
+// Write writes to the file and records the time it took in ops.Write.
+func (f *File) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Write(p)
+	observe(f.ops.Write, start)
+	return n, err
+}
+
+// WriteAt writes to the file at off and records the time it took in
+// ops.Write.
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	start := time.Now()
+	n, err := f.File.WriteAt(p, off)
+	observe(f.ops.Write, start)
+	return n, err
+}
+
+// WriteString is like Write with a string.
+func (f *File) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// Sync commits the file to stable storage and records the time it took in
+// ops.Sync.
+func (f *File) Sync() error {
+	start := time.Now()
+	err := f.File.Sync()
+	observe(f.ops.Sync, start)
+	return err
+}
+
+// ReadFrom copies from r through Write, so that io.Copy into the file is
+// timed instead of taking the untimed fast path of os.File.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, r)
+}
+
+// WriteTo copies to w through Read, so that io.Copy from the file is
+// timed instead of taking the untimed fast path of os.File.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, struct{ io.Reader }{f})
+}
@@ -0,0 +1,62 @@
+package timerio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+func TestFile(t *testing.T) {
+	r := timer.NewRegistry()
+	ops := NewOps(r, "disk")
+	name := filepath.Join(t.TempDir(), "data")
+	raw, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := WrapFile(raw, ops)
+	if _, err := f.WriteString("hello "); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(f, bytes.NewReader([]byte("world"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	f, err = Open(name, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, f); err != nil || buf.String() != "hello world" {
+		t.Fatalf("Expected to read back %q, got %q, %v", "hello world", buf.String(), err)
+	}
+
+	snaps := r.Snapshot()
+	if snaps["disk.write"].Count != 2 || snaps["disk.sync"].Count != 1 || snaps["disk.read"].Count == 0 {
+		t.Errorf("Expected 2 writes, 1 sync, and some reads, got %v", snaps)
+	}
+	if snaps["disk.dial"].Count != 0 {
+		t.Errorf("Expected no dials, got %d", snaps["disk.dial"].Count)
+	}
+}
+
+func TestFileNilTimers(t *testing.T) {
+	raw, err := os.Create(filepath.Join(t.TempDir(), "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := WrapFile(raw, &Ops{})
+	defer f.Close()
+	if _, err := f.Write([]byte("x")); err != nil {
+		t.Errorf("Expected untimed write to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+// Package timerio provides os.File and net.Conn instrumentation built on
+// timer.
+package timerio
+
+import (
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// Ops holds the timers that wrapped files and connections record their
+// operations in. Operations whose timer is nil are not timed.
+type Ops struct {
+	Read  *timer.Timer
+	Write *timer.Timer
+	Sync  *timer.Timer
+	Dial  *timer.Timer
+	// Time from the first write on a connection, or from wrapping it if
+	// nothing was written, to the first byte read
+	FirstByte *timer.Timer
+}
+
+// NewOps returns Ops whose timers are named prefix followed by ".read",
+// ".write", ".sync", ".dial", and ".first_byte" in r, created if they do
+// not exist yet.
+func NewOps(r *timer.Registry, prefix string) *Ops {
+	return &Ops{
+		Read:      r.GetOrCreate(prefix + ".read"),
+		Write:     r.GetOrCreate(prefix + ".write"),
+		Sync:      r.GetOrCreate(prefix + ".sync"),
+		Dial:      r.GetOrCreate(prefix + ".dial"),
+		FirstByte: r.GetOrCreate(prefix + ".first_byte"),
+	}
+}
+
+// observe records the time since start in t, if t is not nil.
+func observe(t *timer.Timer, start time.Time) {
+	if t != nil {
+		t.Observe(time.Since(start))
+	}
+}
@@ -0,0 +1,99 @@
+package timerio
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/jnpr-pranav/go-timer"
+)
+
+// Values of the "op" label of the TimerVec used by WrapConn.
+const (
+	OpDial      = "dial"
+	OpFirstByte = "first_byte"
+	OpRead      = "read"
+	OpWrite     = "write"
+)
+
+// NewConnVec returns a TimerVec labeled by "peer" and "op", as expected
+// by WrapConn, WrapListener, and VecDialFunc.
+func NewConnVec() *timer.TimerVec {
+	return timer.NewTimerVec("peer", "op")
+}
+
+// vecOps returns the connection timers of vec for peer, leaving Dial to
+// the callers that dial.
+func vecOps(vec *timer.TimerVec, peer string) *Ops {
+	return &Ops{
+		Read:      vec.WithLabelValues(peer, OpRead),
+		Write:     vec.WithLabelValues(peer, OpWrite),
+		FirstByte: vec.WithLabelValues(peer, OpFirstByte),
+	}
+}
+
+// peerName returns the host of the remote address of c, without the port
+// so that connections from one host share timers.
+func peerName(c net.Conn) string {
+	addr := c.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// WrapConn returns c recording its first-byte, read, and write latencies
+// into vec, which must be labeled like NewConnVec, under the peer label
+// name. An empty name stands for the host of the remote address.
+// It panics if vec does not have two labels.
+func WrapConn(c net.Conn, vec *timer.TimerVec, name string) net.Conn {
+	if name == "" {
+		name = peerName(c)
+	}
+	return NewConn(c, vecOps(vec, name))
+}
+
+// listener wraps accepted connections with WrapConn.
+type listener struct {
+	net.Listener
+	vec *timer.TimerVec
+}
+
+// WrapListener returns l wrapping every accepted connection with WrapConn,
+// keyed by the host of its remote address. Use TimerVec.SetLimit to bound
+// the number of peers tracked.
+func WrapListener(l net.Listener, vec *timer.TimerVec) net.Listener {
+	return &listener{Listener: l, vec: vec}
+}
+
+// Accept waits for the next connection and wraps it.
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return WrapConn(c, l.vec, ""), nil
+}
+
+// VecDialFunc is like DialFunc, recording into vec under the peer label
+// name, or the dialed address if name is empty.
+func VecDialFunc(d *net.Dialer, vec *timer.TimerVec, name string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d == nil {
+		d = &net.Dialer{}
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		peer := name
+		if peer == "" {
+			peer = addr
+		}
+		ops := vecOps(vec, peer)
+		ops.Dial = vec.WithLabelValues(peer, OpDial)
+		start := time.Now()
+		c, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		observe(ops.Dial, start)
+		return NewConn(c, ops), nil
+	}
+}
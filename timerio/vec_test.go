@@ -0,0 +1,87 @@
+package timerio
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestWrapListener(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on loopback: %v", err)
+	}
+	serverVec := NewConnVec()
+	l := WrapListener(raw, serverVec)
+	defer l.Close()
+	done := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer c.Close()
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(c, buf); err != nil {
+			done <- err
+			return
+		}
+		_, err = c.Write(buf)
+		done <- err
+	}()
+
+	clientVec := NewConnVec()
+	dial := VecDialFunc(nil, clientVec, "backend")
+	c, err := dial(context.Background(), "tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	client := clientVec.Snapshot()
+	for _, op := range []string{OpDial, OpWrite, OpRead, OpFirstByte} {
+		name := `peer="backend",op="` + op + `"`
+		if client[name].Count == 0 {
+			t.Errorf("Expected %s to be recorded, got %v", name, client)
+		}
+	}
+	if s := client[`peer="backend",op="first_byte"`]; s.Count != 1 {
+		t.Errorf("Expected first byte recorded once, got %d", s.Count)
+	}
+
+	server := serverVec.Snapshot()
+	if s := server[`peer="127.0.0.1",op="read"`]; s.Count == 0 {
+		t.Errorf("Expected server reads keyed by remote host, got %v", server)
+	}
+	if _, ok := server[`peer="127.0.0.1",op="dial"`]; ok {
+		t.Errorf("Expected no dial timer for accepted connections, got %v", server)
+	}
+}
+
+func TestWrapConnName(t *testing.T) {
+	vec := NewConnVec()
+	client, server := net.Pipe()
+	defer server.Close()
+	c := WrapConn(client, vec, "cache")
+	go server.Write([]byte("x"))
+	buf := make([]byte, 1)
+	if _, err := c.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if s := vec.Snapshot()[`peer="cache",op="first_byte"`]; s.Count != 1 {
+		t.Errorf("Expected first byte recorded under the given name, got %v", vec.Snapshot())
+	}
+}
@@ -0,0 +1,34 @@
+package timertest
+
+import (
+	"testing"
+	"time"
+
+	timer "github.com/jnpr-pranav/go-timer"
+)
+
+// BenchLoop runs fn once per iteration of b.Loop, timing every iteration,
+// and reports the per-op latency distribution alongside ns/op as the
+// p50-ns/op, p99-ns/op and max-ns/op metrics:
+//
+//	func BenchmarkQuery(b *testing.B) {
+//		timertest.BenchLoop(b, func() { db.Query(q) })
+//	}
+//
+// Timing each iteration adds the cost of two clock reads to ns/op, so
+// BenchLoop suits operations well above a microsecond. It returns the
+// recorded histogram for further analysis.
+func BenchLoop(b *testing.B, fn func()) timer.ExpHistogramSnapshot {
+	b.Helper()
+	h := timer.NewExpHistogram(0)
+	for b.Loop() {
+		start := time.Now()
+		fn()
+		h.Observe(time.Since(start))
+	}
+	s := h.ExpSnapshot()
+	b.ReportMetric(float64(s.Quantile(0.5)), "p50-ns/op")
+	b.ReportMetric(float64(s.Quantile(0.99)), "p99-ns/op")
+	b.ReportMetric(float64(s.Max), "max-ns/op")
+	return s
+}
@@ -0,0 +1,21 @@
+package timertest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBenchLoop(t *testing.T) {
+	var count uint64
+	r := testing.Benchmark(func(b *testing.B) {
+		count = BenchLoop(b, func() { time.Sleep(time.Microsecond) }).Count
+	})
+	for _, metric := range []string{"p50-ns/op", "p99-ns/op", "max-ns/op"} {
+		if r.Extra[metric] <= 0 {
+			t.Errorf("Expected metric %s to be reported, got %v", metric, r.Extra)
+		}
+	}
+	if r.Extra["p50-ns/op"] > r.Extra["max-ns/op"] || count == 0 {
+		t.Errorf("Unexpected result %v with %d iterations", r.Extra, count)
+	}
+}
@@ -0,0 +1,145 @@
+// Package timertest provides helpers for testing code instrumented with
+// timers.
+package timertest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	timer "github.com/jnpr-pranav/go-timer"
+)
+
+var _ timer.Interface = (*RecordingTimer)(nil)
+
+// RecordingTimer is a timer.Interface that stores every observed duration,
+// so tests can inject it into instrumented code and assert on exactly what
+// was recorded. It ignores timer.Disable. The zero value is ready to use.
+type RecordingTimer struct {
+	mutex     sync.Mutex
+	durations []time.Duration
+}
+
+// NewRecordingTimer returns an empty RecordingTimer.
+func NewRecordingTimer() *RecordingTimer {
+	return &RecordingTimer{}
+}
+
+// Observe records d.
+func (r *RecordingTimer) Observe(d time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.durations = append(r.durations, d)
+}
+
+// Update records the duration since start.
+// Returns an error if start is a zero time value.
+func (r *RecordingTimer) Update(start time.Time) error {
+	return r.UpdateAt(start, time.Now())
+}
+
+// UpdateAt records the duration between start and now, clamped to
+// non-negative values.
+// Returns an error if start or now is a zero time value.
+func (r *RecordingTimer) UpdateAt(start, now time.Time) error {
+	if start.IsZero() || now.IsZero() {
+		return timer.ErrZeroTime
+	}
+	r.Observe(max(now.Sub(start), 0))
+	return nil
+}
+
+// Time calls fn and records how long it took.
+func (r *RecordingTimer) Time(fn func()) {
+	start := time.Now()
+	defer func() { r.Observe(time.Since(start)) }()
+	fn()
+}
+
+// Durations returns a copy of the recorded durations in observation order.
+func (r *RecordingTimer) Durations() []time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]time.Duration(nil), r.durations...)
+}
+
+// Between returns the number of recorded durations d with lo <= d <= hi.
+func (r *RecordingTimer) Between(lo, hi time.Duration) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	n := 0
+	for _, d := range r.durations {
+		if d >= lo && d <= hi {
+			n++
+		}
+	}
+	return n
+}
+
+// ExpectCount fails tb unless exactly n durations were recorded.
+func (r *RecordingTimer) ExpectCount(tb testing.TB, n int) {
+	tb.Helper()
+	if got := len(r.Durations()); got != n {
+		tb.Errorf("Expected %d observations, got %d: %v", n, got, r.Durations())
+	}
+}
+
+// ExpectBetween fails tb unless exactly n recorded durations lie between
+// lo and hi inclusive.
+func (r *RecordingTimer) ExpectBetween(tb testing.TB, n int, lo, hi time.Duration) {
+	tb.Helper()
+	if got := r.Between(lo, hi); got != n {
+		tb.Errorf("Expected %d observations between %v and %v, got %d: %v", n, lo, hi, got, r.Durations())
+	}
+}
+
+// ExpectAll fails tb unless every recorded duration lies between lo and hi
+// inclusive.
+func (r *RecordingTimer) ExpectAll(tb testing.TB, lo, hi time.Duration) {
+	tb.Helper()
+	durations := r.Durations()
+	for _, d := range durations {
+		if d < lo || d > hi {
+			tb.Errorf("Expected all observations between %v and %v, got %v", lo, hi, durations)
+			return
+		}
+	}
+}
+
+// Count returns the number of recorded durations.
+func (r *RecordingTimer) Count() uint64 {
+	return r.Snapshot().Count
+}
+
+// Max returns the longest recorded duration.
+func (r *RecordingTimer) Max() time.Duration {
+	return r.Snapshot().Max
+}
+
+// Min returns the shortest recorded duration.
+func (r *RecordingTimer) Min() time.Duration {
+	return r.Snapshot().Min
+}
+
+// Mean returns the mean recorded duration.
+func (r *RecordingTimer) Mean() time.Duration {
+	return r.Snapshot().Mean()
+}
+
+// Snapshot summarizes the recorded durations like a timer.Timer would.
+func (r *RecordingTimer) Snapshot() timer.Snapshot {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var s timer.Snapshot
+	for _, d := range r.durations {
+		s = s.Merge(timer.Snapshot{Count: 1, Min: d, Max: d, Sum: d})
+	}
+	return s
+}
+
+// Reset discards all recorded durations.
+func (r *RecordingTimer) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.durations = nil
+}
@@ -0,0 +1,82 @@
+package timertest
+
+import (
+	"testing"
+	"time"
+
+	timer "github.com/jnpr-pranav/go-timer"
+)
+
+func instrumented(t timer.Interface, ds ...time.Duration) {
+	for _, d := range ds {
+		t.Observe(d)
+	}
+}
+
+func TestRecordingTimer(t *testing.T) {
+	r := NewRecordingTimer()
+	instrumented(r, 12*time.Millisecond, 15*time.Millisecond, 20*time.Millisecond, 30*time.Millisecond)
+
+	r.ExpectCount(t, 4)
+	r.ExpectBetween(t, 3, 10*time.Millisecond, 20*time.Millisecond)
+	r.ExpectAll(t, 10*time.Millisecond, 30*time.Millisecond)
+
+	s := r.Snapshot()
+	want := timer.Snapshot{Count: 4, Min: 12 * time.Millisecond, Max: 30 * time.Millisecond, Sum: 77 * time.Millisecond}
+	if s != want {
+		t.Errorf("Snapshot() = %+v; want %+v", s, want)
+	}
+	if r.Mean() != 19250*time.Microsecond {
+		t.Errorf("Mean() = %v; want 19.25ms", r.Mean())
+	}
+
+	r.Reset()
+	r.ExpectCount(t, 0)
+}
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Helper()               {}
+func (f *fakeTB) Errorf(string, ...any) { f.failed = true }
+func (f *fakeTB) Failed() bool          { return f.failed }
+
+func TestRecordingTimerExpectFailures(t *testing.T) {
+	r := NewRecordingTimer()
+	r.Observe(time.Second)
+
+	ft := &fakeTB{}
+	r.ExpectCount(ft, 2)
+	if !ft.Failed() {
+		t.Errorf("Expected ExpectCount to fail")
+	}
+	ft = &fakeTB{}
+	r.ExpectBetween(ft, 1, 0, time.Millisecond)
+	if !ft.Failed() {
+		t.Errorf("Expected ExpectBetween to fail")
+	}
+	ft = &fakeTB{}
+	r.ExpectAll(ft, 0, time.Millisecond)
+	if !ft.Failed() {
+		t.Errorf("Expected ExpectAll to fail")
+	}
+}
+
+func TestRecordingTimerUpdate(t *testing.T) {
+	r := NewRecordingTimer()
+	start := time.Now()
+	if err := r.UpdateAt(start, start.Add(5*time.Millisecond)); err != nil {
+		t.Fatalf("UpdateAt() = %v; want nil", err)
+	}
+	if err := r.Update(time.Time{}); err == nil {
+		t.Errorf("Expected zero start to be rejected")
+	}
+	r.Time(func() {})
+	r.ExpectCount(t, 2)
+	if d := r.Durations()[0]; d != 5*time.Millisecond {
+		t.Errorf("Durations()[0] = %v; want 5ms", d)
+	}
+}
@@ -0,0 +1,90 @@
+package timertest
+
+import (
+	"sync"
+	"time"
+
+	timer "github.com/jnpr-pranav/go-timer"
+)
+
+var _ timer.Clock = (*Clock)(nil)
+
+// Epoch is the time a Simulation starts at.
+var Epoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a fake timer.Clock that only moves when told to. It is safe for
+// concurrent use.
+type Clock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+// NewClock returns a Clock stopped at start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *Clock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = t
+}
+
+// Step is one entry of a simulation script: the clock idles for Idle, then
+// an operation runs for Op and is recorded when it ends.
+type Step struct {
+	Idle time.Duration
+	Op   time.Duration
+}
+
+// Simulation drives timers and windowed recorders with a fake clock, so
+// time-dependent statistics can be asserted exactly:
+//
+//	sim := timertest.NewSimulation()
+//	t := timer.NewTimer()
+//	t.SetClock(sim.Clock)
+//	sim.Play(t, timertest.Step{Op: 10 * time.Millisecond}, timertest.Step{Idle: time.Second, Op: 20 * time.Millisecond})
+type Simulation struct {
+	Clock *Clock
+}
+
+// NewSimulation returns a Simulation whose clock starts at Epoch.
+func NewSimulation() *Simulation {
+	return &Simulation{Clock: NewClock(Epoch)}
+}
+
+// Sleep advances the clock by d.
+func (s *Simulation) Sleep(d time.Duration) {
+	s.Clock.Advance(d)
+}
+
+// Run simulates an operation lasting d that ends now on the clock, which
+// advances by d, and records it in t.
+func (s *Simulation) Run(t timer.Interface, d time.Duration) {
+	start := s.Clock.Now()
+	_ = t.UpdateAt(start, s.Clock.Advance(d))
+}
+
+// Play runs the script of steps against t in order.
+func (s *Simulation) Play(t timer.Interface, steps ...Step) {
+	for _, step := range steps {
+		s.Sleep(step.Idle)
+		s.Run(t, step.Op)
+	}
+}
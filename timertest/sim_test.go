@@ -0,0 +1,57 @@
+package timertest
+
+import (
+	"testing"
+	"time"
+
+	timer "github.com/jnpr-pranav/go-timer"
+)
+
+func TestSimulation(t *testing.T) {
+	sim := NewSimulation()
+	tm := timer.NewTimer()
+	tm.SetClock(sim.Clock)
+	sim.Play(tm,
+		Step{Op: 10 * time.Millisecond},
+		Step{Idle: 980 * time.Millisecond, Op: 10 * time.Millisecond},
+	)
+	if s := tm.Snapshot(); s.Count != 2 || s.Sum != 20*time.Millisecond {
+		t.Errorf("Snapshot() = %+v; want 2 observations summing to 20ms", s)
+	}
+	if got := sim.Clock.Now().Sub(Epoch); got != time.Second {
+		t.Errorf("Clock advanced %v; want 1s", got)
+	}
+	if tp := tm.Throughput(); tp != 2 {
+		t.Errorf("Throughput() = %v; want 2", tp)
+	}
+}
+
+func TestSimulationHeatmap(t *testing.T) {
+	sim := NewSimulation()
+	h, err := timer.NewHeatmapRecorder([]time.Duration{10 * time.Millisecond}, time.Minute, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetClock(sim.Clock)
+	tm := timer.NewTimer()
+	tm.SetClock(sim.Clock)
+	if err := tm.AddAggregator("heatmap", h); err != nil {
+		t.Fatal(err)
+	}
+
+	sim.Run(tm, 5*time.Millisecond)
+	sim.Sleep(time.Minute)
+	sim.Run(tm, 50*time.Millisecond)
+	sim.Run(tm, 5*time.Millisecond)
+
+	m := h.Heatmap()
+	if len(m.Windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(m.Windows))
+	}
+	want := [][]uint64{{0, 0}, {1, 0}, {1, 1}}
+	for i, w := range m.Windows {
+		if w.Counts[0] != want[i][0] || w.Counts[1] != want[i][1] {
+			t.Errorf("Window %d counts = %v; want %v", i, w.Counts, want[i])
+		}
+	}
+}
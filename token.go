@@ -0,0 +1,135 @@
+package timer
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned when decoding a malformed StopwatchToken.
+var ErrInvalidToken = errors.New("invalid stopwatch token")
+
+// ErrUnknownTimer is returned when completing a StopwatchToken whose timer
+// is not registered.
+var ErrUnknownTimer = errors.New("timer not registered")
+
+// tokenVersion prefixes the text encoding of a StopwatchToken.
+const tokenVersion = "v1"
+
+var (
+	// processOrigin identifies this process in issued tokens.
+	processOrigin = rand.Uint64() | 1
+	// processEpoch is the zero point of monotonic hints in issued tokens.
+	processEpoch = time.Now()
+)
+
+// StopwatchToken is the start of a measurement that can be sent to another
+// process, for example in a message header, and completed there to time
+// cross-service end-to-end latency.
+//
+// When completed in a different process the elapsed time is computed from
+// wall clocks and is subject to clock skew between the hosts. When
+// completed in the issuing process the monotonic hint is used instead.
+type StopwatchToken struct {
+	// Name is the timer the measurement is recorded in.
+	Name string
+	// Start is the wall-clock start time.
+	Start time.Time
+	// Origin identifies the issuing process.
+	Origin uint64
+	// Mono is the start on the issuing process's monotonic clock.
+	Mono time.Duration
+}
+
+// NewStopwatchToken starts a measurement for the timer named name.
+func NewStopwatchToken(name string) StopwatchToken {
+	mono := time.Since(processEpoch)
+	return StopwatchToken{
+		Name:   name,
+		Start:  processEpoch.Add(mono).Round(0),
+		Origin: processOrigin,
+		Mono:   mono,
+	}
+}
+
+// Elapsed returns the time since the token was issued, clamped to 0 if the
+// clocks disagree.
+func (tok StopwatchToken) Elapsed() time.Duration {
+	if tok.Origin == processOrigin {
+		return max(time.Since(processEpoch)-tok.Mono, 0)
+	}
+	return max(time.Since(tok.Start), 0)
+}
+
+// Complete records the elapsed time in the timer named tok.Name in r and
+// returns it. Token names come from other processes and cannot be
+// trusted, so only timers already registered in r are recorded into;
+// register the timers a service accepts tokens for beforehand. Returns
+// ErrUnknownTimer, and records nothing, if there is no such timer.
+func (tok StopwatchToken) Complete(r *Registry) (time.Duration, error) {
+	d := tok.Elapsed()
+	t := r.Get(tok.Name)
+	if t == nil {
+		return d, ErrUnknownTimer
+	}
+	t.Observe(d)
+	return d, nil
+}
+
+// String returns the text encoding of the token.
+func (tok StopwatchToken) String() string {
+	b, _ := tok.MarshalText()
+	return string(b)
+}
+
+// MarshalText encodes the token as
+// "v1:<start unix ns>:<origin hex>:<mono ns>:<name>", suitable for use as
+// a header value.
+func (tok StopwatchToken) MarshalText() ([]byte, error) {
+	b := make([]byte, 0, 64+len(tok.Name))
+	b = append(b, tokenVersion...)
+	b = append(b, ':')
+	b = strconv.AppendInt(b, tok.Start.UnixNano(), 10)
+	b = append(b, ':')
+	b = strconv.AppendUint(b, tok.Origin, 16)
+	b = append(b, ':')
+	b = strconv.AppendInt(b, int64(tok.Mono), 10)
+	b = append(b, ':')
+	return append(b, tok.Name...), nil
+}
+
+// UnmarshalText decodes a token encoded by MarshalText.
+func (tok *StopwatchToken) UnmarshalText(text []byte) error {
+	parts := strings.SplitN(string(text), ":", 5)
+	if len(parts) != 5 || parts[0] != tokenVersion {
+		return ErrInvalidToken
+	}
+	start, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	origin, err := strconv.ParseUint(parts[2], 16, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	mono, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	*tok = StopwatchToken{
+		Name:   parts[4],
+		Start:  time.Unix(0, start),
+		Origin: origin,
+		Mono:   time.Duration(mono),
+	}
+	return nil
+}
+
+// ParseStopwatchToken decodes a token encoded by MarshalText.
+func ParseStopwatchToken(s string) (StopwatchToken, error) {
+	var tok StopwatchToken
+	err := tok.UnmarshalText([]byte(s))
+	return tok, err
+}
@@ -0,0 +1,66 @@
+package timer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStopwatchTokenRoundTrip(t *testing.T) {
+	tok := NewStopwatchToken("orders:end-to-end")
+	got, err := ParseStopwatchToken(tok.String())
+	if err != nil {
+		t.Fatalf("ParseStopwatchToken failed: %v", err)
+	}
+	if got.Name != tok.Name || !got.Start.Equal(tok.Start) || got.Origin != tok.Origin || got.Mono != tok.Mono {
+		t.Errorf("Round trip = %+v; want %+v", got, tok)
+	}
+}
+
+func TestStopwatchTokenComplete(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister("rpc", NewTimer())
+	tok := NewStopwatchToken("rpc")
+	time.Sleep(5 * time.Millisecond)
+	d, err := tok.Complete(r)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if d < 5*time.Millisecond {
+		t.Errorf("Expected elapsed >= 5ms, got %v", d)
+	}
+	if timer := r.Get("rpc"); timer == nil || timer.Count() != 1 || timer.Max() != d {
+		t.Errorf("Expected measurement recorded in registry, got %v", timer)
+	}
+}
+
+func TestStopwatchTokenCompleteUnknown(t *testing.T) {
+	r := NewRegistry()
+	if _, err := NewStopwatchToken("attacker-chosen").Complete(r); !errors.Is(err, ErrUnknownTimer) {
+		t.Errorf("Expected ErrUnknownTimer, got %v", err)
+	}
+	if r.Len() != 0 {
+		t.Errorf("Expected no timer created for an unknown name, got %d", r.Len())
+	}
+}
+
+func TestStopwatchTokenRemote(t *testing.T) {
+	// a token from another process falls back to the wall clock
+	tok := StopwatchToken{Name: "remote", Start: time.Now().Add(-time.Second), Origin: processOrigin + 1}
+	if d := tok.Elapsed(); d < time.Second || d > time.Minute {
+		t.Errorf("Elapsed = %v; want about 1s", d)
+	}
+	// clock skew never yields a negative duration
+	tok.Start = time.Now().Add(time.Hour)
+	if d := tok.Elapsed(); d != 0 {
+		t.Errorf("Elapsed = %v; want 0 for future start", d)
+	}
+}
+
+func TestParseStopwatchTokenInvalid(t *testing.T) {
+	for _, s := range []string{"", "v2:1:1:1:x", "v1:x:1:1:x", "v1:1:zz:1:x", "v1:1:1:x:x", "v1:1:1:1"} {
+		if _, err := ParseStopwatchToken(s); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("ParseStopwatchToken(%q) = %v; want ErrInvalidToken", s, err)
+		}
+	}
+}
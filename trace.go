@@ -0,0 +1,130 @@
+package timer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// traceNameLen is the maximum number of name bytes carried by a trace event.
+// Longer names are truncated.
+const traceNameLen = 64
+
+// traceBackend writes events to an OS tracing facility.
+type traceBackend interface {
+	observation(name string, d time.Duration) error
+	snapshot(name string, s Snapshot) error
+	close() error
+}
+
+// TraceEmitter emits timer observations and snapshots as OS-level trace
+// events, so system profilers can correlate application timings with kernel
+// activity. Events are written to Linux user_events on Linux and to ETW on
+// Windows; other platforms are not supported.
+//
+// Snapshots are emitted with EmitSnapshot, EmitRegistry or Report, and
+// observations with EmitObservation or, for every observation a timer
+// records, by attaching the timer with Attach. Emitting is cheap when no
+// tracing session is consuming the events.
+type TraceEmitter struct {
+	backend traceBackend
+	aggName string
+
+	mutex    sync.Mutex
+	attached []*Timer // detached on Close
+}
+
+// NewTraceEmitter registers the trace events under provider and returns an
+// emitter for them. On Linux, provider prefixes the user_events event names
+// ("<provider>_observation", "<provider>_snapshot"); on Windows it is the
+// ETW provider name from which the provider GUID is derived.
+// Returns errors.ErrUnsupported on other platforms.
+func NewTraceEmitter(provider string) (*TraceEmitter, error) {
+	b, err := newTraceBackend(provider)
+	if err != nil {
+		return nil, err
+	}
+	return newTraceEmitter(b), nil
+}
+
+func newTraceEmitter(b traceBackend) *TraceEmitter {
+	e := &TraceEmitter{backend: b}
+	e.aggName = fmt.Sprintf("timer.TraceEmitter(%p)", e)
+	return e
+}
+
+// Attach emits every observation t records as an observation event under
+// name from now on, until Close.
+func (e *TraceEmitter) Attach(name string, t *Timer) error {
+	if err := t.AddAggregator(e.aggName, traceTap{e, truncateName(name)}); err != nil {
+		return err
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.attached = append(e.attached, t)
+	return nil
+}
+
+// traceTap emits a timer's observations through a TraceEmitter.
+type traceTap struct {
+	emitter *TraceEmitter
+	name    string
+}
+
+func (tap traceTap) Observe(d time.Duration) { _ = tap.emitter.backend.observation(tap.name, d) }
+func (tap traceTap) Snapshot() any           { return nil }
+func (tap traceTap) Reset()                  {}
+func (tap traceTap) Merge(Aggregator) error  { return nil }
+
+// EmitObservation emits a single observed duration for the named timer.
+func (e *TraceEmitter) EmitObservation(name string, d time.Duration) error {
+	return e.backend.observation(truncateName(name), d)
+}
+
+// EmitSnapshot emits the statistics of the named timer.
+func (e *TraceEmitter) EmitSnapshot(name string, s Snapshot) error {
+	return e.backend.snapshot(truncateName(name), s)
+}
+
+// EmitRegistry emits a snapshot event for every timer in r.
+// Returns the first error encountered, after attempting all timers.
+func (e *TraceEmitter) EmitRegistry(r *Registry) error {
+	var firstErr error
+	for name, s := range r.Snapshot() {
+		if err := e.EmitSnapshot(name, s); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Report emits a snapshot event for every entry of snaps. Its signature
+// matches NewReporter, so periodic emission is set up with
+//
+//	timer.NewReporter(r, 10*time.Second, e.Report)
+func (e *TraceEmitter) Report(snaps map[string]Snapshot) {
+	for name, s := range snaps {
+		_ = e.EmitSnapshot(name, s)
+	}
+}
+
+// Close detaches the emitter from its timers and unregisters the trace
+// events.
+func (e *TraceEmitter) Close() error {
+	e.mutex.Lock()
+	attached := e.attached
+	e.attached = nil
+	e.mutex.Unlock()
+	for _, t := range attached {
+		t.RemoveAggregator(e.aggName)
+	}
+	return e.backend.close()
+}
+
+// truncateName shortens name to at most traceNameLen bytes.
+func truncateName(name string) string {
+	if len(name) > traceNameLen {
+		return name[:traceNameLen]
+	}
+	return name
+}
@@ -0,0 +1,138 @@
+package timer
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// userEventsPaths lists the locations of the user_events ABI file, newest first.
+var userEventsPaths = []string{
+	"/sys/kernel/tracing/user_events_data",
+	"/sys/kernel/debug/tracing/user_events_data",
+}
+
+// diagIocsreg is DIAG_IOCSREG, _IOWR('*', 0, struct user_reg *), whose
+// size field is that of a pointer: 0xC0082A00 on 64-bit platforms and
+// 0xC0042A00 on 32-bit ones.
+const diagIocsreg = 3<<30 | unsafe.Sizeof(uintptr(0))<<16 | '*'<<8
+
+// userReg mirrors the kernel's packed struct user_reg.
+type userReg struct {
+	size       uint32
+	enableBit  uint8
+	enableSize uint8
+	flags      uint16
+	enableAddr uint64
+	nameArgs   uint64
+	writeIndex uint32
+}
+
+// userRegSize is sizeof(struct user_reg) without Go's trailing padding.
+const userRegSize = 28
+
+// userEvent is a single registered user_events tracepoint.
+type userEvent struct {
+	// enabled is written by the kernel when a tracing session attaches.
+	enabled    *uint32
+	writeIndex uint32
+}
+
+type userEventsBackend struct {
+	file *os.File
+	obs  userEvent
+	snap userEvent
+}
+
+func newTraceBackend(provider string) (traceBackend, error) {
+	var f *os.File
+	var err error
+	for _, p := range userEventsPaths {
+		if f, err = os.OpenFile(p, os.O_RDWR, 0); err == nil {
+			break
+		}
+	}
+	if f == nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.ErrUnsupported
+		}
+		return nil, err
+	}
+	b := &userEventsBackend{file: f}
+	if b.obs, err = registerUserEvent(f, provider+"_observation char[64] name;u64 duration_ns"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if b.snap, err = registerUserEvent(f, provider+"_snapshot char[64] name;u64 count;u64 min_ns;u64 max_ns;u64 mean_ns"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// registerUserEvent registers an event described by the user_events format
+// string def and returns its write index and enable word.
+func registerUserEvent(f *os.File, def string) (userEvent, error) {
+	ev := userEvent{enabled: new(uint32)}
+	nameArgs := append([]byte(def), 0)
+	reg := userReg{
+		size:       userRegSize,
+		enableBit:  0,
+		enableSize: 4,
+		enableAddr: uint64(uintptr(unsafe.Pointer(ev.enabled))),
+		nameArgs:   uint64(uintptr(unsafe.Pointer(&nameArgs[0]))),
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), diagIocsreg, uintptr(unsafe.Pointer(&reg)))
+	runtime.KeepAlive(nameArgs)
+	if errno != 0 {
+		return userEvent{}, errno
+	}
+	ev.writeIndex = reg.writeIndex
+	return ev, nil
+}
+
+// write emits payload for ev if a tracing session is listening.
+func (b *userEventsBackend) write(ev userEvent, payload []byte) error {
+	if atomic.LoadUint32(ev.enabled) == 0 {
+		return nil
+	}
+	var idx [4]byte
+	binary.NativeEndian.PutUint32(idx[:], ev.writeIndex)
+	iov := [2]syscall.Iovec{{Base: &idx[0]}, {Base: &payload[0]}}
+	iov[0].SetLen(len(idx))
+	iov[1].SetLen(len(payload))
+	_, _, errno := syscall.Syscall(syscall.SYS_WRITEV, b.file.Fd(), uintptr(unsafe.Pointer(&iov[0])), uintptr(len(iov)))
+	runtime.KeepAlive(&idx)
+	runtime.KeepAlive(payload)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func (b *userEventsBackend) observation(name string, d time.Duration) error {
+	buf := make([]byte, traceNameLen, traceNameLen+8)
+	copy(buf, name)
+	buf = binary.NativeEndian.AppendUint64(buf, uint64(d))
+	return b.write(b.obs, buf)
+}
+
+func (b *userEventsBackend) snapshot(name string, s Snapshot) error {
+	buf := make([]byte, traceNameLen, traceNameLen+32)
+	copy(buf, name)
+	buf = binary.NativeEndian.AppendUint64(buf, s.Count)
+	buf = binary.NativeEndian.AppendUint64(buf, uint64(s.Min))
+	buf = binary.NativeEndian.AppendUint64(buf, uint64(s.Max))
+	buf = binary.NativeEndian.AppendUint64(buf, uint64(s.Mean()))
+	return b.write(b.snap, buf)
+}
+
+// close releases the ABI file, which unregisters the events.
+func (b *userEventsBackend) close() error {
+	return b.file.Close()
+}
@@ -0,0 +1,16 @@
+package timer
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestDiagIocsreg(t *testing.T) {
+	want := uintptr(0xC0082A00)
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		want = 0xC0042A00
+	}
+	if diagIocsreg != want {
+		t.Errorf("diagIocsreg = %#x; want %#x", diagIocsreg, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package timer
+
+import "errors"
+
+func newTraceBackend(string) (traceBackend, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,96 @@
+package timer
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeTraceBackend struct {
+	mu    sync.Mutex
+	obs   map[string]time.Duration
+	snaps map[string]Snapshot
+}
+
+func (f *fakeTraceBackend) observation(name string, d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.obs[name] = d
+	return nil
+}
+
+func (f *fakeTraceBackend) snapshot(name string, s Snapshot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snaps[name] = s
+	return nil
+}
+
+func (f *fakeTraceBackend) close() error { return nil }
+
+func TestTraceEmitter(t *testing.T) {
+	fb := &fakeTraceBackend{obs: map[string]time.Duration{}, snaps: map[string]Snapshot{}}
+	e := newTraceEmitter(fb)
+
+	long := strings.Repeat("x", traceNameLen+10)
+	if err := e.EmitObservation(long, time.Millisecond); err != nil {
+		t.Fatalf("EmitObservation failed: %v", err)
+	}
+	if _, ok := fb.obs[long[:traceNameLen]]; !ok {
+		t.Errorf("Expected name to be truncated to %d bytes", traceNameLen)
+	}
+
+	r := NewRegistry()
+	t0 := NewTimer()
+	_ = r.Register("db", t0)
+	t0.Observe(time.Millisecond)
+	if err := e.EmitRegistry(r); err != nil {
+		t.Fatalf("EmitRegistry failed: %v", err)
+	}
+	if fb.snaps["db"] != t0.Snapshot() {
+		t.Errorf("Emitted snapshot = %+v; want %+v", fb.snaps["db"], t0.Snapshot())
+	}
+}
+
+func TestTraceEmitterAttach(t *testing.T) {
+	fb := &fakeTraceBackend{obs: map[string]time.Duration{}, snaps: map[string]Snapshot{}}
+	e := newTraceEmitter(fb)
+	timer := NewTimer()
+	if err := e.Attach("db", timer); err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	timer.Observe(3 * time.Millisecond)
+	if fb.obs["db"] != 3*time.Millisecond {
+		t.Errorf("Expected the observation to be emitted, got %v", fb.obs)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	timer.Observe(5 * time.Millisecond)
+	if fb.obs["db"] != 3*time.Millisecond || len(timer.AggregatorSnapshots()) != 0 {
+		t.Errorf("Expected Close to detach the emitter, got %v", fb.obs)
+	}
+}
+
+func TestNewTraceEmitter(t *testing.T) {
+	e, err := NewTraceEmitter("gotimer_test")
+	if errors.Is(err, errors.ErrUnsupported) || errors.Is(err, os.ErrPermission) {
+		t.Skipf("OS tracing not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("NewTraceEmitter failed: %v", err)
+	}
+	defer e.Close()
+
+	// no session is attached, so emitting must be a silent no-op
+	if err := e.EmitObservation("db", time.Millisecond); err != nil {
+		t.Errorf("EmitObservation failed: %v", err)
+	}
+	if err := e.EmitSnapshot("db", Snapshot{Count: 1}); err != nil {
+		t.Errorf("EmitSnapshot failed: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package timer
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var (
+	modadvapi32         = syscall.NewLazyDLL("advapi32.dll")
+	procEventRegister   = modadvapi32.NewProc("EventRegister")
+	procEventUnregister = modadvapi32.NewProc("EventUnregister")
+	procEventEnabled    = modadvapi32.NewProc("EventProviderEnabled")
+	procEventWriteStr   = modadvapi32.NewProc("EventWriteString")
+)
+
+// etwLevelInfo is TRACE_LEVEL_INFORMATION.
+const etwLevelInfo = 4
+
+// etwNamespace is the namespace used by EventSource and TraceLogging to
+// derive a provider GUID from a provider name.
+var etwNamespace = [16]byte{0x48, 0x2C, 0x2D, 0xB2, 0xC3, 0x90, 0x47, 0xC8, 0x87, 0xF8, 0x1A, 0x15, 0xBF, 0xC1, 0x30, 0xFB}
+
+type etwBackend struct {
+	handle uint64
+}
+
+func newTraceBackend(provider string) (traceBackend, error) {
+	if err := procEventRegister.Find(); err != nil {
+		return nil, err
+	}
+	guid := providerGUID(provider)
+	b := &etwBackend{}
+	r, _, _ := procEventRegister.Call(uintptr(unsafe.Pointer(&guid)), 0, 0, uintptr(unsafe.Pointer(&b.handle)))
+	if r != 0 {
+		return nil, syscall.Errno(r)
+	}
+	return b, nil
+}
+
+// providerGUID derives the provider GUID for name the same way EventSource
+// does, so tools such as PerfView and WPR can enable the provider by name.
+func providerGUID(name string) syscall.GUID {
+	h := sha1.New()
+	h.Write(etwNamespace[:])
+	for _, u := range utf16.Encode([]rune(strings.ToUpper(name))) {
+		h.Write([]byte{byte(u >> 8), byte(u)})
+	}
+	sum := h.Sum(nil)
+	sum[7] = (sum[7] & 0x0F) | 0x50
+	return syscall.GUID{
+		Data1: binary.LittleEndian.Uint32(sum[0:4]),
+		Data2: binary.LittleEndian.Uint16(sum[4:6]),
+		Data3: binary.LittleEndian.Uint16(sum[6:8]),
+		Data4: [8]byte(sum[8:16]),
+	}
+}
+
+// write emits msg as a string event if a session has enabled the provider.
+func (b *etwBackend) write(msg string) error {
+	// REGHANDLE, level and keyword mask
+	args := append(append(u64Args(b.handle), etwLevelInfo), u64Args(0)...)
+	if r, _, _ := procEventEnabled.Call(args...); r == 0 {
+		return nil
+	}
+	p, err := syscall.UTF16PtrFromString(msg)
+	if err != nil {
+		return err
+	}
+	r, _, _ := procEventWriteStr.Call(append(args, uintptr(unsafe.Pointer(p)))...)
+	runtime.KeepAlive(p)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+func (b *etwBackend) observation(name string, d time.Duration) error {
+	return b.write("observation name=" + strconv.Quote(name) + " duration_ns=" + strconv.FormatInt(int64(d), 10))
+}
+
+func (b *etwBackend) snapshot(name string, s Snapshot) error {
+	return b.write("snapshot name=" + strconv.Quote(name) +
+		" count=" + strconv.FormatUint(s.Count, 10) +
+		" min_ns=" + strconv.FormatInt(int64(s.Min), 10) +
+		" max_ns=" + strconv.FormatInt(int64(s.Max), 10) +
+		" mean_ns=" + strconv.FormatInt(int64(s.Mean()), 10))
+}
+
+func (b *etwBackend) close() error {
+	r, _, _ := procEventUnregister.Call(u64Args(b.handle)...)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+// u64Args returns the stack words of a 64-bit argument such as a
+// REGHANDLE or keyword mask: one on 64-bit platforms, and the low and
+// high halves on 32-bit ones.
+func u64Args(v uint64) []uintptr {
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		return []uintptr{uintptr(v), uintptr(v >> 32)}
+	}
+	return []uintptr{uintptr(v)}
+}
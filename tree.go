@@ -0,0 +1,432 @@
+package timer
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimerTree is a hierarchy of timers for the stages of an operation, such
+// as a request and the steps it runs, so a report can break the time of
+// each stage down into its sub-stages:
+//
+//	tree := timer.NewTimerTree("request")
+//	tree.Timer("db").Time(queryDB)
+//	tree.Timer("render", "template").Time(renderTemplate)
+//	...
+//	log.Print(tree.Report())
+//
+// Timers of a parent stage record the whole stage, including the time of
+// its children. Children run one after another unless SetGroup puts them
+// into a parallel group. TimerTree is safe for concurrent use.
+type TimerTree struct {
+	mutex sync.Mutex
+	root  *treeNode
+}
+
+// treeNode is one stage of a TimerTree.
+type treeNode struct {
+	name     string
+	group    string        // parallel group among its siblings, if any
+	budget   time.Duration // latency budget per run, 0 for none
+	timer    *Timer
+	children []*treeNode // in creation order
+}
+
+// child returns the child named name, creating it if needed.
+func (n *treeNode) child(name string) *treeNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &treeNode{name: name, timer: NewTimer()}
+	n.children = append(n.children, c)
+	return c
+}
+
+// NewTimerTree creates a TimerTree whose root stage is named name.
+func NewTimerTree(name string) *TimerTree {
+	return &TimerTree{root: &treeNode{name: name, timer: NewTimer()}}
+}
+
+// Timer returns the timer of the stage at path below the root, creating
+// the stage and its parents as needed. An empty path returns the root's
+// timer.
+func (tt *TimerTree) Timer(path ...string) *Timer {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	return tt.nodeNoLock(path).timer
+}
+
+// nodeNoLock returns the stage at path, creating it as needed.
+// Callers must hold the lock.
+func (tt *TimerTree) nodeNoLock(path []string) *treeNode {
+	n := tt.root
+	for _, name := range path {
+		n = n.child(name)
+	}
+	return n
+}
+
+// SetGroup puts the stage at path, creating it as needed, into the
+// parallel group named group among its siblings. Siblings in the same
+// group run concurrently, such as the calls of a fan-out, so only the
+// slowest of them counts towards the critical path of their parent.
+// Groups and ungrouped stages run one after another. An empty group
+// makes the stage sequential again. The root cannot be grouped.
+func (tt *TimerTree) SetGroup(group string, path ...string) {
+	if len(path) == 0 {
+		return
+	}
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	tt.nodeNoLock(path).group = group
+}
+
+// SetBudget assigns a latency budget to the stage at path, creating it as
+// needed, which reports compare with the stage's mean duration. A budget
+// of 0 removes it.
+func (tt *TimerTree) SetBudget(budget time.Duration, path ...string) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	tt.nodeNoLock(path).budget = max(budget, 0)
+}
+
+// String returns the report of the whole tree in LayoutText.
+func (tt *TimerTree) String() string {
+	return tt.Report().String()
+}
+
+// AppendFormat appends the report of the whole tree rendered in layout
+// to b.
+func (tt *TimerTree) AppendFormat(b []byte, layout Layout) []byte {
+	return tt.Report().AppendFormat(b, layout)
+}
+
+// TreeReport is the report of one stage of a TimerTree and its children.
+type TreeReport struct {
+	Name     string
+	Group    string        // parallel group among its siblings, if any
+	Budget   time.Duration // latency budget of the stage, 0 for none
+	Snapshot Snapshot
+	// CriticalPath is the time of the children along the critical path:
+	// the Sums of ungrouped children and of the slowest member of each
+	// parallel group. Computed from totals, it is exact if the slowest
+	// member of a group is the same in every run, and a lower bound
+	// otherwise.
+	CriticalPath time.Duration
+	// Exclusive is the time of the stage not recorded by its children,
+	// its Sum minus the CriticalPath, and not below zero. It is the Sum
+	// of a stage without children.
+	Exclusive time.Duration
+	// Critical reports whether the stage is on the critical path of the
+	// tree, which every stage is unless it is outrun by a sibling in a
+	// parallel group, or its parent is.
+	Critical bool
+	Children []TreeReport // in creation order
+}
+
+// Report returns the report of the whole tree.
+func (tt *TimerTree) Report() TreeReport {
+	tt.mutex.Lock()
+	r := tt.root.report()
+	tt.mutex.Unlock()
+	r.markCritical(true)
+	return r
+}
+
+// report builds the report of n, without the Critical flags.
+// Callers must hold the tree's lock.
+func (n *treeNode) report() TreeReport {
+	r := TreeReport{Name: n.name, Group: n.group, Budget: n.budget, Snapshot: n.timer.Snapshot()}
+	for _, c := range n.children {
+		r.Children = append(r.Children, c.report())
+	}
+	r.CriticalPath = criticalPath(r.Children, nil)
+	r.Exclusive = max(r.Snapshot.Sum-r.CriticalPath, 0)
+	return r
+}
+
+// markCritical sets the Critical flags of r and its descendants, r being
+// on the critical path if critical is true.
+func (r *TreeReport) markCritical(critical bool) {
+	r.Critical = critical
+	onPath := make([]bool, len(r.Children))
+	criticalPath(r.Children, onPath)
+	for i := range r.Children {
+		r.Children[i].markCritical(critical && onPath[i])
+	}
+}
+
+// criticalPath returns the time of children along the critical path and,
+// if onPath is not nil, sets which of them are on it. Ties within a group
+// go to the first child.
+func criticalPath(children []TreeReport, onPath []bool) time.Duration {
+	var total time.Duration
+	slowest := make(map[string]int) // group to index of its slowest child
+	for i, c := range children {
+		if c.Group == "" {
+			total += c.Snapshot.Sum
+			if onPath != nil {
+				onPath[i] = true
+			}
+			continue
+		}
+		if j, ok := slowest[c.Group]; !ok || c.Snapshot.Sum > children[j].Snapshot.Sum {
+			slowest[c.Group] = i
+		}
+	}
+	for _, i := range slowest {
+		total += children[i].Snapshot.Sum
+		if onPath != nil {
+			onPath[i] = true
+		}
+	}
+	return total
+}
+
+// Exclusive returns the time of parent not recorded by children, the Sum
+// of parent minus the Sums of children, and not below zero, which it
+// would be if children overlapped or recorded outside of parent. It
+// assumes the children ran one after another; see TreeReport.CriticalPath
+// for concurrent ones.
+func Exclusive(parent Snapshot, children ...Snapshot) time.Duration {
+	rest := parent.Sum
+	for _, c := range children {
+		rest -= c.Sum
+	}
+	return max(rest, 0)
+}
+
+// ExclusiveMean returns the exclusive time per observation of the stage.
+// Returns 0 if the stage has no observations.
+func (r TreeReport) ExclusiveMean() time.Duration {
+	if r.Snapshot.Count == 0 {
+		return 0
+	}
+	return r.Exclusive / time.Duration(r.Snapshot.Count)
+}
+
+// Find returns the report of the stage at path below r.
+// Returns false if there is no such stage.
+func (r TreeReport) Find(path ...string) (TreeReport, bool) {
+	for _, name := range path {
+		i := slices.IndexFunc(r.Children, func(c TreeReport) bool { return c.Name == name })
+		if i < 0 {
+			return TreeReport{}, false
+		}
+		r = r.Children[i]
+	}
+	return r, true
+}
+
+// BudgetReport compares the mean duration of a stage with its budget.
+type BudgetReport struct {
+	Path   []string // names from the root to the stage
+	Mean   time.Duration
+	Budget time.Duration
+}
+
+// Over returns how far the mean is over the budget, negative if it is
+// within.
+func (b BudgetReport) Over() time.Duration {
+	return b.Mean - b.Budget
+}
+
+// Budgets returns a BudgetReport for r and every stage below it with a
+// budget, in tree order.
+func (r TreeReport) Budgets() []BudgetReport {
+	var out []BudgetReport
+	var walk func(r TreeReport, path []string)
+	walk = func(r TreeReport, path []string) {
+		path = append(path[:len(path):len(path)], r.Name)
+		if r.Budget > 0 {
+			out = append(out, BudgetReport{Path: path, Mean: r.Snapshot.Mean(), Budget: r.Budget})
+		}
+		for _, c := range r.Children {
+			walk(c, path)
+		}
+	}
+	walk(r, nil)
+	return out
+}
+
+// String renders the tree as one line per stage with its total time and
+// share of its parent's. The time not recorded by the children of a stage
+// is shown on a "(self)" line, so unattributed time is explicit. Members
+// of a parallel group are labeled with the group, and those off the
+// critical path marked with a "-". Stages with a budget are followed by
+// their mean and budget:
+//
+//	request             300ms            (mean 300ms of 250ms, over)
+//	  auth               50ms  16.7%
+//	  shard1 [fan-out]  200ms  66.7%
+//	  shard2 [fan-out]  120ms  40.0%  -
+//	  (self)             50ms  16.7%
+func (r TreeReport) String() string {
+	return string(r.AppendFormat(nil, LayoutText))
+}
+
+// AppendFormat appends the report rendered in layout to b. LayoutText is
+// the layout of String. LayoutLogfmt and LayoutJSON write one line per
+// stage in tree order, with the path of the stage as the names from the
+// root joined by "/", its group if it has one, its Sum, its Exclusive
+// time and whether it is on the critical path. Stages with a budget add
+// the budget, their mean and whether the mean is over the budget:
+//
+//	path=request/shard2 group=fan-out sum=120ms exclusive=100ms critical=false
+//	{"path":"request/shard2","group":"fan-out","sum_ns":120000000,"exclusive_ns":100000000,"critical":false}
+//	path=request sum=300ms exclusive=50ms critical=true budget=250ms mean=300ms over=true
+func (r TreeReport) AppendFormat(b []byte, layout Layout) []byte {
+	if layout != LayoutLogfmt && layout != LayoutJSON {
+		return r.appendText(b)
+	}
+	first := true
+	var walk func(r TreeReport, path string)
+	walk = func(r TreeReport, path string) {
+		path += r.Name
+		if !first {
+			b = append(b, '\n')
+		}
+		first = false
+		b = r.appendStage(b, path, layout)
+		for _, c := range r.Children {
+			walk(c, path+"/")
+		}
+	}
+	walk(r, "")
+	return b
+}
+
+// appendStage appends the logfmt or JSON line of the stage of r at path,
+// without its children.
+func (r TreeReport) appendStage(b []byte, path string, layout Layout) []byte {
+	if layout == LayoutJSON {
+		b = append(b, `{"path":`...)
+		b = appendJSONString(b, path)
+		if r.Group != "" {
+			b = append(b, `,"group":`...)
+			b = appendJSONString(b, r.Group)
+		}
+		b = append(b, `,"sum_ns":`...)
+		b = strconv.AppendInt(b, int64(r.Snapshot.Sum), 10)
+		b = append(b, `,"exclusive_ns":`...)
+		b = strconv.AppendInt(b, int64(r.Exclusive), 10)
+		b = append(b, `,"critical":`...)
+		b = strconv.AppendBool(b, r.Critical)
+		if r.Budget > 0 {
+			mean := r.Snapshot.Mean()
+			b = append(b, `,"budget_ns":`...)
+			b = strconv.AppendInt(b, int64(r.Budget), 10)
+			b = append(b, `,"mean_ns":`...)
+			b = strconv.AppendInt(b, int64(mean), 10)
+			b = append(b, `,"over":`...)
+			b = strconv.AppendBool(b, mean > r.Budget)
+		}
+		return append(b, '}')
+	}
+	b = append(b, "path="...)
+	b = appendLogfmtValue(b, path)
+	if r.Group != "" {
+		b = append(b, " group="...)
+		b = appendLogfmtValue(b, r.Group)
+	}
+	b = append(b, " sum="...)
+	b = append(b, r.Snapshot.Sum.String()...)
+	b = append(b, " exclusive="...)
+	b = append(b, r.Exclusive.String()...)
+	b = append(b, " critical="...)
+	b = strconv.AppendBool(b, r.Critical)
+	if r.Budget > 0 {
+		mean := r.Snapshot.Mean()
+		b = append(b, " budget="...)
+		b = append(b, r.Budget.String()...)
+		b = append(b, " mean="...)
+		b = append(b, mean.String()...)
+		b = append(b, " over="...)
+		b = strconv.AppendBool(b, mean > r.Budget)
+	}
+	return b
+}
+
+// appendText appends the layout of String to b.
+func (r TreeReport) appendText(b []byte) []byte {
+	type line struct {
+		label, total, share, budget string
+		offPath                     bool
+	}
+	var lines []line
+	share := func(d, of time.Duration) string {
+		if of <= 0 {
+			return "-"
+		}
+		return strconv.FormatFloat(float64(d)/float64(of)*100, 'f', 1, 64) + "%"
+	}
+	var walk func(r TreeReport, indent string, parent time.Duration)
+	walk = func(r TreeReport, indent string, parent time.Duration) {
+		l := line{label: indent + r.Name, total: r.Snapshot.Sum.String(), offPath: !r.Critical}
+		if r.Group != "" {
+			l.label += " [" + r.Group + "]"
+		}
+		if indent != "" {
+			l.share = share(r.Snapshot.Sum, parent)
+		}
+		if r.Budget > 0 {
+			mean := r.Snapshot.Mean()
+			l.budget = "(mean " + mean.String() + " of " + r.Budget.String()
+			if mean > r.Budget {
+				l.budget += ", over"
+			}
+			l.budget += ")"
+		}
+		lines = append(lines, l)
+		if len(r.Children) == 0 {
+			return
+		}
+		for _, c := range r.Children {
+			walk(c, indent+"  ", r.Snapshot.Sum)
+		}
+		lines = append(lines, line{
+			label: indent + "  (self)",
+			total: r.Exclusive.String(),
+			share: share(r.Exclusive, r.Snapshot.Sum),
+		})
+	}
+	walk(r, "", 0)
+
+	var width, twidth, swidth int
+	var marks bool
+	for _, l := range lines {
+		width = max(width, len(l.label))
+		twidth = max(twidth, len(l.total))
+		swidth = max(swidth, len(l.share))
+		marks = marks || l.offPath
+	}
+	var sb strings.Builder
+	for i, l := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(l.label)
+		sb.WriteString(strings.Repeat(" ", width-len(l.label)+2+twidth-len(l.total)))
+		sb.WriteString(l.total)
+		if l.share != "" || l.budget != "" {
+			sb.WriteString(strings.Repeat(" ", 2+swidth-len(l.share)))
+			sb.WriteString(l.share)
+		}
+		switch {
+		case l.offPath:
+			sb.WriteString("  -")
+		case marks && l.budget != "":
+			sb.WriteString("   ")
+		}
+		if l.budget != "" {
+			sb.WriteString("  ")
+			sb.WriteString(l.budget)
+		}
+	}
+	return append(b, sb.String()...)
+}
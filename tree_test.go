@@ -0,0 +1,178 @@
+package timer
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimerTree(t *testing.T) {
+	tree := NewTimerTree("request")
+	tree.Timer().Observe(300 * time.Millisecond)
+	tree.Timer("db").Observe(200 * time.Millisecond)
+	tree.Timer("render", "template").Observe(50 * time.Millisecond)
+	tree.Timer("render").Observe(70 * time.Millisecond)
+	if tree.Timer("db") != tree.Timer("db") {
+		t.Errorf("Expected the same timer for the same path")
+	}
+
+	r := tree.Report()
+	if r.Name != "request" || len(r.Children) != 2 || r.Children[0].Name != "db" {
+		t.Fatalf("Unexpected report %+v", r)
+	}
+	if r.Exclusive != 30*time.Millisecond || r.ExclusiveMean() != 30*time.Millisecond {
+		t.Errorf("Exclusive = %v; want 30ms", r.Exclusive)
+	}
+	render, ok := r.Find("render")
+	if !ok || render.Exclusive != 20*time.Millisecond {
+		t.Errorf("Expected 20ms of render outside its template, got %+v", render)
+	}
+	if tmpl, ok := r.Find("render", "template"); !ok || tmpl.Exclusive != 50*time.Millisecond {
+		t.Errorf("Expected a leaf's exclusive time to be its sum, got %+v", tmpl)
+	}
+	if _, ok := r.Find("cache"); ok {
+		t.Errorf("Expected no cache stage")
+	}
+
+	want := "request       300ms\n" +
+		"  db          200ms  66.7%\n" +
+		"  render       70ms  23.3%\n" +
+		"    template   50ms  71.4%\n" +
+		"    (self)     20ms  28.6%\n" +
+		"  (self)       30ms  10.0%"
+	if got := r.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExclusive(t *testing.T) {
+	parent := Snapshot{Count: 1, Sum: 10}
+	if got := Exclusive(parent, Snapshot{Sum: 3}, Snapshot{Sum: 4}); got != 3 {
+		t.Errorf("Exclusive() = %v; want 3ns", got)
+	}
+	// overlapping children do not make it negative
+	if got := Exclusive(parent, Snapshot{Sum: 8}, Snapshot{Sum: 8}); got != 0 {
+		t.Errorf("Exclusive() = %v; want 0", got)
+	}
+}
+
+func TestTimerTreeCriticalPath(t *testing.T) {
+	tree := NewTimerTree("request")
+	tree.Timer().Observe(300 * time.Millisecond)
+	tree.Timer("auth").Observe(50 * time.Millisecond)
+	tree.SetGroup("fan-out", "shard1")
+	tree.SetGroup("fan-out", "shard2")
+	tree.Timer("shard1").Observe(200 * time.Millisecond)
+	tree.Timer("shard2").Observe(120 * time.Millisecond)
+	tree.Timer("shard2", "decode").Observe(20 * time.Millisecond)
+
+	r := tree.Report()
+	if r.CriticalPath != 250*time.Millisecond || r.Exclusive != 50*time.Millisecond {
+		t.Errorf("Expected a 250ms critical path leaving 50ms exclusive, got %v and %v", r.CriticalPath, r.Exclusive)
+	}
+	shard1, _ := r.Find("shard1")
+	shard2, _ := r.Find("shard2")
+	decode, _ := r.Find("shard2", "decode")
+	if !r.Critical || !shard1.Critical || shard2.Critical || decode.Critical {
+		t.Errorf("Expected only request and shard1 on the critical path, got %v %v %v %v",
+			r.Critical, shard1.Critical, shard2.Critical, decode.Critical)
+	}
+
+	want := "request             300ms\n" +
+		"  auth               50ms  16.7%\n" +
+		"  shard1 [fan-out]  200ms  66.7%\n" +
+		"  shard2 [fan-out]  120ms  40.0%  -\n" +
+		"    decode           20ms  16.7%  -\n" +
+		"    (self)          100ms  83.3%\n" +
+		"  (self)             50ms  16.7%"
+	if got := r.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	if tree.String() != want {
+		t.Errorf("TimerTree.String() =\n%s\nwant\n%s", tree.String(), want)
+	}
+	logfmt := strings.Split(string(r.AppendFormat(nil, LayoutLogfmt)), "\n")
+	wantLogfmt := []string{
+		"path=request sum=300ms exclusive=50ms critical=true",
+		"path=request/auth sum=50ms exclusive=50ms critical=true",
+		"path=request/shard1 group=fan-out sum=200ms exclusive=200ms critical=true",
+		"path=request/shard2 group=fan-out sum=120ms exclusive=100ms critical=false",
+		"path=request/shard2/decode sum=20ms exclusive=20ms critical=false",
+	}
+	if !slices.Equal(logfmt, wantLogfmt) {
+		t.Errorf("Logfmt =\n%s\nwant\n%s", strings.Join(logfmt, "\n"), strings.Join(wantLogfmt, "\n"))
+	}
+	lines := strings.Split(string(r.AppendFormat(nil, LayoutJSON)), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected a JSON line per stage, got %q", lines)
+	}
+	var stage struct {
+		Path        string `json:"path"`
+		Group       string `json:"group"`
+		SumNs       int64  `json:"sum_ns"`
+		ExclusiveNs int64  `json:"exclusive_ns"`
+		Critical    bool   `json:"critical"`
+	}
+	if err := json.Unmarshal([]byte(lines[3]), &stage); err != nil {
+		t.Fatalf("Invalid JSON line %q: %v", lines[3], err)
+	}
+	if stage.Path != "request/shard2" || stage.Group != "fan-out" || stage.SumNs != 120e6 ||
+		stage.ExclusiveNs != 100e6 || stage.Critical {
+		t.Errorf("Unexpected JSON stage %+v", stage)
+	}
+
+	// ungrouping makes the shards sequential again
+	tree.SetGroup("", "shard2")
+	if r := tree.Report(); r.CriticalPath != 370*time.Millisecond || r.Exclusive != 0 {
+		t.Errorf("Expected sequential shards to add up, got %v and %v", r.CriticalPath, r.Exclusive)
+	}
+}
+
+func TestTimerTreeBudgets(t *testing.T) {
+	tree := NewTimerTree("request")
+	tree.SetBudget(300 * time.Millisecond)
+	tree.SetBudget(100*time.Millisecond, "db")
+	tree.SetBudget(50*time.Millisecond, "render")
+	for range 2 {
+		tree.Timer().Observe(280 * time.Millisecond)
+		tree.Timer("db").Observe(200 * time.Millisecond)
+		tree.Timer("render").Observe(40 * time.Millisecond)
+	}
+
+	r := tree.Report()
+	budgets := r.Budgets()
+	if len(budgets) != 3 {
+		t.Fatalf("Expected 3 budgets, got %+v", budgets)
+	}
+	db := budgets[1]
+	if len(db.Path) != 2 || db.Path[0] != "request" || db.Path[1] != "db" || db.Mean != 200*time.Millisecond || db.Over() != 100*time.Millisecond {
+		t.Errorf("Expected db to be 100ms over budget, got %+v", db)
+	}
+	if over := budgets[2].Over(); over != -10*time.Millisecond {
+		t.Errorf("Expected render to be 10ms within budget, got %v", over)
+	}
+
+	want := "request   560ms         (mean 280ms of 300ms)\n" +
+		"  db      400ms  71.4%  (mean 200ms of 100ms, over)\n" +
+		"  render   80ms  14.3%  (mean 40ms of 50ms)\n" +
+		"  (self)   80ms  14.3%"
+	if got := r.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+	logfmt := strings.Split(string(r.AppendFormat(nil, LayoutLogfmt)), "\n")
+	if want := "path=request/db sum=400ms exclusive=400ms critical=true budget=100ms mean=200ms over=true"; logfmt[1] != want {
+		t.Errorf("Logfmt line = %q; want %q", logfmt[1], want)
+	}
+	lines := strings.Split(string(r.AppendFormat(nil, LayoutJSON)), "\n")
+	if want := `"budget_ns":50000000,"mean_ns":40000000,"over":false}`; !strings.HasSuffix(lines[2], want) {
+		t.Errorf("JSON line = %q; want suffix %q", lines[2], want)
+	}
+
+	tree.SetBudget(0, "db")
+	if n := len(tree.Report().Budgets()); n != 2 {
+		t.Errorf("Expected 2 budgets after removing one, got %d", n)
+	}
+}
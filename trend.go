@@ -0,0 +1,93 @@
+package timer
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotEnoughData is returned by FitTrend when fewer than two intervals
+// with observations at distinct times are available.
+var ErrNotEnoughData = errors.New("not enough data for a trend")
+
+// Trend is a least-squares line fitted to a metric of a timer's intervals
+// over time, for capacity planning.
+type Trend struct {
+	Start, End time.Time     // times of the first and last interval fitted
+	Points     int           // number of intervals fitted
+	Base       time.Duration // fitted metric at Start
+	PerHour    time.Duration // change of the metric per hour
+}
+
+// At returns the metric the trend predicts at t, which may be before
+// Start or after End. Predictions are not below zero.
+func (tr Trend) At(t time.Time) time.Duration {
+	v := float64(tr.Base) + float64(tr.PerHour)*t.Sub(tr.Start).Hours()
+	return time.Duration(max(v, 0))
+}
+
+// Forecast returns the metric the trend predicts d after End.
+func (tr Trend) Forecast(d time.Duration) time.Duration {
+	return tr.At(tr.End.Add(d))
+}
+
+// FitTrend fits a Trend to the records of a single timer in time order,
+// as returned by IntervalStore.Query. Records hold cumulative snapshots,
+// so FitTrend takes the metric of the interval between consecutive
+// records, and the first record only serves as the start of the second
+// interval. Intervals without observations are skipped.
+// A nil metric stands for Snapshot.Mean.
+// Returns ErrNotEnoughData if fewer than two intervals at distinct times
+// have observations.
+func FitTrend(recs []Record, metric func(Snapshot) time.Duration) (Trend, error) {
+	if metric == nil {
+		metric = Snapshot.Mean
+	}
+	var tr Trend
+	var xs, ys []float64
+	for i, rec := range recs {
+		if i == 0 {
+			continue
+		}
+		interval := intervalSnapshot(recs[i-1].Snapshot, rec.Snapshot)
+		if interval.Count == 0 {
+			continue
+		}
+		if tr.Points == 0 {
+			tr.Start = rec.Time
+		}
+		tr.End = rec.Time
+		tr.Points++
+		xs = append(xs, rec.Time.Sub(tr.Start).Hours())
+		ys = append(ys, float64(metric(interval)))
+	}
+
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx, my = mx/n, my/n
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		sxy += (xs[i] - mx) * (ys[i] - my)
+	}
+	if len(xs) < 2 || sxx == 0 {
+		return Trend{}, ErrNotEnoughData
+	}
+	slope := sxy / sxx
+	tr.PerHour = time.Duration(slope)
+	tr.Base = time.Duration(my - slope*mx)
+	return tr, nil
+}
+
+// Trend fits a Trend to the intervals of the timer named name stored at
+// or after from and before to, see FitTrend.
+func (s *IntervalStore) Trend(name string, from, to time.Time, metric func(Snapshot) time.Duration) (Trend, error) {
+	recs, err := s.Query(name, from, to)
+	if err != nil {
+		return Trend{}, err
+	}
+	return FitTrend(recs, metric)
+}
@@ -0,0 +1,51 @@
+package timer
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFitTrend(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "intervals.jsonl")
+	s, err := OpenIntervalStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// interval means of 10ms growing by 2ms per hour, after a baseline
+	cum := Snapshot{Count: 1, Min: time.Second, Max: time.Second, Sum: time.Second}
+	for i := range 7 {
+		at := start.Add(time.Duration(i) * time.Hour)
+		if i > 0 {
+			mean := 10*time.Millisecond + time.Duration(i-1)*2*time.Millisecond
+			cum = cum.Merge(Snapshot{Count: 4, Min: mean, Max: mean, Sum: 4 * mean})
+		}
+		s.now = func() time.Time { return at }
+		s.Export(map[string]Snapshot{"db": cum})
+	}
+
+	tr, err := s.Trend("db", start, start.Add(24*time.Hour), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Points != 6 || !tr.Start.Equal(start.Add(time.Hour)) || !tr.End.Equal(start.Add(6*time.Hour)) {
+		t.Errorf("Expected 6 points from hour 1 to 6, got %+v", tr)
+	}
+	if tr.PerHour != 2*time.Millisecond || tr.Base != 10*time.Millisecond {
+		t.Errorf("Expected 10ms growing 2ms per hour, got %v and %v", tr.Base, tr.PerHour)
+	}
+	if got := tr.Forecast(24 * time.Hour); got != 68*time.Millisecond {
+		t.Errorf("Forecast(24h) = %v; want 68ms", got)
+	}
+	if got := tr.At(start.Add(-10 * time.Hour)); got != 0 {
+		t.Errorf("Expected predictions clamped at 0, got %v", got)
+	}
+
+	if _, err := FitTrend(nil, nil); !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("FitTrend(nil) = %v; want ErrNotEnoughData", err)
+	}
+}
@@ -0,0 +1,389 @@
+package timer
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrLabelCount is returned when the number of label values does not match
+// the number of label names of a TimerVec.
+var ErrLabelCount = errors.New("wrong number of label values")
+
+// OverflowLabelValue is the value of every label of the overflow bucket
+// used by a TimerVec with the OverflowBucket policy. The label set with
+// every value set to it is reserved for that bucket.
+const OverflowLabelValue = "__overflow__"
+
+// LimitPolicy selects what a TimerVec does when a new label set would
+// exceed its child limit.
+type LimitPolicy int
+
+const (
+	// EvictLRU removes the least recently used child to make room.
+	EvictLRU LimitPolicy = iota
+	// OverflowBucket records new label sets into a single shared child
+	// whose label values are all OverflowLabelValue.
+	OverflowBucket
+)
+
+// TimerVec is a family of timers partitioned by label values, such as one
+// timer per HTTP method and route. Children are created on first use.
+// All methods are safe for concurrent use.
+type TimerVec struct {
+	labelNames []string
+
+	mutex    sync.Mutex
+	children map[string]*list.Element // Keyed by vecKey of the label values
+	lru      *list.List               // Front is the most recently used child
+	limit    int                      // Maximum number of children, 0 for no limit
+	policy   LimitPolicy
+	overflow *vecChild // Shared child for OverflowBucket, created lazily
+	evicted  uint64    // Label sets evicted or redirected to the overflow bucket
+}
+
+// vecChild is a single labeled timer in a TimerVec.
+type vecChild struct {
+	key    string // empty for the overflow bucket
+	values []string
+	timer  *Timer
+}
+
+// NewTimerVec creates a TimerVec partitioned by the given label names.
+func NewTimerVec(labelNames ...string) *TimerVec {
+	return &TimerVec{
+		labelNames: labelNames,
+		children:   make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// LabelNames returns the label names of the vec.
+func (v *TimerVec) LabelNames() []string {
+	return v.labelNames
+}
+
+// SetLimit caps the number of children at max, protecting memory when label
+// values are user-controlled. When a new label set arrives at the cap,
+// policy decides whether the least recently used child is evicted or the
+// observation goes to a shared overflow bucket. If the vec already holds
+// more than max children, the least recently used are evicted.
+// A max of 0 removes the limit.
+func (v *TimerVec) SetLimit(max int, policy LimitPolicy) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.limit = max
+	v.policy = policy
+	for v.limit > 0 && v.lru.Len() > v.limit {
+		v.evictOldestNoLock()
+	}
+}
+
+// EvictedCount returns the number of label sets that were evicted or
+// redirected to the overflow bucket because of the child limit.
+func (v *TimerVec) EvictedCount() uint64 {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.evicted
+}
+
+// Len returns the number of children, including the overflow bucket.
+func (v *TimerVec) Len() int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	n := v.lru.Len()
+	if v.overflow != nil {
+		n++
+	}
+	return n
+}
+
+// GetWithLabelValues returns the timer for the given label values, creating
+// it if needed. Values are matched to label names by position. Values that
+// are all OverflowLabelValue return the overflow bucket.
+// Returns an error wrapping ErrLabelCount if the number of values is wrong.
+func (v *TimerVec) GetWithLabelValues(values ...string) (*Timer, error) {
+	if len(values) != len(v.labelNames) {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrLabelCount, len(values), len(v.labelNames))
+	}
+	key := vecKey(values)
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if e, ok := v.children[key]; ok {
+		v.lru.MoveToFront(e)
+		return e.Value.(*vecChild).timer, nil
+	}
+	if isOverflow(values) {
+		return v.overflowNoLock().timer, nil
+	}
+
+	if v.limit > 0 && v.lru.Len() >= v.limit {
+		if v.policy == OverflowBucket {
+			v.evicted++
+			return v.overflowNoLock().timer, nil
+		}
+		v.evictOldestNoLock()
+	}
+
+	c := &vecChild{key: key, values: append([]string(nil), values...), timer: NewTimer()}
+	v.children[key] = v.lru.PushFront(c)
+	return c.timer, nil
+}
+
+// overflowNoLock returns the overflow bucket, creating it if needed.
+// Callers must hold the lock.
+func (v *TimerVec) overflowNoLock() *vecChild {
+	if v.overflow == nil {
+		ov := make([]string, len(v.labelNames))
+		for i := range ov {
+			ov[i] = OverflowLabelValue
+		}
+		v.overflow = &vecChild{values: ov, timer: NewTimer()}
+	}
+	return v.overflow
+}
+
+// isOverflow reports whether values are those of the overflow bucket.
+func isOverflow(values []string) bool {
+	return len(values) > 0 && !slices.ContainsFunc(values, func(value string) bool {
+		return value != OverflowLabelValue
+	})
+}
+
+// vecKey returns the key of the child with values, length-prefixing each
+// value so that no two label sets share a key.
+func vecKey(values []string) string {
+	var sb strings.Builder
+	for _, value := range values {
+		sb.WriteString(strconv.Itoa(len(value)))
+		sb.WriteByte(':')
+		sb.WriteString(value)
+	}
+	return sb.String()
+}
+
+// WithLabelValues is like GetWithLabelValues but panics if the number of
+// values is wrong.
+func (v *TimerVec) WithLabelValues(values ...string) *Timer {
+	t, err := v.GetWithLabelValues(values...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// evictOldestNoLock removes the least recently used child.
+// Callers must hold the lock.
+func (v *TimerVec) evictOldestNoLock() {
+	e := v.lru.Back()
+	if e == nil {
+		return
+	}
+	v.lru.Remove(e)
+	c := e.Value.(*vecChild)
+	delete(v.children, c.key)
+	c.timer.logEvent(EventEvicted)
+	v.evicted++
+}
+
+// timers returns the timers of every child, including the overflow bucket.
+func (v *TimerVec) timers() []*Timer {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	timers := make([]*Timer, 0, v.lru.Len()+1)
+	for e := v.lru.Front(); e != nil; e = e.Next() {
+		timers = append(timers, e.Value.(*vecChild).timer)
+	}
+	if v.overflow != nil {
+		timers = append(timers, v.overflow.timer)
+	}
+	return timers
+}
+
+// DeleteLabelValues removes the child with the given label values, so it is
+// no longer exported. A later use of the same values creates a new child.
+// Returns false if there is no such child or the number of values is wrong.
+func (v *TimerVec) DeleteLabelValues(values ...string) bool {
+	if len(values) != len(v.labelNames) {
+		return false
+	}
+	key := vecKey(values)
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.overflow != nil && isOverflow(values) {
+		v.overflow = nil
+		return true
+	}
+	e, ok := v.children[key]
+	if !ok {
+		return false
+	}
+	v.lru.Remove(e)
+	delete(v.children, key)
+	return true
+}
+
+// DeletePartialMatch removes every child whose labels have the values given
+// in labels, keyed by label name, such as all children of a tenant that
+// went away. Returns the number of children removed.
+func (v *TimerVec) DeletePartialMatch(labels map[string]string) int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	match := func(c *vecChild) bool {
+		return v.matchNoLock(c, labels, func(want, value string) bool {
+			return want == value
+		})
+	}
+	n := 0
+	for e := v.lru.Front(); e != nil; {
+		next := e.Next()
+		if c := e.Value.(*vecChild); match(c) {
+			v.lru.Remove(e)
+			delete(v.children, c.key)
+			n++
+		}
+		e = next
+	}
+	if v.overflow != nil && match(v.overflow) {
+		v.overflow = nil
+		n++
+	}
+	return n
+}
+
+// ResetMatching resets every child whose label values match the
+// path.Match patterns in patterns, keyed by label name, keeping the
+// children. Returns the number of children reset, or an error wrapping
+// path.ErrBadPattern if a pattern is malformed.
+func (v *TimerVec) ResetMatching(patterns map[string]string) (int, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return 0, fmt.Errorf("invalid label pattern %q: %w", p, err)
+		}
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	n := 0
+	reset := func(c *vecChild) {
+		if v.matchNoLock(c, patterns, func(pattern, value string) bool {
+			ok, _ := path.Match(pattern, value)
+			return ok
+		}) {
+			c.timer.Reset()
+			n++
+		}
+	}
+	for e := v.lru.Front(); e != nil; e = e.Next() {
+		reset(e.Value.(*vecChild))
+	}
+	if v.overflow != nil {
+		reset(v.overflow)
+	}
+	return n, nil
+}
+
+// matchNoLock reports whether, for every label name in want, c's value for
+// that label matches the wanted value according to match. Labels the vec
+// does not have never match.
+// Callers must hold the lock.
+func (v *TimerVec) matchNoLock(c *vecChild, want map[string]string, match func(want, value string) bool) bool {
+	for name, w := range want {
+		i := slices.Index(v.labelNames, name)
+		if i < 0 || !match(w, c.values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot returns a snapshot of every child keyed by its label set,
+// formatted as `name="value",...` in label name order.
+func (v *TimerVec) Snapshot() map[string]Snapshot {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	snaps := make(map[string]Snapshot, v.lru.Len()+1)
+	for e := v.lru.Front(); e != nil; e = e.Next() {
+		c := e.Value.(*vecChild)
+		snaps[formatLabels(v.labelNames, c.values)] = c.timer.Snapshot()
+	}
+	if v.overflow != nil {
+		snaps[formatLabels(v.labelNames, v.overflow.values)] = v.overflow.timer.Snapshot()
+	}
+	return snaps
+}
+
+// eachChild calls fn with the formatted labels and timer of every child,
+// including the overflow bucket.
+func (v *TimerVec) eachChild(fn func(labels string, t *Timer)) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	for e := v.lru.Front(); e != nil; e = e.Next() {
+		c := e.Value.(*vecChild)
+		fn(formatLabels(v.labelNames, c.values), c.timer)
+	}
+	if v.overflow != nil {
+		fn(formatLabels(v.labelNames, v.overflow.values), v.overflow.timer)
+	}
+}
+
+// formatLabels renders label pairs as `name="value",...` with values
+// quoted by strconv.Quote.
+func formatLabels(names, values []string) string {
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(values[i]))
+	}
+	return sb.String()
+}
+
+// Label is a label name and value of a vec child.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// SplitName splits a registry snapshot name of the form
+// `name{label="value",...}` into the base name and its labels, for
+// exporters that map labels to dimensions. Names without braces have no
+// labels. Returns false if the label part is malformed.
+func SplitName(name string) (base string, labels []Label, ok bool) {
+	i := strings.IndexByte(name, '{')
+	if i < 0 {
+		return name, nil, true
+	}
+	base, rest := name[:i], name[i+1:]
+	rest, ok = strings.CutSuffix(rest, "}")
+	if !ok {
+		return name, nil, false
+	}
+	for rest != "" {
+		label, after, found := strings.Cut(rest, "=")
+		if !found || label == "" {
+			return name, nil, false
+		}
+		quoted, err := strconv.QuotedPrefix(after)
+		if err != nil {
+			return name, nil, false
+		}
+		value, _ := strconv.Unquote(quoted)
+		labels = append(labels, Label{Name: label, Value: value})
+		rest = after[len(quoted):]
+		if rest != "" {
+			if rest, found = strings.CutPrefix(rest, ","); !found || rest == "" {
+				return name, nil, false
+			}
+		}
+	}
+	return base, labels, true
+}
@@ -0,0 +1,205 @@
+package timer
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTimerVec(t *testing.T) {
+	v := NewTimerVec("method", "path")
+
+	get := v.WithLabelValues("GET", "/users")
+	get.Observe(time.Millisecond)
+	if v.WithLabelValues("GET", "/users") != get {
+		t.Errorf("Expected the same timer for the same label values")
+	}
+	v.WithLabelValues("POST", "/users").Observe(2 * time.Millisecond)
+
+	if v.Len() != 2 {
+		t.Errorf("Len = %d; want 2", v.Len())
+	}
+	snaps := v.Snapshot()
+	if snaps[`method="GET",path="/users"`].Count != 1 {
+		t.Errorf("Unexpected snapshot keys: %v", snaps)
+	}
+
+	if _, err := v.GetWithLabelValues("GET"); !errors.Is(err, ErrLabelCount) {
+		t.Errorf("Expected ErrLabelCount, got %v", err)
+	}
+}
+
+func TestTimerVecEvictLRU(t *testing.T) {
+	v := NewTimerVec("path")
+	v.SetLimit(2, EvictLRU)
+
+	a := v.WithLabelValues("/a")
+	v.WithLabelValues("/b")
+	// touch /a so /b becomes the least recently used
+	if v.WithLabelValues("/a") != a {
+		t.Fatalf("Expected /a to be cached")
+	}
+	v.WithLabelValues("/c")
+
+	if v.Len() != 2 {
+		t.Errorf("Len = %d; want 2", v.Len())
+	}
+	if v.EvictedCount() != 1 {
+		t.Errorf("EvictedCount = %d; want 1", v.EvictedCount())
+	}
+	snaps := v.Snapshot()
+	if _, ok := snaps[`path="/b"`]; ok {
+		t.Errorf("Expected /b to be evicted, got %v", snaps)
+	}
+	if _, ok := snaps[`path="/a"`]; !ok {
+		t.Errorf("Expected /a to be kept, got %v", snaps)
+	}
+
+	// shrinking the limit evicts immediately
+	v.SetLimit(1, EvictLRU)
+	if v.Len() != 1 || v.EvictedCount() != 2 {
+		t.Errorf("Len/EvictedCount = %d/%d; want 1/2", v.Len(), v.EvictedCount())
+	}
+}
+
+func TestTimerVecOverflowBucket(t *testing.T) {
+	v := NewTimerVec("path")
+	v.SetLimit(3, OverflowBucket)
+
+	for i := range 10 {
+		v.WithLabelValues("/users/" + strconv.Itoa(i)).Observe(time.Millisecond)
+	}
+
+	if v.Len() != 4 {
+		t.Errorf("Len = %d; want 4 including overflow bucket", v.Len())
+	}
+	if v.EvictedCount() != 7 {
+		t.Errorf("EvictedCount = %d; want 7", v.EvictedCount())
+	}
+	ov := v.Snapshot()[`path="`+OverflowLabelValue+`"`]
+	if ov.Count != 7 {
+		t.Errorf("Expected 7 observations in overflow bucket, got %d", ov.Count)
+	}
+	// existing children keep their own timers
+	if v.WithLabelValues("/users/0").Count() != 1 {
+		t.Errorf("Expected /users/0 to keep its own timer")
+	}
+}
+
+func TestTimerVecKeys(t *testing.T) {
+	v := NewTimerVec("a", "b")
+	v.WithLabelValues("x\xffy", "z").Observe(1)
+	v.WithLabelValues("x", "y\xffz").Observe(2)
+	if v.Len() != 2 {
+		t.Errorf("Expected distinct children for label sets sharing a separator, got %d", v.Len())
+	}
+
+	// the all-overflow label set is the overflow bucket, not a second
+	// child of the same name
+	v.SetLimit(2, OverflowBucket)
+	v.WithLabelValues(OverflowLabelValue, OverflowLabelValue).Observe(3)
+	v.WithLabelValues("new", "set").Observe(4)
+	if v.Len() != 3 || v.EvictedCount() != 1 {
+		t.Errorf("Len/EvictedCount = %d/%d; want 3/1", v.Len(), v.EvictedCount())
+	}
+	ov := v.Snapshot()[`a="`+OverflowLabelValue+`",b="`+OverflowLabelValue+`"`]
+	if ov.Count != 2 || ov.Sum != 7 {
+		t.Errorf("Expected both observations in the overflow bucket, got %+v", ov)
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		labels []Label
+		ok     bool
+	}{
+		{"plain", "plain", nil, true},
+		{`http{method="GET",route="/a,b=\"c\""}`, "http", []Label{{"method", "GET"}, {"route", `/a,b="c"`}}, true},
+		{`x{}`, "x", nil, true},
+		{`x{a="1"`, "", nil, false},
+		{`x{a=1}`, "", nil, false},
+		{`x{="1"}`, "", nil, false},
+		{`x{a="1",}`, "", nil, false},
+		{`x{a="1"b="2"}`, "", nil, false},
+	}
+	for _, tt := range tests {
+		base, labels, ok := SplitName(tt.name)
+		if ok != tt.ok || (ok && (base != tt.base || !slices.Equal(labels, tt.labels))) {
+			t.Errorf("SplitName(%q) = %q, %v, %v; want %q, %v, %v", tt.name, base, labels, ok, tt.base, tt.labels, tt.ok)
+		}
+	}
+
+	// round trip through a vec snapshot
+	r := NewRegistry()
+	v := NewTimerVec("method")
+	_ = r.RegisterVec("rpc", v)
+	v.WithLabelValues("Get\n").Observe(time.Millisecond)
+	for name := range r.Snapshot() {
+		if base, labels, ok := SplitName(name); !ok || base != "rpc" || labels[0].Value != "Get\n" {
+			t.Errorf("SplitName(%q) = %q, %v, %v", name, base, labels, ok)
+		}
+	}
+}
+
+func TestTimerVecDelete(t *testing.T) {
+	v := NewTimerVec("tenant", "route")
+	old := v.WithLabelValues("acme", "/a")
+	old.Observe(time.Millisecond)
+	v.WithLabelValues("acme", "/b")
+	v.WithLabelValues("globex", "/a")
+
+	if !v.DeleteLabelValues("acme", "/a") {
+		t.Errorf("Expected DeleteLabelValues to remove an existing child")
+	}
+	if v.DeleteLabelValues("acme", "/a") || v.DeleteLabelValues("acme") {
+		t.Errorf("Expected DeleteLabelValues to fail for missing children and wrong counts")
+	}
+	if v.WithLabelValues("acme", "/a") == old {
+		t.Errorf("Expected a new child after delete")
+	}
+
+	if n := v.DeletePartialMatch(map[string]string{"tenant": "acme"}); n != 2 {
+		t.Errorf("DeletePartialMatch = %d; want 2", n)
+	}
+	if n := v.DeletePartialMatch(map[string]string{"region": "eu"}); n != 0 {
+		t.Errorf("DeletePartialMatch on unknown label = %d; want 0", n)
+	}
+	if v.Len() != 1 {
+		t.Errorf("Len = %d; want 1", v.Len())
+	}
+}
+
+func TestTimerVecDeleteOverflow(t *testing.T) {
+	v := NewTimerVec("route")
+	v.SetLimit(1, OverflowBucket)
+	v.WithLabelValues("/a")
+	v.WithLabelValues("/b")
+	if !v.DeleteLabelValues(OverflowLabelValue) || v.Len() != 1 {
+		t.Errorf("Expected the overflow bucket to be deletable, Len = %d", v.Len())
+	}
+}
+
+func TestTimerVecResetMatching(t *testing.T) {
+	v := NewTimerVec("tenant", "route")
+	v.WithLabelValues("acme", "/api/a").Observe(time.Millisecond)
+	v.WithLabelValues("acme", "/web").Observe(time.Millisecond)
+	v.WithLabelValues("globex", "/api/a").Observe(time.Millisecond)
+
+	n, err := v.ResetMatching(map[string]string{"tenant": "acme", "route": "/api/*"})
+	if err != nil || n != 1 {
+		t.Errorf("ResetMatching = %d, %v; want 1, nil", n, err)
+	}
+	if v.WithLabelValues("acme", "/api/a").Count() != 0 || v.WithLabelValues("globex", "/api/a").Count() != 1 {
+		t.Errorf("Expected only the matching child to be reset")
+	}
+	if v.Len() != 3 {
+		t.Errorf("Expected ResetMatching to keep children, Len = %d", v.Len())
+	}
+	if _, err := v.ResetMatching(map[string]string{"route": "["}); err == nil {
+		t.Errorf("Expected an error for a malformed pattern")
+	}
+}
@@ -0,0 +1,115 @@
+package timer
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ChildSnapshot is the snapshot of one child of a TimerVec.
+type ChildSnapshot struct {
+	Labels   []Label
+	Snapshot Snapshot
+}
+
+// rankedChild is the snapshot of a child along with its timer, and its
+// value of the metric it is ranked by.
+type rankedChild struct {
+	ChildSnapshot
+	timer *Timer
+	value time.Duration
+}
+
+// childSnapshots returns a snapshot of every child, including the overflow
+// bucket, ordered by label values.
+func (v *TimerVec) childSnapshots() []rankedChild {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	children := make([]rankedChild, 0, v.lru.Len()+1)
+	add := func(c *vecChild) {
+		labels := make([]Label, len(v.labelNames))
+		for i, name := range v.labelNames {
+			labels[i] = Label{Name: name, Value: c.values[i]}
+		}
+		children = append(children, rankedChild{
+			ChildSnapshot: ChildSnapshot{Labels: labels, Snapshot: c.timer.Snapshot()},
+			timer:         c.timer,
+		})
+	}
+	for e := v.lru.Front(); e != nil; e = e.Next() {
+		add(e.Value.(*vecChild))
+	}
+	if v.overflow != nil {
+		add(v.overflow)
+	}
+	slices.SortFunc(children, func(a, b rankedChild) int {
+		return slices.CompareFunc(a.Labels, b.Labels, func(x, y Label) int {
+			return strings.Compare(x.Value, y.Value)
+		})
+	})
+	return children
+}
+
+// Aggregate returns the merge of every child's snapshot, the statistics of
+// the family as a whole.
+func (v *TimerVec) Aggregate() Snapshot {
+	var s Snapshot
+	for _, c := range v.childSnapshots() {
+		s = s.Merge(c.Snapshot)
+	}
+	return s
+}
+
+// Worst returns the child with the highest metric among children with
+// observations. The metric is evaluated on the child's timer, so it can
+// rank by a statistic such as (*Timer).Mean or by a quantile of an
+// attached aggregator with ByQuantile:
+//
+//	worst, ok := vec.Worst(timer.ByQuantile(0.99))
+//
+// Ties go to the lowest label values. Returns false if no child has
+// observations.
+func (v *TimerVec) Worst(metric func(*Timer) time.Duration) (ChildSnapshot, bool) {
+	top := v.TopN(1, metric)
+	if len(top) == 0 {
+		return ChildSnapshot{}, false
+	}
+	return top[0], true
+}
+
+// ByQuantile returns a metric for ranking the children of a TimerVec by
+// their q-quantile (0 <= q <= 1), as estimated by Timer.Quantile.
+// Children without a quantile aggregator rank as 0.
+func ByQuantile(q float64) func(*Timer) time.Duration {
+	return func(t *Timer) time.Duration {
+		d, _ := t.Quantile(q)
+		return d
+	}
+}
+
+// TopN returns up to n children with observations, ordered by metric from
+// highest to lowest, answering questions like "which routes are slowest".
+// Like Worst, the metric is evaluated once per child on its timer:
+//
+//	slowest := vec.TopN(5, (*timer.Timer).Mean)
+//	tail := vec.TopN(5, timer.ByQuantile(0.99))
+//
+// Ties are ordered by label values.
+func (v *TimerVec) TopN(n int, metric func(*Timer) time.Duration) []ChildSnapshot {
+	var ranked []rankedChild
+	for _, c := range v.childSnapshots() {
+		if c.Snapshot.Count > 0 {
+			c.value = metric(c.timer)
+			ranked = append(ranked, c)
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b rankedChild) int {
+		return cmp.Compare(b.value, a.value)
+	})
+	top := make([]ChildSnapshot, min(max(n, 0), len(ranked)))
+	for i := range top {
+		top[i] = ranked[i].ChildSnapshot
+	}
+	return top
+}
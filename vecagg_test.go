@@ -0,0 +1,107 @@
+package timer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// withHistograms returns a vec whose children a, b and c have ExpHistograms:
+// a has the highest mean and b the highest p99.
+func withHistograms(t *testing.T) *TimerVec {
+	t.Helper()
+	v := NewTimerVec("route")
+	for _, route := range []string{"a", "b", "c"} {
+		if err := v.WithLabelValues(route).AddAggregator("hist", NewExpHistogram(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 100 {
+		v.WithLabelValues("a").Observe(50 * time.Millisecond)
+		v.WithLabelValues("c").Observe(10 * time.Millisecond)
+	}
+	for range 98 {
+		v.WithLabelValues("b").Observe(time.Millisecond)
+	}
+	v.WithLabelValues("b").Observe(time.Second)
+	v.WithLabelValues("b").Observe(time.Second)
+	return v
+}
+
+func TestTimerVecAggregate(t *testing.T) {
+	v := NewTimerVec("route")
+	v.WithLabelValues("/a").Observe(10 * time.Millisecond)
+	v.WithLabelValues("/a").Observe(30 * time.Millisecond)
+	v.WithLabelValues("/b").Observe(25 * time.Millisecond)
+	v.WithLabelValues("/c")
+
+	want := Snapshot{Count: 3, Min: 10 * time.Millisecond, Max: 30 * time.Millisecond, Sum: 65 * time.Millisecond}
+	if s := v.Aggregate(); s != want {
+		t.Errorf("Aggregate() = %+v; want %+v", s, want)
+	}
+
+	worst, ok := v.Worst((*Timer).Mean)
+	if !ok || worst.Labels[0] != (Label{Name: "route", Value: "/b"}) {
+		t.Errorf("Worst(Mean) = %+v, %v; want /b", worst, ok)
+	}
+	worst, ok = v.Worst((*Timer).Max)
+	if !ok || worst.Labels[0].Value != "/a" || worst.Snapshot.Max != 30*time.Millisecond {
+		t.Errorf("Worst(Max) = %+v, %v; want /a", worst, ok)
+	}
+}
+
+func TestTimerVecWorstEmpty(t *testing.T) {
+	v := NewTimerVec("route")
+	v.WithLabelValues("/a")
+	if _, ok := v.Worst((*Timer).Mean); ok {
+		t.Errorf("Expected no worst child without observations")
+	}
+	if s := v.Aggregate(); s.Count != 0 {
+		t.Errorf("Aggregate() = %+v; want empty", s)
+	}
+}
+
+func TestTimerVecWorstQuantile(t *testing.T) {
+	v := withHistograms(t)
+	if worst, _ := v.Worst((*Timer).Mean); worst.Labels[0].Value != "a" {
+		t.Errorf("Worst(Mean) = %v; want a", worst.Labels)
+	}
+	worst, ok := v.Worst(ByQuantile(0.99))
+	if !ok || worst.Labels[0].Value != "b" {
+		t.Errorf("Worst(p99) = %+v, %v; want b", worst, ok)
+	}
+}
+
+func TestTimerVecTopN(t *testing.T) {
+	v := NewTimerVec("route")
+	for i, d := range []time.Duration{5, 40, 20, 40, 10} {
+		v.WithLabelValues(string(rune('a' + i))).Observe(d * time.Millisecond)
+	}
+	v.WithLabelValues("idle")
+
+	top := v.TopN(3, (*Timer).Mean)
+	var got []string
+	for _, c := range top {
+		got = append(got, c.Labels[0].Value)
+	}
+	if want := []string{"b", "d", "c"}; !slices.Equal(got, want) {
+		t.Errorf("TopN(3) = %v; want %v", got, want)
+	}
+	if n := len(v.TopN(10, (*Timer).Mean)); n != 5 {
+		t.Errorf("Expected TopN to skip children without observations, got %d", n)
+	}
+	if n := len(v.TopN(0, (*Timer).Mean)); n != 0 {
+		t.Errorf("TopN(0) returned %d children", n)
+	}
+}
+
+func TestTimerVecTopNQuantile(t *testing.T) {
+	v := withHistograms(t)
+	var got []string
+	for _, c := range v.TopN(3, ByQuantile(0.99)) {
+		got = append(got, c.Labels[0].Value)
+	}
+	if want := []string{"b", "a", "c"}; !slices.Equal(got, want) {
+		t.Errorf("TopN(3, p99) = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,276 @@
+package timer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWallProfileInterval is the sampling interval of a WallProfiler
+// created with a non-positive interval.
+const DefaultWallProfileInterval = 10 * time.Millisecond
+
+// WallProfiler is an experimental wall-clock sampling profiler keyed by
+// timer names, in the spirit of fgprof. Code marks named spans with Begin
+// and End; spans begun on the same goroutine nest. While started, the
+// profiler periodically records which spans are running on every
+// goroutine, and WriteProfile renders the result as a pprof profile in
+// which each span name is a function and nested spans form call stacks.
+// The profile is weighted by wall time, so it shows where time goes
+// including waiting on I/O, locks, and other goroutines, which CPU
+// profiles miss:
+//
+//	p := timer.NewWallProfiler(0)
+//	p.Start(ctx)
+//	span := p.Begin("checkout", checkoutTimer)
+//	defer span.End()
+//	...
+//	p.WriteProfile(f) // go tool pprof -top f
+type WallProfiler struct {
+	interval tickerInterval
+
+	mutex   sync.Mutex
+	stacks  map[uint64][]*WallSpan // running spans by goroutine, outermost first
+	samples map[string]*wallSample // by joined span names
+	start   time.Time              // start of the profile
+	last    time.Time              // time of the last sample
+	lc      lifecycle
+}
+
+// wallSample accumulates the samples of one stack of span names.
+type wallSample struct {
+	names []string // outermost first
+	count int64
+	wall  time.Duration
+}
+
+// WallSpan is a named span running on a goroutine, begun with
+// WallProfiler.Begin.
+type WallSpan struct {
+	p       *WallProfiler
+	gid     uint64
+	name    string
+	start   time.Time
+	sw      *Stopwatch
+	ended   bool
+	elapsed time.Duration
+}
+
+// NewWallProfiler creates a WallProfiler sampling every interval, or every
+// DefaultWallProfileInterval if interval is not positive.
+func NewWallProfiler(interval time.Duration) *WallProfiler {
+	if interval <= 0 {
+		interval = DefaultWallProfileInterval
+	}
+	now := time.Now()
+	return &WallProfiler{
+		interval: newTickerInterval(interval),
+		stacks:   make(map[uint64][]*WallSpan),
+		samples:  make(map[string]*wallSample),
+		start:    now,
+		last:     now,
+	}
+}
+
+// Begin starts a span named name on the calling goroutine. If t is
+// non-nil, the span's duration is also recorded in t, as by a Stopwatch.
+// The span must be ended with End on the same goroutine.
+func (p *WallProfiler) Begin(name string, t *Timer) *WallSpan {
+	s := &WallSpan{p: p, gid: goroutineID(), name: name, start: time.Now()}
+	if t != nil {
+		s.sw = t.Start()
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.stacks[s.gid] = append(p.stacks[s.gid], s)
+	return s
+}
+
+// End ends the span and returns its duration. Only the first call has an
+// effect; later calls return the same duration.
+func (s *WallSpan) End() time.Duration {
+	if s.ended {
+		return s.elapsed
+	}
+	s.ended = true
+	s.elapsed = max(time.Since(s.start), 0)
+	if s.sw != nil {
+		s.sw.Stop()
+	}
+	p := s.p
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stack := slices.DeleteFunc(p.stacks[s.gid], func(o *WallSpan) bool { return o == s })
+	if len(stack) == 0 {
+		delete(p.stacks, s.gid)
+	} else {
+		p.stacks[s.gid] = stack
+	}
+	return s.elapsed
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b, _ = bytes.CutPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// Start begins sampling until ctx is done or Close is called.
+func (p *WallProfiler) Start(ctx context.Context) error {
+	return p.lc.start(ctx, p, p.run)
+}
+
+func (p *WallProfiler) run(ctx context.Context) {
+	ticker := p.interval.start()
+	defer p.interval.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.sample(now)
+		}
+	}
+}
+
+// Close stops sampling. The profile stays available to WriteProfile.
+func (p *WallProfiler) Close() error {
+	p.lc.close()
+	return nil
+}
+
+// sample attributes the wall time since the previous sample to the spans
+// running on every goroutine.
+func (p *WallProfiler) sample(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	elapsed := max(now.Sub(p.last), 0)
+	p.last = now
+	for _, stack := range p.stacks {
+		names := make([]string, len(stack))
+		for i, s := range stack {
+			names[i] = s.name
+		}
+		key := strings.Join(names, "\x00")
+		ws, ok := p.samples[key]
+		if !ok {
+			ws = &wallSample{names: names}
+			p.samples[key] = ws
+		}
+		ws.count++
+		ws.wall += elapsed
+	}
+}
+
+// Reset discards the samples collected so far and starts a new profile.
+func (p *WallProfiler) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	clear(p.samples)
+	p.start = time.Now()
+	p.last = p.start
+}
+
+// WriteProfile writes the samples collected so far to w as a
+// gzip-compressed pprof profile with "samples/count" and
+// "wall/nanoseconds" sample types.
+func (p *WallProfiler) WriteProfile(w io.Writer) error {
+	p.mutex.Lock()
+	samples := make([]wallSample, 0, len(p.samples))
+	for _, ws := range p.samples {
+		samples = append(samples, *ws)
+	}
+	start, end := p.start, p.last
+	p.mutex.Unlock()
+	slices.SortFunc(samples, func(a, b wallSample) int {
+		return slices.Compare(a.names, b.names)
+	})
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(encodeWallProfile(samples, start, end, p.interval.get())); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// encodeWallProfile encodes samples as a perftools.profiles.Profile
+// protocol buffer. Every span name is both a function and a location,
+// sharing one ID.
+func encodeWallProfile(samples []wallSample, start, end time.Time, period time.Duration) []byte {
+	strs := []string{""}
+	index := map[string]uint64{"": 0}
+	str := func(s string) uint64 {
+		i, ok := index[s]
+		if !ok {
+			i = uint64(len(strs))
+			index[s] = i
+			strs = append(strs, s)
+		}
+		return i
+	}
+	valueType := func(typ, unit string) []byte {
+		var vt []byte
+		vt = protoVarint(vt, 1, str(typ))
+		return protoVarint(vt, 2, str(unit))
+	}
+
+	var b []byte
+	b = protoBytes(b, 1, valueType("samples", "count"))
+	b = protoBytes(b, 1, valueType("wall", "nanoseconds"))
+
+	ids := make(map[string]uint64)
+	var names []string
+	for _, ws := range samples {
+		var locs []byte
+		for _, name := range slices.Backward(ws.names) {
+			id, ok := ids[name]
+			if !ok {
+				id = uint64(len(ids) + 1)
+				ids[name] = id
+				names = append(names, name)
+			}
+			locs = appendVarint(locs, id)
+		}
+		var values []byte
+		values = appendVarint(values, uint64(ws.count))
+		values = appendVarint(values, uint64(ws.wall))
+		var sample []byte
+		sample = protoBytes(sample, 1, locs)
+		sample = protoBytes(sample, 2, values)
+		b = protoBytes(b, 2, sample)
+	}
+	for i, name := range names {
+		id := uint64(i + 1)
+		var line, loc, fn []byte
+		line = protoVarint(line, 1, id)
+		loc = protoVarint(loc, 1, id)
+		loc = protoBytes(loc, 4, line)
+		b = protoBytes(b, 4, loc)
+		fn = protoVarint(fn, 1, id)
+		fn = protoVarint(fn, 2, str(name))
+		fn = protoVarint(fn, 3, str(name))
+		b = protoBytes(b, 5, fn)
+	}
+	periodType := valueType("wall", "nanoseconds")
+	for _, s := range strs {
+		b = protoBytes(b, 6, []byte(s))
+	}
+	b = protoVarint(b, 9, uint64(start.UnixNano()))
+	b = protoVarint(b, 10, uint64(max(end.Sub(start), 0)))
+	b = protoBytes(b, 11, periodType)
+	return protoVarint(b, 12, uint64(period))
+}
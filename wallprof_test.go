@@ -0,0 +1,106 @@
+package timer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+// protoMessage decodes the top-level fields of a protocol buffer message,
+// returning varints and length-delimited payloads by field number.
+func protoMessage(t *testing.T, b []byte) map[int][]any {
+	t.Helper()
+	fields := make(map[int][]any)
+	err := protoFields(b, func(field int, n uint64, payload []byte) error {
+		if payload != nil {
+			fields[field] = append(fields[field], payload)
+		} else {
+			fields[field] = append(fields[field], n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+func TestWallProfiler(t *testing.T) {
+	p := NewWallProfiler(10 * time.Millisecond)
+	tm := NewTimer()
+	outer := p.Begin("handler", tm)
+	inner := p.Begin("db", nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Begin("background", nil).End()
+	}()
+	<-done
+
+	base := p.last
+	p.sample(base.Add(10 * time.Millisecond))
+	inner.End()
+	p.sample(base.Add(30 * time.Millisecond))
+	if d := outer.End(); d != outer.End() {
+		t.Errorf("Expected End to be idempotent")
+	}
+	p.sample(base.Add(40 * time.Millisecond))
+	if tm.Count() != 1 {
+		t.Errorf("Expected the span to be recorded in its timer, got %d", tm.Count())
+	}
+
+	var buf bytes.Buffer
+	if err := p.WriteProfile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(zr)
+	profile := protoMessage(t, raw)
+
+	var strs []string
+	for _, s := range profile[6] {
+		strs = append(strs, string(s.([]byte)))
+	}
+	if strs[0] != "" || !slices.Contains(strs, "handler") || !slices.Contains(strs, "db") || slices.Contains(strs, "background") {
+		t.Errorf("Unexpected string table %q", strs)
+	}
+	if len(profile[1]) != 2 || len(profile[4]) != 2 || len(profile[5]) != 2 {
+		t.Errorf("Expected 2 sample types, locations and functions, got %d, %d, %d", len(profile[1]), len(profile[4]), len(profile[5]))
+	}
+
+	// samples are sorted by stack: [handler] then [handler db]
+	want := []struct {
+		locs  int
+		count uint64
+		wall  time.Duration
+	}{{1, 1, 20 * time.Millisecond}, {2, 1, 10 * time.Millisecond}}
+	if len(profile[2]) != len(want) {
+		t.Fatalf("Expected %d samples, got %d", len(want), len(profile[2]))
+	}
+	for i, s := range profile[2] {
+		sample := protoMessage(t, s.([]byte))
+		locs := sample[1][0].([]byte)
+		values := sample[2][0].([]byte)
+		if len(locs) != want[i].locs || values[0] != byte(want[i].count) {
+			t.Errorf("Sample %d: locations %v, values %v", i, locs, values)
+		}
+		if wall := protoMessage(t, append([]byte{0x08}, values[1:]...))[1][0].(uint64); time.Duration(wall) != want[i].wall {
+			t.Errorf("Sample %d: wall %v; want %v", i, time.Duration(wall), want[i].wall)
+		}
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	other := make(chan uint64)
+	go func() { other <- goroutineID() }()
+	if id == 0 || id == <-other {
+		t.Errorf("Expected distinct non-zero goroutine IDs")
+	}
+}
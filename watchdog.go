@@ -0,0 +1,179 @@
+package timer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Watchdog tracks in-flight operations and reports those running longer
+// than a configured age. Operations that never finish are never observed
+// by a Timer, so a Watchdog catches stuck requests that stats alone miss.
+//
+// A background scanner started with Start checks the in-flight operations
+// periodically. The results of the latest scan are exposed as the Stuck
+// and OldestAge gauges.
+type Watchdog struct {
+	interval tickerInterval
+	onStuck  func(name string, age time.Duration)
+
+	mutex  sync.Mutex
+	maxAge time.Duration
+	ops    map[*Operation]struct{}
+	stuck  int
+	oldest time.Duration
+	lc     lifecycle
+}
+
+// Operation is an in-flight operation registered with a Watchdog.
+type Operation struct {
+	w        *Watchdog
+	name     string
+	start    time.Time
+	reported bool
+}
+
+// NewWatchdog creates a Watchdog flagging operations older than maxAge.
+// If onStuck is non-nil it is called once for each operation when a scan
+// first finds it stuck, with its name and age at that time.
+func NewWatchdog(maxAge time.Duration, onStuck func(name string, age time.Duration)) *Watchdog {
+	return &Watchdog{
+		maxAge:   maxAge,
+		interval: newTickerInterval(watchdogInterval(maxAge)),
+		onStuck:  onStuck,
+		ops:      make(map[*Operation]struct{}),
+	}
+}
+
+// watchdogInterval returns the scan interval for maxAge.
+func watchdogInterval(maxAge time.Duration) time.Duration {
+	return max(maxAge/4, time.Millisecond)
+}
+
+// SetMaxAge changes the age above which operations are stuck, also while
+// running. Operations already reported are not reported again.
+func (w *Watchdog) SetMaxAge(maxAge time.Duration) {
+	w.mutex.Lock()
+	w.maxAge = maxAge
+	w.mutex.Unlock()
+	w.interval.set(watchdogInterval(maxAge))
+}
+
+// MaxAge returns the age above which operations are stuck.
+func (w *Watchdog) MaxAge() time.Duration {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.maxAge
+}
+
+// Begin registers an in-flight operation named name. The operation must be
+// ended with Stop.
+func (w *Watchdog) Begin(name string) *Operation {
+	op := &Operation{w: w, name: name, start: time.Now()}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.ops[op] = struct{}{}
+	return op
+}
+
+// Stop ends the operation and returns its duration. Stopping an operation
+// more than once is a no-op that returns the duration so far.
+func (op *Operation) Stop() time.Duration {
+	d := max(time.Since(op.start), 0)
+	op.w.mutex.Lock()
+	defer op.w.mutex.Unlock()
+	delete(op.w.ops, op)
+	return d
+}
+
+// Name returns the name of the operation.
+func (op *Operation) Name() string {
+	return op.name
+}
+
+// Start begins scanning every quarter of the maximum age until ctx is done
+// or Close is called.
+func (w *Watchdog) Start(ctx context.Context) error {
+	return w.lc.start(ctx, w, w.run)
+}
+
+func (w *Watchdog) run(ctx context.Context) {
+	ticker := w.interval.start()
+	defer w.interval.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.scan(now)
+		}
+	}
+}
+
+// Scan checks the in-flight operations immediately and returns how many
+// are stuck.
+func (w *Watchdog) Scan() int {
+	return w.scan(time.Now())
+}
+
+func (w *Watchdog) scan(now time.Time) int {
+	type report struct {
+		name string
+		age  time.Duration
+	}
+	var reports []report
+
+	w.mutex.Lock()
+	w.stuck, w.oldest = 0, 0
+	for op := range w.ops {
+		age := now.Sub(op.start)
+		w.oldest = max(w.oldest, age)
+		if age < w.maxAge {
+			continue
+		}
+		w.stuck++
+		if !op.reported {
+			op.reported = true
+			reports = append(reports, report{op.name, age})
+		}
+	}
+	stuck := w.stuck
+	w.mutex.Unlock()
+
+	if w.onStuck != nil {
+		for _, r := range reports {
+			w.onStuck(r.name, r.age)
+		}
+	}
+	return stuck
+}
+
+// InFlight returns the number of operations that have begun but not
+// stopped.
+func (w *Watchdog) InFlight() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return len(w.ops)
+}
+
+// Stuck returns how many operations exceeded the maximum age at the last
+// scan.
+func (w *Watchdog) Stuck() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.stuck
+}
+
+// OldestAge returns the age of the oldest in-flight operation at the last
+// scan, or 0 if none was in flight.
+func (w *Watchdog) OldestAge() time.Duration {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.oldest
+}
+
+// Close stops scanning.
+func (w *Watchdog) Close() error {
+	w.lc.close()
+	return nil
+}
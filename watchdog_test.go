@@ -0,0 +1,81 @@
+package timer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatchdogScan(t *testing.T) {
+	var reported []string
+	w := NewWatchdog(time.Minute, func(name string, age time.Duration) {
+		reported = append(reported, name)
+	})
+	stuck := w.Begin("stuck")
+	done := w.Begin("done")
+	if w.InFlight() != 2 {
+		t.Errorf("InFlight = %d; want 2", w.InFlight())
+	}
+	done.Stop()
+	done.Stop()
+
+	now := time.Now()
+	if n := w.scan(now); n != 0 || w.OldestAge() <= 0 {
+		t.Errorf("Expected nothing stuck yet, got %d (oldest %v)", n, w.OldestAge())
+	}
+	if n := w.scan(now.Add(2 * time.Minute)); n != 1 || w.Stuck() != 1 {
+		t.Errorf("Expected 1 stuck operation, got %d", n)
+	}
+	if w.OldestAge() < 2*time.Minute {
+		t.Errorf("OldestAge = %v; want >= 2m", w.OldestAge())
+	}
+	w.scan(now.Add(3 * time.Minute))
+	if len(reported) != 1 || reported[0] != "stuck" {
+		t.Errorf("Expected stuck operation reported once, got %v", reported)
+	}
+
+	stuck.Stop()
+	if n := w.scan(now.Add(4 * time.Minute)); n != 0 || w.OldestAge() != 0 || w.InFlight() != 0 {
+		t.Errorf("Expected gauges cleared after Stop, got stuck %d oldest %v", n, w.OldestAge())
+	}
+}
+
+func TestWatchdogStart(t *testing.T) {
+	defer checkNoLeaks(t)
+	found := make(chan string, 1)
+	w := NewWatchdog(5*time.Millisecond, func(name string, age time.Duration) {
+		found <- name
+	})
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Close()
+	op := w.Begin("hung")
+	defer op.Stop()
+
+	select {
+	case name := <-found:
+		if name != "hung" {
+			t.Errorf("Expected hung reported, got %q", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for stuck operation report")
+	}
+}
+
+func TestWatchdogSetMaxAge(t *testing.T) {
+	w := NewWatchdog(time.Hour, nil)
+	op := w.Begin("op")
+	defer op.Stop()
+	now := time.Now()
+	if n := w.scan(now.Add(time.Minute)); n != 0 {
+		t.Errorf("Expected nothing stuck under a 1h max age, got %d", n)
+	}
+	w.SetMaxAge(30 * time.Second)
+	if w.MaxAge() != 30*time.Second {
+		t.Errorf("MaxAge = %v; want 30s", w.MaxAge())
+	}
+	if n := w.scan(now.Add(time.Minute)); n != 1 {
+		t.Errorf("Expected operation stuck after lowering max age, got %d", n)
+	}
+}
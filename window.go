@@ -0,0 +1,182 @@
+package timer
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MultiWindow keeps sliding-window statistics at several resolutions at
+// once, such as the last minute, five minutes and hour, from one ring of
+// fine-grained slots. Each window is the merge of its most recent slots,
+// so all windows share the same storage and instrumentation. Slots are
+// aligned to the wall clock like HeatmapRecorder windows, and the current
+// slot is partial.
+//
+//	mw, _ := timer.NewMultiWindow(10*time.Second, time.Minute, 5*time.Minute, time.Hour)
+//	_ = t.AddAggregator("windows", mw)
+//	lastMinute, _ := mw.Window(time.Minute)
+//
+// MultiWindow implements Aggregator, so it can be attached to a Timer with
+// AddAggregator, and is also safe for standalone concurrent use.
+type MultiWindow struct {
+	slot    time.Duration
+	windows []time.Duration
+	size    int // number of slots
+	now     func() time.Time
+	loc     *time.Location
+
+	mutex sync.Mutex
+	slots []Snapshot // ring of slots, nil when compacted
+	head  int        // index of the current slot in slots
+	start time.Time  // start of the current slot
+}
+
+// NewMultiWindow creates a MultiWindow with slots of length slot, keeping
+// enough of them for the longest of windows. Every window must be a
+// positive multiple of slot.
+func NewMultiWindow(slot time.Duration, windows ...time.Duration) (*MultiWindow, error) {
+	if slot <= 0 || len(windows) == 0 {
+		return nil, errors.New("window slot and windows must be given")
+	}
+	for _, w := range windows {
+		if w <= 0 || w%slot != 0 {
+			return nil, errors.New("windows must be positive multiples of the slot")
+		}
+	}
+	windows = slices.Clone(windows)
+	slices.Sort(windows)
+	windows = slices.Compact(windows)
+	return &MultiWindow{
+		slot:    slot,
+		windows: windows,
+		size:    int(windows[len(windows)-1] / slot),
+		now:     time.Now,
+	}, nil
+}
+
+// SetClock makes mw read the time from c instead of time.Now. It must be
+// called before mw is used.
+func (mw *MultiWindow) SetClock(c Clock) {
+	mw.now = c.Now
+}
+
+// SetLocation aligns slots to the wall clock in loc instead of UTC. It
+// must be called before mw is used.
+func (mw *MultiWindow) SetLocation(loc *time.Location) {
+	mw.loc = loc
+}
+
+// Observe records d in the current slot.
+func (mw *MultiWindow) Observe(d time.Duration) {
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	mw.advanceNoLock(mw.now())
+	mw.slots[mw.head] = mw.slots[mw.head].Merge(Snapshot{Count: 1, Min: d, Max: d, Sum: d})
+}
+
+// advanceNoLock rotates the ring so the current slot contains now.
+func (mw *MultiWindow) advanceNoLock(now time.Time) {
+	if mw.slots == nil {
+		mw.slots = make([]Snapshot, mw.size)
+	}
+	start := alignTime(now, mw.slot, mw.loc)
+	if mw.start.IsZero() {
+		mw.start = start
+		return
+	}
+	// rounded, as local days around daylight saving changes are not 24h
+	n := int((start.Sub(mw.start) + mw.slot/2) / mw.slot)
+	if n <= 0 {
+		return
+	}
+	for range min(n, len(mw.slots)) {
+		mw.head = (mw.head + 1) % len(mw.slots)
+		mw.slots[mw.head] = Snapshot{}
+	}
+	mw.start = start
+}
+
+// windowNoLock merges the slots covering the last w.
+func (mw *MultiWindow) windowNoLock(w time.Duration) Snapshot {
+	var s Snapshot
+	for i := range int(w / mw.slot) {
+		s = s.Merge(mw.slots[(mw.head-i+len(mw.slots))%len(mw.slots)])
+	}
+	return s
+}
+
+// Window returns the statistics of the last w, which must be one of the
+// configured windows. Returns false otherwise.
+func (mw *MultiWindow) Window(w time.Duration) (Snapshot, bool) {
+	if _, ok := slices.BinarySearch(mw.windows, w); !ok {
+		return Snapshot{}, false
+	}
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	mw.advanceNoLock(mw.now())
+	return mw.windowNoLock(w), true
+}
+
+// Windows returns the statistics of every configured window, keyed by
+// the window length formatted like time.Duration.String, such as "1m0s".
+func (mw *MultiWindow) Windows() map[string]Snapshot {
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	mw.advanceNoLock(mw.now())
+	out := make(map[string]Snapshot, len(mw.windows))
+	for _, w := range mw.windows {
+		out[w.String()] = mw.windowNoLock(w)
+	}
+	return out
+}
+
+// Snapshot returns Windows, implementing Aggregator.
+func (mw *MultiWindow) Snapshot() any {
+	return mw.Windows()
+}
+
+// Reset clears all slots.
+func (mw *MultiWindow) Reset() {
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	clear(mw.slots)
+}
+
+// Compact releases the slots, discarding their statistics, until the
+// next use, implementing Compacter.
+func (mw *MultiWindow) Compact() {
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	mw.slots, mw.head, mw.start = nil, 0, time.Time{}
+}
+
+// Merge adds the slots of other, a MultiWindow with the same slot length,
+// into the slots with the same start time.
+func (mw *MultiWindow) Merge(other Aggregator) error {
+	o, ok := other.(*MultiWindow)
+	if !ok {
+		return errors.New("can only merge a MultiWindow")
+	}
+	if o.slot != mw.slot {
+		return errors.New("window slots differ")
+	}
+	o.mutex.Lock()
+	theirs, theirStart, theirHead := slices.Clone(o.slots), o.start, o.head
+	o.mutex.Unlock()
+
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+	mw.advanceNoLock(mw.now())
+	for i := range theirs {
+		s := theirs[(theirHead-i+len(theirs))%len(theirs)]
+		back := int(mw.start.Sub(theirStart)/mw.slot) + i
+		if s.Count == 0 || back < 0 || back >= len(mw.slots) {
+			continue
+		}
+		j := (mw.head - back + len(mw.slots)) % len(mw.slots)
+		mw.slots[j] = mw.slots[j].Merge(s)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMultiWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mw, err := NewMultiWindow(10*time.Second, time.Hour, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw.SetClock(clock)
+	timer := NewTimer()
+	if err := timer.AddAggregator("windows", mw); err != nil {
+		t.Fatal(err)
+	}
+
+	timer.Observe(100 * time.Millisecond)
+	clock.t = clock.t.Add(30 * time.Minute)
+	timer.Observe(10 * time.Millisecond)
+	clock.t = clock.t.Add(30 * time.Second)
+	timer.Observe(20 * time.Millisecond)
+
+	minute, ok := mw.Window(time.Minute)
+	if !ok || minute.Count != 2 || minute.Max != 20*time.Millisecond {
+		t.Errorf("Window(1m) = %+v, %v; want the last 2 observations", minute, ok)
+	}
+	hour, _ := mw.Window(time.Hour)
+	if hour.Count != 3 || hour.Max != 100*time.Millisecond {
+		t.Errorf("Window(1h) = %+v; want all 3 observations", hour)
+	}
+	if _, ok := mw.Window(5 * time.Minute); ok {
+		t.Errorf("Expected an unconfigured window to be rejected")
+	}
+
+	clock.t = clock.t.Add(31 * time.Minute)
+	windows := mw.Windows()
+	if windows["1m0s"].Count != 0 || windows["1h0m0s"].Count != 2 {
+		t.Errorf("Windows() = %v; want the oldest observation expired", windows)
+	}
+
+	timer.Reset()
+	if s, _ := mw.Window(time.Hour); s.Count != 0 {
+		t.Errorf("Expected Reset to clear all windows, got %+v", s)
+	}
+}
+
+func TestMultiWindowMerge(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC)}
+	a, _ := NewMultiWindow(time.Second, time.Minute)
+	b, _ := NewMultiWindow(time.Second, time.Minute)
+	a.SetClock(clock)
+	b.SetClock(clock)
+	b.Observe(time.Millisecond)
+	clock.t = clock.t.Add(2 * time.Second)
+	a.Observe(2 * time.Millisecond)
+	b.Observe(3 * time.Millisecond)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := a.Window(time.Minute); s.Count != 3 || s.Sum != 6*time.Millisecond {
+		t.Errorf("Merged window = %+v; want 3 observations summing to 6ms", s)
+	}
+	if s, _ := a.Window(time.Minute); s.Min != time.Millisecond {
+		t.Errorf("Expected the older slot to be merged, got %+v", s)
+	}
+	c, _ := NewMultiWindow(2*time.Second, time.Minute)
+	if err := a.Merge(c); err == nil {
+		t.Errorf("Expected an error merging different slot lengths")
+	}
+}
+
+func TestNewMultiWindowInvalid(t *testing.T) {
+	for _, windows := range [][]time.Duration{nil, {15 * time.Second}, {-time.Minute}} {
+		if _, err := NewMultiWindow(10*time.Second, windows...); err == nil {
+			t.Errorf("Expected an error for windows %v", windows)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package timer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// WireVersion is the version of the Dump formats written by this package.
+// Versions only ever add fields, and readers skip fields they do not
+// know, so a dump written by any version can be read by any other and
+// snapshots from mixed-version fleets can be merged centrally.
+//
+// Version 0 is the bare JSON object of snapshots keyed by name that
+// Registry.Snapshot encodes to; Version 1 adds the envelope and the
+// binary format.
+const WireVersion = 1
+
+// ErrInvalidDump is returned when a dump cannot be decoded.
+var ErrInvalidDump = errors.New("invalid snapshot dump")
+
+// Limits on decoded dumps, so hostile or corrupted input cannot exhaust
+// memory. Dumps exceeding them are rejected with ErrInvalidDump.
+const (
+	// MaxDumpSize is the largest encoded dump accepted, in bytes.
+	MaxDumpSize = 64 << 20
+	// MaxDumpSnapshots is the largest number of snapshots in a dump.
+	MaxDumpSnapshots = 1 << 20
+	// MaxNameLength is the longest timer name in a dump, in bytes.
+	MaxNameLength = 4 << 10
+)
+
+// dumpMagic starts the binary encoding of a Dump, telling it apart from
+// JSON.
+var dumpMagic = []byte("TMRD")
+
+// Dump is a versioned, self-describing set of named snapshots, for
+// persisting snapshots and shipping them between processes.
+//
+// Its JSON encoding is
+//
+//	{"version":1,"time":"2024-01-01T00:00:00Z","snapshots":{"db":{"count":1,...}}}
+//
+// and its binary encoding is the magic "TMRD" followed by protocol buffer
+// fields, the most compact form for large registries. Both may carry the
+// distributions of timers that have them, in JSON as an optional
+// "histograms" object, so readers can compute quantiles.
+type Dump struct {
+	// Version is the format version the dump was written with.
+	Version int `json:"version"`
+	// Time is when the snapshots were taken.
+	Time time.Time `json:"time"`
+	// Snapshots are keyed by timer name.
+	Snapshots map[string]Snapshot `json:"snapshots"`
+	// Histograms are the distributions of the timers with an ExpHistogram
+	// attached, keyed by timer name. Optional.
+	Histograms map[string]ExpHistogramSnapshot `json:"histograms,omitempty"`
+}
+
+// NewDump returns a Dump of snaps taken now, in the current version.
+func NewDump(snaps map[string]Snapshot) Dump {
+	return Dump{Version: WireVersion, Time: time.Now(), Snapshots: snaps}
+}
+
+// Dump returns a Dump of Snapshot taken now, with the distributions of
+// the exported timers that have an ExpHistogram attached. Export rules
+// filter and rename the distributions like the snapshots, merging those
+// of timers renamed to the same name.
+func (r *Registry) Dump() Dump {
+	d := NewDump(r.Snapshot())
+	hists := make(map[string]ExpHistogramSnapshot)
+	add := func(name string, t *Timer) {
+		aggs := t.AggregatorSnapshots()
+		for _, agg := range sortedNames(aggs) {
+			if h, ok := aggs[agg].(ExpHistogramSnapshot); ok {
+				hists[name] = h
+				return
+			}
+		}
+	}
+	s := r.store
+	s.mutex.RLock()
+	for name, t := range s.timers {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			add(rel, t)
+		}
+	}
+	for name, v := range s.vecs {
+		if rel, ok := strings.CutPrefix(name, r.prefix); ok {
+			v.eachChild(func(labels string, t *Timer) {
+				add(rel+"{"+labels+"}", t)
+			})
+		}
+	}
+	s.mutex.RUnlock()
+	if r.rules != nil {
+		hists = applyRules(r.rules, hists, ExpHistogramSnapshot.Merge)
+	}
+	if len(hists) > 0 {
+		d.Histograms = hists
+	}
+	return d
+}
+
+// Merge merges the snapshots and histograms of o into d by name, keeping
+// the later time and version.
+func (d *Dump) Merge(o Dump) {
+	if d.Snapshots == nil {
+		d.Snapshots = make(map[string]Snapshot, len(o.Snapshots))
+	}
+	for name, s := range o.Snapshots {
+		d.Snapshots[name] = d.Snapshots[name].Merge(s)
+	}
+	for name, h := range o.Histograms {
+		if d.Histograms == nil {
+			d.Histograms = make(map[string]ExpHistogramSnapshot, len(o.Histograms))
+		}
+		if prev, ok := d.Histograms[name]; ok {
+			h = prev.Merge(h)
+		}
+		d.Histograms[name] = h
+	}
+	if o.Time.After(d.Time) {
+		d.Time = o.Time
+	}
+	d.Version = max(d.Version, o.Version)
+}
+
+// UnmarshalJSON decodes a dump of any version, including version 0 bare
+// snapshot objects, which are converted to the current version. Unknown
+// fields are ignored.
+func (d *Dump) UnmarshalJSON(data []byte) error {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDump, err)
+	}
+	if !isEnvelope(probe) {
+		// version 0: a bare object of snapshots, which may include timers
+		// named version or snapshots
+		snaps := make(map[string]Snapshot, len(probe))
+		if err := json.Unmarshal(data, &snaps); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDump, err)
+		}
+		if err := checkDumpLimits(snaps); err != nil {
+			return err
+		}
+		*d = Dump{Snapshots: snaps}
+		return nil
+	}
+	type dump Dump // without methods
+	var v dump
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDump, err)
+	}
+	if err := checkDumpLimits(v.Snapshots); err != nil {
+		return err
+	}
+	for name, h := range v.Histograms {
+		if err := checkHistogram(name, h); err != nil {
+			return err
+		}
+	}
+	*d = Dump(v)
+	return nil
+}
+
+// isEnvelope reports whether the top-level fields of a JSON dump are those
+// of a version 1 or later envelope: a numeric version and an object of
+// snapshots, which is null for a Dump without snapshots. Snapshots in a
+// version 0 dump are objects, so a timer named version cannot be taken
+// for the envelope.
+func isEnvelope(probe map[string]json.RawMessage) bool {
+	version := bytes.TrimSpace(probe["version"])
+	if len(version) == 0 || (version[0] != '-' && (version[0] < '0' || version[0] > '9')) {
+		return false
+	}
+	snaps := bytes.TrimSpace(probe["snapshots"])
+	return bytes.HasPrefix(snaps, []byte("{")) || bytes.Equal(snaps, []byte("null"))
+}
+
+// checkDumpLimits rejects decoded snapshots exceeding MaxDumpSnapshots or
+// MaxNameLength.
+func checkDumpLimits(snaps map[string]Snapshot) error {
+	if len(snaps) > MaxDumpSnapshots {
+		return fmt.Errorf("%w: more than %d snapshots", ErrInvalidDump, MaxDumpSnapshots)
+	}
+	for name := range snaps {
+		if len(name) > MaxNameLength {
+			return fmt.Errorf("%w: name longer than %d bytes", ErrInvalidDump, MaxNameLength)
+		}
+	}
+	return nil
+}
+
+// checkHistogram rejects a decoded histogram with a scale or buckets no
+// ExpHistogram could have, which merging could not handle.
+func checkHistogram(name string, h ExpHistogramSnapshot) error {
+	switch {
+	case len(name) > MaxNameLength:
+		return fmt.Errorf("%w: name longer than %d bytes", ErrInvalidDump, MaxNameLength)
+	case h.Scale < expMinScale || h.Scale > expMaxScale:
+		return fmt.Errorf("%w: histogram %q has scale %d", ErrInvalidDump, name, h.Scale)
+	case int64(h.Positive.Offset)+int64(len(h.Positive.BucketCounts)) > math.MaxInt32:
+		return fmt.Errorf("%w: histogram %q has too many buckets", ErrInvalidDump, name)
+	}
+	return nil
+}
+
+// Binary field numbers. New fields get new numbers; numbers are never
+// reused.
+const (
+	dumpFieldVersion   = 1
+	dumpFieldTime      = 2
+	dumpFieldSnapshot  = 3 // repeated, a named snapshot
+	dumpFieldHistogram = 4 // repeated, a named histogram
+
+	namedFieldName      = 1
+	namedFieldSnapshot  = 2
+	namedFieldHistogram = 3
+
+	snapFieldCount         = 1
+	snapFieldMin           = 2
+	snapFieldMax           = 3
+	snapFieldSum           = 4
+	snapFieldSumOverflowed = 5
+	snapFieldPanicked      = 6
+	snapFieldSumEpoch      = 7
+	snapFieldSumRemainder  = 8
+
+	histFieldScale     = 1 // zigzag
+	histFieldCount     = 2
+	histFieldSum       = 3
+	histFieldMin       = 4
+	histFieldMax       = 5
+	histFieldZeroCount = 6
+	histFieldOffset    = 7 // zigzag
+	histFieldBuckets   = 8 // packed
+)
+
+// MarshalBinary encodes the dump in the binary format, with snapshots in
+// name order.
+func (d Dump) MarshalBinary() ([]byte, error) {
+	b := append([]byte(nil), dumpMagic...)
+	b = protoVarint(b, dumpFieldVersion, uint64(d.Version))
+	if !d.Time.IsZero() {
+		b = protoVarint(b, dumpFieldTime, uint64(d.Time.UnixNano()))
+	}
+	var entry, snap []byte
+	for _, name := range sortedNames(d.Snapshots) {
+		snap = appendSnapshotBinary(snap[:0], d.Snapshots[name])
+		entry = protoBytes(entry[:0], namedFieldName, []byte(name))
+		entry = protoBytes(entry, namedFieldSnapshot, snap)
+		b = protoBytes(b, dumpFieldSnapshot, entry)
+	}
+	for _, name := range sortedNames(d.Histograms) {
+		snap = appendHistogramBinary(snap[:0], d.Histograms[name])
+		entry = protoBytes(entry[:0], namedFieldName, []byte(name))
+		entry = protoBytes(entry, namedFieldHistogram, snap)
+		b = protoBytes(b, dumpFieldHistogram, entry)
+	}
+	return b, nil
+}
+
+// appendHistogramBinary appends the binary fields of h, omitting zeros.
+func appendHistogramBinary(b []byte, h ExpHistogramSnapshot) []byte {
+	for _, f := range [...]struct {
+		field int
+		v     uint64
+	}{
+		{histFieldScale, zigzag(int64(h.Scale))},
+		{histFieldCount, h.Count},
+		{histFieldSum, uint64(h.Sum)},
+		{histFieldMin, uint64(h.Min)},
+		{histFieldMax, uint64(h.Max)},
+		{histFieldZeroCount, h.ZeroCount},
+		{histFieldOffset, zigzag(int64(h.Positive.Offset))},
+	} {
+		if f.v != 0 {
+			b = protoVarint(b, f.field, f.v)
+		}
+	}
+	var packed []byte
+	for _, c := range h.Positive.BucketCounts {
+		packed = appendVarint(packed, c)
+	}
+	if len(packed) > 0 {
+		b = protoBytes(b, histFieldBuckets, packed)
+	}
+	return b
+}
+
+// zigzag maps signed integers to unsigned ones with small absolute values
+// staying small, as protocol buffer sint fields do.
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// unzigzag reverses zigzag.
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// appendSnapshotBinary appends the binary fields of s, omitting zeros.
+func appendSnapshotBinary(b []byte, s Snapshot) []byte {
+	for _, f := range [...]struct {
+		field int
+		v     uint64
+	}{
+		{snapFieldCount, s.Count},
+		{snapFieldMin, uint64(s.Min)},
+		{snapFieldMax, uint64(s.Max)},
+		{snapFieldSum, uint64(s.Sum)},
+		{snapFieldSumOverflowed, boolToUint(s.SumOverflowed)},
+		{snapFieldPanicked, s.Panicked},
+		{snapFieldSumEpoch, s.SumEpoch},
+		{snapFieldSumRemainder, uint64(s.SumRemainder)},
+	} {
+		if f.v != 0 {
+			b = protoVarint(b, f.field, f.v)
+		}
+	}
+	return b
+}
+
+func boolToUint(v bool) uint64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// UnmarshalBinary decodes a dump in the binary format of any version,
+// skipping unknown fields.
+func (d *Dump) UnmarshalBinary(data []byte) error {
+	data, ok := bytes.CutPrefix(data, dumpMagic)
+	if !ok {
+		return fmt.Errorf("%w: missing magic", ErrInvalidDump)
+	}
+	if len(data) > MaxDumpSize {
+		return fmt.Errorf("%w: larger than %d bytes", ErrInvalidDump, MaxDumpSize)
+	}
+	v := Dump{Snapshots: make(map[string]Snapshot)}
+	err := protoFields(data, func(field int, n uint64, payload []byte) error {
+		switch field {
+		case dumpFieldVersion:
+			v.Version = int(n)
+		case dumpFieldTime:
+			v.Time = time.Unix(0, int64(n))
+		case dumpFieldSnapshot:
+			name, s, err := decodeNamedSnapshot(payload)
+			if err != nil {
+				return err
+			}
+			if len(name) > MaxNameLength {
+				return fmt.Errorf("%w: name longer than %d bytes", ErrInvalidDump, MaxNameLength)
+			}
+			if prev, ok := v.Snapshots[name]; ok {
+				s = prev.Merge(s)
+			} else if len(v.Snapshots) == MaxDumpSnapshots {
+				return fmt.Errorf("%w: more than %d snapshots", ErrInvalidDump, MaxDumpSnapshots)
+			}
+			v.Snapshots[name] = s
+		case dumpFieldHistogram:
+			name, h, err := decodeNamedHistogram(payload)
+			if err != nil {
+				return err
+			}
+			if err := checkHistogram(name, h); err != nil {
+				return err
+			}
+			if v.Histograms == nil {
+				v.Histograms = make(map[string]ExpHistogramSnapshot)
+			}
+			if prev, ok := v.Histograms[name]; ok {
+				h = prev.Merge(h)
+			} else if len(v.Histograms) == MaxDumpSnapshots {
+				return fmt.Errorf("%w: more than %d histograms", ErrInvalidDump, MaxDumpSnapshots)
+			}
+			v.Histograms[name] = h
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func decodeNamedSnapshot(data []byte) (name string, s Snapshot, err error) {
+	err = protoFields(data, func(field int, n uint64, payload []byte) error {
+		switch field {
+		case namedFieldName:
+			name = string(payload)
+		case namedFieldSnapshot:
+			return protoFields(payload, func(field int, n uint64, _ []byte) error {
+				switch field {
+				case snapFieldCount:
+					s.Count = n
+				case snapFieldMin:
+					s.Min = time.Duration(n)
+				case snapFieldMax:
+					s.Max = time.Duration(n)
+				case snapFieldSum:
+					s.Sum = time.Duration(n)
+				case snapFieldSumOverflowed:
+					s.SumOverflowed = n != 0
+				case snapFieldPanicked:
+					s.Panicked = n
+				case snapFieldSumEpoch:
+					s.SumEpoch = n
+				case snapFieldSumRemainder:
+					s.SumRemainder = time.Duration(n)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return name, s, err
+}
+
+func decodeNamedHistogram(data []byte) (name string, h ExpHistogramSnapshot, err error) {
+	err = protoFields(data, func(field int, n uint64, payload []byte) error {
+		switch field {
+		case namedFieldName:
+			name = string(payload)
+		case namedFieldHistogram:
+			return protoFields(payload, func(field int, n uint64, payload []byte) error {
+				switch field {
+				case histFieldScale:
+					h.Scale = int32(unzigzag(n))
+				case histFieldCount:
+					h.Count = n
+				case histFieldSum:
+					h.Sum = time.Duration(n)
+				case histFieldMin:
+					h.Min = time.Duration(n)
+				case histFieldMax:
+					h.Max = time.Duration(n)
+				case histFieldZeroCount:
+					h.ZeroCount = n
+				case histFieldOffset:
+					h.Positive.Offset = int32(unzigzag(n))
+				case histFieldBuckets:
+					for len(payload) > 0 {
+						c, k := binary.Uvarint(payload)
+						if k <= 0 {
+							return fmt.Errorf("%w: bad varint", ErrInvalidDump)
+						}
+						h.Positive.BucketCounts = append(h.Positive.BucketCounts, c)
+						payload = payload[k:]
+					}
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return name, h, err
+}
+
+// ParseDump decodes a dump in either the binary or the JSON format, of
+// any version, within the dump limits. It is safe to use on untrusted
+// input.
+func ParseDump(data []byte) (Dump, error) {
+	var d Dump
+	if len(data) > MaxDumpSize {
+		return d, fmt.Errorf("%w: larger than %d bytes", ErrInvalidDump, MaxDumpSize)
+	}
+	var err error
+	if bytes.HasPrefix(data, dumpMagic) {
+		err = d.UnmarshalBinary(data)
+	} else if err = json.Unmarshal(data, &d); err != nil && !errors.Is(err, ErrInvalidDump) {
+		// syntax errors are reported before UnmarshalJSON runs
+		err = fmt.Errorf("%w: %v", ErrInvalidDump, err)
+	}
+	return d, err
+}
+
+// protoFields calls fn for every field of a protocol buffer message, with
+// the value of varint fields or the payload of length-delimited ones.
+// Fixed-width fields, which the dump formats do not use, are skipped, so
+// newer writers may add them. Other wire types are rejected.
+func protoFields(b []byte, fn func(field int, n uint64, payload []byte) error) error {
+	for len(b) > 0 {
+		key, k := binary.Uvarint(b)
+		if k <= 0 || key>>3 == 0 || key>>3 > 1<<29 {
+			return fmt.Errorf("%w: bad field key", ErrInvalidDump)
+		}
+		b = b[k:]
+		var n uint64
+		var payload []byte
+		switch key & 7 {
+		case 0:
+			if n, k = binary.Uvarint(b); k <= 0 {
+				return fmt.Errorf("%w: bad varint", ErrInvalidDump)
+			}
+			b = b[k:]
+		case 2:
+			size, k := binary.Uvarint(b)
+			if k <= 0 || size > uint64(len(b)-k) {
+				return fmt.Errorf("%w: bad length", ErrInvalidDump)
+			}
+			payload = b[k : k+int(size)]
+			b = b[k+int(size):]
+		case 1, 5:
+			size := 8 // fixed64
+			if key&7 == 5 {
+				size = 4 // fixed32
+			}
+			if len(b) < size {
+				return fmt.Errorf("%w: truncated fixed-width field", ErrInvalidDump)
+			}
+			b = b[size:]
+			continue
+		default:
+			return fmt.Errorf("%w: unsupported wire type %d", ErrInvalidDump, key&7)
+		}
+		if err := fn(int(key>>3), n, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoVarint appends a varint field.
+func protoVarint(b []byte, field int, v uint64) []byte {
+	b = appendVarint(b, uint64(field)<<3)
+	return appendVarint(b, v)
+}
+
+// protoBytes appends a length-delimited field.
+func protoBytes(b []byte, field int, v []byte) []byte {
+	b = appendVarint(b, uint64(field)<<3|2)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
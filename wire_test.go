@@ -0,0 +1,234 @@
+package timer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"maps"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func testDump() Dump {
+	return Dump{
+		Version: WireVersion,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Snapshots: map[string]Snapshot{
+			"db":                 {Count: 2, Min: time.Millisecond, Max: 3 * time.Millisecond, Sum: 4 * time.Millisecond},
+			`http{route="/a"}`:   {Count: 1, Min: time.Second, Max: time.Second, Sum: time.Second, Panicked: 1},
+			"overflowed.counter": {Count: 3, Sum: 1<<63 - 1, SumOverflowed: true},
+		},
+	}
+}
+
+func TestDumpBinaryRoundTrip(t *testing.T) {
+	d := testDump()
+	b, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseDump(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != d.Version || !got.Time.Equal(d.Time) || !maps.Equal(got.Snapshots, d.Snapshots) {
+		t.Errorf("ParseDump = %+v; want %+v", got, d)
+	}
+}
+
+func TestDumpJSONRoundTrip(t *testing.T) {
+	d := testDump()
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseDump(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != d.Version || !got.Time.Equal(d.Time) || !maps.Equal(got.Snapshots, d.Snapshots) {
+		t.Errorf("ParseDump = %+v; want %+v", got, d)
+	}
+}
+
+func TestDumpVersion0(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("db").Observe(time.Millisecond)
+	b, _ := json.Marshal(r.Snapshot())
+	d, err := ParseDump(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Version != 0 || d.Snapshots["db"].Count != 1 {
+		t.Errorf("Expected a version 0 dump with the db snapshot, got %+v", d)
+	}
+
+	// timers named like the envelope fields
+	r = NewRegistry()
+	r.GetOrCreate("version").Observe(time.Millisecond)
+	r.GetOrCreate("snapshots").Observe(2 * time.Millisecond)
+	b, _ = json.Marshal(r.Snapshot())
+	d, err = ParseDump(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Version != 0 || d.Snapshots["version"].Count != 1 || d.Snapshots["snapshots"].Sum != 2*time.Millisecond {
+		t.Errorf("Expected a version 0 dump with the version and snapshots timers, got %+v", d)
+	}
+
+	d, err = ParseDump([]byte(`{"version":1,"snapshots":null}`))
+	if err != nil || d.Version != 1 || len(d.Snapshots) != 0 {
+		t.Errorf("ParseDump = %+v, %v; want an empty version 1 dump", d, err)
+	}
+}
+
+func TestDumpUnknownFields(t *testing.T) {
+	d, err := ParseDump([]byte(`{"version":7,"host":"a","snapshots":{"db":{"count":1,"p99_ns":5}}}`))
+	if err != nil || d.Version != 7 || d.Snapshots["db"].Count != 1 {
+		t.Errorf("ParseDump = %+v, %v; want unknown JSON fields ignored", d, err)
+	}
+
+	b, _ := testDump().MarshalBinary()
+	b = protoVarint(b, 99, 42)
+	b = protoBytes(b, 98, []byte("future"))
+	b = append(binary.AppendUvarint(b, 97<<3|1), 1, 2, 3, 4, 5, 6, 7, 8) // fixed64
+	b = append(binary.AppendUvarint(b, 96<<3|5), 1, 2, 3, 4)             // fixed32
+	if d, err := ParseDump(b); err != nil || len(d.Snapshots) != 3 {
+		t.Errorf("ParseDump = %+v, %v; want unknown binary fields skipped", d, err)
+	}
+	if _, err := ParseDump(b[:len(b)-1]); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("Expected ErrInvalidDump for a truncated fixed32 field, got %v", err)
+	}
+}
+
+func TestDumpInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("TMRD\x0a\xff"),
+		[]byte("TMRD\x0b"),
+		[]byte(`{"version":"x"}`),
+		[]byte(`[1]`),
+	} {
+		if _, err := ParseDump(data); !errors.Is(err, ErrInvalidDump) {
+			t.Errorf("ParseDump(%q) = %v; want ErrInvalidDump", data, err)
+		}
+	}
+}
+
+func TestDumpMerge(t *testing.T) {
+	a := Dump{Version: 0, Snapshots: map[string]Snapshot{"db": {Count: 1, Min: 1, Max: 1, Sum: 1}}}
+	b := NewDump(map[string]Snapshot{"db": {Count: 1, Min: 3, Max: 3, Sum: 3}, "cache": {Count: 1}})
+	a.Merge(b)
+	if a.Version != WireVersion || !a.Time.Equal(b.Time) || a.Snapshots["db"].Count != 2 || len(a.Snapshots) != 2 {
+		t.Errorf("Merge = %+v", a)
+	}
+}
+
+func TestDumpLimits(t *testing.T) {
+	long := string(make([]byte, MaxNameLength+1))
+	b, _ := NewDump(map[string]Snapshot{long: {}}).MarshalBinary()
+	if _, err := ParseDump(b); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("Binary name over the limit: got %v; want ErrInvalidDump", err)
+	}
+	j, _ := json.Marshal(map[string]Snapshot{long: {}})
+	if _, err := ParseDump(j); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("JSON name over the limit: got %v; want ErrInvalidDump", err)
+	}
+	if _, err := ParseDump(make([]byte, MaxDumpSize+1)); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("Dump over the size limit: got %v; want ErrInvalidDump", err)
+	}
+}
+
+func TestDumpHistograms(t *testing.T) {
+	r := NewRegistry()
+	db := r.GetOrCreate("db")
+	if err := db.AddAggregator("hist", NewExpHistogram(0)); err != nil {
+		t.Fatal(err)
+	}
+	r.GetOrCreate("cache").Observe(time.Millisecond)
+	for i := range 100 {
+		db.Observe(time.Duration(i+1) * time.Millisecond)
+	}
+	d := r.Dump()
+	if len(d.Histograms) != 1 || d.Histograms["db"].Count != 100 {
+		t.Fatalf("Expected a histogram of db only, got %+v", d.Histograms)
+	}
+
+	b, _ := d.MarshalBinary()
+	j, _ := json.Marshal(d)
+	for _, data := range [][]byte{b, j} {
+		got, err := ParseDump(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Histograms, d.Histograms) {
+			t.Errorf("ParseDump histograms = %+v; want %+v", got.Histograms, d.Histograms)
+		}
+	}
+
+	d.Merge(r.Dump())
+	h := d.Histograms["db"]
+	if h.Count != 200 || h.Min != time.Millisecond || h.Max != 100*time.Millisecond {
+		t.Errorf("Merged histogram = %+v", h)
+	}
+	if p99 := h.Quantile(0.99); p99 < 95*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Errorf("Merged p99 = %v; want about 99ms", p99)
+	}
+
+	bad := Dump{Snapshots: map[string]Snapshot{}, Histograms: map[string]ExpHistogramSnapshot{"db": {Scale: 99}}}
+	b, _ = bad.MarshalBinary()
+	if _, err := ParseDump(b); !errors.Is(err, ErrInvalidDump) {
+		t.Errorf("Histogram with scale 99: got %v; want ErrInvalidDump", err)
+	}
+}
+
+func TestDumpHistogramsRenamed(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"db.primary", "db.replica", "cache"} {
+		if err := r.GetOrCreate(name).AddAggregator("hist", NewExpHistogram(0)); err != nil {
+			t.Fatal(err)
+		}
+		r.GetOrCreate(name).Observe(time.Millisecond)
+	}
+	view, err := r.WithExportRules(ExportRules{
+		Deny:   []string{"cache"},
+		Rename: []RenameRule{{Pattern: regexp.MustCompile(`^db\..*`), Replacement: "db"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := view.Dump()
+	if len(d.Histograms) != 1 || d.Histograms["db"].Count != 2 || d.Snapshots["db"].Count != 2 {
+		t.Errorf("Expected the renamed histograms merged under db, got %+v", d.Histograms)
+	}
+}
+
+func FuzzParseDump(f *testing.F) {
+	b, _ := testDump().MarshalBinary()
+	j, _ := json.Marshal(testDump())
+	f.Add(b)
+	f.Add(j)
+	f.Add([]byte(`{"db":{"count":1,"min_ns":1,"max_ns":1,"sum_ns":1}}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := ParseDump(data)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidDump) {
+				t.Fatalf("ParseDump returned %v, not wrapping ErrInvalidDump", err)
+			}
+			return
+		}
+		// whatever was accepted must survive a binary round trip
+		b, err := d.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := ParseDump(b)
+		if err != nil {
+			t.Fatalf("Re-parsing %q: %v", b, err)
+		}
+		if !maps.Equal(again.Snapshots, d.Snapshots) {
+			t.Fatalf("Round trip changed snapshots: %v != %v", again.Snapshots, d.Snapshots)
+		}
+	})
+}